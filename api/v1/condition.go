@@ -19,11 +19,10 @@ import (
 	"context"
 	"encoding/json"
 	"fmt"
+	"maps"
 	"math/big"
 	"reflect"
-	"regexp"
 	"runtime"
-	"sort"
 	"strconv"
 	"strings"
 	"time"
@@ -33,536 +32,125 @@ import (
 	"github.com/aws/aws-sdk-go/service/dynamodb"
 	"github.com/cloudspannerecosystem/dynamodb-adapter/models"
 	"github.com/cloudspannerecosystem/dynamodb-adapter/pkg/errors"
+	"github.com/cloudspannerecosystem/dynamodb-adapter/pkg/expression"
 	"github.com/cloudspannerecosystem/dynamodb-adapter/pkg/logger"
 	"github.com/cloudspannerecosystem/dynamodb-adapter/service/services"
 	"github.com/cloudspannerecosystem/dynamodb-adapter/utils"
 )
 
-var operations = map[string]string{"SET": "(?i) SET ", "DELETE": "(?i) DELETE ", "ADD": "(?i) ADD ", "REMOVE": "(?i) REMOVE "}
 var byteSliceType = reflect.TypeOf([]byte(nil))
 var defaultLevel int16 = 1
-var (
-	listRegex             = regexp.MustCompile(`list_append\(([^,]+),\s*([^\)]+)\)`)
-	listIndexRegex        = regexp.MustCompile(`(\w+)\[(\d+)\]`)
-	listUpdateAppendRegex = regexp.MustCompile(`(?i)list_append\(([^)]+),\s*(:\w+)\)`)
-)
-
-func between(value string, a string, b string) string {
-	// Get substring between two strings.
-	posFirst := strings.Index(value, a)
-	if posFirst == -1 {
-		return ""
-	}
-	posLast := strings.Index(value, b)
-	if posLast == -1 {
-		return ""
-	}
-	posFirstAdjusted := posFirst + len(a)
-	if posFirstAdjusted >= posLast {
-		return ""
-	}
-	return value[posFirstAdjusted:posLast]
-}
-
-func before(value string, a string) string {
-	// Get substring before a string.
-	pos := strings.Index(value, a)
-	if pos == -1 {
-		return ""
-	}
-	return value[0:pos]
-}
-
-func after(value string, a string) string {
-	// Get substring after a string.
-	pos := strings.LastIndex(value, a)
-	if pos == -1 {
-		return ""
-	}
-	adjustedPos := pos + len(a)
-	if adjustedPos >= len(value) {
-		return ""
-	}
-	return value[adjustedPos:]
-}
-
-func deleteEmpty(s []string) []string {
-	var r []string
-	for _, str := range s {
-		if str != "" {
-			r = append(r, str)
-		}
-	}
-	return r
-}
-
-// parseActionValue parses the action value string from an update expression,
-// processes it, and returns a map of attribute names to their new values,
-// along with an UpdateExpressionCondition if one was parsed.
-//
-// Args:
-//
-//	actionValue: The string representing the action to be performed (e.g., "count + 1, name = :newName").
-//	updateAtrr: The UpdateAttr struct containing expression attribute names and values.
-//	assignment: A boolean indicating whether the action is an assignment (true) or a set operation (false).
-//	oldRes: The existing item's values (map[string]interface{}), fetched from Spanner.
-//
-// Returns:
-//
-//	A map of attribute names to their new values (map[string]interface{}), and
-//	an UpdateExpressionCondition pointer, which might be nil if no condition was parsed.
-func parseActionValue(actionValue string, updateAtrr models.UpdateAttr, assignment bool, oldRes map[string]interface{}) (map[string]interface{}, *models.UpdateExpressionCondition) {
-	expr := parseUpdateExpresstion(actionValue)
-	if expr != nil {
-		actionValue = expr.ActionVal
-		expr.AddValues = make(map[string]float64)
-	}
 
-	resp := make(map[string]interface{})
-	var pairs []string
-	if strings.Contains(actionValue, "list_append") {
-		pairs = []string{actionValue}
-	} else {
-		pairs = strings.Split(actionValue, ",")
-	}
-	var v []string
-	for _, p := range pairs {
-		var addValue float64
-		status := false
-
-		// Handle addition (e.g., "count + 1")
-		if strings.Contains(p, "+") {
-			tokens := strings.Split(p, "+")
-			tokens[1] = strings.TrimSpace(tokens[1])
-			p = tokens[0]
-			v1, ok := updateAtrr.ExpressionAttributeMap[tokens[1]]
-			if ok {
-				switch v2 := v1.(type) { // TODO: Support timestamp and big.rat
-				case float64:
-					addValue = v2
-					status = true
-				case int64:
-					addValue = float64(v2)
-					status = true
-				}
-			}
-		}
-
-		// Handle subtraction (e.g., "count - 2")
-		if strings.Contains(p, "-") {
-			tokens := strings.Split(p, "-")
-			tokens[1] = strings.TrimSpace(tokens[1])
-			v1, ok := updateAtrr.ExpressionAttributeMap[tokens[1]]
-			if ok {
-				switch v2 := v1.(type) { // TODO: Support timestamp and big.rat
-				case float64:
-					addValue = -v2
-					status = true
-				case int64:
-					addValue = float64(-v2)
-					status = true
-				}
-			}
-		}
-
-		if strings.Contains(p, "list_append") {
-			matches := listRegex.FindStringSubmatch(p)
-			if len(matches) == 3 {
-				fieldName := matches[1]
-				newValueKey := matches[2]
-				// Fetch the old value from OldData
-				oldValue, _ := oldRes[fieldName].([]interface{})
-
-				// Fetch the new value from ExpressionAttributeMap
-				newValue, ok := updateAtrr.ExpressionAttributeMap[newValueKey]
-				if ok {
-					if newValueList, ok := newValue.([]interface{}); ok {
-						// Append new values to the old list
-						mergedList := append(oldValue, newValueList...)
-						resp[fieldName] = mergedList
-					} else {
-						// Handle case where newValue is a single element
-						mergedList := append(oldValue, newValue)
-						resp[fieldName] = mergedList
-					}
-
-				} else {
-					// If newValue is not found in ExpressionAttributeMap, use placeholder
-					resp[fieldName] = oldValue
-				}
-				continue
-			}
-		}
-
-		// Handle SET with list index, e.g., guid[1] = :new_value
-		if strings.Contains(p, "=") {
-			parts := strings.Split(p, "=")
-			if len(parts) != 2 {
-				continue
-			}
-
-			field, valueKey := strings.TrimSpace(parts[0]), strings.TrimSpace(parts[1])
-			value, ok := updateAtrr.ExpressionAttributeMap[valueKey]
-			if !ok {
-				continue
-			}
-			// Handle SET with list index, e.g., guid[1] = :new_value
-			matches := listIndexRegex.FindStringSubmatch(field)
-			if len(matches) == 3 {
-				listField := matches[1]
-				index, err := strconv.Atoi(matches[2])
-				if err != nil {
-					continue
-				}
-
-				// Retrieve the old list and modify the specified index
-				oldList, ok := oldRes[listField].([]interface{})
-				if !ok {
-					continue
-				}
-				// Validate index bounds
-				if index < 0 || index > len(oldList) {
-					continue
-				}
-
-				updatedList := make([]interface{}, len(oldList))
-				copy(updatedList, oldList)
-				if index == len(oldList) {
-					updatedList = append(updatedList, value) // Append new value
-				} else if index < len(oldList) {
-					updatedList[index] = value // Modify existing value
-				}
-				resp[listField] = updatedList
-				continue
-			}
-
-			// Handle simple SET assignments
-			resp[field] = value
-		}
-
-		// For assignment operations (SET)
-		if assignment {
-			v = strings.Split(p, " ")
-			v = deleteEmpty(v)
-		} else {
-			v = strings.Split(p, "=")
-		}
-
-		if len(v) < 2 {
-			continue
-		}
-
-		v[0] = strings.Replace(v[0], " ", "", -1)
-		v[1] = strings.Replace(v[1], " ", "", -1)
-
-		// Handle numeric additions
-		if status {
-			expr.AddValues[v[0]] = addValue
-		}
-
-		key := v[0]
-		if updateAtrr.ExpressionAttributeNames[v[0]] != "" {
-			key = updateAtrr.ExpressionAttributeNames[v[0]]
-		}
-		if strings.Contains(v[1], "%") {
-			for j := 0; j < len(expr.Field); j++ {
-				if strings.Contains(v[1], "%"+expr.Value[j]+"%") {
-					tmp, ok := updateAtrr.ExpressionAttributeMap[expr.Value[j]]
-					if ok {
-						resp[key] = tmp
-					}
-				}
-			}
-		} else {
-			tmp, ok := updateAtrr.ExpressionAttributeMap[v[1]]
-			if ok {
-				switch newValue := tmp.(type) {
-				case []string: // String Set
-					resp[key] = handleStringSet(oldRes, key, newValue, updateAtrr.UpdateExpression)
-				case []float64: // Number Set // TODO: Support timestamp and big.rat
-					resp[key] = handleNumberSet(oldRes, key, newValue, updateAtrr.UpdateExpression)
-				case [][]byte: // Binary Set
-					resp[key] = handleByteSet(oldRes, key, newValue, updateAtrr.UpdateExpression)
-				default: // Other types
-					resp[key] = tmp
-				}
-			}
-		}
-
-	}
-
-	// Merge primaryKeyMap and updateAttributes
-	for k, v := range updateAtrr.PrimaryKeyMap {
-		resp[k] = v
-	}
-	return resp, expr
-}
-
-// handleStringSet handles set operations (ADD/DELETE) for string sets.
-func handleStringSet(oldRes map[string]interface{}, key string, newValue []string, updateExpression string) []string {
-	if strSlice, ok := oldRes[key].([]string); ok {
-		if strings.Contains(updateExpression, "ADD") {
-			return utils.RemoveDuplicatesString(append(strSlice, newValue...))
-		} else if strings.Contains(updateExpression, "DELETE") {
-			return removeFromSlice(strSlice, newValue)
-		} else {
-			return utils.RemoveDuplicatesString(newValue)
-		}
-	} else {
-		return utils.RemoveDuplicatesString(newValue)
-	}
-}
-
-// handleNumberSet handles set operations for number sets (float64). // TODO: Support timestamp and big.rat
-func handleNumberSet(oldRes map[string]interface{}, key string, newValue []float64, updateExpression string) []float64 {
-	if floatSlice, ok := oldRes[key].([]float64); ok {
-		if strings.Contains(updateExpression, "ADD") {
-			return utils.RemoveDuplicatesFloat(append(floatSlice, newValue...))
-		} else if strings.Contains(updateExpression, "DELETE") {
-			return removeFromSlice(floatSlice, newValue)
-		} else {
-			return utils.RemoveDuplicatesFloat(newValue)
+// UpdateExpression applies the UpdateExpression of an UpdateItem request in a single read-write transaction:
+// the item is read, the ConditionExpression is evaluated and the updated item is written in it, so that all
+// the clauses are applied atomically and ReturnValues are computed from the item the update was applied to
+func UpdateExpression(ctx context.Context, updateAtrr models.UpdateAttr, svc services.Service) (interface{}, error) {
+	// the closure runs again when Spanner aborts the transaction, so that every attempt reads the item again
+	var output map[string]interface{}
+	err := svc.ReadWriteTransaction(ctx, func(ctx context.Context, txn *spanner.ReadWriteTransaction) error {
+		res, mut, err := TransactWriteUpdateExpression(ctx, updateAtrr, txn, svc)
+		if err != nil {
+			return err
 		}
-	} else { // No existing value
-		return utils.RemoveDuplicatesFloat(newValue)
+		output = res
+		return txn.BufferWrite([]*spanner.Mutation{mut})
+	})
+	if err != nil {
+		return nil, err
 	}
-}
-
-// handleByteSet handles set operations for byte sets (byte).
-func handleByteSet(oldRes map[string]interface{}, key string, newValue [][]byte, updateExpression string) [][]byte {
-	if byteSlice, ok := oldRes[key].([][]byte); ok {
-		if strings.Contains(updateExpression, "ADD") {
-			return utils.RemoveDuplicatesByteSlice(append(byteSlice, newValue...))
-		} else if strings.Contains(updateExpression, "DELETE") {
-			return removeFromByteSlice(byteSlice, newValue)
-		} else {
-			return utils.RemoveDuplicatesByteSlice(newValue)
-		}
-	} else { // No existing value
-		return utils.RemoveDuplicatesByteSlice(newValue)
+	if output == nil {
+		return nil, nil
 	}
+	return output, nil
 }
 
-// removeFromSlice removes elements from a generic slice.
-func removeFromSlice[T comparable](slice []T, toRemove []T) []T {
-	result := []T{}
-	removeMap := make(map[T]struct{}, len(toRemove))
-
-	for _, val := range toRemove {
-		removeMap[val] = struct{}{}
+// parseUpdate parses the UpdateExpression of the request, an update without expression only writes the key
+func parseUpdate(updateAtrr models.UpdateAttr) (*expression.Update, error) {
+	if strings.TrimSpace(updateAtrr.UpdateExpression) == "" {
+		return &expression.Update{}, nil
 	}
-
-	for _, val := range slice {
-		if _, found := removeMap[val]; !found {
-			result = append(result, val)
-		}
+	update, err := expression.ParseUpdate(updateAtrr.UpdateExpression, updateAtrr.ExpressionAttributeNames, updateAtrr.ExpressionAttributeMap)
+	if err != nil {
+		return nil, errors.New("ValidationException", err)
 	}
-	return result
+	return update, nil
 }
 
-// removeFromByteSlice removes elements from a byte slice.
-func removeFromByteSlice(slice [][]byte, toRemove [][]byte) [][]byte {
-	result := [][]byte{}
-
-	for _, item := range slice {
-		found := false
-		for _, rem := range toRemove {
-			if bytes.Equal(item, rem) { // Use bytes.Equal to compare byte slices
-				found = true
-				break
-			}
+// applyUpdate applies the update to the item read from Spanner, oldRes is the item as returned by the API
+// and spannerRow holds the plain JSON of its map columns. It returns the updated item and the attributes
+// to write, the removed attributes are written as nil.
+func applyUpdate(update *expression.Update, primaryKeyMap, oldRes, spannerRow map[string]interface{}) (map[string]interface{}, map[string]interface{}, error) {
+	attrs := update.Attributes()
+	for _, attr := range attrs {
+		if _, ok := primaryKeyMap[attr]; ok {
+			return nil, nil, errors.New("ValidationException", "One or more parameter values were invalid: Cannot update attribute "+attr+". This attribute is part of the key")
 		}
-		if !found {
-			result = append(result, item)
-		}
-	}
-	return result
-}
-
-func parseUpdateExpresstion(actionValue string) *models.UpdateExpressionCondition {
-	if actionValue == "" {
-		return nil
 	}
-	expr := new(models.UpdateExpressionCondition)
-	expr.ActionVal = actionValue
-	for {
-		index := strings.Index(expr.ActionVal, "if_not_exists")
-		if index == -1 {
-			index = strings.Index(expr.ActionVal, "if_exists")
-			if index == -1 {
-				break
-			}
-			expr.Condition = append(expr.Condition, "if_exists")
-		} else {
-			expr.Condition = append(expr.Condition, "if_not_exists")
-		}
-		if len(expr.Condition) == 0 {
-			break
-		}
-		start := -1
-		end := -1
-		for i := index; i < len(expr.ActionVal); i++ {
-			if expr.ActionVal[i] == '(' && start == -1 {
-				start = i
-			}
-			if expr.ActionVal[i] == ')' && end == -1 {
-				end = i
-				break
-			}
-		}
-
-		if start == -1 || end == -1 {
-			return nil
-		}
-
-		bracketValue := expr.ActionVal[start+1 : end]
-		tokens := strings.Split(bracketValue, ",")
-		expr.Field = append(expr.Field, strings.TrimSpace(tokens[0]))
-		v := strings.TrimSpace(tokens[1])
-		expr.Value = append(expr.Value, v)
-		expr.ActionVal = strings.Replace(expr.ActionVal, expr.ActionVal[index:end+1], "%"+v+"%", 1)
+	item := make(map[string]interface{}, len(oldRes)+len(primaryKeyMap))
+	maps.Copy(item, oldRes)
+	maps.Copy(item, spannerRow)
+	maps.Copy(item, primaryKeyMap)
+	newItem, err := update.Apply(item)
+	if err != nil {
+		return nil, nil, errors.New("ValidationException", err)
 	}
-	return expr
-}
-
-func performOperation(ctx context.Context, action string, actionValue string, updateAtrr models.UpdateAttr, oldRes map[string]interface{}, spannerRow map[string]interface{}) (map[string]interface{}, map[string]interface{}, error) {
-	switch {
-	case action == "DELETE":
-		// perform delete
-		m, expr := parseActionValue(actionValue, updateAtrr, true, oldRes)
-		res, err := services.Del(ctx, updateAtrr.TableName, updateAtrr.PrimaryKeyMap, updateAtrr.ConditionExpression, m, expr)
-		return res, m, err
-	case action == "SET":
-		if strings.Contains(actionValue, "list_append") {
-			// parse list_append operation here
-			m, expr := parseActionValue(actionValue, updateAtrr, false, oldRes)
-			res, err := services.Put(ctx, updateAtrr.TableName, m, expr, updateAtrr.ConditionExpression, updateAtrr.ExpressionAttributeMap, oldRes, spannerRow)
-			return res, m, err
-		}
-		// Update data in table
-		m, expr := parseActionValue(actionValue, updateAtrr, false, oldRes)
-		res, err := services.Put(ctx, updateAtrr.TableName, m, expr, updateAtrr.ConditionExpression, updateAtrr.ExpressionAttributeMap, oldRes, spannerRow)
-		return res, m, err
-	case action == "ADD":
-		// Add data in table
-		m, expr := parseActionValue(actionValue, updateAtrr, true, oldRes)
-		res, err := services.Add(ctx, updateAtrr.TableName, updateAtrr.PrimaryKeyMap, updateAtrr.ConditionExpression, m, updateAtrr.ExpressionAttributeMap, expr, oldRes)
-		return res, m, err
-
-	case action == "REMOVE":
-		res, err := services.Remove(ctx, updateAtrr.TableName, updateAtrr, actionValue, nil, oldRes)
-		return res, updateAtrr.PrimaryKeyMap, err
-	default:
+	putObj := maps.Clone(primaryKeyMap)
+	for _, attr := range attrs {
+		putObj[attr] = newItem[attr]
 	}
-	return nil, nil, nil
+	return newItem, putObj, nil
 }
 
-// UpdateExpression performs an expression
-func UpdateExpression(ctx context.Context, updateAtrr models.UpdateAttr, svc services.Service) (interface{}, error) {
-	updateAtrr.ExpressionAttributeNames = ChangeColumnToSpannerExpressionName(updateAtrr.TableName, updateAtrr.ExpressionAttributeNames)
-	var oldRes map[string]interface{}
-	var spannerRow map[string]interface{}
-	var err error
-	if updateAtrr.ReturnValues != "NONE" {
-		oldRes, spannerRow, err = svc.GetWithProjection(ctx, updateAtrr.TableName, updateAtrr.PrimaryKeyMap, "", nil)
-		if err != nil {
-			return nil, err
-		}
-	}
-	var resp map[string]interface{}
-	var actVal = make(map[string]interface{})
-	for k, v := range updateAtrr.ExpressionAttributeNames {
-		updateAtrr.UpdateExpression = strings.ReplaceAll(updateAtrr.UpdateExpression, k, v)
-		updateAtrr.ConditionExpression = strings.ReplaceAll(updateAtrr.ConditionExpression, k, v)
-	}
-	m := extractOperations(updateAtrr.UpdateExpression)
-
-	for k, v := range m {
-		res, acVal, err := performOperation(ctx, k, v, updateAtrr, oldRes, spannerRow)
-		resp = res
-		if err != nil {
-			return nil, err
-		}
-		for k, v := range acVal {
-			actVal[k] = v
-		}
-	}
-	logger.Debug(updateAtrr.ReturnValues, resp, oldRes)
-
-	var output map[string]interface{}
-	var errOutput error
+// updateOutput returns the Attributes of the response selected by ReturnValues, nil when there is none
+func updateOutput(updateAtrr models.UpdateAttr, update *expression.Update, oldRes, newItem map[string]interface{}) (map[string]interface{}, error) {
+	var attrs map[string]interface{}
 	switch updateAtrr.ReturnValues {
 	case "NONE":
 		return nil, nil
-	case "ALL_NEW":
-		output, errOutput = ChangeMaptoDynamoMap(ChangeResponseToOriginalColumns(updateAtrr.TableName, resp))
 	case "ALL_OLD":
-		if len(oldRes) == 0 {
-			return nil, err
-		}
-		output, errOutput = ChangeMaptoDynamoMap(ChangeResponseToOriginalColumns(updateAtrr.TableName, oldRes))
-	case "UPDATED_NEW":
-		var resVal = make(map[string]interface{})
-		for k := range actVal {
-			resVal[k] = resp[k]
-		}
-		output, errOutput = ChangeMaptoDynamoMap(ChangeResponseToOriginalColumns(updateAtrr.TableName, resVal))
+		attrs = oldRes
 	case "UPDATED_OLD":
-		if len(oldRes) == 0 {
-			return nil, err
-		}
-		var resVal = make(map[string]interface{})
-		for k := range actVal {
-			resVal[k] = oldRes[k]
-		}
-		output, errOutput = ChangeMaptoDynamoMap(ChangeResponseToOriginalColumns(updateAtrr.TableName, resVal))
-
+		attrs = selectAttributes(oldRes, update.Attributes())
+	case "UPDATED_NEW":
+		attrs = selectAttributes(itemResponse(updateAtrr.TableName, newItem), update.Attributes())
 	default:
-		output, errOutput = ChangeMaptoDynamoMap(ChangeResponseToOriginalColumns(updateAtrr.TableName, resp))
+		attrs = itemResponse(updateAtrr.TableName, newItem)
+	}
+	if len(attrs) == 0 {
+		return nil, nil
 	}
-	return map[string]interface{}{"Attributes": output}, errOutput
+	output, err := ChangeMaptoDynamoMap(ChangeResponseToOriginalColumns(updateAtrr.TableName, attrs))
+	return map[string]interface{}{"Attributes": output}, err
 }
 
-func extractOperations(updateExpression string) map[string]string {
-	if updateExpression == "" {
-		return nil
-	}
-	updateExpression = strings.TrimSpace(updateExpression)
-	updateExpression = " " + updateExpression
-	opsIndex := []int{}
-	opsSeq := map[int]string{}
-	for op, regex := range operations {
-		re := regexp.MustCompile(regex)
-		indexes := re.FindAllStringIndex(updateExpression, -1)
-		for _, index := range indexes {
-			opsSeq[index[0]] = op
-			opsIndex = append(opsIndex, index[0])
+// itemResponse wraps the maps of the map columns of an updated item as they are read from Spanner
+func itemResponse(tableName string, item map[string]interface{}) map[string]interface{} {
+	ddl := models.TableDDL[utils.ChangeTableNameForSpanner(tableName)]
+	res := make(map[string]interface{}, len(item))
+	for k, v := range item {
+		if _, ok := v.(map[string]interface{}); ok && ddl[k] == "M" {
+			v = utils.ParseNestedJSON(v)
 		}
-		updateExpression = re.ReplaceAllString(updateExpression, "%")
-	}
-
-	// Handle list_append explicitly
-	listAppendIndexes := listUpdateAppendRegex.FindAllStringIndex(updateExpression, -1)
-	for _, index := range listAppendIndexes {
-		opsSeq[index[0]] = "SET" // assuming list_append falls under a SET operation
-		opsIndex = append(opsIndex, index[0])
+		res[k] = v
 	}
+	return res
+}
 
-	sort.Ints(opsIndex)
-	tokens := strings.Split(updateExpression, "%")[1:]
-	ops := map[string]string{}
-	for i, index := range opsIndex {
-		if index < len(opsSeq) {
-			ops[strings.TrimSpace(opsSeq[index])] = tokens[i]
+// selectAttributes returns the attributes of the item which exist
+func selectAttributes(item map[string]interface{}, attrs []string) map[string]interface{} {
+	res := make(map[string]interface{})
+	for _, attr := range attrs {
+		if v, ok := item[attr]; ok && v != nil {
+			res[attr] = v
 		}
 	}
-	return ops
+	return res
 }
 
-// ReplaceHashRangeExpr replaces the attribute names from Filter Expression and Range Expression
+// ReplaceHashRangeExpr replaces the attribute names from Range Expression, the Filter Expression is
+// parsed with the attribute names
 func ReplaceHashRangeExpr(query models.Query) models.Query {
 	for k, v := range query.ExpressionAttributeNames {
-		query.FilterExp = strings.ReplaceAll(query.FilterExp, k, v)
 		query.RangeExp = strings.ReplaceAll(query.RangeExp, k, v)
 	}
 	return query
@@ -1106,110 +694,30 @@ func convertStruct(output map[string]interface{}, v reflect.Value) error {
 // with a transaction. If the condition expression fails, the transaction is
 // rolled back.
 func TransactWriteUpdateExpression(ctx context.Context, updateAtrr models.UpdateAttr, txn *spanner.ReadWriteTransaction, svc services.Service) (map[string]interface{}, *spanner.Mutation, error) {
-	// replace the placeholder column names with the original column names
+	// map the placeholder column names to the Spanner column names
 	updateAtrr.ExpressionAttributeNames = ChangeColumnToSpannerExpressionName(updateAtrr.TableName, updateAtrr.ExpressionAttributeNames)
-	// get the old item if it exists
-	var oldRes map[string]interface{}
-	var mut *spanner.Mutation
-	if updateAtrr.ReturnValues != "NONE" {
-		oldRes, _, _ = svc.GetWithProjection(ctx, updateAtrr.TableName, updateAtrr.PrimaryKeyMap, "", nil)
+	update, err := parseUpdate(updateAtrr)
+	if err != nil {
+		return nil, nil, err
 	}
-	// loop through each operation and perform it
-	var resp map[string]interface{}
-	var actVal = make(map[string]interface{})
-	var er error
-	for k, v := range updateAtrr.ExpressionAttributeNames {
-		updateAtrr.UpdateExpression = strings.ReplaceAll(updateAtrr.UpdateExpression, k, v)
-		updateAtrr.ConditionExpression = strings.ReplaceAll(updateAtrr.ConditionExpression, k, v)
+	// get the old item if it exists
+	oldRes, spannerRow, err := svc.GetWithProjection(ctx, updateAtrr.TableName, updateAtrr.PrimaryKeyMap, "", nil)
+	if err != nil {
+		return nil, nil, err
 	}
-	m := extractOperations(updateAtrr.UpdateExpression)
-	for k, v := range m {
-		res, acVal, mutation, err := TransactWritePerformOperation(ctx, k, v, updateAtrr, oldRes, txn, svc)
-		resp = res
-		er = err
-		mut = mutation
-		for k, v := range acVal {
-			actVal[k] = v
-		}
+	newItem, putObj, err := applyUpdate(update, updateAtrr.PrimaryKeyMap, oldRes, spannerRow)
+	if err != nil {
+		return nil, nil, err
 	}
-	// log the result of the transaction
-	logger.Debug(updateAtrr.ReturnValues, resp, oldRes, mut)
-	// return the result of the transaction
-	switch updateAtrr.ReturnValues {
-	case "NONE":
-		return nil, nil, er
-	case "ALL_NEW":
-		output, errOutput := ChangeMaptoDynamoMap(ChangeResponseToOriginalColumns(updateAtrr.TableName, resp))
-		return map[string]interface{}{"Attributes": output}, mut, errOutput
-	case "ALL_OLD":
-		if len(oldRes) == 0 {
-			return nil, nil, er
-		}
-		output, errOutput := ChangeMaptoDynamoMap(ChangeResponseToOriginalColumns(updateAtrr.TableName, oldRes))
-		return map[string]interface{}{"Attributes": output}, mut, errOutput
-	case "UPDATED_NEW":
-		var resVal = make(map[string]interface{})
-		for k := range actVal {
-			resVal[k] = resp[k]
-		}
-		output, errOutput := ChangeMaptoDynamoMap(ChangeResponseToOriginalColumns(updateAtrr.TableName, resVal))
-		return map[string]interface{}{"Attributes": output}, mut, errOutput
-	case "UPDATED_OLD":
-		if len(oldRes) == 0 {
-			return nil, nil, er
-		}
-		var resVal = make(map[string]interface{})
-		for k := range actVal {
-			resVal[k] = oldRes[k]
-		}
-		output, errOutput := ChangeMaptoDynamoMap(ChangeResponseToOriginalColumns(updateAtrr.TableName, resVal))
-		return map[string]interface{}{"Attributes": output}, mut, errOutput
-	default:
-		output, errOutput := ChangeMaptoDynamoMap(ChangeResponseToOriginalColumns(updateAtrr.TableName, resp))
-		return map[string]interface{}{"Attributes": output}, mut, errOutput
+	if oldRes == nil {
+		oldRes = map[string]interface{}{}
 	}
-}
-
-// TransactWritePerformOperation performs a transactional write operation based on the given action and actionValue.
-// The operation is performed based on the given action and actionValue. The UpdateAttr struct contains information about
-// the operation, such as the table name, primary key map, condition expression, and expression attribute map. The oldRes map
-// contains the old values of the item, and the txn is the Spanner transaction object.
-//
-// Args:
-//
-//	ctx: The context.Context object.
-//	action: The action to be performed (e.g., DELETE, SET, ADD, REMOVE).
-//	actionValue: The value of the action (e.g., "count + 1", "name = :newName").
-//	updateAtrr: The UpdateAttr struct containing information about the operation.
-//	oldRes: The old values of the item (map[string]interface{}).
-//	txn: The Spanner transaction object.
-//
-// Returns:
-//
-//	A map of attribute names to their new values (map[string]interface{}), the action value (map[string]interface{}),
-//	a Spanner mutation object, and an error.
-func TransactWritePerformOperation(ctx context.Context, action string, actionValue string, updateAtrr models.UpdateAttr, oldRes map[string]interface{}, txn *spanner.ReadWriteTransaction, svc services.Service) (map[string]interface{}, map[string]interface{}, *spanner.Mutation, error) {
-	switch {
-	case action == "DELETE":
-		// perform delete
-		m, expr := parseActionValue(actionValue, updateAtrr, true, oldRes)
-		res, mut, err := svc.TransactWriteDel(ctx, updateAtrr.TableName, updateAtrr.PrimaryKeyMap, updateAtrr.ConditionExpression, m, expr, txn)
-		return res, m, mut, err
-	case action == "SET":
-		// Update data in table
-		m, expr := parseActionValue(actionValue, updateAtrr, false, oldRes)
-		res, mut, err := svc.TransactWritePut(ctx, updateAtrr.TableName, m, expr, updateAtrr.ConditionExpression, updateAtrr.ExpressionAttributeMap, oldRes, txn)
-		return res, m, mut, err
-	case action == "ADD":
-		// Add data in table
-		m, expr := parseActionValue(actionValue, updateAtrr, true, oldRes)
-		res, mut, err := svc.TransactWriteAdd(ctx, updateAtrr.TableName, updateAtrr.PrimaryKeyMap, updateAtrr.ConditionExpression, m, updateAtrr.ExpressionAttributeMap, expr, oldRes, txn)
-		return res, m, mut, err
-
-	case action == "REMOVE":
-		res, mut, err := svc.TransactWriteRemove(ctx, updateAtrr.TableName, updateAtrr, actionValue, nil, oldRes, txn)
-		return res, updateAtrr.PrimaryKeyMap, mut, err
-	default:
+	_, mut, err := svc.TransactWritePut(ctx, updateAtrr.TableName, putObj, nil, updateAtrr.ConditionExpression, updateAtrr.ExpressionAttributeNames, updateAtrr.ExpressionAttributeMap, oldRes, txn)
+	if err != nil {
+		return nil, nil, err
 	}
-	return nil, nil, nil, nil
+	// log the result of the transaction
+	logger.Debug(updateAtrr.ReturnValues, newItem, oldRes, mut)
+	output, err := updateOutput(updateAtrr, update, oldRes, newItem)
+	return output, mut, err
 }
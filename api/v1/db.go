@@ -19,8 +19,10 @@ import (
 	"context"
 	"encoding/json"
 	"fmt"
+	"maps"
 	"net/http"
 	"regexp"
+	"slices"
 	"strings"
 	"time"
 
@@ -38,6 +40,7 @@ import (
 	"github.com/cloudspannerecosystem/dynamodb-adapter/storage"
 	"github.com/cloudspannerecosystem/dynamodb-adapter/utils"
 	"github.com/gin-gonic/gin"
+	"google.golang.org/grpc/codes"
 )
 
 type APIHandler struct {
@@ -50,34 +53,63 @@ func NewAPIHandler(svc services.Service) *APIHandler {
 
 // InitDBAPI - routes for apis
 func InitDBAPI(r *gin.Engine) {
-	r.Use(LogRequestResponse())
+	r.Use(AmzResponseHeaders(), GzipEncoding(), AmzJSONContentType(), LogRequestResponse())
 	svc := services.GetServiceInstance()
 	apiHandler := NewAPIHandler(svc)
+	r.POST("/", apiHandler.RouteRequest)
 	r.POST("/v1", apiHandler.RouteRequest)
 }
 
+// amzTargetAction returns the action of an X-Amz-Target header value such as
+// DynamoDB_20120810.GetItem, the service prefix is optional
+func amzTargetAction(amzTarget string) string {
+	return amzTarget[strings.LastIndex(amzTarget, ".")+1:]
+}
+
 // RouteRequest - parse X-Amz-Target and call appropiate handler
 func (h *APIHandler) RouteRequest(c *gin.Context) {
 	var amzTarget = c.Request.Header.Get("X-Amz-Target")
-	switch strings.Split(amzTarget, ".")[1] {
+	switch amzTargetAction(amzTarget) {
 	case "BatchGetItem":
 		h.BatchGetItem(c)
 	case "BatchWriteItem":
 		h.BatchWriteItem(c)
+	case "CreateTable":
+		h.CreateTable(c)
 	case "DeleteItem":
 		h.DeleteItem(c)
+	case "DeleteTable":
+		h.DeleteTable(c)
+	case "DescribeEndpoints":
+		h.DescribeEndpoints(c)
+	case "DescribeLimits":
+		h.DescribeLimits(c)
 	case "DescribeTable":
 		h.DescribeTable(c)
+	case "DescribeTimeToLive":
+		h.DescribeTimeToLive(c)
 	case "GetItem":
 		h.GetItemMeta(c)
+	case "ListTables":
+		h.ListTables(c)
+	case "ListTagsOfResource":
+		h.ListTagsOfResource(c)
 	case "PutItem":
 		h.UpdateMeta(c)
 	case "Query":
 		h.QueryTable(c)
 	case "Scan":
 		h.Scan(c)
+	case "TagResource":
+		h.TagResource(c)
+	case "UntagResource":
+		h.UntagResource(c)
 	case "UpdateItem":
 		h.Update(c)
+	case "UpdateTable":
+		h.UpdateTable(c)
+	case "UpdateTimeToLive":
+		h.UpdateTimeToLive(c)
 	case "TransactGetItems":
 		h.TransactGetItems(c)
 	case "TransactWriteItems":
@@ -85,7 +117,7 @@ func (h *APIHandler) RouteRequest(c *gin.Context) {
 	case "ExecuteStatement":
 		h.ExecuteStatement(c)
 	default:
-		c.JSON(errors.New("ValidationException", "Invalid X-Amz-Target header value of "+amzTarget).
+		c.JSON(errors.New("UnknownOperationException", "Invalid X-Amz-Target header value of "+amzTarget).
 			HTTPResponse("X-Amz-Target Header not supported"))
 	}
 }
@@ -125,7 +157,7 @@ func (h *APIHandler) UpdateMeta(c *gin.Context) {
 
 	otelInstance := models.GlobalProxy.OtelInst
 	if otelInstance == nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "OpenTelemetry instance not initialized"})
+		c.JSON(errors.New("InternalServerError", "OpenTelemetry instance not initialized").HTTPResponse(nil))
 		return
 	}
 	ctx, span := otelInstance.StartSpan(ctx, "PutItem", []attribute.KeyValue{
@@ -159,11 +191,9 @@ func (h *APIHandler) UpdateMeta(c *gin.Context) {
 			return
 		}
 
-		for k, v := range meta.ExpressionAttributeNames {
-			meta.ConditionExpression = strings.ReplaceAll(meta.ConditionExpression, k, v)
-		}
+		meta.ExpressionAttributeNames = ChangeColumnToSpannerExpressionName(meta.TableName, meta.ExpressionAttributeNames)
 
-		res, err := put(ctx, meta.TableName, meta.AttrMap, nil, meta.ConditionExpression, meta.ExpressionAttributeMap)
+		res, err := put(ctx, meta.TableName, meta.AttrMap, nil, meta.ConditionExpression, meta.ExpressionAttributeNames, meta.ExpressionAttributeMap)
 		if err != nil {
 			c.JSON(errors.HTTPResponse(err, meta))
 		} else {
@@ -181,7 +211,7 @@ func (h *APIHandler) UpdateMeta(c *gin.Context) {
 	}
 }
 
-func put(ctx context.Context, tableName string, putObj map[string]interface{}, expr *models.UpdateExpressionCondition, conditionExp string, expressionAttr map[string]interface{}) (map[string]interface{}, error) {
+func put(ctx context.Context, tableName string, putObj map[string]interface{}, expr *models.UpdateExpressionCondition, conditionExp string, expressionAttributeNames map[string]string, expressionAttr map[string]interface{}) (map[string]interface{}, error) {
 	tableConf, err := config.GetTableConf(tableName)
 	if err != nil {
 		return nil, err
@@ -195,7 +225,7 @@ func put(ctx context.Context, tableName string, putObj map[string]interface{}, e
 		return nil, err
 	}
 	logger.Debug("oldResp: ", oldResp)
-	newResp, err := services.Put(ctx, tableName, putObj, nil, conditionExp, expressionAttr, oldResp, spannerRow)
+	newResp, err := services.Put(ctx, tableName, putObj, nil, conditionExp, expressionAttributeNames, expressionAttr, oldResp, spannerRow)
 	logger.Debug("newResp: ", newResp)
 	if err != nil {
 		return nil, err
@@ -209,7 +239,7 @@ func queryResponse(query models.Query, c *gin.Context, svc services.Service) {
 	ctx := c.Request.Context()
 	otelInstance := models.GlobalProxy.OtelInst
 	if otelInstance == nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "OpenTelemetry instance not initialized"})
+		c.JSON(errors.New("InternalServerError", "OpenTelemetry instance not initialized").HTTPResponse(nil))
 		return
 	}
 	_, span := otelInstance.StartSpan(ctx, "UpdateMeta", []attribute.KeyValue{
@@ -223,10 +253,6 @@ func queryResponse(query models.Query, c *gin.Context, svc services.Service) {
 		return
 	}
 
-	if query.Select == "COUNT" {
-		query.OnlyCount = true
-	}
-
 	query.StartFrom, err1 = ConvertDynamoToMap(query.TableName, query.ExclusiveStartKey)
 	if err1 != nil {
 		c.JSON(errors.New("ValidationException", err1).HTTPResponse(query))
@@ -257,6 +283,7 @@ func queryResponse(query models.Query, c *gin.Context, svc services.Service) {
 			finalResult["Count"] = changedOutput["Count"]
 			finalResult["Items"] = changedOutput["Items"].(map[string]interface{})["L"]
 		}
+		finalResult["ScannedCount"] = changedOutput["ScannedCount"]
 
 		if _, ok := changedOutput["LastEvaluatedKey"]; ok && changedOutput["LastEvaluatedKey"] != nil {
 			finalResult["LastEvaluatedKey"], err = ChangeMaptoDynamoMap(changedOutput["LastEvaluatedKey"])
@@ -293,7 +320,7 @@ func (h *APIHandler) QueryTable(c *gin.Context) {
 	defer c.Request.Body.Close()
 	otelInstance := models.GlobalProxy.OtelInst
 	if otelInstance == nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "OpenTelemetry instance not initialized"})
+		c.JSON(errors.New("InternalServerError", "OpenTelemetry instance not initialized").HTTPResponse(nil))
 		return
 	}
 
@@ -334,7 +361,7 @@ func (h *APIHandler) GetItemMeta(c *gin.Context) {
 	defer c.Request.Body.Close()
 	otelInstance := models.GlobalProxy.OtelInst
 	if otelInstance == nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "OpenTelemetry instance not initialized"})
+		c.JSON(errors.New("InternalServerError", "OpenTelemetry instance not initialized").HTTPResponse(nil))
 		return
 	}
 	ctx, span := otelInstance.StartSpan(ctx, "GetItem", []attribute.KeyValue{
@@ -379,7 +406,8 @@ func (h *APIHandler) GetItemMeta(c *gin.Context) {
 		getItemMeta.ExpressionAttributeNames = ChangeColumnToSpannerExpressionName(getItemMeta.TableName, getItemMeta.ExpressionAttributeNames)
 		// Add annotation before calling the Get service
 		otelgo.AddAnnotation(ctx, "Calling GetWithProjection Service")
-		res, _, rowErr := h.svc.GetWithProjection(c.Request.Context(), getItemMeta.TableName, getItemMeta.PrimaryKeyMap, getItemMeta.ProjectionExpression, getItemMeta.ExpressionAttributeNames)
+		readCtx := storage.WithConsistentRead(ctx, getItemMeta.ConsistentRead)
+		res, _, rowErr := h.svc.GetWithProjection(readCtx, getItemMeta.TableName, getItemMeta.PrimaryKeyMap, getItemMeta.ProjectionExpression, getItemMeta.ExpressionAttributeNames)
 		if rowErr == nil {
 			// Add annotation for processing the response
 			otelgo.AddAnnotation(ctx, "Changing Response Columns to Original Format")
@@ -419,7 +447,7 @@ func (h *APIHandler) BatchGetItem(c *gin.Context) {
 	defer c.Request.Body.Close()
 	otelInstance := models.GlobalProxy.OtelInst
 	if otelInstance == nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "OpenTelemetry instance not initialized"})
+		c.JSON(errors.New("InternalServerError", "OpenTelemetry instance not initialized").HTTPResponse(nil))
 		return
 	}
 	ctx, span := otelInstance.StartSpan(ctx, "BatchGetItem", []attribute.KeyValue{
@@ -434,62 +462,89 @@ func (h *APIHandler) BatchGetItem(c *gin.Context) {
 	if err1 := c.ShouldBindJSON(&batchGetMeta); err1 != nil {
 		otelgo.AddAnnotation(ctx, "Validation failed for BatchGetItem request")
 		c.JSON(errors.New("ValidationException", err1).HTTPResponse(batchGetMeta))
-	} else {
-		otelgo.AddAnnotation(ctx, "BatchGetItem validation passed, processing batch get request")
-		output := make(map[string]interface{})
-
-		for k, v := range batchGetMeta.RequestItems {
-			batchGetWithProjectionMeta := v
-			batchGetWithProjectionMeta.TableName = k
-			logger.Debug(batchGetWithProjectionMeta)
-			if allow := h.svc.MayIReadOrWrite(batchGetWithProjectionMeta.TableName, false, ""); !allow {
-				c.JSON(http.StatusOK, []gin.H{})
-				return
-			}
-			var singleOutput interface{}
-			singleOutput, span, err = batchGetDataSingleTable(c.Request.Context(), batchGetWithProjectionMeta, span)
-			if err != nil {
-				otelgo.AddAnnotation(ctx, "BatchGetItem data retrieval failed")
-				c.JSON(errors.HTTPResponse(err, batchGetWithProjectionMeta))
-			}
-			currOutput, err := ChangeMaptoDynamoMap(singleOutput)
-			if err != nil {
-				otelgo.AddAnnotation(ctx, "BatchGetItem data transformation failed")
-				c.JSON(errors.HTTPResponse(err, batchGetWithProjectionMeta))
-			}
-			output[k] = currOutput["L"]
+		return
+	}
+	otelgo.AddAnnotation(ctx, "BatchGetItem validation passed, processing batch get request")
+
+	requests := make([]models.BatchGetWithProjectionMeta, 0, len(batchGetMeta.RequestItems))
+	for _, tableName := range slices.Sorted(maps.Keys(batchGetMeta.RequestItems)) {
+		req := batchGetMeta.RequestItems[tableName]
+		req.TableName = tableName
+		logger.Debug(req)
+		if allow := h.svc.MayIReadOrWrite(tableName, false, ""); !allow {
+			c.JSON(http.StatusOK, []gin.H{})
+			return
 		}
-
-		otelgo.AddAnnotation(ctx, "Successfully processed BatchGetItem request")
-		c.JSON(http.StatusOK, map[string]interface{}{"Responses": output})
-
-		if time.Since(startTime) > time.Second*1 {
-			go logger.Debug("BatchGetCall", batchGetMeta)
+		req.KeyArray, err = ConvertDynamoArrayToMapArray(tableName, req.Keys)
+		if err != nil {
+			c.JSON(errors.New("ValidationException", err).HTTPResponse(req))
+			return
 		}
+		req.ExpressionAttributeNames = ChangeColumnToSpannerExpressionName(tableName, req.ExpressionAttributeNames)
+		requests = append(requests, req)
 	}
-}
-func batchGetDataSingleTable(ctx context.Context, batchGetWithProjectionMeta models.BatchGetWithProjectionMeta, span trace.Span) (interface{}, trace.Span, error) {
-
-	var err1 error
-	batchGetWithProjectionMeta.KeyArray, err1 = ConvertDynamoArrayToMapArray(batchGetWithProjectionMeta.TableName, batchGetWithProjectionMeta.Keys)
-	if err1 != nil {
-		return nil, nil, errors.New("ValidationException", err1.Error())
+	if err = services.ValidateBatchGet(requests); err != nil {
+		c.JSON(errors.HTTPResponse(err, batchGetMeta))
+		return
 	}
-	batchGetWithProjectionMeta.ExpressionAttributeNames = ChangeColumnToSpannerExpressionName(batchGetWithProjectionMeta.TableName, batchGetWithProjectionMeta.ExpressionAttributeNames)
-	res, err2 := services.BatchGetWithProjection(ctx, batchGetWithProjectionMeta.TableName, batchGetWithProjectionMeta.KeyArray, batchGetWithProjectionMeta.ProjectionExpression, batchGetWithProjectionMeta.ExpressionAttributeNames)
 
+	res, err := services.BatchGetItems(ctx, requests)
+	if err != nil {
+		otelgo.AddAnnotation(ctx, "BatchGetItem data retrieval failed")
+		c.JSON(errors.HTTPResponse(err, batchGetMeta))
+		return
+	}
+	responses := make(map[string]interface{}, len(res.Responses))
+	for tableName, items := range res.Responses {
+		output, err := ChangeMaptoDynamoMap(ChangesArrayResponseToOriginalColumns(tableName, items))
+		if err != nil {
+			otelgo.AddAnnotation(ctx, "BatchGetItem data transformation failed")
+			c.JSON(errors.HTTPResponse(err, batchGetMeta))
+			return
+		}
+		responses[tableName] = output["L"]
+	}
 	if span != nil {
 		span.SetAttributes(
-			attribute.String("table", batchGetWithProjectionMeta.TableName),
-			attribute.Int("batchRequestCount", len(batchGetWithProjectionMeta.Keys)),
-			attribute.Int("batchResponseCount", len(res)),
+			attribute.Int("batchTableCount", len(requests)),
+			attribute.Int("batchUnprocessedTableCount", len(res.UnprocessedKeys)),
 		)
 	}
 
-	if err2 != nil {
-		return nil, span, err2
+	otelgo.AddAnnotation(ctx, "Successfully processed BatchGetItem request")
+	c.JSON(http.StatusOK, map[string]interface{}{
+		"Responses":       responses,
+		"UnprocessedKeys": unprocessedKeys(batchGetMeta, res.UnprocessedKeys),
+	})
+
+	if time.Since(startTime) > time.Second*1 {
+		go logger.Debug("BatchGetCall", batchGetMeta)
+	}
+}
+
+// unprocessedKeys builds the UnprocessedKeys of a BatchGetItem from the requested keys, so that the
+// client can send them again as they are
+func unprocessedKeys(batchGetMeta models.BatchGetMeta, keyIdxs map[string][]int) map[string]interface{} {
+	unprocessed := make(map[string]interface{}, len(keyIdxs))
+	for tableName, idxs := range keyIdxs {
+		req := batchGetMeta.RequestItems[tableName]
+		keys := make([]map[string]*dynamodb.AttributeValue, 0, len(idxs))
+		for _, i := range idxs {
+			keys = append(keys, req.Keys[i])
+		}
+		item := map[string]interface{}{"Keys": keys}
+		if req.ProjectionExpression != "" {
+			item["ProjectionExpression"] = req.ProjectionExpression
+		}
+		if len(req.ExpressionAttributeNames) > 0 {
+			item["ExpressionAttributeNames"] = req.ExpressionAttributeNames
+		}
+		if req.ConsistentRead {
+			item["ConsistentRead"] = true
+		}
+		unprocessed[tableName] = item
 	}
-	return ChangesArrayResponseToOriginalColumns(batchGetWithProjectionMeta.TableName, res), span, nil
+	return unprocessed
 }
 
 // DeleteItem  ...
@@ -510,7 +565,7 @@ func (h *APIHandler) DeleteItem(c *gin.Context) {
 	defer c.Request.Body.Close()
 	otelInstance := models.GlobalProxy.OtelInst
 	if otelInstance == nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "OpenTelemetry instance not initialized"})
+		c.JSON(errors.New("InternalServerError", "OpenTelemetry instance not initialized").HTTPResponse(nil))
 		return
 	}
 	ctx, span := otelInstance.StartSpan(ctx, "DeleteItem", []attribute.KeyValue{
@@ -554,14 +609,12 @@ func (h *APIHandler) DeleteItem(c *gin.Context) {
 			return
 		}
 
-		for k, v := range deleteItem.ExpressionAttributeNames {
-			deleteItem.ConditionExpression = strings.ReplaceAll(deleteItem.ConditionExpression, k, v)
-		}
+		deleteItem.ExpressionAttributeNames = ChangeColumnToSpannerExpressionName(deleteItem.TableName, deleteItem.ExpressionAttributeNames)
 
 		otelgo.AddAnnotation(ctx, "Fetching current item for deletion")
 		oldRes, _, _ := h.svc.GetWithProjection(c.Request.Context(), deleteItem.TableName, deleteItem.PrimaryKeyMap, "", nil)
 		otelgo.AddAnnotation(ctx, "Attempting to delete item")
-		err := services.Delete(c.Request.Context(), deleteItem.TableName, deleteItem.PrimaryKeyMap, deleteItem.ConditionExpression, deleteItem.ExpressionAttributeMap, nil)
+		err := services.Delete(c.Request.Context(), deleteItem.TableName, deleteItem.PrimaryKeyMap, deleteItem.ConditionExpression, deleteItem.ExpressionAttributeNames, deleteItem.ExpressionAttributeMap, nil)
 		if err == nil {
 			otelgo.AddAnnotation(ctx, "Item deleted successfully")
 			output, _ := ChangeMaptoDynamoMap(ChangeResponseToOriginalColumns(deleteItem.TableName, oldRes))
@@ -591,7 +644,7 @@ func (h *APIHandler) Scan(c *gin.Context) {
 	defer c.Request.Body.Close()
 	otelInstance := models.GlobalProxy.OtelInst
 	if otelInstance == nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "OpenTelemetry instance not initialized"})
+		c.JSON(errors.New("InternalServerError", "OpenTelemetry instance not initialized").HTTPResponse(nil))
 		return
 	}
 
@@ -624,10 +677,7 @@ func (h *APIHandler) Scan(c *gin.Context) {
 			c.JSON(errors.New("ValidationException", err).HTTPResponse(meta))
 			return
 		}
-		if meta.Select == "COUNT" {
-			meta.OnlyCount = true
-		}
-
+		meta.ExpressionAttributeNames = ChangeColumnToSpannerExpressionName(meta.TableName, meta.ExpressionAttributeNames)
 		logger.Debug(meta)
 		otelgo.AddAnnotation(ctx, "Calling Scan Service")
 		res, err := services.Scan(ctx, meta)
@@ -674,7 +724,7 @@ func (h *APIHandler) Update(c *gin.Context) {
 	defer c.Request.Body.Close()
 	otelInstance := models.GlobalProxy.OtelInst
 	if otelInstance == nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "OpenTelemetry instance not initialized"})
+		c.JSON(errors.New("InternalServerError", "OpenTelemetry instance not initialized").HTTPResponse(nil))
 		return
 	}
 
@@ -747,7 +797,7 @@ func (h *APIHandler) BatchWriteItem(c *gin.Context) {
 	defer c.Request.Body.Close()
 	otelInstance := models.GlobalProxy.OtelInst
 	if otelInstance == nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "OpenTelemetry instance not initialized"})
+		c.JSON(errors.New("InternalServerError", "OpenTelemetry instance not initialized").HTTPResponse(nil))
 		return
 	}
 
@@ -760,105 +810,80 @@ func (h *APIHandler) BatchWriteItem(c *gin.Context) {
 	defer recordMetrics(ctx, models.GlobalProxy.OtelInst, "BatchWriteItem", startTime, err)
 
 	var batchWriteItem models.BatchWriteItem
-	var unprocessedBatchWriteItems models.BatchWriteItemResponse
-
-	// Clients expect UnprocessedItems to be present even if empty
-	unprocessedBatchWriteItems.UnprocessedItems = map[string][]models.BatchWriteSubItems{}
-
 	if err1 := c.ShouldBindJSON(&batchWriteItem); err1 != nil {
 		otelgo.AddAnnotation(ctx, "Validation failed for BatchWriteItem request")
 		c.JSON(errors.New("ValidationException", err1).HTTPResponse(batchWriteItem))
-	} else {
-		otelgo.AddAnnotation(ctx, "BatchWriteItem validation passed, processing batch write request")
-		for key, _ := range batchWriteItem.RequestItems {
-			if _, tableErr := config.GetTableConf(key); tableErr != nil {
-				c.JSON(errors.New("ResourceNotFoundException", "Requested resource not found: "+key).HTTPResponse(batchWriteItem))
-				return
-			}
+		return
+	}
+	otelgo.AddAnnotation(ctx, "BatchWriteItem validation passed, processing batch write request")
+	tableNames := slices.Sorted(maps.Keys(batchWriteItem.RequestItems))
+	for _, tableName := range tableNames {
+		if _, tableErr := config.GetTableConf(tableName); tableErr != nil {
+			c.JSON(errors.New("ResourceNotFoundException", "Requested resource not found: "+tableName).HTTPResponse(batchWriteItem))
+			return
 		}
-		for key, value := range batchWriteItem.RequestItems {
-			if allow := h.svc.MayIReadOrWrite(key, true, "BatchWriteItem"); !allow {
-				c.JSON(http.StatusOK, gin.H{})
-				return
-			}
-			var putData models.BatchMetaUpdate
-			putData.TableName = key
-
-			var deleteData models.BulkDelete
-			deleteData.TableName = key
-
-			for _, v := range value {
-				if v.PutReq.Item != nil {
-					putData.DynamoObject = append(putData.DynamoObject, v.PutReq.Item)
-				}
+	}
 
-				if v.DelReq.Key != nil {
-					deleteData.DynamoObject = append(deleteData.DynamoObject, v.DelReq.Key)
-				}
+	// the writes of all the tables, in the order of the tables, with the request of every write, so that
+	// the unprocessed writes can be sent back as they are
+	var writes []models.BatchWriteMeta
+	var requests []models.BatchWriteSubItems
+	for _, tableName := range tableNames {
+		if allow := h.svc.MayIReadOrWrite(tableName, true, "BatchWriteItem"); !allow {
+			c.JSON(http.StatusOK, gin.H{})
+			return
+		}
+		for _, req := range batchWriteItem.RequestItems[tableName] {
+			write := models.BatchWriteMeta{TableName: tableName}
+			if req.PutReq.Item != nil {
+				write.Item, err = convertBatchWriteItem(tableName, req.PutReq.Item)
 			}
-
-			if putData.DynamoObject != nil {
-				err = batchUpdateItems(c.Request.Context(), putData)
-				if err != nil {
-					for _, v := range value {
-						if v.PutReq.Item != nil {
-							// Ensure that the specific key's slice is initialized
-							if _, exists := unprocessedBatchWriteItems.UnprocessedItems[key]; !exists {
-								unprocessedBatchWriteItems.UnprocessedItems[key] = []models.BatchWriteSubItems{} // Instantiate the slice
-							}
-
-							unprocessedBatchWriteItems.UnprocessedItems[key] = append(unprocessedBatchWriteItems.UnprocessedItems[key], v)
-						}
-					}
-				}
+			if err == nil && req.DelReq.Key != nil {
+				write.Key, err = convertBatchWriteItem(tableName, req.DelReq.Key)
 			}
-
-			if deleteData.DynamoObject != nil {
-				err = batchDeleteItems(c.Request.Context(), deleteData)
-				if err != nil {
-					for _, v := range value {
-						if v.DelReq.Key != nil {
-							unprocessedBatchWriteItems.UnprocessedItems[key] = append(unprocessedBatchWriteItems.UnprocessedItems[key], v)
-						}
-					}
-				}
+			if err != nil {
+				c.JSON(errors.New("ValidationException", err).HTTPResponse(batchWriteItem))
+				return
 			}
+			writes = append(writes, write)
+			requests = append(requests, req)
 		}
-
-		otelgo.AddAnnotation(ctx, "Successfully processed BatchWriteItem request")
-		if span != nil {
-			span.SetAttributes(
-				attribute.Int("unprocessedBatchWriteItems", len(unprocessedBatchWriteItems.UnprocessedItems)),
-			)
-		}
-		c.JSON(http.StatusOK, unprocessedBatchWriteItems)
 	}
-}
+	if err = services.ValidateBatchWrite(tableNames, writes); err != nil {
+		c.JSON(errors.HTTPResponse(err, batchWriteItem))
+		return
+	}
 
-func batchDeleteItems(con context.Context, bulkDelete models.BulkDelete) error {
-	var err error
-	bulkDelete.PrimaryKeyMapArray, err = ConvertDynamoArrayToMapArray(bulkDelete.TableName, bulkDelete.DynamoObject)
+	unprocessedIdxs, err := services.BatchWriteItems(ctx, writes)
 	if err != nil {
-		return err
+		otelgo.AddAnnotation(ctx, "BatchWriteItem write failed")
+		c.JSON(errors.HTTPResponse(err, batchWriteItem))
+		return
 	}
-	err = services.BatchDelete(con, bulkDelete.TableName, bulkDelete.PrimaryKeyMapArray)
-	if err != nil {
-		return err
+	// Clients expect UnprocessedItems to be present even if empty
+	unprocessedBatchWriteItems := models.BatchWriteItemResponse{UnprocessedItems: map[string][]models.BatchWriteSubItems{}}
+	for _, i := range unprocessedIdxs {
+		tableName := writes[i].TableName
+		unprocessedBatchWriteItems.UnprocessedItems[tableName] = append(unprocessedBatchWriteItems.UnprocessedItems[tableName], requests[i])
 	}
-	return nil
-}
 
-func batchUpdateItems(con context.Context, batchMetaUpdate models.BatchMetaUpdate) error {
-	var err error
-	batchMetaUpdate.ArrAttrMap, err = ConvertDynamoArrayToMapArray(batchMetaUpdate.TableName, batchMetaUpdate.DynamoObject)
-	if err != nil {
-		return err
+	otelgo.AddAnnotation(ctx, "Successfully processed BatchWriteItem request")
+	if span != nil {
+		span.SetAttributes(
+			attribute.Int("batchWriteCount", len(writes)),
+			attribute.Int("unprocessedBatchWriteItems", len(unprocessedIdxs)),
+		)
 	}
-	err = services.BatchPut(con, batchMetaUpdate.TableName, batchMetaUpdate.ArrAttrMap, nil)
+	c.JSON(http.StatusOK, unprocessedBatchWriteItems)
+}
+
+// convertBatchWriteItem converts the item of a put, or the key of a delete, of a BatchWriteItem
+func convertBatchWriteItem(tableName string, item map[string]*dynamodb.AttributeValue) (map[string]interface{}, error) {
+	rs, err := ConvertDynamoArrayToMapArray(tableName, []map[string]*dynamodb.AttributeValue{item})
 	if err != nil {
-		return err
+		return nil, err
 	}
-	return nil
+	return rs[0], nil
 }
 
 // TransactGetItems to get with projections
@@ -872,100 +897,94 @@ func batchUpdateItems(con context.Context, batchMetaUpdate models.BatchMetaUpdat
 // @Router /transactGetItems/ [post]
 // @Failure 401 {object} gin.H "{"errorMessage":"API access not allowed","errorCode": "E0005"}"
 func (h *APIHandler) TransactGetItems(c *gin.Context) {
-	start := time.Now()
+	startTime := time.Now()
 	ctx := c.Request.Context()
+	var err error
+	defer PanicHandler(c)
+	defer c.Request.Body.Close()
+	otelInstance := models.GlobalProxy.OtelInst
+	if otelInstance == nil {
+		c.JSON(errors.New("InternalServerError", "OpenTelemetry instance not initialized").HTTPResponse(nil))
+		return
+	}
+	ctx, span := otelInstance.StartSpan(ctx, "TransactGetItems", []attribute.KeyValue{
+		attribute.String("request.method", c.Request.Method),
+		attribute.String("request.url", c.Request.URL.Path),
+	})
+	defer models.GlobalProxy.OtelInst.EndSpan(span)
+	span = addParentSpanID(c, span)
+	defer recordMetrics(ctx, models.GlobalProxy.OtelInst, "TransactGetItems", startTime, err)
 
-	// Parse request body into struct
 	var transactGetMeta models.TransactGetItemsRequest
-	if err := c.ShouldBindJSON(&transactGetMeta); err != nil {
-		c.JSON(errors.New("ValidationException", err).HTTPResponse(transactGetMeta))
+	if err1 := c.ShouldBindJSON(&transactGetMeta); err1 != nil {
+		c.JSON(errors.New("ValidationException", err1).HTTPResponse(transactGetMeta))
 		return
 	}
-	// Iterate over each transact item
-	for _, transactItem := range transactGetMeta.TransactItems {
-		getRequest := transactItem.Get
-
-		// Validate read permissions
-		if allow := h.svc.MayIReadOrWrite(getRequest.TableName, false, ""); !allow {
+	gets, err := transactGetKeys(transactGetMeta.TransactItems)
+	if err == nil {
+		err = services.ValidateTransactGet(gets)
+	}
+	if err != nil {
+		c.JSON(errors.HTTPResponse(err, transactGetMeta))
+		return
+	}
+	for _, get := range gets {
+		if allow := h.svc.MayIReadOrWrite(get.TableName, false, ""); !allow {
 			c.JSON(http.StatusOK, gin.H{"Responses": []gin.H{}})
 			return
 		}
 	}
-	// Fetch data from Spanner
-	output, err := transactGetDataSingleTable(ctx, transactGetMeta, h.svc)
+
+	items, err := h.svc.TransactGetItems(ctx, gets)
 	if err != nil {
 		c.JSON(errors.HTTPResponse(err, transactGetMeta))
 		return
 	}
-
-	var currOutput []models.ResponseItem
-	for _, row := range output {
-		if row["Item"] != nil {
-			dataMap, ok := row["Item"].(map[string]interface{})
-			if !ok {
-				c.JSON(errors.New("Invalid data format").HTTPResponse(transactGetMeta))
-				return
-			}
-			convertedMap, err := ChangeMaptoDynamoMap(dataMap)
-			if err != nil {
-				c.JSON(errors.HTTPResponse(err, transactGetMeta))
-				return
-			}
-			currOutput = append(currOutput, models.ResponseItem{
-				TableName: row["TableName"],
-				Item:      map[string]interface{}{"L": []interface{}{convertedMap}},
-			})
-		} else {
-			c.JSON(errors.New("ValidationException").HTTPResponse(transactGetMeta))
+	// the responses are in the order of the gets, an empty entry for an item which does not exist
+	responses := make([]models.ResponseItem, len(items))
+	for i, item := range items {
+		if item == nil {
+			continue
+		}
+		convertedMap, err := ChangeMaptoDynamoMap(ChangeResponseToOriginalColumns(gets[i].TableName, item))
+		if err != nil {
+			c.JSON(errors.HTTPResponse(err, transactGetMeta))
+			return
+		}
+		responses[i] = models.ResponseItem{
+			TableName: gets[i].TableName,
+			Item:      map[string]interface{}{"L": []interface{}{convertedMap}},
 		}
 	}
-	// Send final response
-	c.JSON(http.StatusOK, gin.H{"Responses": currOutput})
+	if span != nil {
+		span.SetAttributes(attribute.Int("transactItemCount", len(gets)))
+	}
+	c.JSON(http.StatusOK, gin.H{"Responses": responses})
 
 	// Log slow transactions
-	if time.Since(start) > time.Second*1 {
+	if time.Since(startTime) > time.Second*1 {
 		go logger.Debug("TransactGetCall", transactGetMeta)
 	}
 }
 
-// TransactGetDataSingleTable - fetch data from Spanner using Spanner TransactGetItems function
-//
-// This function takes a context, a TransactGetItemsRequest, a service, and returns a slice of maps and an error.
-// The function first gets the table configuration using the table name from the TransactGetItemsRequest.
-// Then it converts the projection expression to a slice of column names.
-// Then it creates two slices, pValues and sValues, to store the partition key and the sort key values.
-// Finally, it calls the SpannerTransactGetItems function on the Storage interface to fetch the data from Spanner.
-func transactGetDataSingleTable(ctx context.Context, transactGetMeta models.TransactGetItemsRequest, svc services.Service) ([]map[string]interface{}, error) {
-	// Convert DynamoDB Keys to Spanner KeyArray
-	var err1 error
-
-	tableProjectionCols := make(map[string][]string)
-	pValues := make(map[string]interface{})
-	sValues := make(map[string]interface{})
-
-	// Iterate over the TransactGetItemsRequest
-	for _, transactItem := range transactGetMeta.TransactItems {
-		// Get the GetItemRequest
+// transactGetKeys converts the key of every get of a TransactGetItems, in request order, so that the gets
+// can be validated before they are read
+func transactGetKeys(transactItems []models.TransactGetItem) ([]models.GetItemMeta, error) {
+	gets := make([]models.GetItemMeta, len(transactItems))
+	for i, transactItem := range transactItems {
 		getRequest := transactItem.Get
-
-		// Convert the DynamoDB KeyArray to a Spanner-style KeyArray
-		getRequest.KeyArray, err1 = ConvertDynamoArrayToMapArray(getRequest.TableName, []map[string]*dynamodb.AttributeValue{getRequest.Keys})
-		if err1 != nil {
-			return nil, nil
+		key, err := ConvertDynamoToMap(getRequest.TableName, getRequest.Keys)
+		if err != nil {
+			return nil, errors.New("ValidationException", err)
+		}
+		gets[i] = models.GetItemMeta{
+			TableName:                getRequest.TableName,
+			PrimaryKeyMap:            key,
+			ProjectionExpression:     getRequest.ProjectionExpression,
+			ExpressionAttributeNames: ChangeColumnToSpannerExpressionName(getRequest.TableName, getRequest.ExpressionAttributeNames),
 		}
-
-		// Change ExpressionAttributeNames to Spanner-style
-		getRequest.ExpressionAttributeNames = ChangeColumnToSpannerExpressionName(getRequest.TableName, getRequest.ExpressionAttributeNames)
-
-		// Get the projection columns
-		projectionCols, pvalues, svalues, _ := svc.TransactGetProjectionCols(ctx, getRequest)
-		tableProjectionCols[getRequest.TableName] = projectionCols
-		pValues[getRequest.TableName] = pvalues
-		sValues[getRequest.TableName] = svalues
 	}
-
-	// Fetch data from Spanner
-	return svc.TransactGetItem(ctx, tableProjectionCols, pValues, sValues)
+	return gets, nil
 }
 
 func recordMetrics(ctx context.Context, o *otelgo.OpenTelemetry, method string, start time.Time, err error) {
@@ -1003,7 +1022,7 @@ func (h *APIHandler) ExecuteStatement(c *gin.Context) {
 	defer c.Request.Body.Close()
 	otelInstance := models.GlobalProxy.OtelInst
 	if otelInstance == nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "OpenTelemetry instance not initialized"})
+		c.JSON(errors.New("InternalServerError", "OpenTelemetry instance not initialized").HTTPResponse(nil))
 		return
 	}
 	ctx, span := otelInstance.StartSpan(ctx, "ExecuteStatement", []attribute.KeyValue{
@@ -1077,87 +1096,235 @@ func extractTableName(query string) string {
 // @Failure 500 {object} gin.H "{"errorMessage":"We had a problem with our server. Try again later.","errorCode":"E0001"}"
 // @Router /transact-write-items/ [post]
 // @Failure 401 {object} gin.H "{"errorMessage":"API access not allowed","errorCode": "E0005"}"
-
 func (h *APIHandler) TransactWriteItems(c *gin.Context) {
+	startTime := time.Now()
+	ctx := c.Request.Context()
+	var err error
 	defer PanicHandler(c)
 	defer c.Request.Body.Close()
+	otelInstance := models.GlobalProxy.OtelInst
+	if otelInstance == nil {
+		c.JSON(errors.New("InternalServerError", "OpenTelemetry instance not initialized").HTTPResponse(nil))
+		return
+	}
+	ctx, span := otelInstance.StartSpan(ctx, "TransactWriteItems", []attribute.KeyValue{
+		attribute.String("request.method", c.Request.Method),
+		attribute.String("request.url", c.Request.URL.Path),
+	})
+	defer models.GlobalProxy.OtelInst.EndSpan(span)
+	span = addParentSpanID(c, span)
+	defer recordMetrics(ctx, models.GlobalProxy.OtelInst, "TransactWriteItems", startTime, err)
 
 	var transactWriteMeta models.TransactWriteItemsRequest
-	if err := c.ShouldBindJSON(&transactWriteMeta); err != nil {
-		c.JSON(errors.New("ValidationException", err).HTTPResponse(transactWriteMeta))
+	if err1 := c.ShouldBindJSON(&transactWriteMeta); err1 != nil {
+		c.JSON(errors.New("ValidationException", err1).HTTPResponse(transactWriteMeta))
+		return
+	}
+	keys, err := transactWriteKeys(transactWriteMeta.TransactItems)
+	if err == nil {
+		err = services.ValidateTransactWrite(keys)
+	}
+	if err != nil {
+		c.JSON(errors.HTTPResponse(err, transactWriteMeta))
+		return
+	}
+	for _, key := range keys {
+		if allow := h.svc.MayIReadOrWrite(key.TableName, true, "TransactWriteItems"); !allow {
+			c.JSON(http.StatusOK, gin.H{})
+			return
+		}
+	}
+
+	// a request with a ClientRequestToken is idempotent, its token is stored in the same transaction
+	idempotent, err := services.PrepareClientRequestToken(ctx, transactWriteMeta.ClientRequestToken)
+	var requestHash string
+	if err == nil && idempotent {
+		requestHash, err = services.TransactWriteRequestHash(transactWriteMeta.TransactItems)
+	}
+	if err != nil {
+		c.JSON(errors.HTTPResponse(err, transactWriteMeta))
 		return
 	}
-	storageInstance := storage.GetStorageInstance()
-	spannerClient, _ := storageInstance.GetSpannerClient()
-	ctx := context.Background()
-	var resp models.TransactWriteItemsOutput
-	var resultItems []map[string]interface{}
 
-	_, _ = spannerClient.ReadWriteTransaction(ctx, func(ctx context.Context, txn *spanner.ReadWriteTransaction) error {
+	// the closure runs again when Spanner aborts the transaction, so that every attempt starts from scratch
+	// and the items are converted again, as the write operations modify them
+	var resultItems []map[string]interface{}
+	err = h.svc.ReadWriteTransaction(ctx, func(ctx context.Context, txn *spanner.ReadWriteTransaction) error {
 		resultItems = nil
+		if idempotent {
+			responses, replayed, err := services.ReplayTransactWrite(ctx, txn, transactWriteMeta.ClientRequestToken, requestHash)
+			if err != nil || replayed {
+				resultItems = responses
+				return err
+			}
+		}
+		reasons := make([]errors.CancellationReason, len(transactWriteMeta.TransactItems))
+		canceled := false
 		var mutations []*spanner.Mutation
 
-		for _, transactItem := range transactWriteMeta.TransactItems {
+		for i, transactItem := range transactWriteMeta.TransactItems {
 			var mut *spanner.Mutation
 			var result map[string]interface{}
 			var err error
+			var returnValues string
 
 			switch {
 			case transactItem.ConditionCheck.Key != nil:
-				mut, err = handleConditionCheck(c, transactItem.ConditionCheck, txn)
-				if err != nil {
-					c.JSON(errors.New("ConditionCheckFailed", err).HTTPResponse(transactItem.ConditionCheck))
-					return err
-				}
+				mut, err = handleConditionCheck(ctx, transactItem.ConditionCheck, txn)
+				returnValues = transactItem.ConditionCheck.ReturnValues
 			case transactItem.Put.Item != nil:
-				mut, result, err = handleWriteOperation(c, transactItem.Put, txn, "Put", h.svc)
-				resultItems = append(resultItems, map[string]interface{}{"Put": result})
+				mut, result, err = handleWriteOperation(ctx, transactItem.Put, txn, "Put", h.svc)
+				returnValues = transactItem.Put.ReturnValues
+				result = map[string]interface{}{"Put": result}
 			case transactItem.Update.Key != nil:
-				mut, result, err = handleWriteOperation(c, transactItem.Update, txn, "Update", h.svc)
-				resultItems = append(resultItems, map[string]interface{}{"Update": result})
+				mut, result, err = handleWriteOperation(ctx, transactItem.Update, txn, "Update", h.svc)
+				returnValues = transactItem.Update.ReturnValuesOnConditionCheckFailure
+				result = map[string]interface{}{"Update": result}
 			case transactItem.Delete.Key != nil:
-				mut, result, err = handleWriteOperation(c, transactItem.Delete, txn, "Delete", h.svc)
-				resultItems = append(resultItems, map[string]interface{}{"Delete": result})
-
+				mut, result, err = handleWriteOperation(ctx, transactItem.Delete, txn, "Delete", h.svc)
+				returnValues = transactItem.Delete.ReturnValues
+				result = map[string]interface{}{"Delete": result}
 			}
 
+			reasons[i].Code = "None"
 			if err != nil {
-				return err
+				if !isConditionalCheckFailed(err) {
+					return err
+				}
+				canceled = true
+				reasons[i] = errors.CancellationReason{Code: "ConditionalCheckFailed", Message: "The conditional request failed"}
+				if returnValues == "ALL_OLD" {
+					if reasons[i].Item, err = transactOldItem(ctx, keys[i]); err != nil {
+						return err
+					}
+				}
+				continue
+			}
+			if result != nil {
+				resultItems = append(resultItems, result)
 			}
 			if mut != nil {
 				mutations = append(mutations, mut)
 			}
 		}
-		err := txn.BufferWrite(mutations)
-		if e := errors.AssignError(err); e != nil {
-			return e
+		if canceled {
+			return errors.NewTransactionCanceled(reasons)
 		}
-		resp.Item = resultItems
-		return nil
+		if idempotent {
+			if err := services.SaveTransactWrite(txn, transactWriteMeta.ClientRequestToken, requestHash, resultItems); err != nil {
+				return err
+			}
+		}
+		return txn.BufferWrite(mutations)
 	})
+	if err != nil {
+		otelgo.AddAnnotation(ctx, "TransactWriteItems transaction failed")
+		c.JSON(errors.HTTPResponse(err, transactWriteMeta))
+		return
+	}
+	if span != nil {
+		span.SetAttributes(attribute.Int("transactItemCount", len(transactWriteMeta.TransactItems)))
+	}
+	otelgo.AddAnnotation(ctx, "Successfully processed TransactWriteItems request")
 	c.JSON(http.StatusOK, gin.H{"Responses": resultItems})
 }
 
+// transactWriteKeys converts the key of the item, and the expression attribute values, of every action of a
+// TransactWriteItems, in request order, so that the actions can be validated before the transaction starts.
+// Item is set for a Put and Key for the other actions.
+func transactWriteKeys(transactItems []models.TransactWriteItem) ([]models.BatchWriteMeta, error) {
+	keys := make([]models.BatchWriteMeta, len(transactItems))
+	for i, transactItem := range transactItems {
+		actions := 0
+		var values map[string]*dynamodb.AttributeValue
+		var err error
+		if transactItem.ConditionCheck.Key != nil {
+			actions++
+			keys[i].TableName = transactItem.ConditionCheck.TableName
+			keys[i].Key, err = ConvertDynamoToMap(keys[i].TableName, transactItem.ConditionCheck.Key)
+			values = transactItem.ConditionCheck.ExpressionAttributeValues
+		}
+		if transactItem.Put.Item != nil {
+			actions++
+			keys[i].TableName = transactItem.Put.TableName
+			keys[i].Item, err = ConvertDynamoToMap(keys[i].TableName, transactItem.Put.Item)
+			values = transactItem.Put.ExpressionAttributeValues
+		}
+		if transactItem.Update.Key != nil {
+			actions++
+			keys[i].TableName = transactItem.Update.TableName
+			keys[i].Key, err = ConvertDynamoToMap(keys[i].TableName, transactItem.Update.Key)
+			values = transactItem.Update.ExpressionAttributeValues
+		}
+		if transactItem.Delete.Key != nil {
+			actions++
+			keys[i].TableName = transactItem.Delete.TableName
+			keys[i].Key, err = ConvertDynamoToMap(keys[i].TableName, transactItem.Delete.Key)
+			values = transactItem.Delete.ExpressionAttributeValues
+		}
+		if actions != 1 {
+			return nil, errors.New("ValidationException", "TransactItems can only contain one of Check, Put, Update or Delete")
+		}
+		if err == nil {
+			keys[i].ExpressionAttributeMap, err = ConvertDynamoToMap(keys[i].TableName, values)
+		}
+		if err != nil {
+			return nil, errors.New("ValidationException", err)
+		}
+	}
+	return keys, nil
+}
+
+// isConditionalCheckFailed checks whether the action failed because of its condition. The aborted
+// transactions are never reported as failed conditions, so that Spanner retries them.
+func isConditionalCheckFailed(err error) bool {
+	if spanner.ErrCode(err) == codes.Aborted {
+		return false
+	}
+	e, ok := err.(*errors.Error)
+	return ok && e.ErrorCode == "ConditionalCheckFailedException"
+}
+
+// transactOldItem reads the item of an action which failed its condition within the transaction, for
+// ReturnValuesOnConditionCheckFailure ALL_OLD. It returns nil when the item does not exist.
+func transactOldItem(ctx context.Context, key models.BatchWriteMeta) (map[string]interface{}, error) {
+	tableConf, err := config.GetTableConf(key.TableName)
+	if err != nil {
+		return nil, err
+	}
+	item := key.Item
+	if item == nil {
+		item = key.Key
+	}
+	oldItem, _, err := storage.GetStorageInstance().SpannerGet(ctx, tableConf.ActualTable, item[tableConf.PartitionKey], item[tableConf.SortKey], nil)
+	if err != nil || len(oldItem) == 0 {
+		return nil, err
+	}
+	return ChangeMaptoDynamoMap(ChangeResponseToOriginalColumns(key.TableName, oldItem))
+}
+
 // handleConditionCheck takes a ConditionCheckRequest and a ReadWriteTransaction and returns a Mutation and an error.
 // It first converts the DynamoDB Key and ExpressionAttributeValues to Spanner's map type.
-// Then it replaces the ExpressionAttributeNames with the actual values in the ConditionExpression.
+// Then it parses the ConditionExpression with the ExpressionAttributeNames.
 // After that, it evaluates the condition expression using the EvaluateConditionalExpression function.
 // If the evaluation is false, it returns an error of ConditionalCheckFailedException.
 // If the evaluation is true, it returns nil.
-func handleConditionCheck(c *gin.Context, details models.ConditionCheckRequest, txn *spanner.ReadWriteTransaction) (*spanner.Mutation, error) {
+func handleConditionCheck(ctx context.Context, details models.ConditionCheckRequest, txn *spanner.ReadWriteTransaction) (*spanner.Mutation, error) {
 	var err error
 	var expr *models.UpdateExpressionCondition
-	ctx := context.Background()
 	details.PrimaryKeyMap, err = ConvertDynamoToMap(details.TableName, details.Key)
 	if err != nil {
-		c.JSON(errors.New("ValidationException", err).HTTPResponse(details))
-		return nil, nil
+		return nil, errors.New("ValidationException", err)
+	}
+	details.ExpressionAttributeMap, err = ConvertDynamoToMap(details.TableName, details.ExpressionAttributeValues)
+	if err != nil {
+		return nil, errors.New("ValidationException", err)
 	}
-	details.ExpressionAttributeMap, _ = ConvertDynamoToMap(details.TableName, details.ExpressionAttributeValues)
-	for k, v := range details.ExpressionAttributeNames {
-		details.ConditionExpression = strings.ReplaceAll(details.ConditionExpression, k, v)
+	details.ExpressionAttributeNames = ChangeColumnToSpannerExpressionName(details.TableName, details.ExpressionAttributeNames)
+	eval, err := utils.CreateConditionExpression(details.ConditionExpression, details.ExpressionAttributeNames, details.ExpressionAttributeMap)
+	if err != nil {
+		return nil, err
 	}
-	eval, _ := utils.CreateConditionExpression(details.ConditionExpression, details.ExpressionAttributeMap)
 	tmpMap := map[string]interface{}{}
 	for k, v := range details.PrimaryKeyMap {
 		tmpMap[k] = v
@@ -1176,12 +1343,13 @@ func handleConditionCheck(c *gin.Context, details models.ConditionCheckRequest,
 
 // handleWriteOperation processes different write operations (Put, Update, Delete) on a specified table in Spanner
 // using the provided transaction, context, and operation details. It returns a mutation, response map, and error.
-func handleWriteOperation(c *gin.Context, details interface{}, txn *spanner.ReadWriteTransaction, operationType string, svc services.Service) (*spanner.Mutation, map[string]interface{}, error) {
+func handleWriteOperation(ctx context.Context, details interface{}, txn *spanner.ReadWriteTransaction, operationType string, svc services.Service) (*spanner.Mutation, map[string]interface{}, error) {
 	// Initialize variables for operation details and error handling
 	var tableName string
 	var err error
 	var attrMap, expressionAttr map[string]interface{}
 	var conditionExpression string
+	var names map[string]string
 	var primaryKeyMap map[string]interface{}
 	var returnValues string
 
@@ -1190,23 +1358,31 @@ func handleWriteOperation(c *gin.Context, details interface{}, txn *spanner.Read
 	case "Put":
 		putDetails := details.(models.PutItemRequest)
 		tableName = putDetails.TableName
-		attrMap, _ = ConvertDynamoToMap(tableName, putDetails.Item)
-		expressionAttr, err = ConvertDynamoToMap(tableName, putDetails.ExpressionAttributeValues)
+		attrMap, err = ConvertDynamoToMap(tableName, putDetails.Item)
+		if err == nil {
+			expressionAttr, err = ConvertDynamoToMap(tableName, putDetails.ExpressionAttributeValues)
+		}
 		conditionExpression = putDetails.ConditionExpression
+		names = putDetails.ExpressionAttributeNames
 		returnValues = putDetails.ReturnValues
 	case "Update":
 		updateDetails := details.(models.UpdateAttr)
 		tableName = updateDetails.TableName
-		primaryKeyMap, _ = ConvertDynamoToMap(tableName, updateDetails.Key)
-		expressionAttr, err = ConvertDynamoToMap(tableName, updateDetails.ExpressionAttributeValues)
+		primaryKeyMap, err = ConvertDynamoToMap(tableName, updateDetails.Key)
+		if err == nil {
+			expressionAttr, err = ConvertDynamoToMap(tableName, updateDetails.ExpressionAttributeValues)
+		}
 		conditionExpression = updateDetails.ConditionExpression
 		returnValues = updateDetails.ReturnValues
 	case "Delete":
 		deleteDetails := details.(models.DeleteItemRequest)
 		tableName = deleteDetails.TableName
-		primaryKeyMap, _ = ConvertDynamoToMap(tableName, deleteDetails.Key)
-		expressionAttr, err = ConvertDynamoToMap(tableName, deleteDetails.ExpressionAttributeValues)
+		primaryKeyMap, err = ConvertDynamoToMap(tableName, deleteDetails.Key)
+		if err == nil {
+			expressionAttr, err = ConvertDynamoToMap(tableName, deleteDetails.ExpressionAttributeValues)
+		}
 		conditionExpression = deleteDetails.ConditionExpression
+		names = deleteDetails.ExpressionAttributeNames
 		returnValues = deleteDetails.ReturnValues
 	default:
 		return nil, nil, fmt.Errorf("invalid operation type: %s", operationType)
@@ -1214,21 +1390,16 @@ func handleWriteOperation(c *gin.Context, details interface{}, txn *spanner.Read
 
 	// Validate table name
 	if tableName == "" {
-		return nil, nil, fmt.Errorf("missing TableName in %s operation", operationType)
+		return nil, nil, errors.New("ValidationException", "missing TableName in "+operationType+" operation")
 	}
 
 	// Handle conversion errors
 	if err != nil {
-		c.JSON(errors.New("ValidationException", err).HTTPResponse(details))
-		return nil, nil, nil
+		return nil, nil, errors.New("ValidationException", err)
 	}
 
-	// Replace expression attribute names in condition expression
-	if details, ok := details.(interface{ GetExpressionAttributeNames() map[string]string }); ok {
-		for k, v := range details.GetExpressionAttributeNames() {
-			conditionExpression = strings.ReplaceAll(conditionExpression, k, v)
-		}
-	}
+	// Map the expression attribute names to the Spanner columns
+	names = ChangeColumnToSpannerExpressionName(tableName, names)
 
 	var mut *spanner.Mutation
 	var resp map[string]interface{}
@@ -1236,23 +1407,24 @@ func handleWriteOperation(c *gin.Context, details interface{}, txn *spanner.Read
 	switch operationType {
 	// Execute the appropriate transaction operation based on type
 	case "Put":
-		resp, mut, err = TransactPut(c.Request.Context(), tableName, attrMap, nil, conditionExpression, expressionAttr, txn, svc)
+		resp, mut, err = TransactPut(ctx, tableName, attrMap, nil, conditionExpression, names, expressionAttr, txn, svc)
 	case "Update":
 		updateDetails := details.(models.UpdateAttr)
 		updateDetails.PrimaryKeyMap = primaryKeyMap
 		updateDetails.ExpressionAttributeMap = expressionAttr
-		resp, mut, err = TransactWriteUpdateExpression(c.Request.Context(), updateDetails, txn, svc)
+		resp, mut, err = TransactWriteUpdateExpression(ctx, updateDetails, txn, svc)
 	case "Delete":
-		oldRes, _, _ := svc.GetWithProjection(c.Request.Context(), tableName, primaryKeyMap, "", nil)
-		mut, err = services.TransactWriteDelete(c.Request.Context(), tableName, primaryKeyMap, conditionExpression, expressionAttr, nil, txn)
+		var oldRes map[string]interface{}
+		oldRes, _, err = svc.GetWithProjection(ctx, tableName, primaryKeyMap, "", nil)
+		if err == nil {
+			mut, err = services.TransactWriteDelete(ctx, tableName, primaryKeyMap, conditionExpression, names, expressionAttr, nil, txn)
+		}
 		if err == nil {
 			resp, _ = ChangeMaptoDynamoMap(ChangeResponseToOriginalColumns(tableName, oldRes))
 		}
 	}
 
 	if err != nil {
-		// Handle operation errors
-		c.JSON(errors.HTTPResponse(err, details))
 		return nil, nil, err
 	}
 
@@ -1269,7 +1441,7 @@ func handleWriteOperation(c *gin.Context, details interface{}, txn *spanner.Read
 }
 
 // TransactPut manages a transactional put operation in Spanner, ensuring old data is fetched and conditions are evaluated.
-func TransactPut(ctx context.Context, tableName string, putObj map[string]interface{}, expr *models.UpdateExpressionCondition, conditionExp string, expressionAttr map[string]interface{}, txn *spanner.ReadWriteTransaction, svc services.Service) (map[string]interface{}, *spanner.Mutation, error) {
+func TransactPut(ctx context.Context, tableName string, putObj map[string]interface{}, expr *models.UpdateExpressionCondition, conditionExp string, expressionAttributeNames map[string]string, expressionAttr map[string]interface{}, txn *spanner.ReadWriteTransaction, svc services.Service) (map[string]interface{}, *spanner.Mutation, error) {
 	// Fetch the table configuration to retrieve partition and sort keys
 	tableConf, err := config.GetTableConf(tableName)
 	if err != nil {
@@ -1288,7 +1460,7 @@ func TransactPut(ctx context.Context, tableName string, putObj map[string]interf
 	}
 
 	// Perform the transactional write operation with the provided object and conditions
-	res, mut, err := svc.TransactWritePut(ctx, tableName, putObj, nil, conditionExp, expressionAttr, oldResp, txn)
+	res, mut, err := svc.TransactWritePut(ctx, tableName, putObj, nil, conditionExp, expressionAttributeNames, expressionAttr, oldResp, txn)
 	if err != nil {
 		return nil, nil, err
 	}
@@ -1296,8 +1468,3 @@ func TransactPut(ctx context.Context, tableName string, putObj map[string]interf
 	// Return the result and the mutation
 	return res, mut, nil
 }
-
-// Test: DescribeTable provides a simple response to indicate the service is operational.
-func (h *APIHandler) DescribeTable(c *gin.Context) {
-	c.JSON(http.StatusOK, gin.H{"status": "ok"})
-}
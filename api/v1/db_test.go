@@ -11,12 +11,14 @@ import (
 	"github.com/aws/aws-sdk-go/aws"
 	"github.com/aws/aws-sdk-go/service/dynamodb"
 	"github.com/cloudspannerecosystem/dynamodb-adapter/models"
-	"github.com/cloudspannerecosystem/dynamodb-adapter/service/services"
-	"github.com/cloudspannerecosystem/dynamodb-adapter/storage"
+	otelgo "github.com/cloudspannerecosystem/dynamodb-adapter/otel"
+	"github.com/cloudspannerecosystem/dynamodb-adapter/pkg/errors"
 
 	"github.com/gin-gonic/gin"
 	"github.com/stretchr/testify/mock"
 	"github.com/tj/assert"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
 )
 
 // MockService struct
@@ -37,16 +39,11 @@ func (m *MockStorage) GetStorageInstance(ctx context.Context, tableName string,
 }
 
 // Mock TransactGetItems method
-func (m *MockService) TransactGetItem(ctx context.Context, tableProjectionCols map[string][]string, pValues map[string]interface{}, sValues map[string]interface{}) ([]map[string]interface{}, error) {
-	args := m.Called(ctx, tableProjectionCols, pValues, sValues)
+func (m *MockService) TransactGetItems(ctx context.Context, gets []models.GetItemMeta) ([]map[string]interface{}, error) {
+	args := m.Called(ctx, gets)
 	return args.Get(0).([]map[string]interface{}), args.Error(1)
 }
 
-func (m *MockService) TransactGetProjectionCols(ctx context.Context, transactGetMeta models.GetItemRequest) ([]string, []interface{}, []interface{}, error) {
-	args := m.Called(ctx, transactGetMeta)
-	return args.Get(0).([]string), args.Get(1).([]interface{}), args.Get(2).([]interface{}), args.Error(3)
-}
-
 func (m *MockService) ChangeMaptoDynamoMap(input interface{}) (map[string]interface{}, error) {
 	args := m.Called(input)
 	return args.Get(0).(map[string]interface{}), args.Error(1)
@@ -58,41 +55,25 @@ func TestTransactGetItems_ValidRequestWithMultipleItems(t *testing.T) {
 	recorder := httptest.NewRecorder()
 	c, _ := gin.CreateTestContext(recorder)
 
-	mockSvc := new(MockService)
-	mockStorage := new(MockStorage)
-	mockStorageInstance := &storage.Storage{}
-
-	mockStorage.On("GetSpannerClient").Return(&MockSpannerClient{})
-	mockStorage.On("InitializeDriver").Return()
+	proxy, dbConfigMap := models.GlobalProxy, models.DbConfigMap
+	defer func() { models.GlobalProxy, models.DbConfigMap = proxy, dbConfigMap }()
+	models.GlobalProxy = &models.Proxy{OtelInst: &otelgo.OpenTelemetry{Config: &otelgo.OTelConfig{}}}
+	models.DbConfigMap = map[string]models.TableConfig{"employee": {PartitionKey: "emp_id", ActualTable: "employee"}}
 
-	// Mocking service methods
-	mockStorage.On("GetStorageInstance").Return(mockStorage)
+	mockSvc := new(MockService)
 	mockSvc.On("MayIReadOrWrite", "employee", false, "").Return(true)
 
-	mockSvc.On("TransactGetProjectionCols",
-		mock.Anything,
-		mock.AnythingOfType("models.GetItemRequest"),
-	).Return(
-		[]string{},
-		[]interface{}{},
-		[]interface{}{},
+	// the second item does not exist
+	items := []map[string]interface{}{
+		{"emp_id": float64(1), "first_name": "John", "last_name": "Doe"},
 		nil,
-	)
-
-	response1 := []map[string]interface{}{
-		{
-			"Item": map[string]interface{}{
-				"emp_id":     map[string]interface{}{"N": "1"},
-				"first_name": map[string]interface{}{"S": "John"},
-				"last_name":  map[string]interface{}{"S": "Doe"},
-			},
-		},
 	}
-	// Mock TransactGetItems response
-	mockSvc.On("TransactGetItem", mock.Anything, mock.Anything, mock.Anything, mock.Anything).Return(response1, nil).Once()
+	mockSvc.On("TransactGetItems", mock.Anything, []models.GetItemMeta{
+		{TableName: "employee", PrimaryKeyMap: map[string]interface{}{"emp_id": float64(1)}, ProjectionExpression: "emp_id, first_name, last_name"},
+		{TableName: "employee", PrimaryKeyMap: map[string]interface{}{"emp_id": float64(2)}},
+	}).Return(items, nil).Once()
 
 	h := &APIHandler{svc: mockSvc}
-	// Create request payload
 	transactGetMeta := models.TransactGetItemsRequest{
 		TransactItems: []models.TransactGetItem{
 			{
@@ -101,6 +82,7 @@ func TestTransactGetItems_ValidRequestWithMultipleItems(t *testing.T) {
 					Keys: map[string]*dynamodb.AttributeValue{
 						"emp_id": {N: aws.String("1")},
 					},
+					ProjectionExpression: "emp_id, first_name, last_name",
 				},
 			},
 			{
@@ -113,26 +95,54 @@ func TestTransactGetItems_ValidRequestWithMultipleItems(t *testing.T) {
 			},
 		},
 	}
-	//models.TableColChangeMap[tableName]
 	reqBody, _ := json.Marshal(transactGetMeta)
-
-	// Set request in context
 	c.Request, _ = http.NewRequest("POST", "/transact-get-items", bytes.NewBuffer(reqBody))
 
-	// Set mock service in context
-	services.SetServiceInstance(mockSvc)
-	storage.SetStorageInstance(mockStorageInstance)
-	//	apiHandler := NewAPIHandler(mockService)
 	h.TransactGetItems(c)
 
-	// Assertions
 	assert.Equal(t, http.StatusOK, recorder.Code)
+	assert.JSONEq(t, `{"Responses":[{"TableName":"employee","Item":{"L":[{"emp_id":{"N":"1"},"first_name":{"S":"John"},"last_name":{"S":"Doe"}}]}},{}]}`, recorder.Body.String())
+	mockSvc.AssertNumberOfCalls(t, "TransactGetItems", 1)
+	mockSvc.AssertExpectations(t)
+}
 
-	var responseBody models.TransactGetItemsResponse // Use the correct response type
-	err := json.Unmarshal(recorder.Body.Bytes(), &responseBody)
-	assert.NoError(t, err, "Response should be valid JSON")
+func TestTransactGetKeys(t *testing.T) {
+	gets, err := transactGetKeys([]models.TransactGetItem{
+		{Get: models.GetItemRequest{TableName: "employee", Keys: map[string]*dynamodb.AttributeValue{"emp_id": {N: aws.String("1")}}}},
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, []models.GetItemMeta{{TableName: "employee", PrimaryKeyMap: map[string]interface{}{"emp_id": float64(1)}}}, gets)
+}
 
-	mockSvc.AssertNumberOfCalls(t, "TransactGetItem", 1)
-	mockSvc.AssertNumberOfCalls(t, "TransactGetProjectionCols", 2)
-	mockSvc.AssertExpectations(t)
+func TestTransactWriteKeys(t *testing.T) {
+	key := map[string]*dynamodb.AttributeValue{"emp_id": {N: aws.String("1")}}
+
+	keys, err := transactWriteKeys([]models.TransactWriteItem{
+		{Put: models.PutItemRequest{TableName: "employee", Item: map[string]*dynamodb.AttributeValue{
+			"emp_id":     {N: aws.String("2")},
+			"first_name": {S: aws.String("John")},
+		}}},
+		{Delete: models.DeleteItemRequest{TableName: "employee", Key: key}},
+		{Update: models.UpdateAttr{TableName: "employee", Key: map[string]*dynamodb.AttributeValue{"emp_id": {N: aws.String("3")}},
+			UpdateExpression: "SET first_name = :name", ExpressionAttributeValues: map[string]*dynamodb.AttributeValue{":name": {S: aws.String("Jane")}}}},
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, []models.BatchWriteMeta{
+		{TableName: "employee", Item: map[string]interface{}{"emp_id": float64(2), "first_name": "John"}},
+		{TableName: "employee", Key: map[string]interface{}{"emp_id": float64(1)}},
+		{TableName: "employee", Key: map[string]interface{}{"emp_id": float64(3)}, ExpressionAttributeMap: map[string]interface{}{":name": "Jane"}},
+	}, keys)
+
+	_, err = transactWriteKeys([]models.TransactWriteItem{
+		{Update: models.UpdateAttr{TableName: "employee", Key: key}, Delete: models.DeleteItemRequest{TableName: "employee", Key: key}},
+	})
+	assert.Equal(t, "ValidationException", err.(*errors.Error).ErrorCode)
+}
+
+func TestIsConditionalCheckFailed(t *testing.T) {
+	assert.True(t, isConditionalCheckFailed(errors.New("ConditionalCheckFailedException", "The conditional request failed")))
+	assert.False(t, isConditionalCheckFailed(errors.New("ValidationException", "wrong key type")))
+	// an aborted read is retried by Spanner, even when it surfaces as a failed condition
+	aborted := status.Error(codes.Aborted, "transaction aborted")
+	assert.False(t, isConditionalCheckFailed(errors.New("ConditionalCheckFailedException", aborted)))
 }
@@ -16,12 +16,17 @@ package v1
 
 import (
 	"bytes"
+	"compress/gzip"
+	"hash/crc32"
 	"io"
 	"runtime/debug"
+	"strconv"
+	"strings"
 
 	"github.com/cloudspannerecosystem/dynamodb-adapter/pkg/errors"
 	"github.com/cloudspannerecosystem/dynamodb-adapter/pkg/logger"
 	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
 )
 
 // PanicHandler is global handler for all type of panic
@@ -29,7 +34,7 @@ func PanicHandler(c *gin.Context) {
 	if e := recover(); e != nil {
 		stack := string(debug.Stack())
 		logger.Error("panic stack trace", stack)
-		c.JSON(errors.New("ServerInternalError", e, stack).HTTPResponse(e))
+		c.JSON(errors.New("InternalServerError", e, stack).HTTPResponse(e))
 	}
 }
 
@@ -78,3 +83,97 @@ func (w *bodyLogWriter) Write(b []byte) (int, error) {
 	w.body.Write(b)
 	return w.ResponseWriter.Write(b)
 }
+
+// gzipMinLength - responses smaller than this are not worth compressing
+const gzipMinLength = 1024
+
+// AmzResponseHeaders sets the x-amzn-RequestId and x-amz-crc32 headers sent by DynamoDB. The checksum
+// covers the response body as sent, i.e. after the compression, which is what the SDKs validate.
+func AmzResponseHeaders() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		c.Header("x-amzn-RequestId", uuid.NewString())
+
+		w := &bufferedWriter{ResponseWriter: c.Writer}
+		c.Writer = w
+		c.Next()
+		c.Writer = w.ResponseWriter
+
+		body := w.body.Bytes()
+		c.Header("x-amz-crc32", strconv.FormatUint(uint64(crc32.ChecksumIEEE(body)), 10))
+		writeBody(c, body)
+	}
+}
+
+// AmzJSONContentType answers with the DynamoDB JSON protocol version of the request,
+// application/x-amz-json-1.0 or application/x-amz-json-1.1, instead of application/json
+func AmzJSONContentType() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		contentType := c.ContentType()
+		if strings.HasPrefix(contentType, "application/x-amz-json-") {
+			c.Header("Content-Type", contentType)
+		}
+		c.Next()
+	}
+}
+
+// GzipEncoding decompresses the requests sent with Content-Encoding: gzip and compresses
+// the large responses when the client sent Accept-Encoding: gzip
+func GzipEncoding() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if strings.EqualFold(c.GetHeader("Content-Encoding"), "gzip") && c.Request.Body != nil {
+			reader, err := gzip.NewReader(c.Request.Body)
+			if err != nil {
+				c.AbortWithStatusJSON(errors.New("ValidationException", "Invalid gzip request body: ", err).HTTPResponse(nil))
+				return
+			}
+			defer reader.Close()
+			c.Request.Body = reader
+			c.Request.Header.Del("Content-Encoding")
+			c.Request.ContentLength = -1
+		}
+		if !strings.Contains(c.GetHeader("Accept-Encoding"), "gzip") {
+			c.Next()
+			return
+		}
+
+		w := &bufferedWriter{ResponseWriter: c.Writer}
+		c.Writer = w
+		c.Next()
+		c.Writer = w.ResponseWriter
+
+		body := w.body.Bytes()
+		if len(body) >= gzipMinLength && c.Writer.Header().Get("Content-Encoding") == "" {
+			var compressed bytes.Buffer
+			gz := gzip.NewWriter(&compressed)
+			if _, err := gz.Write(body); err == nil && gz.Close() == nil {
+				c.Header("Content-Encoding", "gzip")
+				c.Header("Vary", "Accept-Encoding")
+				body = compressed.Bytes()
+			}
+		}
+		writeBody(c, body)
+	}
+}
+
+// writeBody writes the buffered response body, or only the status when the body is empty
+func writeBody(c *gin.Context, body []byte) {
+	if len(body) == 0 {
+		c.Writer.WriteHeaderNow()
+		return
+	}
+	_, _ = c.Writer.Write(body)
+}
+
+// bufferedWriter holds back the response body, so that it can be compressed once complete
+type bufferedWriter struct {
+	gin.ResponseWriter
+	body bytes.Buffer
+}
+
+func (w *bufferedWriter) Write(b []byte) (int, error) {
+	return w.body.Write(b)
+}
+
+func (w *bufferedWriter) WriteString(s string) (int, error) {
+	return w.body.WriteString(s)
+}
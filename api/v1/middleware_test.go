@@ -0,0 +1,128 @@
+// Copyright 2020 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package v1
+
+import (
+	"bytes"
+	"compress/gzip"
+	"hash/crc32"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/tj/assert"
+)
+
+func gzipBytes(t *testing.T, b []byte) []byte {
+	var buf bytes.Buffer
+	gz := gzip.NewWriter(&buf)
+	_, err := gz.Write(b)
+	assert.NoError(t, err)
+	assert.NoError(t, gz.Close())
+	return buf.Bytes()
+}
+
+func TestAmzTargetAction(t *testing.T) {
+	tests := []struct {
+		amzTarget string
+		want      string
+	}{
+		{"DynamoDB_20120810.GetItem", "GetItem"},
+		{"GetItem", "GetItem"},
+		{"", ""},
+		{"DynamoDB_20120810.", ""},
+	}
+	for _, tc := range tests {
+		assert.Equal(t, tc.want, amzTargetAction(tc.amzTarget))
+	}
+}
+
+func TestGzipEncoding(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	large := `{"Items":"` + strings.Repeat("x", gzipMinLength) + `"}`
+	r := gin.New()
+	r.Use(GzipEncoding(), AmzJSONContentType())
+	r.POST("/", func(c *gin.Context) {
+		body, _ := io.ReadAll(c.Request.Body)
+		if string(body) == "large" {
+			c.String(http.StatusOK, large)
+			return
+		}
+		c.String(http.StatusOK, string(body))
+	})
+
+	t.Run("gzip request", func(t *testing.T) {
+		req := httptest.NewRequest(http.MethodPost, "/", bytes.NewReader(gzipBytes(t, []byte(`{"TableName":"t"}`))))
+		req.Header.Set("Content-Encoding", "gzip")
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, req)
+		assert.Equal(t, http.StatusOK, w.Code)
+		assert.Equal(t, `{"TableName":"t"}`, w.Body.String())
+	})
+
+	t.Run("invalid gzip request", func(t *testing.T) {
+		req := httptest.NewRequest(http.MethodPost, "/", strings.NewReader("not gzip"))
+		req.Header.Set("Content-Encoding", "gzip")
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, req)
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+	})
+
+	t.Run("small response is not compressed", func(t *testing.T) {
+		req := httptest.NewRequest(http.MethodPost, "/", strings.NewReader("{}"))
+		req.Header.Set("Accept-Encoding", "gzip")
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, req)
+		assert.Equal(t, "", w.Header().Get("Content-Encoding"))
+		assert.Equal(t, "{}", w.Body.String())
+	})
+
+	t.Run("large response is compressed", func(t *testing.T) {
+		req := httptest.NewRequest(http.MethodPost, "/", strings.NewReader("large"))
+		req.Header.Set("Accept-Encoding", "gzip")
+		req.Header.Set("Content-Type", "application/x-amz-json-1.0")
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, req)
+		assert.Equal(t, "gzip", w.Header().Get("Content-Encoding"))
+		reader, err := gzip.NewReader(w.Body)
+		assert.NoError(t, err)
+		body, err := io.ReadAll(reader)
+		assert.NoError(t, err)
+		assert.Equal(t, large, string(body))
+	})
+}
+
+func TestAmzResponseHeaders(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	large := strings.Repeat("x", gzipMinLength)
+	r := gin.New()
+	r.Use(AmzResponseHeaders(), GzipEncoding())
+	r.POST("/", func(c *gin.Context) {
+		c.String(http.StatusOK, large)
+	})
+
+	for _, acceptEncoding := range []string{"", "gzip"} {
+		req := httptest.NewRequest(http.MethodPost, "/", strings.NewReader("{}"))
+		req.Header.Set("Accept-Encoding", acceptEncoding)
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, req)
+		assert.NotEmpty(t, w.Header().Get("x-amzn-RequestId"))
+		assert.Equal(t, strconv.FormatUint(uint64(crc32.ChecksumIEEE(w.Body.Bytes())), 10), w.Header().Get("x-amz-crc32"))
+	}
+}
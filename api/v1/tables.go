@@ -0,0 +1,574 @@
+// Copyright 2020 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package v1
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/cloudspannerecosystem/dynamodb-adapter/models"
+	otelgo "github.com/cloudspannerecosystem/dynamodb-adapter/otel"
+	"github.com/cloudspannerecosystem/dynamodb-adapter/pkg/errors"
+	"github.com/cloudspannerecosystem/dynamodb-adapter/pkg/logger"
+	"github.com/cloudspannerecosystem/dynamodb-adapter/service/services"
+	"github.com/gin-gonic/gin"
+	"go.opentelemetry.io/otel/attribute"
+)
+
+// CreateTable creates a table
+// @Description Creates a table with its indexes in Spanner and registers the adapter metadata
+// @Summary Creates a table
+// @ID create-table
+// @Produce  json
+// @Success 200 {object} gin.H
+// @Param requestBody body models.CreateTableMeta true "Please add request body of type models.CreateTableMeta"
+// @Failure 500 {object} gin.H "{"errorMessage":"We had a problem with our server. Try again later.","errorCode":"E0001"}"
+// @Router /createTable/ [post]
+// @Failure 401 {object} gin.H "{"errorMessage":"API access not allowed","errorCode": "E0005"}"
+func (h *APIHandler) CreateTable(c *gin.Context) {
+	startTime := time.Now()
+	ctx := c.Request.Context()
+	var err error
+	defer PanicHandler(c)
+	defer c.Request.Body.Close()
+	otelInstance := models.GlobalProxy.OtelInst
+	if otelInstance == nil {
+		c.JSON(errors.New("InternalServerError", "OpenTelemetry instance not initialized").HTTPResponse(nil))
+		return
+	}
+
+	ctx, span := otelInstance.StartSpan(ctx, "CreateTable", []attribute.KeyValue{
+		attribute.String("request.method", c.Request.Method),
+		attribute.String("request.url", c.Request.URL.Path),
+	})
+	addParentSpanID(c, span)
+	defer models.GlobalProxy.OtelInst.EndSpan(span)
+	defer recordMetrics(ctx, models.GlobalProxy.OtelInst, "CreateTable", startTime, err)
+
+	var meta models.CreateTableMeta
+	if err = c.ShouldBindJSON(&meta); err != nil {
+		c.JSON(errors.New("ValidationException", err).HTTPResponse(meta))
+		return
+	}
+	if allow := h.svc.MayIReadOrWrite(meta.TableName, true, "CreateTable"); !allow {
+		c.JSON(http.StatusOK, gin.H{})
+		return
+	}
+	logger.Debug(meta)
+	otelgo.AddAnnotation(ctx, "Calling CreateTable Service")
+	desc, err := services.CreateTable(ctx, meta)
+	if err != nil {
+		c.JSON(errors.HTTPResponse(err, meta))
+		return
+	}
+	otelgo.AddAnnotation(ctx, "Successfully processed the CreateTable request.")
+	c.JSON(http.StatusOK, gin.H{"TableDescription": desc})
+}
+
+// DeleteTable deletes a table
+// @Description Drops a table with its indexes from Spanner and removes the adapter metadata
+// @Summary Deletes a table
+// @ID delete-table
+// @Produce  json
+// @Success 200 {object} gin.H
+// @Param requestBody body models.DeleteTableMeta true "Please add request body of type models.DeleteTableMeta"
+// @Failure 500 {object} gin.H "{"errorMessage":"We had a problem with our server. Try again later.","errorCode":"E0001"}"
+// @Router /deleteTable/ [post]
+// @Failure 401 {object} gin.H "{"errorMessage":"API access not allowed","errorCode": "E0005"}"
+func (h *APIHandler) DeleteTable(c *gin.Context) {
+	startTime := time.Now()
+	ctx := c.Request.Context()
+	var err error
+	defer PanicHandler(c)
+	defer c.Request.Body.Close()
+	otelInstance := models.GlobalProxy.OtelInst
+	if otelInstance == nil {
+		c.JSON(errors.New("InternalServerError", "OpenTelemetry instance not initialized").HTTPResponse(nil))
+		return
+	}
+
+	ctx, span := otelInstance.StartSpan(ctx, "DeleteTable", []attribute.KeyValue{
+		attribute.String("request.method", c.Request.Method),
+		attribute.String("request.url", c.Request.URL.Path),
+	})
+	addParentSpanID(c, span)
+	defer models.GlobalProxy.OtelInst.EndSpan(span)
+	defer recordMetrics(ctx, models.GlobalProxy.OtelInst, "DeleteTable", startTime, err)
+
+	var meta models.DeleteTableMeta
+	if err = c.ShouldBindJSON(&meta); err != nil {
+		c.JSON(errors.New("ValidationException", err).HTTPResponse(meta))
+		return
+	}
+	if allow := h.svc.MayIReadOrWrite(meta.TableName, true, "DeleteTable"); !allow {
+		c.JSON(http.StatusOK, gin.H{})
+		return
+	}
+	otelgo.AddAnnotation(ctx, "Calling DeleteTable Service")
+	desc, err := services.DeleteTable(ctx, meta.TableName)
+	if err != nil {
+		c.JSON(errors.HTTPResponse(err, meta))
+		return
+	}
+	otelgo.AddAnnotation(ctx, "Successfully processed the DeleteTable request.")
+	c.JSON(http.StatusOK, gin.H{"TableDescription": desc})
+}
+
+// DescribeTable describes a table
+// @Description Returns the table description with its key schema, indexes and size estimates
+// @Summary Describes a table
+// @ID describe-table
+// @Produce  json
+// @Success 200 {object} gin.H
+// @Param requestBody body models.DescribeTableMeta true "Please add request body of type models.DescribeTableMeta"
+// @Failure 500 {object} gin.H "{"errorMessage":"We had a problem with our server. Try again later.","errorCode":"E0001"}"
+// @Router /describeTable/ [post]
+// @Failure 401 {object} gin.H "{"errorMessage":"API access not allowed","errorCode": "E0005"}"
+func (h *APIHandler) DescribeTable(c *gin.Context) {
+	startTime := time.Now()
+	ctx := c.Request.Context()
+	var err error
+	defer PanicHandler(c)
+	defer c.Request.Body.Close()
+	otelInstance := models.GlobalProxy.OtelInst
+	if otelInstance == nil {
+		c.JSON(errors.New("InternalServerError", "OpenTelemetry instance not initialized").HTTPResponse(nil))
+		return
+	}
+
+	ctx, span := otelInstance.StartSpan(ctx, "DescribeTable", []attribute.KeyValue{
+		attribute.String("request.method", c.Request.Method),
+		attribute.String("request.url", c.Request.URL.Path),
+	})
+	addParentSpanID(c, span)
+	defer models.GlobalProxy.OtelInst.EndSpan(span)
+	defer recordMetrics(ctx, models.GlobalProxy.OtelInst, "DescribeTable", startTime, err)
+
+	var meta models.DescribeTableMeta
+	if err = c.ShouldBindJSON(&meta); err != nil {
+		c.JSON(errors.New("ValidationException", err).HTTPResponse(meta))
+		return
+	}
+	if allow := h.svc.MayIReadOrWrite(meta.TableName, false, "DescribeTable"); !allow {
+		c.JSON(http.StatusOK, gin.H{})
+		return
+	}
+	otelgo.AddAnnotation(ctx, "Calling DescribeTable Service")
+	desc, err := services.DescribeTable(ctx, meta.TableName)
+	if err != nil {
+		c.JSON(errors.HTTPResponse(err, meta))
+		return
+	}
+	otelgo.AddAnnotation(ctx, "Successfully processed the DescribeTable request.")
+	c.JSON(http.StatusOK, gin.H{"Table": desc})
+}
+
+// ListTables lists the tables
+// @Description Returns the names of the tables managed by the adapter, one page at a time
+// @Summary Lists the tables
+// @ID list-tables
+// @Produce  json
+// @Success 200 {object} gin.H
+// @Param requestBody body models.ListTablesMeta true "Please add request body of type models.ListTablesMeta"
+// @Failure 500 {object} gin.H "{"errorMessage":"We had a problem with our server. Try again later.","errorCode":"E0001"}"
+// @Router /listTables/ [post]
+// @Failure 401 {object} gin.H "{"errorMessage":"API access not allowed","errorCode": "E0005"}"
+func (h *APIHandler) ListTables(c *gin.Context) {
+	startTime := time.Now()
+	ctx := c.Request.Context()
+	var err error
+	defer PanicHandler(c)
+	defer c.Request.Body.Close()
+	otelInstance := models.GlobalProxy.OtelInst
+	if otelInstance == nil {
+		c.JSON(errors.New("InternalServerError", "OpenTelemetry instance not initialized").HTTPResponse(nil))
+		return
+	}
+
+	ctx, span := otelInstance.StartSpan(ctx, "ListTables", []attribute.KeyValue{
+		attribute.String("request.method", c.Request.Method),
+		attribute.String("request.url", c.Request.URL.Path),
+	})
+	addParentSpanID(c, span)
+	defer models.GlobalProxy.OtelInst.EndSpan(span)
+	defer recordMetrics(ctx, models.GlobalProxy.OtelInst, "ListTables", startTime, err)
+
+	var meta models.ListTablesMeta
+	if err = c.ShouldBindJSON(&meta); err != nil {
+		c.JSON(errors.New("ValidationException", err).HTTPResponse(meta))
+		return
+	}
+	otelgo.AddAnnotation(ctx, "Calling ListTables Service")
+	output, err := services.ListTables(ctx, meta)
+	if err != nil {
+		c.JSON(errors.HTTPResponse(err, meta))
+		return
+	}
+	otelgo.AddAnnotation(ctx, "Successfully processed the ListTables request.")
+	c.JSON(http.StatusOK, output)
+}
+
+// UpdateTable updates a table
+// @Description Creates or deletes a global secondary index of a table, the index is built in the background
+// @Summary Updates a table
+// @ID update-table
+// @Produce  json
+// @Success 200 {object} gin.H
+// @Param requestBody body models.UpdateTableMeta true "Please add request body of type models.UpdateTableMeta"
+// @Failure 500 {object} gin.H "{"errorMessage":"We had a problem with our server. Try again later.","errorCode":"E0001"}"
+// @Router /updateTable/ [post]
+// @Failure 401 {object} gin.H "{"errorMessage":"API access not allowed","errorCode": "E0005"}"
+func (h *APIHandler) UpdateTable(c *gin.Context) {
+	startTime := time.Now()
+	ctx := c.Request.Context()
+	var err error
+	defer PanicHandler(c)
+	defer c.Request.Body.Close()
+	otelInstance := models.GlobalProxy.OtelInst
+	if otelInstance == nil {
+		c.JSON(errors.New("InternalServerError", "OpenTelemetry instance not initialized").HTTPResponse(nil))
+		return
+	}
+
+	ctx, span := otelInstance.StartSpan(ctx, "UpdateTable", []attribute.KeyValue{
+		attribute.String("request.method", c.Request.Method),
+		attribute.String("request.url", c.Request.URL.Path),
+	})
+	addParentSpanID(c, span)
+	defer models.GlobalProxy.OtelInst.EndSpan(span)
+	defer recordMetrics(ctx, models.GlobalProxy.OtelInst, "UpdateTable", startTime, err)
+
+	var meta models.UpdateTableMeta
+	if err = c.ShouldBindJSON(&meta); err != nil {
+		c.JSON(errors.New("ValidationException", err).HTTPResponse(meta))
+		return
+	}
+	if allow := h.svc.MayIReadOrWrite(meta.TableName, true, "UpdateTable"); !allow {
+		c.JSON(http.StatusOK, gin.H{})
+		return
+	}
+	logger.Debug(meta)
+	otelgo.AddAnnotation(ctx, "Calling UpdateTable Service")
+	desc, err := services.UpdateTable(ctx, meta)
+	if err != nil {
+		c.JSON(errors.HTTPResponse(err, meta))
+		return
+	}
+	otelgo.AddAnnotation(ctx, "Successfully processed the UpdateTable request.")
+	c.JSON(http.StatusOK, gin.H{"TableDescription": desc})
+}
+
+// UpdateTimeToLive updates the TimeToLive of a table
+// @Description Enables or disables the TimeToLive of a table, expired items are deleted by the background sweeper
+// @Summary Updates the TimeToLive of a table
+// @ID update-time-to-live
+// @Produce  json
+// @Success 200 {object} gin.H
+// @Param requestBody body models.UpdateTimeToLiveMeta true "Please add request body of type models.UpdateTimeToLiveMeta"
+// @Failure 500 {object} gin.H "{"errorMessage":"We had a problem with our server. Try again later.","errorCode":"E0001"}"
+// @Router /updateTimeToLive/ [post]
+// @Failure 401 {object} gin.H "{"errorMessage":"API access not allowed","errorCode": "E0005"}"
+func (h *APIHandler) UpdateTimeToLive(c *gin.Context) {
+	startTime := time.Now()
+	ctx := c.Request.Context()
+	var err error
+	defer PanicHandler(c)
+	defer c.Request.Body.Close()
+	otelInstance := models.GlobalProxy.OtelInst
+	if otelInstance == nil {
+		c.JSON(errors.New("InternalServerError", "OpenTelemetry instance not initialized").HTTPResponse(nil))
+		return
+	}
+
+	ctx, span := otelInstance.StartSpan(ctx, "UpdateTimeToLive", []attribute.KeyValue{
+		attribute.String("request.method", c.Request.Method),
+		attribute.String("request.url", c.Request.URL.Path),
+	})
+	addParentSpanID(c, span)
+	defer models.GlobalProxy.OtelInst.EndSpan(span)
+	defer recordMetrics(ctx, models.GlobalProxy.OtelInst, "UpdateTimeToLive", startTime, err)
+
+	var meta models.UpdateTimeToLiveMeta
+	if err = c.ShouldBindJSON(&meta); err != nil {
+		c.JSON(errors.New("ValidationException", err).HTTPResponse(meta))
+		return
+	}
+	if allow := h.svc.MayIReadOrWrite(meta.TableName, true, "UpdateTimeToLive"); !allow {
+		c.JSON(http.StatusOK, gin.H{})
+		return
+	}
+	otelgo.AddAnnotation(ctx, "Calling UpdateTimeToLive Service")
+	spec, err := services.UpdateTimeToLive(ctx, meta)
+	if err != nil {
+		c.JSON(errors.HTTPResponse(err, meta))
+		return
+	}
+	otelgo.AddAnnotation(ctx, "Successfully processed the UpdateTimeToLive request.")
+	c.JSON(http.StatusOK, gin.H{"TimeToLiveSpecification": spec})
+}
+
+// DescribeTimeToLive describes the TimeToLive of a table
+// @Description Returns the TimeToLive status and attribute of a table
+// @Summary Describes the TimeToLive of a table
+// @ID describe-time-to-live
+// @Produce  json
+// @Success 200 {object} gin.H
+// @Param requestBody body models.DescribeTimeToLiveMeta true "Please add request body of type models.DescribeTimeToLiveMeta"
+// @Failure 500 {object} gin.H "{"errorMessage":"We had a problem with our server. Try again later.","errorCode":"E0001"}"
+// @Router /describeTimeToLive/ [post]
+// @Failure 401 {object} gin.H "{"errorMessage":"API access not allowed","errorCode": "E0005"}"
+func (h *APIHandler) DescribeTimeToLive(c *gin.Context) {
+	startTime := time.Now()
+	ctx := c.Request.Context()
+	var err error
+	defer PanicHandler(c)
+	defer c.Request.Body.Close()
+	otelInstance := models.GlobalProxy.OtelInst
+	if otelInstance == nil {
+		c.JSON(errors.New("InternalServerError", "OpenTelemetry instance not initialized").HTTPResponse(nil))
+		return
+	}
+
+	ctx, span := otelInstance.StartSpan(ctx, "DescribeTimeToLive", []attribute.KeyValue{
+		attribute.String("request.method", c.Request.Method),
+		attribute.String("request.url", c.Request.URL.Path),
+	})
+	addParentSpanID(c, span)
+	defer models.GlobalProxy.OtelInst.EndSpan(span)
+	defer recordMetrics(ctx, models.GlobalProxy.OtelInst, "DescribeTimeToLive", startTime, err)
+
+	var meta models.DescribeTimeToLiveMeta
+	if err = c.ShouldBindJSON(&meta); err != nil {
+		c.JSON(errors.New("ValidationException", err).HTTPResponse(meta))
+		return
+	}
+	if allow := h.svc.MayIReadOrWrite(meta.TableName, false, "DescribeTimeToLive"); !allow {
+		c.JSON(http.StatusOK, gin.H{})
+		return
+	}
+	otelgo.AddAnnotation(ctx, "Calling DescribeTimeToLive Service")
+	desc, err := services.DescribeTimeToLive(ctx, meta.TableName)
+	if err != nil {
+		c.JSON(errors.HTTPResponse(err, meta))
+		return
+	}
+	otelgo.AddAnnotation(ctx, "Successfully processed the DescribeTimeToLive request.")
+	c.JSON(http.StatusOK, gin.H{"TimeToLiveDescription": desc})
+}
+
+// TagResource tags a table
+// @Description Adds tags to the table identified by its TableArn
+// @Summary Tags a table
+// @ID tag-resource
+// @Produce  json
+// @Success 200 {object} gin.H
+// @Param requestBody body models.TagResourceMeta true "Please add request body of type models.TagResourceMeta"
+// @Failure 500 {object} gin.H "{"errorMessage":"We had a problem with our server. Try again later.","errorCode":"E0001"}"
+// @Router /tagResource/ [post]
+// @Failure 401 {object} gin.H "{"errorMessage":"API access not allowed","errorCode": "E0005"}"
+func (h *APIHandler) TagResource(c *gin.Context) {
+	startTime := time.Now()
+	ctx := c.Request.Context()
+	var err error
+	defer PanicHandler(c)
+	defer c.Request.Body.Close()
+	otelInstance := models.GlobalProxy.OtelInst
+	if otelInstance == nil {
+		c.JSON(errors.New("InternalServerError", "OpenTelemetry instance not initialized").HTTPResponse(nil))
+		return
+	}
+
+	ctx, span := otelInstance.StartSpan(ctx, "TagResource", []attribute.KeyValue{
+		attribute.String("request.method", c.Request.Method),
+		attribute.String("request.url", c.Request.URL.Path),
+	})
+	addParentSpanID(c, span)
+	defer models.GlobalProxy.OtelInst.EndSpan(span)
+	defer recordMetrics(ctx, models.GlobalProxy.OtelInst, "TagResource", startTime, err)
+
+	var meta models.TagResourceMeta
+	if err = c.ShouldBindJSON(&meta); err != nil {
+		c.JSON(errors.New("ValidationException", err).HTTPResponse(meta))
+		return
+	}
+	otelgo.AddAnnotation(ctx, "Calling TagResource Service")
+	err = services.TagResource(ctx, meta)
+	if err != nil {
+		c.JSON(errors.HTTPResponse(err, meta))
+		return
+	}
+	otelgo.AddAnnotation(ctx, "Successfully processed the TagResource request.")
+	c.JSON(http.StatusOK, gin.H{})
+}
+
+// UntagResource untags a table
+// @Description Removes tags from the table identified by its TableArn
+// @Summary Untags a table
+// @ID untag-resource
+// @Produce  json
+// @Success 200 {object} gin.H
+// @Param requestBody body models.UntagResourceMeta true "Please add request body of type models.UntagResourceMeta"
+// @Failure 500 {object} gin.H "{"errorMessage":"We had a problem with our server. Try again later.","errorCode":"E0001"}"
+// @Router /untagResource/ [post]
+// @Failure 401 {object} gin.H "{"errorMessage":"API access not allowed","errorCode": "E0005"}"
+func (h *APIHandler) UntagResource(c *gin.Context) {
+	startTime := time.Now()
+	ctx := c.Request.Context()
+	var err error
+	defer PanicHandler(c)
+	defer c.Request.Body.Close()
+	otelInstance := models.GlobalProxy.OtelInst
+	if otelInstance == nil {
+		c.JSON(errors.New("InternalServerError", "OpenTelemetry instance not initialized").HTTPResponse(nil))
+		return
+	}
+
+	ctx, span := otelInstance.StartSpan(ctx, "UntagResource", []attribute.KeyValue{
+		attribute.String("request.method", c.Request.Method),
+		attribute.String("request.url", c.Request.URL.Path),
+	})
+	addParentSpanID(c, span)
+	defer models.GlobalProxy.OtelInst.EndSpan(span)
+	defer recordMetrics(ctx, models.GlobalProxy.OtelInst, "UntagResource", startTime, err)
+
+	var meta models.UntagResourceMeta
+	if err = c.ShouldBindJSON(&meta); err != nil {
+		c.JSON(errors.New("ValidationException", err).HTTPResponse(meta))
+		return
+	}
+	otelgo.AddAnnotation(ctx, "Calling UntagResource Service")
+	err = services.UntagResource(ctx, meta)
+	if err != nil {
+		c.JSON(errors.HTTPResponse(err, meta))
+		return
+	}
+	otelgo.AddAnnotation(ctx, "Successfully processed the UntagResource request.")
+	c.JSON(http.StatusOK, gin.H{})
+}
+
+// ListTagsOfResource lists the tags of a table
+// @Description Returns the tags of the table identified by its TableArn
+// @Summary Lists the tags of a table
+// @ID list-tags-of-resource
+// @Produce  json
+// @Success 200 {object} gin.H
+// @Param requestBody body models.ListTagsOfResourceMeta true "Please add request body of type models.ListTagsOfResourceMeta"
+// @Failure 500 {object} gin.H "{"errorMessage":"We had a problem with our server. Try again later.","errorCode":"E0001"}"
+// @Router /listTagsOfResource/ [post]
+// @Failure 401 {object} gin.H "{"errorMessage":"API access not allowed","errorCode": "E0005"}"
+func (h *APIHandler) ListTagsOfResource(c *gin.Context) {
+	startTime := time.Now()
+	ctx := c.Request.Context()
+	var err error
+	defer PanicHandler(c)
+	defer c.Request.Body.Close()
+	otelInstance := models.GlobalProxy.OtelInst
+	if otelInstance == nil {
+		c.JSON(errors.New("InternalServerError", "OpenTelemetry instance not initialized").HTTPResponse(nil))
+		return
+	}
+
+	ctx, span := otelInstance.StartSpan(ctx, "ListTagsOfResource", []attribute.KeyValue{
+		attribute.String("request.method", c.Request.Method),
+		attribute.String("request.url", c.Request.URL.Path),
+	})
+	addParentSpanID(c, span)
+	defer models.GlobalProxy.OtelInst.EndSpan(span)
+	defer recordMetrics(ctx, models.GlobalProxy.OtelInst, "ListTagsOfResource", startTime, err)
+
+	var meta models.ListTagsOfResourceMeta
+	if err = c.ShouldBindJSON(&meta); err != nil {
+		c.JSON(errors.New("ValidationException", err).HTTPResponse(meta))
+		return
+	}
+	otelgo.AddAnnotation(ctx, "Calling ListTagsOfResource Service")
+	output, err := services.ListTagsOfResource(ctx, meta)
+	if err != nil {
+		c.JSON(errors.HTTPResponse(err, meta))
+		return
+	}
+	otelgo.AddAnnotation(ctx, "Successfully processed the ListTagsOfResource request.")
+	c.JSON(http.StatusOK, output)
+}
+
+// DescribeEndpoints returns the endpoints of the adapter
+// @Description Returns the advertised address of the adapter, used by the SDKs for endpoint discovery
+// @Summary Describes the endpoints
+// @ID describe-endpoints
+// @Produce  json
+// @Success 200 {object} gin.H
+// @Failure 500 {object} gin.H "{"errorMessage":"We had a problem with our server. Try again later.","errorCode":"E0001"}"
+// @Router /describeEndpoints/ [post]
+// @Failure 401 {object} gin.H "{"errorMessage":"API access not allowed","errorCode": "E0005"}"
+func (h *APIHandler) DescribeEndpoints(c *gin.Context) {
+	startTime := time.Now()
+	ctx := c.Request.Context()
+	var err error
+	defer PanicHandler(c)
+	defer c.Request.Body.Close()
+	otelInstance := models.GlobalProxy.OtelInst
+	if otelInstance == nil {
+		c.JSON(errors.New("InternalServerError", "OpenTelemetry instance not initialized").HTTPResponse(nil))
+		return
+	}
+
+	ctx, span := otelInstance.StartSpan(ctx, "DescribeEndpoints", []attribute.KeyValue{
+		attribute.String("request.method", c.Request.Method),
+		attribute.String("request.url", c.Request.URL.Path),
+	})
+	addParentSpanID(c, span)
+	defer models.GlobalProxy.OtelInst.EndSpan(span)
+	defer recordMetrics(ctx, models.GlobalProxy.OtelInst, "DescribeEndpoints", startTime, err)
+
+	otelgo.AddAnnotation(ctx, "Calling DescribeEndpoints Service")
+	output := services.DescribeEndpoints(c.Request.Host)
+	otelgo.AddAnnotation(ctx, "Successfully processed the DescribeEndpoints request.")
+	c.JSON(http.StatusOK, output)
+}
+
+// DescribeLimits returns the capacity limits
+// @Description Returns the account and table capacity limits configured for the adapter
+// @Summary Describes the capacity limits
+// @ID describe-limits
+// @Produce  json
+// @Success 200 {object} gin.H
+// @Failure 500 {object} gin.H "{"errorMessage":"We had a problem with our server. Try again later.","errorCode":"E0001"}"
+// @Router /describeLimits/ [post]
+// @Failure 401 {object} gin.H "{"errorMessage":"API access not allowed","errorCode": "E0005"}"
+func (h *APIHandler) DescribeLimits(c *gin.Context) {
+	startTime := time.Now()
+	ctx := c.Request.Context()
+	var err error
+	defer PanicHandler(c)
+	defer c.Request.Body.Close()
+	otelInstance := models.GlobalProxy.OtelInst
+	if otelInstance == nil {
+		c.JSON(errors.New("InternalServerError", "OpenTelemetry instance not initialized").HTTPResponse(nil))
+		return
+	}
+
+	ctx, span := otelInstance.StartSpan(ctx, "DescribeLimits", []attribute.KeyValue{
+		attribute.String("request.method", c.Request.Method),
+		attribute.String("request.url", c.Request.URL.Path),
+	})
+	addParentSpanID(c, span)
+	defer models.GlobalProxy.OtelInst.EndSpan(span)
+	defer recordMetrics(ctx, models.GlobalProxy.OtelInst, "DescribeLimits", startTime, err)
+
+	otelgo.AddAnnotation(ctx, "Calling DescribeLimits Service")
+	output := services.DescribeLimits()
+	otelgo.AddAnnotation(ctx, "Successfully processed the DescribeLimits request.")
+	c.JSON(http.StatusOK, output)
+}
@@ -62,6 +62,38 @@ var (
         enabledStream STRING(MAX),
         uniqueValue   STRING(MAX),
     ) PRIMARY KEY (tableName)`
+
+	adapterIndexDDL = `
+	CREATE TABLE dynamodb_adapter_index_ddl (
+		tableName STRING(MAX) NOT NULL,
+		indexName STRING(MAX) NOT NULL,
+		spannerIndexName STRING(MAX),
+		indexType STRING(MAX),
+		partitionKey STRING(MAX),
+		sortKey STRING(MAX),
+		projectionType STRING(MAX),
+		nonKeyAttributes ARRAY<STRING(MAX)>,
+	) PRIMARY KEY (tableName, indexName)`
+
+	adapterTTLDDL = `
+	CREATE TABLE dynamodb_adapter_ttl (
+		tableName STRING(MAX) NOT NULL,
+		attributeName STRING(MAX),
+	) PRIMARY KEY (tableName)`
+
+	adapterTagsDDL = `
+	CREATE TABLE dynamodb_adapter_tags (
+		tableName STRING(MAX) NOT NULL,
+		tagKey STRING(MAX) NOT NULL,
+		tagValue STRING(MAX),
+	) PRIMARY KEY (tableName, tagKey)`
+
+	adapterLeaseDDL = `
+	CREATE TABLE dynamodb_adapter_lease (
+		name STRING(MAX) NOT NULL,
+		owner STRING(MAX),
+		expiresAt TIMESTAMP,
+	) PRIMARY KEY (name)`
 )
 
 // Entry point for the application
@@ -185,6 +217,18 @@ func executeSetup(ctx context.Context, adminClient *Admindatabase.DatabaseAdminC
 	if err := createTable(ctx, adminClient, databaseName, adapterConfigManagerDDL); err != nil {
 		log.Fatalf("Failed to create config manager table: %v", err)
 	}
+	if err := createTable(ctx, adminClient, databaseName, adapterIndexDDL); err != nil {
+		log.Fatalf("Failed to create index metadata table: %v", err)
+	}
+	if err := createTable(ctx, adminClient, databaseName, adapterTTLDDL); err != nil {
+		log.Fatalf("Failed to create TimeToLive metadata table: %v", err)
+	}
+	if err := createTable(ctx, adminClient, databaseName, adapterTagsDDL); err != nil {
+		log.Fatalf("Failed to create tags table: %v", err)
+	}
+	if err := createTable(ctx, adminClient, databaseName, adapterLeaseDDL); err != nil {
+		log.Fatalf("Failed to create lease table: %v", err)
+	}
 
 	// Process each DynamoDB table
 	client := createDynamoClient()
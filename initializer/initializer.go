@@ -40,5 +40,7 @@ func InitAll(filepath string) error {
 		return err
 	}
 	services.StartConfigManager()
+	services.RestoreIndexOperations(context.Background())
+	services.StartTTLSweeper()
 	return nil
 }
@@ -145,7 +145,7 @@ var (
 			"employee": {},
 		},
 	}
-	TestGetBatch2Output = `{"Responses":{"employee":[]}}`
+	TestGetBatch2Output = `{"Responses":{"employee":[]},"UnprocessedKeys":{}}`
 
 	TestGetBatch3Name = "3: Keys present for 1 table"
 	TestGetBatch3     = models.BatchGetMeta{
@@ -159,7 +159,7 @@ var (
 			},
 		},
 	}
-	TestGetBatch3Output = `{"Responses":{"employee":[{"address":{"S":"Shamli"},"age":{"N":"10"},"emp_id":{"N":"1"},"first_name":{"S":"Marc"},"last_name":{"S":"Richards"},"phone_numbers":{"SS":["+1111111111","+1222222222"]},"profile_pics":{"BS":["U29tZUJ5dGVzRGF0YTE=","U29tZUJ5dGVzRGF0YTI="]},"salaries":{"NS":["1000.5","2000.75"]}},{"address":{"S":"Pune"},"age":{"N":"30"},"emp_id":{"N":"3"},"first_name":{"S":"Alice"},"last_name":{"S":"Trentor"},"phone_numbers":{"SS":["+1444444444","+1555555555"]},"profile_pics":{"BS":["U29tZUJ5dGVzRGF0YTQ=","U29tZUJ5dGVzRGF0YTU="]},"salaries":{"NS":["4000.25","5000.5","6000.75"]}},{"address":{"S":"London"},"age":{"N":"50"},"emp_id":{"N":"5"},"first_name":{"S":"David"},"last_name":{"S":"Lomond"},"phone_numbers":{"SS":["+1777777777","+1888888888","+1999999999"]},"profile_pics":{"BS":["U29tZUJ5dGVzRGF0YTc=","U29tZUJ5dGVzRGF0YTg="]},"salaries":{"NS":["9000.5"]}}]}}`
+	TestGetBatch3Output = `{"Responses":{"employee":[{"address":{"S":"Shamli"},"age":{"N":"10"},"emp_id":{"N":"1"},"first_name":{"S":"Marc"},"last_name":{"S":"Richards"},"phone_numbers":{"SS":["+1111111111","+1222222222"]},"profile_pics":{"BS":["U29tZUJ5dGVzRGF0YTE=","U29tZUJ5dGVzRGF0YTI="]},"salaries":{"NS":["1000.5","2000.75"]}},{"address":{"S":"Pune"},"age":{"N":"30"},"emp_id":{"N":"3"},"first_name":{"S":"Alice"},"last_name":{"S":"Trentor"},"phone_numbers":{"SS":["+1444444444","+1555555555"]},"profile_pics":{"BS":["U29tZUJ5dGVzRGF0YTQ=","U29tZUJ5dGVzRGF0YTU="]},"salaries":{"NS":["4000.25","5000.5","6000.75"]}},{"address":{"S":"London"},"age":{"N":"50"},"emp_id":{"N":"5"},"first_name":{"S":"David"},"last_name":{"S":"Lomond"},"phone_numbers":{"SS":["+1777777777","+1888888888","+1999999999"]},"profile_pics":{"BS":["U29tZUJ5dGVzRGF0YTc=","U29tZUJ5dGVzRGF0YTg="]},"salaries":{"NS":["9000.5"]}}]},"UnprocessedKeys":{}}`
 
 	TestGetBatch4Name = "4: Keys present for 2 table"
 	TestGetBatch4     = models.BatchGetMeta{
@@ -179,7 +179,7 @@ var (
 			},
 		},
 	}
-	TestGetBatch4Output = `{"Responses":{"department":[{"d_id":{"N":"100"},"d_name":{"S":"Engineering"},"d_specialization":{"S":"CSE, ECE, Civil"}},{"d_id":{"N":"300"},"d_name":{"S":"Culture"},"d_specialization":{"S":"History"}}],"employee":[{"address":{"S":"Shamli"},"age":{"N":"10"},"emp_id":{"N":"1"},"first_name":{"S":"Marc"},"last_name":{"S":"Richards"},"phone_numbers":{"SS":["+1111111111","+1222222222"]},"profile_pics":{"BS":["U29tZUJ5dGVzRGF0YTE=","U29tZUJ5dGVzRGF0YTI="]},"salaries":{"NS":["1000.5","2000.75"]}},{"address":{"S":"Pune"},"age":{"N":"30"},"emp_id":{"N":"3"},"first_name":{"S":"Alice"},"last_name":{"S":"Trentor"},"phone_numbers":{"SS":["+1444444444","+1555555555"]},"profile_pics":{"BS":["U29tZUJ5dGVzRGF0YTQ=","U29tZUJ5dGVzRGF0YTU="]},"salaries":{"NS":["4000.25","5000.5","6000.75"]}},{"address":{"S":"London"},"age":{"N":"50"},"emp_id":{"N":"5"},"first_name":{"S":"David"},"last_name":{"S":"Lomond"},"phone_numbers":{"SS":["+1777777777","+1888888888","+1999999999"]},"profile_pics":{"BS":["U29tZUJ5dGVzRGF0YTc=","U29tZUJ5dGVzRGF0YTg="]},"salaries":{"NS":["9000.5"]}}]}}`
+	TestGetBatch4Output = `{"Responses":{"department":[{"d_id":{"N":"100"},"d_name":{"S":"Engineering"},"d_specialization":{"S":"CSE, ECE, Civil"}},{"d_id":{"N":"300"},"d_name":{"S":"Culture"},"d_specialization":{"S":"History"}}],"employee":[{"address":{"S":"Shamli"},"age":{"N":"10"},"emp_id":{"N":"1"},"first_name":{"S":"Marc"},"last_name":{"S":"Richards"},"phone_numbers":{"SS":["+1111111111","+1222222222"]},"profile_pics":{"BS":["U29tZUJ5dGVzRGF0YTE=","U29tZUJ5dGVzRGF0YTI="]},"salaries":{"NS":["1000.5","2000.75"]}},{"address":{"S":"Pune"},"age":{"N":"30"},"emp_id":{"N":"3"},"first_name":{"S":"Alice"},"last_name":{"S":"Trentor"},"phone_numbers":{"SS":["+1444444444","+1555555555"]},"profile_pics":{"BS":["U29tZUJ5dGVzRGF0YTQ=","U29tZUJ5dGVzRGF0YTU="]},"salaries":{"NS":["4000.25","5000.5","6000.75"]}},{"address":{"S":"London"},"age":{"N":"50"},"emp_id":{"N":"5"},"first_name":{"S":"David"},"last_name":{"S":"Lomond"},"phone_numbers":{"SS":["+1777777777","+1888888888","+1999999999"]},"profile_pics":{"BS":["U29tZUJ5dGVzRGF0YTc=","U29tZUJ5dGVzRGF0YTg="]},"salaries":{"NS":["9000.5"]}}]},"UnprocessedKeys":{}}`
 
 	TestGetBatch5Name = "5: ProjectionExpression without ExpressionAttributeNames for 1 table"
 	TestGetBatch5     = models.BatchGetMeta{
@@ -194,7 +194,7 @@ var (
 			},
 		},
 	}
-	TestGetBatch5Output = `{"Responses":{"employee":[{"address":{"S":"Shamli"},"emp_id":{"N":"1"},"first_name":{"S":"Marc"},"last_name":{"S":"Richards"},"phone_numbers":{"SS":["+1111111111","+1222222222"]},"profile_pics":{"BS":["U29tZUJ5dGVzRGF0YTE=","U29tZUJ5dGVzRGF0YTI="]}},{"address":{"S":"Pune"},"emp_id":{"N":"3"},"first_name":{"S":"Alice"},"last_name":{"S":"Trentor"},"phone_numbers":{"SS":["+1444444444","+1555555555"]},"profile_pics":{"BS":["U29tZUJ5dGVzRGF0YTQ=","U29tZUJ5dGVzRGF0YTU="]}},{"address":{"S":"London"},"emp_id":{"N":"5"},"first_name":{"S":"David"},"last_name":{"S":"Lomond"},"phone_numbers":{"SS":["+1777777777","+1888888888","+1999999999"]},"profile_pics":{"BS":["U29tZUJ5dGVzRGF0YTc=","U29tZUJ5dGVzRGF0YTg="]}}]}}`
+	TestGetBatch5Output = `{"Responses":{"employee":[{"address":{"S":"Shamli"},"emp_id":{"N":"1"},"first_name":{"S":"Marc"},"last_name":{"S":"Richards"},"phone_numbers":{"SS":["+1111111111","+1222222222"]},"profile_pics":{"BS":["U29tZUJ5dGVzRGF0YTE=","U29tZUJ5dGVzRGF0YTI="]}},{"address":{"S":"Pune"},"emp_id":{"N":"3"},"first_name":{"S":"Alice"},"last_name":{"S":"Trentor"},"phone_numbers":{"SS":["+1444444444","+1555555555"]},"profile_pics":{"BS":["U29tZUJ5dGVzRGF0YTQ=","U29tZUJ5dGVzRGF0YTU="]}},{"address":{"S":"London"},"emp_id":{"N":"5"},"first_name":{"S":"David"},"last_name":{"S":"Lomond"},"phone_numbers":{"SS":["+1777777777","+1888888888","+1999999999"]},"profile_pics":{"BS":["U29tZUJ5dGVzRGF0YTc=","U29tZUJ5dGVzRGF0YTg="]}}]},"UnprocessedKeys":{}}`
 
 	TestGetBatch6Name = "6: ProjectionExpression without ExpressionAttributeNames for 2 table"
 	TestGetBatch6     = models.BatchGetMeta{
@@ -216,7 +216,7 @@ var (
 			},
 		},
 	}
-	TestGetBatch6Output = `{"Responses":{"department":[{"d_id":{"N":"100"},"d_name":{"S":"Engineering"},"d_specialization":{"S":"CSE, ECE, Civil"}},{"d_id":{"N":"300"},"d_name":{"S":"Culture"},"d_specialization":{"S":"History"}}],"employee":[{"address":{"S":"Shamli"},"emp_id":{"N":"1"},"first_name":{"S":"Marc"},"last_name":{"S":"Richards"},"phone_numbers":{"SS":["+1111111111","+1222222222"]},"profile_pics":{"BS":["U29tZUJ5dGVzRGF0YTE=","U29tZUJ5dGVzRGF0YTI="]}},{"address":{"S":"Pune"},"emp_id":{"N":"3"},"first_name":{"S":"Alice"},"last_name":{"S":"Trentor"},"phone_numbers":{"SS":["+1444444444","+1555555555"]},"profile_pics":{"BS":["U29tZUJ5dGVzRGF0YTQ=","U29tZUJ5dGVzRGF0YTU="]}},{"address":{"S":"London"},"emp_id":{"N":"5"},"first_name":{"S":"David"},"last_name":{"S":"Lomond"},"phone_numbers":{"SS":["+1777777777","+1888888888","+1999999999"]},"profile_pics":{"BS":["U29tZUJ5dGVzRGF0YTc=","U29tZUJ5dGVzRGF0YTg="]}}]}}`
+	TestGetBatch6Output = `{"Responses":{"department":[{"d_id":{"N":"100"},"d_name":{"S":"Engineering"},"d_specialization":{"S":"CSE, ECE, Civil"}},{"d_id":{"N":"300"},"d_name":{"S":"Culture"},"d_specialization":{"S":"History"}}],"employee":[{"address":{"S":"Shamli"},"emp_id":{"N":"1"},"first_name":{"S":"Marc"},"last_name":{"S":"Richards"},"phone_numbers":{"SS":["+1111111111","+1222222222"]},"profile_pics":{"BS":["U29tZUJ5dGVzRGF0YTE=","U29tZUJ5dGVzRGF0YTI="]}},{"address":{"S":"Pune"},"emp_id":{"N":"3"},"first_name":{"S":"Alice"},"last_name":{"S":"Trentor"},"phone_numbers":{"SS":["+1444444444","+1555555555"]},"profile_pics":{"BS":["U29tZUJ5dGVzRGF0YTQ=","U29tZUJ5dGVzRGF0YTU="]}},{"address":{"S":"London"},"emp_id":{"N":"5"},"first_name":{"S":"David"},"last_name":{"S":"Lomond"},"phone_numbers":{"SS":["+1777777777","+1888888888","+1999999999"]},"profile_pics":{"BS":["U29tZUJ5dGVzRGF0YTc=","U29tZUJ5dGVzRGF0YTg="]}}]},"UnprocessedKeys":{}}`
 
 	TestGetBatch7Name = "7: ProjectionExpression with ExpressionAttributeNames for 1 table"
 	TestGetBatch7     = models.BatchGetMeta{
@@ -235,7 +235,7 @@ var (
 			},
 		},
 	}
-	TestGetBatch7Output = `{"Responses":{"employee":[{"address":{"S":"Shamli"},"emp_id":{"N":"1"},"first_name":{"S":"Marc"},"last_name":{"S":"Richards"},"phone_numbers":{"SS":["+1111111111","+1222222222"]},"profile_pics":{"BS":["U29tZUJ5dGVzRGF0YTE=","U29tZUJ5dGVzRGF0YTI="]}},{"address":{"S":"Pune"},"emp_id":{"N":"3"},"first_name":{"S":"Alice"},"last_name":{"S":"Trentor"},"phone_numbers":{"SS":["+1444444444","+1555555555"]},"profile_pics":{"BS":["U29tZUJ5dGVzRGF0YTQ=","U29tZUJ5dGVzRGF0YTU="]}},{"address":{"S":"London"},"emp_id":{"N":"5"},"first_name":{"S":"David"},"last_name":{"S":"Lomond"},"phone_numbers":{"SS":["+1777777777","+1888888888","+1999999999"]},"profile_pics":{"BS":["U29tZUJ5dGVzRGF0YTc=","U29tZUJ5dGVzRGF0YTg="]}}]}}`
+	TestGetBatch7Output = `{"Responses":{"employee":[{"address":{"S":"Shamli"},"emp_id":{"N":"1"},"first_name":{"S":"Marc"},"last_name":{"S":"Richards"},"phone_numbers":{"SS":["+1111111111","+1222222222"]},"profile_pics":{"BS":["U29tZUJ5dGVzRGF0YTE=","U29tZUJ5dGVzRGF0YTI="]}},{"address":{"S":"Pune"},"emp_id":{"N":"3"},"first_name":{"S":"Alice"},"last_name":{"S":"Trentor"},"phone_numbers":{"SS":["+1444444444","+1555555555"]},"profile_pics":{"BS":["U29tZUJ5dGVzRGF0YTQ=","U29tZUJ5dGVzRGF0YTU="]}},{"address":{"S":"London"},"emp_id":{"N":"5"},"first_name":{"S":"David"},"last_name":{"S":"Lomond"},"phone_numbers":{"SS":["+1777777777","+1888888888","+1999999999"]},"profile_pics":{"BS":["U29tZUJ5dGVzRGF0YTc=","U29tZUJ5dGVzRGF0YTg="]}}]},"UnprocessedKeys":{}}`
 
 	TestGetBatch8Name = "8: ProjectionExpression with ExpressionAttributeNames for 2 table"
 	TestGetBatch8     = models.BatchGetMeta{
@@ -265,7 +265,7 @@ var (
 			},
 		},
 	}
-	TestGetBatch8Output = `{"Responses":{"department":[{"d_id":{"N":"100"},"d_name":{"S":"Engineering"},"d_specialization":{"S":"CSE, ECE, Civil"}},{"d_id":{"N":"300"},"d_name":{"S":"Culture"},"d_specialization":{"S":"History"}}],"employee":[{"address":{"S":"Shamli"},"emp_id":{"N":"1"},"first_name":{"S":"Marc"},"last_name":{"S":"Richards"},"phone_numbers":{"SS":["+1111111111","+1222222222"]},"profile_pics":{"BS":["U29tZUJ5dGVzRGF0YTE=","U29tZUJ5dGVzRGF0YTI="]}},{"address":{"S":"Pune"},"emp_id":{"N":"3"},"first_name":{"S":"Alice"},"last_name":{"S":"Trentor"},"phone_numbers":{"SS":["+1444444444","+1555555555"]},"profile_pics":{"BS":["U29tZUJ5dGVzRGF0YTQ=","U29tZUJ5dGVzRGF0YTU="]}},{"address":{"S":"London"},"emp_id":{"N":"5"},"first_name":{"S":"David"},"last_name":{"S":"Lomond"},"phone_numbers":{"SS":["+1777777777","+1888888888","+1999999999"]},"profile_pics":{"BS":["U29tZUJ5dGVzRGF0YTc=","U29tZUJ5dGVzRGF0YTg="]}}]}}`
+	TestGetBatch8Output = `{"Responses":{"department":[{"d_id":{"N":"100"},"d_name":{"S":"Engineering"},"d_specialization":{"S":"CSE, ECE, Civil"}},{"d_id":{"N":"300"},"d_name":{"S":"Culture"},"d_specialization":{"S":"History"}}],"employee":[{"address":{"S":"Shamli"},"emp_id":{"N":"1"},"first_name":{"S":"Marc"},"last_name":{"S":"Richards"},"phone_numbers":{"SS":["+1111111111","+1222222222"]},"profile_pics":{"BS":["U29tZUJ5dGVzRGF0YTE=","U29tZUJ5dGVzRGF0YTI="]}},{"address":{"S":"Pune"},"emp_id":{"N":"3"},"first_name":{"S":"Alice"},"last_name":{"S":"Trentor"},"phone_numbers":{"SS":["+1444444444","+1555555555"]},"profile_pics":{"BS":["U29tZUJ5dGVzRGF0YTQ=","U29tZUJ5dGVzRGF0YTU="]}},{"address":{"S":"London"},"emp_id":{"N":"5"},"first_name":{"S":"David"},"last_name":{"S":"Lomond"},"phone_numbers":{"SS":["+1777777777","+1888888888","+1999999999"]},"profile_pics":{"BS":["U29tZUJ5dGVzRGF0YTc=","U29tZUJ5dGVzRGF0YTg="]}}]},"UnprocessedKeys":{}}`
 
 	TestGetBatch9Name = "9: ProjectionExpression but ExpressionAttributeNames not present"
 	TestGetBatch9     = models.BatchGetMeta{
@@ -280,7 +280,7 @@ var (
 			},
 		},
 	}
-	TestGetBatch9Output = `{"Responses":{"employee":[{"address":{"S":"Shamli"},"first_name":{"S":"Marc"},"profile_pics":{"BS":["U29tZUJ5dGVzRGF0YTE=","U29tZUJ5dGVzRGF0YTI="]}},{"address":{"S":"Pune"},"first_name":{"S":"Alice"},"profile_pics":{"BS":["U29tZUJ5dGVzRGF0YTQ=","U29tZUJ5dGVzRGF0YTU="]}},{"address":{"S":"London"},"first_name":{"S":"David"},"profile_pics":{"BS":["U29tZUJ5dGVzRGF0YTc=","U29tZUJ5dGVzRGF0YTg="]}}]}}`
+	TestGetBatch9Output = `{"Responses":{"employee":[{"address":{"S":"Shamli"},"first_name":{"S":"Marc"},"profile_pics":{"BS":["U29tZUJ5dGVzRGF0YTE=","U29tZUJ5dGVzRGF0YTI="]}},{"address":{"S":"Pune"},"first_name":{"S":"Alice"},"profile_pics":{"BS":["U29tZUJ5dGVzRGF0YTQ=","U29tZUJ5dGVzRGF0YTU="]}},{"address":{"S":"London"},"first_name":{"S":"David"},"profile_pics":{"BS":["U29tZUJ5dGVzRGF0YTc=","U29tZUJ5dGVzRGF0YTg="]}}]},"UnprocessedKeys":{}}`
 
 	TestGetBatch10Name = "10: Wrong Keys"
 	TestGetBatch10     = models.BatchGetMeta{
@@ -316,8 +316,8 @@ var (
 			},
 		},
 	}
-	TestGetBatchForListOutput = `{"Responses":{"test_table":[{"category":{"S":"category"},"id":{"S":"testing"},"list_type":{"L":[{"S":"John Doe"},{"S":"62536"},{"BOOL":true}]},"rank_list":{"S":"rank_list"},"updated_at":{"S":"2024-12-04T11:02:02Z"}},{"category":{"S":"category1"},"id":{"S":"id"},"list_type":{"L":[{"S":"string_value"},{"S":"12345"},{"BOOL":true},{"L":[{"N":"1"},{"N":"2"},{"N":"3"}]},{"S":"testing"}]},"rank_list":{"S":"rank_list1"},"updated_at":{"S":"2024-12-04T11:02:02Z"}},{"category":{"S":"category2"},"id":{"S":"id2"},"list_type":{"L":[{"S":"test"},{"S":"dummy_value"},{"S":"62536"}]},"rank_list":{"S":"rank_list2"},"updated_at":{"S":"2024-12-04T11:02:02Z"}}]}}`
-	TestGetBatch11Output      = `{"Responses":{"mapdynamo":[{"address":{"M":{"active":{"BOOL":true},"additional_details":{"M":{"additional_details_2":{"M":{"landmark_field":{"S":"near water tank road"},"landmark_field_number":{"N":"1001"}}},"apartment_number":{"S":"5B"},"landmark":{"S":"Near Central Park"},"landmark notes":{"B":"YmluYXJ5X2RhdGE="}}},"mobilenumber":{"N":"9035599089"},"notes":{"B":"YmluYXJ5X2RhdGE="},"permanent_address":{"S":"789 Elm St, Springfield, SP"},"present_address":{"S":"101 Maple Ave, Metropolis, MP"}}},"contact_ranking_list":{"S":"1,2,3"},"context":{"S":"user-profile"},"guid":{"S":"123e4567-e89b-12d3-a456-value001"},"name":{"S":"Jane Smith"}}]}}`
+	TestGetBatchForListOutput = `{"Responses":{"test_table":[{"category":{"S":"category"},"id":{"S":"testing"},"list_type":{"L":[{"S":"John Doe"},{"S":"62536"},{"BOOL":true}]},"rank_list":{"S":"rank_list"},"updated_at":{"S":"2024-12-04T11:02:02Z"}},{"category":{"S":"category1"},"id":{"S":"id"},"list_type":{"L":[{"S":"string_value"},{"S":"12345"},{"BOOL":true},{"L":[{"N":"1"},{"N":"2"},{"N":"3"}]},{"S":"testing"}]},"rank_list":{"S":"rank_list1"},"updated_at":{"S":"2024-12-04T11:02:02Z"}},{"category":{"S":"category2"},"id":{"S":"id2"},"list_type":{"L":[{"S":"test"},{"S":"dummy_value"},{"S":"62536"}]},"rank_list":{"S":"rank_list2"},"updated_at":{"S":"2024-12-04T11:02:02Z"}}]},"UnprocessedKeys":{}}`
+	TestGetBatch11Output      = `{"Responses":{"mapdynamo":[{"address":{"M":{"active":{"BOOL":true},"additional_details":{"M":{"additional_details_2":{"M":{"landmark_field":{"S":"near water tank road"},"landmark_field_number":{"N":"1001"}}},"apartment_number":{"S":"5B"},"landmark":{"S":"Near Central Park"},"landmark notes":{"B":"YmluYXJ5X2RhdGE="}}},"mobilenumber":{"N":"9035599089"},"notes":{"B":"YmluYXJ5X2RhdGE="},"permanent_address":{"S":"789 Elm St, Springfield, SP"},"present_address":{"S":"101 Maple Ave, Metropolis, MP"}}},"contact_ranking_list":{"S":"1,2,3"},"context":{"S":"user-profile"},"guid":{"S":"123e4567-e89b-12d3-a456-value001"},"name":{"S":"Jane Smith"}}]},"UnprocessedKeys":{}}`
 )
 
 // test Data for Query API
@@ -490,9 +490,27 @@ var (
 			":last": {S: aws.String("Trentor")},
 		},
 		FilterExp: "last_name = :last",
+		Select:    "SPECIFIC_ATTRIBUTES",
+	}
+
+	//Select which is not a DynamoDB Select
+	queryTestCaseInvalidSelect = models.Query{
+		TableName: "employee",
 		Select:    "ALL",
 	}
 
+	//SPECIFIC_ATTRIBUTES without ProjectionExpression
+	queryTestCaseSpecificWithoutProjection = models.Query{
+		TableName: "employee",
+		Select:    "SPECIFIC_ATTRIBUTES",
+	}
+
+	//ALL_PROJECTED_ATTRIBUTES without IndexName
+	queryTestCaseProjectedWithoutIndex = models.Query{
+		TableName: "employee",
+		Select:    "ALL_PROJECTED_ATTRIBUTES",
+	}
+
 	//all attributes
 	queryTestCase16 = models.Query{
 		TableName: "employee",
@@ -512,33 +530,33 @@ var (
 		Limit:         4,
 	}
 
-	queryTestCaseOutput1 = `{"Count":5,"Items":[{"address":{"S":"Shamli"},"age":{"N":"10"},"emp_id":{"N":"1"},"first_name":{"S":"Marc"},"last_name":{"S":"Richards"},"phone_numbers":{"SS":["+1111111111","+1222222222"]},"profile_pics":{"BS":["U29tZUJ5dGVzRGF0YTE=","U29tZUJ5dGVzRGF0YTI="]},"salaries":{"NS":["1000.5","2000.75"]}},{"address":{"S":"New York"},"age":{"N":"20"},"emp_id":{"N":"2"},"first_name":{"S":"Catalina"},"last_name":{"S":"Smith"},"phone_numbers":{"SS":["+1333333333"]},"profile_pics":{"BS":["U29tZUJ5dGVzRGF0YTM="]},"salaries":{"NS":["3000"]}},{"address":{"S":"Pune"},"age":{"N":"30"},"emp_id":{"N":"3"},"first_name":{"S":"Alice"},"last_name":{"S":"Trentor"},"phone_numbers":{"SS":["+1444444444","+1555555555"]},"profile_pics":{"BS":["U29tZUJ5dGVzRGF0YTQ=","U29tZUJ5dGVzRGF0YTU="]},"salaries":{"NS":["4000.25","5000.5","6000.75"]}},{"address":{"S":"Silicon Valley"},"age":{"N":"40"},"emp_id":{"N":"4"},"first_name":{"S":"Lea"},"last_name":{"S":"Martin"},"phone_numbers":{"SS":["+1666666666"]},"profile_pics":{"BS":["U29tZUJ5dGVzRGF0YTY="]},"salaries":{"NS":["7000","8000.25"]}},{"address":{"S":"London"},"age":{"N":"50"},"emp_id":{"N":"5"},"first_name":{"S":"David"},"last_name":{"S":"Lomond"},"phone_numbers":{"SS":["+1777777777","+1888888888","+1999999999"]},"profile_pics":{"BS":["U29tZUJ5dGVzRGF0YTc=","U29tZUJ5dGVzRGF0YTg="]},"salaries":{"NS":["9000.5"]}}]}`
+	queryTestCaseOutput1 = `{"Count":5,"Items":[{"address":{"S":"Shamli"},"age":{"N":"10"},"emp_id":{"N":"1"},"first_name":{"S":"Marc"},"last_name":{"S":"Richards"},"phone_numbers":{"SS":["+1111111111","+1222222222"]},"profile_pics":{"BS":["U29tZUJ5dGVzRGF0YTE=","U29tZUJ5dGVzRGF0YTI="]},"salaries":{"NS":["1000.5","2000.75"]}},{"address":{"S":"New York"},"age":{"N":"20"},"emp_id":{"N":"2"},"first_name":{"S":"Catalina"},"last_name":{"S":"Smith"},"phone_numbers":{"SS":["+1333333333"]},"profile_pics":{"BS":["U29tZUJ5dGVzRGF0YTM="]},"salaries":{"NS":["3000"]}},{"address":{"S":"Pune"},"age":{"N":"30"},"emp_id":{"N":"3"},"first_name":{"S":"Alice"},"last_name":{"S":"Trentor"},"phone_numbers":{"SS":["+1444444444","+1555555555"]},"profile_pics":{"BS":["U29tZUJ5dGVzRGF0YTQ=","U29tZUJ5dGVzRGF0YTU="]},"salaries":{"NS":["4000.25","5000.5","6000.75"]}},{"address":{"S":"Silicon Valley"},"age":{"N":"40"},"emp_id":{"N":"4"},"first_name":{"S":"Lea"},"last_name":{"S":"Martin"},"phone_numbers":{"SS":["+1666666666"]},"profile_pics":{"BS":["U29tZUJ5dGVzRGF0YTY="]},"salaries":{"NS":["7000","8000.25"]}},{"address":{"S":"London"},"age":{"N":"50"},"emp_id":{"N":"5"},"first_name":{"S":"David"},"last_name":{"S":"Lomond"},"phone_numbers":{"SS":["+1777777777","+1888888888","+1999999999"]},"profile_pics":{"BS":["U29tZUJ5dGVzRGF0YTc=","U29tZUJ5dGVzRGF0YTg="]},"salaries":{"NS":["9000.5"]}}],"ScannedCount":5}`
 
-	queryTestCaseOutput2 = `{"Count":5,"Items":[{"emp_id":{"N":"1"},"first_name":{"S":"Marc"}},{"emp_id":{"N":"2"},"first_name":{"S":"Catalina"}},{"emp_id":{"N":"3"},"first_name":{"S":"Alice"}},{"emp_id":{"N":"4"},"first_name":{"S":"Lea"}},{"emp_id":{"N":"5"},"first_name":{"S":"David"}}]}`
+	queryTestCaseOutput2 = `{"Count":5,"Items":[{"emp_id":{"N":"1"},"first_name":{"S":"Marc"}},{"emp_id":{"N":"2"},"first_name":{"S":"Catalina"}},{"emp_id":{"N":"3"},"first_name":{"S":"Alice"}},{"emp_id":{"N":"4"},"first_name":{"S":"Lea"}},{"emp_id":{"N":"5"},"first_name":{"S":"David"}}],"ScannedCount":5}`
 
-	queryTestCaseOutput3 = `{"Count":5,"Items":[{"emp_id":{"N":"1"},"first_name":{"S":"Marc"},"last_name":{"S":"Richards"}},{"emp_id":{"N":"2"},"first_name":{"S":"Catalina"},"last_name":{"S":"Smith"}},{"emp_id":{"N":"3"},"first_name":{"S":"Alice"},"last_name":{"S":"Trentor"}},{"emp_id":{"N":"4"},"first_name":{"S":"Lea"},"last_name":{"S":"Martin"}},{"emp_id":{"N":"5"},"first_name":{"S":"David"},"last_name":{"S":"Lomond"}}]}`
+	queryTestCaseOutput3 = `{"Count":5,"Items":[{"emp_id":{"N":"1"},"first_name":{"S":"Marc"},"last_name":{"S":"Richards"}},{"emp_id":{"N":"2"},"first_name":{"S":"Catalina"},"last_name":{"S":"Smith"}},{"emp_id":{"N":"3"},"first_name":{"S":"Alice"},"last_name":{"S":"Trentor"}},{"emp_id":{"N":"4"},"first_name":{"S":"Lea"},"last_name":{"S":"Martin"}},{"emp_id":{"N":"5"},"first_name":{"S":"David"},"last_name":{"S":"Lomond"}}],"ScannedCount":5}`
 
-	queryTestCaseOutput4 = `{"Count":1,"Items":[{"emp_id":{"N":"2"},"first_name":{"S":"Catalina"},"last_name":{"S":"Smith"}}]}`
+	queryTestCaseOutput4 = `{"Count":1,"Items":[{"emp_id":{"N":"2"},"first_name":{"S":"Catalina"},"last_name":{"S":"Smith"}}],"ScannedCount":1}`
 
-	queryTestCaseOutput6 = `{"Count":1,"Items":[{"emp_id":{"N":"3"},"first_name":{"S":"Alice"},"last_name":{"S":"Trentor"}}]}`
+	queryTestCaseOutput6 = `{"Count":1,"Items":[{"emp_id":{"N":"3"},"first_name":{"S":"Alice"},"last_name":{"S":"Trentor"}}],"ScannedCount":1}`
 
-	queryTestCaseOutput8 = `{"Count":1,"Items":[{"emp_id":{"N":"3"},"first_name":{"S":"Alice"},"last_name":{"S":"Trentor"}}]}`
+	queryTestCaseOutput8 = `{"Count":1,"Items":[{"emp_id":{"N":"3"},"first_name":{"S":"Alice"},"last_name":{"S":"Trentor"}}],"ScannedCount":5}`
 
-	queryTestCaseOutput9 = `{"Count":1,"Items":[{"emp_id":{"N":"3"},"first_name":{"S":"Alice"},"last_name":{"S":"Trentor"}}]}`
+	queryTestCaseOutput9 = `{"Count":1,"Items":[{"emp_id":{"N":"3"},"first_name":{"S":"Alice"},"last_name":{"S":"Trentor"}}],"ScannedCount":1}`
 
-	queryTestCaseOutput10 = `{"Count":5,"Items":[{"address":{"S":"Shamli"},"age":{"N":"10"},"emp_id":{"N":"1"},"first_name":{"S":"Marc"},"last_name":{"S":"Richards"},"phone_numbers":{"SS":["+1111111111","+1222222222"]},"profile_pics":{"BS":["U29tZUJ5dGVzRGF0YTE=","U29tZUJ5dGVzRGF0YTI="]},"salaries":{"NS":["1000.5","2000.75"]}},{"address":{"S":"New York"},"age":{"N":"20"},"emp_id":{"N":"2"},"first_name":{"S":"Catalina"},"last_name":{"S":"Smith"},"phone_numbers":{"SS":["+1333333333"]},"profile_pics":{"BS":["U29tZUJ5dGVzRGF0YTM="]},"salaries":{"NS":["3000"]}},{"address":{"S":"Pune"},"age":{"N":"30"},"emp_id":{"N":"3"},"first_name":{"S":"Alice"},"last_name":{"S":"Trentor"},"phone_numbers":{"SS":["+1444444444","+1555555555"]},"profile_pics":{"BS":["U29tZUJ5dGVzRGF0YTQ=","U29tZUJ5dGVzRGF0YTU="]},"salaries":{"NS":["4000.25","5000.5","6000.75"]}},{"address":{"S":"Silicon Valley"},"age":{"N":"40"},"emp_id":{"N":"4"},"first_name":{"S":"Lea"},"last_name":{"S":"Martin"},"phone_numbers":{"SS":["+1666666666"]},"profile_pics":{"BS":["U29tZUJ5dGVzRGF0YTY="]},"salaries":{"NS":["7000","8000.25"]}},{"address":{"S":"London"},"age":{"N":"50"},"emp_id":{"N":"5"},"first_name":{"S":"David"},"last_name":{"S":"Lomond"},"phone_numbers":{"SS":["+1777777777","+1888888888","+1999999999"]},"profile_pics":{"BS":["U29tZUJ5dGVzRGF0YTc=","U29tZUJ5dGVzRGF0YTg="]},"salaries":{"NS":["9000.5"]}}]}`
+	queryTestCaseOutput10 = `{"Count":5,"Items":[{"address":{"S":"Shamli"},"age":{"N":"10"},"emp_id":{"N":"1"},"first_name":{"S":"Marc"},"last_name":{"S":"Richards"},"phone_numbers":{"SS":["+1111111111","+1222222222"]},"profile_pics":{"BS":["U29tZUJ5dGVzRGF0YTE=","U29tZUJ5dGVzRGF0YTI="]},"salaries":{"NS":["1000.5","2000.75"]}},{"address":{"S":"New York"},"age":{"N":"20"},"emp_id":{"N":"2"},"first_name":{"S":"Catalina"},"last_name":{"S":"Smith"},"phone_numbers":{"SS":["+1333333333"]},"profile_pics":{"BS":["U29tZUJ5dGVzRGF0YTM="]},"salaries":{"NS":["3000"]}},{"address":{"S":"Pune"},"age":{"N":"30"},"emp_id":{"N":"3"},"first_name":{"S":"Alice"},"last_name":{"S":"Trentor"},"phone_numbers":{"SS":["+1444444444","+1555555555"]},"profile_pics":{"BS":["U29tZUJ5dGVzRGF0YTQ=","U29tZUJ5dGVzRGF0YTU="]},"salaries":{"NS":["4000.25","5000.5","6000.75"]}},{"address":{"S":"Silicon Valley"},"age":{"N":"40"},"emp_id":{"N":"4"},"first_name":{"S":"Lea"},"last_name":{"S":"Martin"},"phone_numbers":{"SS":["+1666666666"]},"profile_pics":{"BS":["U29tZUJ5dGVzRGF0YTY="]},"salaries":{"NS":["7000","8000.25"]}},{"address":{"S":"London"},"age":{"N":"50"},"emp_id":{"N":"5"},"first_name":{"S":"David"},"last_name":{"S":"Lomond"},"phone_numbers":{"SS":["+1777777777","+1888888888","+1999999999"]},"profile_pics":{"BS":["U29tZUJ5dGVzRGF0YTc=","U29tZUJ5dGVzRGF0YTg="]},"salaries":{"NS":["9000.5"]}}],"ScannedCount":5}`
 
-	queryTestCaseOutput11 = `{"Count":4,"Items":[{"address":{"S":"Shamli"},"age":{"N":"10"},"emp_id":{"N":"1"},"first_name":{"S":"Marc"},"last_name":{"S":"Richards"},"phone_numbers":{"SS":["+1111111111","+1222222222"]},"profile_pics":{"BS":["U29tZUJ5dGVzRGF0YTE=","U29tZUJ5dGVzRGF0YTI="]},"salaries":{"NS":["1000.5","2000.75"]}},{"address":{"S":"New York"},"age":{"N":"20"},"emp_id":{"N":"2"},"first_name":{"S":"Catalina"},"last_name":{"S":"Smith"},"phone_numbers":{"SS":["+1333333333"]},"profile_pics":{"BS":["U29tZUJ5dGVzRGF0YTM="]},"salaries":{"NS":["3000"]}},{"address":{"S":"Pune"},"age":{"N":"30"},"emp_id":{"N":"3"},"first_name":{"S":"Alice"},"last_name":{"S":"Trentor"},"phone_numbers":{"SS":["+1444444444","+1555555555"]},"profile_pics":{"BS":["U29tZUJ5dGVzRGF0YTQ=","U29tZUJ5dGVzRGF0YTU="]},"salaries":{"NS":["4000.25","5000.5","6000.75"]}},{"address":{"S":"Silicon Valley"},"age":{"N":"40"},"emp_id":{"N":"4"},"first_name":{"S":"Lea"},"last_name":{"S":"Martin"},"phone_numbers":{"SS":["+1666666666"]},"profile_pics":{"BS":["U29tZUJ5dGVzRGF0YTY="]},"salaries":{"NS":["7000","8000.25"]}}],"LastEvaluatedKey":{"emp_id":{"N":"4"},"offset":{"N":"4"}}}`
+	queryTestCaseOutput11 = `{"Count":4,"Items":[{"address":{"S":"Shamli"},"age":{"N":"10"},"emp_id":{"N":"1"},"first_name":{"S":"Marc"},"last_name":{"S":"Richards"},"phone_numbers":{"SS":["+1111111111","+1222222222"]},"profile_pics":{"BS":["U29tZUJ5dGVzRGF0YTE=","U29tZUJ5dGVzRGF0YTI="]},"salaries":{"NS":["1000.5","2000.75"]}},{"address":{"S":"New York"},"age":{"N":"20"},"emp_id":{"N":"2"},"first_name":{"S":"Catalina"},"last_name":{"S":"Smith"},"phone_numbers":{"SS":["+1333333333"]},"profile_pics":{"BS":["U29tZUJ5dGVzRGF0YTM="]},"salaries":{"NS":["3000"]}},{"address":{"S":"Pune"},"age":{"N":"30"},"emp_id":{"N":"3"},"first_name":{"S":"Alice"},"last_name":{"S":"Trentor"},"phone_numbers":{"SS":["+1444444444","+1555555555"]},"profile_pics":{"BS":["U29tZUJ5dGVzRGF0YTQ=","U29tZUJ5dGVzRGF0YTU="]},"salaries":{"NS":["4000.25","5000.5","6000.75"]}},{"address":{"S":"Silicon Valley"},"age":{"N":"40"},"emp_id":{"N":"4"},"first_name":{"S":"Lea"},"last_name":{"S":"Martin"},"phone_numbers":{"SS":["+1666666666"]},"profile_pics":{"BS":["U29tZUJ5dGVzRGF0YTY="]},"salaries":{"NS":["7000","8000.25"]}}],"LastEvaluatedKey":{"emp_id":{"N":"4"}},"ScannedCount":4}`
 
-	queryTestCaseOutput12 = `{"Count":4,"Items":[{"address":{"S":"Shamli"},"age":{"N":"10"},"emp_id":{"N":"1"},"first_name":{"S":"Marc"},"last_name":{"S":"Richards"},"phone_numbers":{"SS":["+1111111111","+1222222222"]},"profile_pics":{"BS":["U29tZUJ5dGVzRGF0YTE=","U29tZUJ5dGVzRGF0YTI="]},"salaries":{"NS":["1000.5","2000.75"]}},{"address":{"S":"New York"},"age":{"N":"20"},"emp_id":{"N":"2"},"first_name":{"S":"Catalina"},"last_name":{"S":"Smith"},"phone_numbers":{"SS":["+1333333333"]},"profile_pics":{"BS":["U29tZUJ5dGVzRGF0YTM="]},"salaries":{"NS":["3000"]}},{"address":{"S":"Pune"},"age":{"N":"30"},"emp_id":{"N":"3"},"first_name":{"S":"Alice"},"last_name":{"S":"Trentor"},"phone_numbers":{"SS":["+1444444444","+1555555555"]},"profile_pics":{"BS":["U29tZUJ5dGVzRGF0YTQ=","U29tZUJ5dGVzRGF0YTU="]},"salaries":{"NS":["4000.25","5000.5","6000.75"]}},{"address":{"S":"Silicon Valley"},"age":{"N":"40"},"emp_id":{"N":"4"},"first_name":{"S":"Lea"},"last_name":{"S":"Martin"},"phone_numbers":{"SS":["+1666666666"]},"profile_pics":{"BS":["U29tZUJ5dGVzRGF0YTY="]},"salaries":{"NS":["7000","8000.25"]}}],"LastEvaluatedKey":{"emp_id":{"N":"4"},"offset":{"N":"4"}}}`
+	queryTestCaseOutput12 = `{"Count":4,"Items":[{"address":{"S":"Shamli"},"age":{"N":"10"},"emp_id":{"N":"1"},"first_name":{"S":"Marc"},"last_name":{"S":"Richards"},"phone_numbers":{"SS":["+1111111111","+1222222222"]},"profile_pics":{"BS":["U29tZUJ5dGVzRGF0YTE=","U29tZUJ5dGVzRGF0YTI="]},"salaries":{"NS":["1000.5","2000.75"]}},{"address":{"S":"New York"},"age":{"N":"20"},"emp_id":{"N":"2"},"first_name":{"S":"Catalina"},"last_name":{"S":"Smith"},"phone_numbers":{"SS":["+1333333333"]},"profile_pics":{"BS":["U29tZUJ5dGVzRGF0YTM="]},"salaries":{"NS":["3000"]}},{"address":{"S":"Pune"},"age":{"N":"30"},"emp_id":{"N":"3"},"first_name":{"S":"Alice"},"last_name":{"S":"Trentor"},"phone_numbers":{"SS":["+1444444444","+1555555555"]},"profile_pics":{"BS":["U29tZUJ5dGVzRGF0YTQ=","U29tZUJ5dGVzRGF0YTU="]},"salaries":{"NS":["4000.25","5000.5","6000.75"]}},{"address":{"S":"Silicon Valley"},"age":{"N":"40"},"emp_id":{"N":"4"},"first_name":{"S":"Lea"},"last_name":{"S":"Martin"},"phone_numbers":{"SS":["+1666666666"]},"profile_pics":{"BS":["U29tZUJ5dGVzRGF0YTY="]},"salaries":{"NS":["7000","8000.25"]}}],"LastEvaluatedKey":{"emp_id":{"N":"4"}},"ScannedCount":4}`
 
-	queryTestCaseOutput13 = `{"Count":5,"Items":[]}`
+	queryTestCaseOutput13 = `{"Count":5,"Items":[],"ScannedCount":5}`
 
-	queryTestCaseOutput14 = `{"Count":1,"Items":[]}`
+	queryTestCaseOutput14 = `{"Count":1,"Items":[],"ScannedCount":1}`
 
-	queryTestCaseOutput15 = `{"Count":1,"Items":[{"emp_id":{"N":"3"},"first_name":{"S":"Alice"},"last_name":{"S":"Trentor"}}]}`
+	queryTestCaseOutput15 = `{"Count":1,"Items":[{"emp_id":{"N":"3"},"first_name":{"S":"Alice"},"last_name":{"S":"Trentor"}}],"ScannedCount":1}`
 
-	queryTestCaseOutput16 = `{"Count":1,"Items":[]}`
+	queryTestCaseOutput16 = `{"Count":1,"Items":[],"ScannedCount":1}`
 
 	queryTestCase17 = models.Query{
 		TableName: "department",
@@ -547,7 +565,7 @@ var (
 			":val1": {N: aws.String("200")}, // d_id 200 has NULL d_name
 		},
 	}
-	queryTestCaseOutput17 = `{"Count":1,"Items":[{"d_id":{"N":"200"},"d_name":{"NULL":true},"d_specialization":{"S":"BA"}}]}`
+	queryTestCaseOutput17 = `{"Count":1,"Items":[{"d_id":{"N":"200"},"d_name":{"NULL":true},"d_specialization":{"S":"BA"}}],"ScannedCount":1}`
 )
 
 // Test Data for Scan API
@@ -561,21 +579,21 @@ var (
 	ScanTestCase2     = models.ScanMeta{
 		TableName: "employee",
 	}
-	ScanTestCase2Output = `{"Count":5,"Items":[{"address":{"S":"Shamli"},"age":{"N":"10"},"emp_id":{"N":"1"},"first_name":{"S":"Marc"},"last_name":{"S":"Richards"},"phone_numbers":{"SS":["+1111111111","+1222222222"]},"profile_pics":{"BS":["U29tZUJ5dGVzRGF0YTE=","U29tZUJ5dGVzRGF0YTI="]},"salaries":{"NS":["1000.5","2000.75"]}},{"address":{"S":"New York"},"age":{"N":"20"},"emp_id":{"N":"2"},"first_name":{"S":"Catalina"},"last_name":{"S":"Smith"},"phone_numbers":{"SS":["+1333333333"]},"profile_pics":{"BS":["U29tZUJ5dGVzRGF0YTM="]},"salaries":{"NS":["3000"]}},{"address":{"S":"Pune"},"age":{"N":"30"},"emp_id":{"N":"3"},"first_name":{"S":"Alice"},"last_name":{"S":"Trentor"},"phone_numbers":{"SS":["+1444444444","+1555555555"]},"profile_pics":{"BS":["U29tZUJ5dGVzRGF0YTQ=","U29tZUJ5dGVzRGF0YTU="]},"salaries":{"NS":["4000.25","5000.5","6000.75"]}},{"address":{"S":"Silicon Valley"},"age":{"N":"40"},"emp_id":{"N":"4"},"first_name":{"S":"Lea"},"last_name":{"S":"Martin"},"phone_numbers":{"SS":["+1666666666"]},"profile_pics":{"BS":["U29tZUJ5dGVzRGF0YTY="]},"salaries":{"NS":["7000","8000.25"]}},{"address":{"S":"London"},"age":{"N":"50"},"emp_id":{"N":"5"},"first_name":{"S":"David"},"last_name":{"S":"Lomond"},"phone_numbers":{"SS":["+1777777777","+1888888888","+1999999999"]},"profile_pics":{"BS":["U29tZUJ5dGVzRGF0YTc=","U29tZUJ5dGVzRGF0YTg="]},"salaries":{"NS":["9000.5"]}}]}`
+	ScanTestCase2Output = `{"Count":5,"Items":[{"address":{"S":"Shamli"},"age":{"N":"10"},"emp_id":{"N":"1"},"first_name":{"S":"Marc"},"last_name":{"S":"Richards"},"phone_numbers":{"SS":["+1111111111","+1222222222"]},"profile_pics":{"BS":["U29tZUJ5dGVzRGF0YTE=","U29tZUJ5dGVzRGF0YTI="]},"salaries":{"NS":["1000.5","2000.75"]}},{"address":{"S":"New York"},"age":{"N":"20"},"emp_id":{"N":"2"},"first_name":{"S":"Catalina"},"last_name":{"S":"Smith"},"phone_numbers":{"SS":["+1333333333"]},"profile_pics":{"BS":["U29tZUJ5dGVzRGF0YTM="]},"salaries":{"NS":["3000"]}},{"address":{"S":"Pune"},"age":{"N":"30"},"emp_id":{"N":"3"},"first_name":{"S":"Alice"},"last_name":{"S":"Trentor"},"phone_numbers":{"SS":["+1444444444","+1555555555"]},"profile_pics":{"BS":["U29tZUJ5dGVzRGF0YTQ=","U29tZUJ5dGVzRGF0YTU="]},"salaries":{"NS":["4000.25","5000.5","6000.75"]}},{"address":{"S":"Silicon Valley"},"age":{"N":"40"},"emp_id":{"N":"4"},"first_name":{"S":"Lea"},"last_name":{"S":"Martin"},"phone_numbers":{"SS":["+1666666666"]},"profile_pics":{"BS":["U29tZUJ5dGVzRGF0YTY="]},"salaries":{"NS":["7000","8000.25"]}},{"address":{"S":"London"},"age":{"N":"50"},"emp_id":{"N":"5"},"first_name":{"S":"David"},"last_name":{"S":"Lomond"},"phone_numbers":{"SS":["+1777777777","+1888888888","+1999999999"]},"profile_pics":{"BS":["U29tZUJ5dGVzRGF0YTc=","U29tZUJ5dGVzRGF0YTg="]},"salaries":{"NS":["9000.5"]}}],"ScannedCount":5}`
 
 	ScanTestCase3Name = "3: With Limit Attribute"
 	ScanTestCase3     = models.ScanMeta{
 		TableName: "employee",
 		Limit:     3,
 	}
-	ScanTestCase3Output = `{"Count":3,"Items":[{"address":{"S":"Shamli"},"age":{"N":"10"},"emp_id":{"N":"1"},"first_name":{"S":"Marc"},"last_name":{"S":"Richards"},"phone_numbers":{"SS":["+1111111111","+1222222222"]},"profile_pics":{"BS":["U29tZUJ5dGVzRGF0YTE=","U29tZUJ5dGVzRGF0YTI="]},"salaries":{"NS":["1000.5","2000.75"]}},{"address":{"S":"New York"},"age":{"N":"20"},"emp_id":{"N":"2"},"first_name":{"S":"Catalina"},"last_name":{"S":"Smith"},"phone_numbers":{"SS":["+1333333333"]},"profile_pics":{"BS":["U29tZUJ5dGVzRGF0YTM="]},"salaries":{"NS":["3000"]}},{"address":{"S":"Pune"},"age":{"N":"30"},"emp_id":{"N":"3"},"first_name":{"S":"Alice"},"last_name":{"S":"Trentor"},"phone_numbers":{"SS":["+1444444444","+1555555555"]},"profile_pics":{"BS":["U29tZUJ5dGVzRGF0YTQ=","U29tZUJ5dGVzRGF0YTU="]},"salaries":{"NS":["4000.25","5000.5","6000.75"]}}],"LastEvaluatedKey":{"emp_id":{"N":"3"},"offset":{"N":"3"}}}`
+	ScanTestCase3Output = `{"Count":3,"Items":[{"address":{"S":"Shamli"},"age":{"N":"10"},"emp_id":{"N":"1"},"first_name":{"S":"Marc"},"last_name":{"S":"Richards"},"phone_numbers":{"SS":["+1111111111","+1222222222"]},"profile_pics":{"BS":["U29tZUJ5dGVzRGF0YTE=","U29tZUJ5dGVzRGF0YTI="]},"salaries":{"NS":["1000.5","2000.75"]}},{"address":{"S":"New York"},"age":{"N":"20"},"emp_id":{"N":"2"},"first_name":{"S":"Catalina"},"last_name":{"S":"Smith"},"phone_numbers":{"SS":["+1333333333"]},"profile_pics":{"BS":["U29tZUJ5dGVzRGF0YTM="]},"salaries":{"NS":["3000"]}},{"address":{"S":"Pune"},"age":{"N":"30"},"emp_id":{"N":"3"},"first_name":{"S":"Alice"},"last_name":{"S":"Trentor"},"phone_numbers":{"SS":["+1444444444","+1555555555"]},"profile_pics":{"BS":["U29tZUJ5dGVzRGF0YTQ=","U29tZUJ5dGVzRGF0YTU="]},"salaries":{"NS":["4000.25","5000.5","6000.75"]}}],"LastEvaluatedKey":{"emp_id":{"N":"3"}},"ScannedCount":3}`
 
 	ScanTestCase4Name = "4: With Projection Expression"
 	ScanTestCase4     = models.ScanMeta{
 		TableName:            "employee",
 		ProjectionExpression: "address, emp_id, first_name",
 	}
-	ScanTestCase4Output = `{"Count":5,"Items":[{"address":{"S":"Shamli"},"emp_id":{"N":"1"},"first_name":{"S":"Marc"}},{"address":{"S":"New York"},"emp_id":{"N":"2"},"first_name":{"S":"Catalina"}},{"address":{"S":"Pune"},"emp_id":{"N":"3"},"first_name":{"S":"Alice"}},{"address":{"S":"Silicon Valley"},"emp_id":{"N":"4"},"first_name":{"S":"Lea"}},{"address":{"S":"London"},"emp_id":{"N":"5"},"first_name":{"S":"David"}}]}`
+	ScanTestCase4Output = `{"Count":5,"Items":[{"address":{"S":"Shamli"},"emp_id":{"N":"1"},"first_name":{"S":"Marc"}},{"address":{"S":"New York"},"emp_id":{"N":"2"},"first_name":{"S":"Catalina"}},{"address":{"S":"Pune"},"emp_id":{"N":"3"},"first_name":{"S":"Alice"}},{"address":{"S":"Silicon Valley"},"emp_id":{"N":"4"},"first_name":{"S":"Lea"}},{"address":{"S":"London"},"emp_id":{"N":"5"},"first_name":{"S":"David"}}],"ScannedCount":5}`
 
 	ScanTestCase5Name = "5: With Projection Expression & limit"
 	ScanTestCase5     = models.ScanMeta{
@@ -583,19 +601,18 @@ var (
 		Limit:                3,
 		ProjectionExpression: "address, emp_id, first_name",
 	}
-	ScanTestCase5Output = `{"Count":3,"Items":[{"address":{"S":"Shamli"},"emp_id":{"N":"1"},"first_name":{"S":"Marc"}},{"address":{"S":"New York"},"emp_id":{"N":"2"},"first_name":{"S":"Catalina"}},{"address":{"S":"Pune"},"emp_id":{"N":"3"},"first_name":{"S":"Alice"}}],"LastEvaluatedKey":{"emp_id":{"N":"3"},"offset":{"N":"3"}}}`
+	ScanTestCase5Output = `{"Count":3,"Items":[{"address":{"S":"Shamli"},"emp_id":{"N":"1"},"first_name":{"S":"Marc"}},{"address":{"S":"New York"},"emp_id":{"N":"2"},"first_name":{"S":"Catalina"}},{"address":{"S":"Pune"},"emp_id":{"N":"3"},"first_name":{"S":"Alice"}}],"LastEvaluatedKey":{"emp_id":{"N":"3"}},"ScannedCount":3}`
 
 	ScanTestCase6Name = "6: Projection Expression without ExpressionAttributeNames"
 	ScanTestCase6     = models.ScanMeta{
 		TableName: "employee",
 		Limit:     3,
 		ExclusiveStartKey: map[string]*dynamodb.AttributeValue{
-			"emp_id": {N: aws.String("4")},
-			"offset": {N: aws.String("3")},
+			"emp_id": {N: aws.String("3")},
 		},
 		ProjectionExpression: "address, #ag, emp_id, first_name, last_name",
 	}
-	ScanTestCase6Output = `{"Count":2,"Items":[{"address":{"S":"Silicon Valley"},"emp_id":{"N":"4"},"first_name":{"S":"Lea"},"last_name":{"S":"Martin"}},{"address":{"S":"London"},"emp_id":{"N":"5"},"first_name":{"S":"David"},"last_name":{"S":"Lomond"}}]}`
+	ScanTestCase6Output = `{"Count":2,"Items":[{"address":{"S":"Silicon Valley"},"emp_id":{"N":"4"},"first_name":{"S":"Lea"},"last_name":{"S":"Martin"}},{"address":{"S":"London"},"emp_id":{"N":"5"},"first_name":{"S":"David"},"last_name":{"S":"Lomond"}}],"ScannedCount":2}`
 
 	ScanTestCase7Name = "7: Projection Expression with ExpressionAttributeNames"
 	ScanTestCase7     = models.ScanMeta{
@@ -604,15 +621,14 @@ var (
 		Limit:                    3,
 		ProjectionExpression:     "address, #ag, emp_id, first_name, last_name",
 	}
-	ScanTestCase7Output = `{"Count":3,"Items":[{"address":{"S":"Shamli"},"age":{"N":"10"},"emp_id":{"N":"1"},"first_name":{"S":"Marc"},"last_name":{"S":"Richards"}},{"address":{"S":"New York"},"age":{"N":"20"},"emp_id":{"N":"2"},"first_name":{"S":"Catalina"},"last_name":{"S":"Smith"}},{"address":{"S":"Pune"},"age":{"N":"30"},"emp_id":{"N":"3"},"first_name":{"S":"Alice"},"last_name":{"S":"Trentor"}}],"LastEvaluatedKey":{"emp_id":{"N":"3"},"offset":{"N":"3"}}}`
+	ScanTestCase7Output = `{"Count":3,"Items":[{"address":{"S":"Shamli"},"age":{"N":"10"},"emp_id":{"N":"1"},"first_name":{"S":"Marc"},"last_name":{"S":"Richards"}},{"address":{"S":"New York"},"age":{"N":"20"},"emp_id":{"N":"2"},"first_name":{"S":"Catalina"},"last_name":{"S":"Smith"}},{"address":{"S":"Pune"},"age":{"N":"30"},"emp_id":{"N":"3"},"first_name":{"S":"Alice"},"last_name":{"S":"Trentor"}}],"LastEvaluatedKey":{"emp_id":{"N":"3"}},"ScannedCount":3}`
 
 	//400 Bad request
 	ScanTestCase8Name = "8: Filter Expression without ExpressionAttributeValues"
 	ScanTestCase8     = models.ScanMeta{
 		TableName: "employee",
 		ExclusiveStartKey: map[string]*dynamodb.AttributeValue{
-			"emp_id": {N: aws.String("4")},
-			"offset": {N: aws.String("3")},
+			"emp_id": {N: aws.String("3")},
 		},
 		FilterExpression: "age > :val1",
 	}
@@ -625,7 +641,7 @@ var (
 		},
 		FilterExpression: "age > :val1",
 	}
-	ScanTestCase9Output = `{"Count":4,"Items":[{"address":{"S":"New York"},"age":{"N":"20"},"emp_id":{"N":"2"},"first_name":{"S":"Catalina"},"last_name":{"S":"Smith"},"phone_numbers":{"SS":["+1333333333"]},"profile_pics":{"BS":["U29tZUJ5dGVzRGF0YTM="]},"salaries":{"NS":["3000"]}},{"address":{"S":"Pune"},"age":{"N":"30"},"emp_id":{"N":"3"},"first_name":{"S":"Alice"},"last_name":{"S":"Trentor"},"phone_numbers":{"SS":["+1444444444","+1555555555"]},"profile_pics":{"BS":["U29tZUJ5dGVzRGF0YTQ=","U29tZUJ5dGVzRGF0YTU="]},"salaries":{"NS":["4000.25","5000.5","6000.75"]}},{"address":{"S":"Silicon Valley"},"age":{"N":"40"},"emp_id":{"N":"4"},"first_name":{"S":"Lea"},"last_name":{"S":"Martin"},"phone_numbers":{"SS":["+1666666666"]},"profile_pics":{"BS":["U29tZUJ5dGVzRGF0YTY="]},"salaries":{"NS":["7000","8000.25"]}},{"address":{"S":"London"},"age":{"N":"50"},"emp_id":{"N":"5"},"first_name":{"S":"David"},"last_name":{"S":"Lomond"},"phone_numbers":{"SS":["+1777777777","+1888888888","+1999999999"]},"profile_pics":{"BS":["U29tZUJ5dGVzRGF0YTc=","U29tZUJ5dGVzRGF0YTg="]},"salaries":{"NS":["9000.5"]}}]}`
+	ScanTestCase9Output = `{"Count":4,"Items":[{"address":{"S":"New York"},"age":{"N":"20"},"emp_id":{"N":"2"},"first_name":{"S":"Catalina"},"last_name":{"S":"Smith"},"phone_numbers":{"SS":["+1333333333"]},"profile_pics":{"BS":["U29tZUJ5dGVzRGF0YTM="]},"salaries":{"NS":["3000"]}},{"address":{"S":"Pune"},"age":{"N":"30"},"emp_id":{"N":"3"},"first_name":{"S":"Alice"},"last_name":{"S":"Trentor"},"phone_numbers":{"SS":["+1444444444","+1555555555"]},"profile_pics":{"BS":["U29tZUJ5dGVzRGF0YTQ=","U29tZUJ5dGVzRGF0YTU="]},"salaries":{"NS":["4000.25","5000.5","6000.75"]}},{"address":{"S":"Silicon Valley"},"age":{"N":"40"},"emp_id":{"N":"4"},"first_name":{"S":"Lea"},"last_name":{"S":"Martin"},"phone_numbers":{"SS":["+1666666666"]},"profile_pics":{"BS":["U29tZUJ5dGVzRGF0YTY="]},"salaries":{"NS":["7000","8000.25"]}},{"address":{"S":"London"},"age":{"N":"50"},"emp_id":{"N":"5"},"first_name":{"S":"David"},"last_name":{"S":"Lomond"},"phone_numbers":{"SS":["+1777777777","+1888888888","+1999999999"]},"profile_pics":{"BS":["U29tZUJ5dGVzRGF0YTc=","U29tZUJ5dGVzRGF0YTg="]},"salaries":{"NS":["9000.5"]}}],"ScannedCount":5}`
 
 	//400 bad request
 	ScanTestCase10Name = "10: FilterExpression & ExpressionAttributeValues without ExpressionAttributeNames"
@@ -646,18 +662,17 @@ var (
 		},
 		FilterExpression: "age > :val1",
 	}
-	ScanTestCase11Output = `{"Count":4,"Items":[{"address":{"S":"New York"},"age":{"N":"20"},"emp_id":{"N":"2"},"first_name":{"S":"Catalina"},"last_name":{"S":"Smith"},"phone_numbers":{"SS":["+1333333333"]},"profile_pics":{"BS":["U29tZUJ5dGVzRGF0YTM="]},"salaries":{"NS":["3000"]}},{"address":{"S":"Pune"},"age":{"N":"30"},"emp_id":{"N":"3"},"first_name":{"S":"Alice"},"last_name":{"S":"Trentor"},"phone_numbers":{"SS":["+1444444444","+1555555555"]},"profile_pics":{"BS":["U29tZUJ5dGVzRGF0YTQ=","U29tZUJ5dGVzRGF0YTU="]},"salaries":{"NS":["4000.25","5000.5","6000.75"]}},{"address":{"S":"Silicon Valley"},"age":{"N":"40"},"emp_id":{"N":"4"},"first_name":{"S":"Lea"},"last_name":{"S":"Martin"},"phone_numbers":{"SS":["+1666666666"]},"profile_pics":{"BS":["U29tZUJ5dGVzRGF0YTY="]},"salaries":{"NS":["7000","8000.25"]}},{"address":{"S":"London"},"age":{"N":"50"},"emp_id":{"N":"5"},"first_name":{"S":"David"},"last_name":{"S":"Lomond"},"phone_numbers":{"SS":["+1777777777","+1888888888","+1999999999"]},"profile_pics":{"BS":["U29tZUJ5dGVzRGF0YTc=","U29tZUJ5dGVzRGF0YTg="]},"salaries":{"NS":["9000.5"]}}]}`
+	ScanTestCase11Output = `{"Count":4,"Items":[{"address":{"S":"New York"},"age":{"N":"20"},"emp_id":{"N":"2"},"first_name":{"S":"Catalina"},"last_name":{"S":"Smith"},"phone_numbers":{"SS":["+1333333333"]},"profile_pics":{"BS":["U29tZUJ5dGVzRGF0YTM="]},"salaries":{"NS":["3000"]}},{"address":{"S":"Pune"},"age":{"N":"30"},"emp_id":{"N":"3"},"first_name":{"S":"Alice"},"last_name":{"S":"Trentor"},"phone_numbers":{"SS":["+1444444444","+1555555555"]},"profile_pics":{"BS":["U29tZUJ5dGVzRGF0YTQ=","U29tZUJ5dGVzRGF0YTU="]},"salaries":{"NS":["4000.25","5000.5","6000.75"]}},{"address":{"S":"Silicon Valley"},"age":{"N":"40"},"emp_id":{"N":"4"},"first_name":{"S":"Lea"},"last_name":{"S":"Martin"},"phone_numbers":{"SS":["+1666666666"]},"profile_pics":{"BS":["U29tZUJ5dGVzRGF0YTY="]},"salaries":{"NS":["7000","8000.25"]}},{"address":{"S":"London"},"age":{"N":"50"},"emp_id":{"N":"5"},"first_name":{"S":"David"},"last_name":{"S":"Lomond"},"phone_numbers":{"SS":["+1777777777","+1888888888","+1999999999"]},"profile_pics":{"BS":["U29tZUJ5dGVzRGF0YTc=","U29tZUJ5dGVzRGF0YTg="]},"salaries":{"NS":["9000.5"]}}],"ScannedCount":5}`
 
 	ScanTestCase12Name = "12: With ExclusiveStartKey"
 	ScanTestCase12     = models.ScanMeta{
 		TableName: "employee",
 		ExclusiveStartKey: map[string]*dynamodb.AttributeValue{
-			"emp_id": {N: aws.String("4")},
-			"offset": {N: aws.String("3")},
+			"emp_id": {N: aws.String("3")},
 		},
 		Limit: 3,
 	}
-	ScanTestCase12Output = `{"Count":2,"Items":[{"address":{"S":"Silicon Valley"},"age":{"N":"40"},"emp_id":{"N":"4"},"first_name":{"S":"Lea"},"last_name":{"S":"Martin"},"phone_numbers":{"SS":["+1666666666"]},"profile_pics":{"BS":["U29tZUJ5dGVzRGF0YTY="]},"salaries":{"NS":["7000","8000.25"]}},{"address":{"S":"London"},"age":{"N":"50"},"emp_id":{"N":"5"},"first_name":{"S":"David"},"last_name":{"S":"Lomond"},"phone_numbers":{"SS":["+1777777777","+1888888888","+1999999999"]},"profile_pics":{"BS":["U29tZUJ5dGVzRGF0YTc=","U29tZUJ5dGVzRGF0YTg="]},"salaries":{"NS":["9000.5"]}}]}`
+	ScanTestCase12Output = `{"Count":2,"Items":[{"address":{"S":"Silicon Valley"},"age":{"N":"40"},"emp_id":{"N":"4"},"first_name":{"S":"Lea"},"last_name":{"S":"Martin"},"phone_numbers":{"SS":["+1666666666"]},"profile_pics":{"BS":["U29tZUJ5dGVzRGF0YTY="]},"salaries":{"NS":["7000","8000.25"]}},{"address":{"S":"London"},"age":{"N":"50"},"emp_id":{"N":"5"},"first_name":{"S":"David"},"last_name":{"S":"Lomond"},"phone_numbers":{"SS":["+1777777777","+1888888888","+1999999999"]},"profile_pics":{"BS":["U29tZUJ5dGVzRGF0YTc=","U29tZUJ5dGVzRGF0YTg="]},"salaries":{"NS":["9000.5"]}}],"ScannedCount":2}`
 
 	ScanTestCase13Name = "13: With Count"
 	ScanTestCase13     = models.ScanMeta{
@@ -665,7 +680,7 @@ var (
 		Limit:     3,
 		Select:    "COUNT",
 	}
-	ScanTestCase13Output = `{"Count":5,"Items":[]}`
+	ScanTestCase13Output = `{"Count":3,"Items":[],"LastEvaluatedKey":{"emp_id":{"N":"3"}},"ScannedCount":3}`
 
 	ScanTestCase14Name = "14: NULL Value"
 	ScanTestCase14     = models.ScanMeta{
@@ -675,14 +690,46 @@ var (
 			":val1": {N: aws.String("200")}, // Filter for NULL d_name
 		},
 	}
-	ScanTestCase14Output = `{"Count":1,"Items":[{"d_id":{"N":"200"},"d_name":{"NULL":true},"d_specialization":{"S":"BA"}}],"LastEvaluatedKey":null}`
+	ScanTestCase14Output = `{"Count":1,"Items":[{"d_id":{"N":"200"},"d_name":{"NULL":true},"d_specialization":{"S":"BA"}}],"LastEvaluatedKey":null,"ScannedCount":3}`
 	ScanTestCaseListName = "15: List Type"
 	ScanTestCaseList     = models.ScanMeta{
 		TableName: "test_table",
 		Limit:     2,
 		Select:    "COUNT",
 	}
-	ScanTestCaseListOutput = `{"Count":3,"Items":[]}`
+	ScanTestCaseListOutput = `{"Count":2,"Items":[],"LastEvaluatedKey":{"rank_list":{"S":"rank_list1"}},"ScannedCount":2}`
+
+	ScanTestCase16Name = "16: Filter Expression with functions on sets"
+	ScanTestCase16     = models.ScanMeta{
+		TableName:            "employee",
+		ProjectionExpression: "emp_id, first_name",
+		FilterExpression:     "contains(phone_numbers, :phone) OR size(salaries) > :two",
+		ExpressionAttributeValues: map[string]*dynamodb.AttributeValue{
+			":phone": {S: aws.String("+1666666666")},
+			":two":   {N: aws.String("2")},
+		},
+	}
+	ScanTestCase16Output = `{"Count":2,"Items":[{"emp_id":{"N":"3"},"first_name":{"S":"Alice"}},{"emp_id":{"N":"4"},"first_name":{"S":"Lea"}}],"LastEvaluatedKey":null,"ScannedCount":5}`
+
+	//400 bad request
+	ScanTestCase17Name = "17: Filter Expression with an incorrect operand type"
+	ScanTestCase17     = models.ScanMeta{
+		TableName:        "employee",
+		FilterExpression: "begins_with(first_name, :two)",
+		ExpressionAttributeValues: map[string]*dynamodb.AttributeValue{
+			":two": {N: aws.String("2")},
+		},
+	}
+	ScanTestCase17Output = `{"__type":"com.amazonaws.dynamodb.v20120810#ValidationException","message":"Invalid FilterExpression: Incorrect operand type for operator or function; operator or function: begins_with, operand type: N"}`
+
+	// session2 has expired, it is not read, so that the page still ends with a LastEvaluatedKey
+	ScanTestCase18Name = "18: Limit with an expired item inside the page"
+	ScanTestCase18     = models.ScanMeta{
+		TableName:            "sessions",
+		ProjectionExpression: "session_id",
+		Limit:                2,
+	}
+	ScanTestCase18Output = `{"Count":2,"Items":[{"session_id":{"S":"session1"}},{"session_id":{"S":"session3"}}],"LastEvaluatedKey":{"session_id":{"S":"session3"}},"ScannedCount":2}`
 )
 
 // Test Data for UpdateItem API
@@ -707,7 +754,7 @@ var (
 			":salaries": {NS: aws.StringSlice([]string{
 				"1000.5", "2000.75", "1000.5", "2000.75",
 			})},
-			"profile_pics": {BS: [][]byte{[]byte("SomeBytesData1"), []byte("SomeBytesData2"), []byte("SomeBytesData1"), []byte("SomeBytesData2")}},
+			":profile_pics": {BS: [][]byte{[]byte("SomeBytesData1"), []byte("SomeBytesData2"), []byte("SomeBytesData1"), []byte("SomeBytesData2")}},
 		},
 		ReturnValues: "ALL_NEW",
 	}
@@ -730,6 +777,7 @@ var (
 	}
 	UpdateItemTestCase3Output = `{"Attributes":{"address":{"S":"Shamli"},"age":{"N":"10"},"emp_id":{"N":"1"},"first_name":{"S":"Marc"},"last_name":{"S":"Richards"},"phone_numbers":{"SS":["+1111111111","+1222222222"]},"profile_pics":{"BS":["U29tZUJ5dGVzRGF0YTE=","U29tZUJ5dGVzRGF0YTI="]},"salaries":{"NS":["1000.5","2000.75"]}}}`
 
+	//400 bad request
 	UpdateItemTestCase4Name = "4: Update Expression without ExpressionAttributeValues"
 	UpdateItemTestCase4     = models.UpdateAttr{
 		TableName: "employee",
@@ -863,6 +911,50 @@ var (
 		ReturnValues: "UPDATED_ALL",
 	}
 	UpdateItemTestCase12Output = `{"Attributes":{"category":{"S":"category2"},"id":{"S":"id2"},"list_type":{"L":[{"S":"test"},{"S":"updated_value"},{"S":"62536"}]},"rank_list":{"S":"rank_list2"},"updated_at":{"S":"2024-12-04T11:02:02Z"}}}`
+
+	//400 bad request
+	UpdateItemTestCase13Name = "13: UpdateExpression with overlapping document paths"
+	UpdateItemTestCase13     = models.UpdateAttr{
+		TableName: "employee",
+		Key: map[string]*dynamodb.AttributeValue{
+			"emp_id": {N: aws.String("1")},
+		},
+		UpdateExpression: "SET age = :age REMOVE age",
+		ExpressionAttributeValues: map[string]*dynamodb.AttributeValue{
+			":age": {N: aws.String("10")},
+		},
+	}
+	UpdateItemTestCase13Output = `{"__type":"com.amazonaws.dynamodb.v20120810#ValidationException","message":"Invalid UpdateExpression: Two document paths overlap with each other; must remove or rewrite one of these paths; path one: [age], path two: [age]"}`
+
+	//400 bad request
+	UpdateItemTestCase14Name = "14: UpdateExpression updating a key attribute"
+	UpdateItemTestCase14     = models.UpdateAttr{
+		TableName: "employee",
+		Key: map[string]*dynamodb.AttributeValue{
+			"emp_id": {N: aws.String("1")},
+		},
+		UpdateExpression: "SET emp_id = emp_id + :one",
+		ExpressionAttributeValues: map[string]*dynamodb.AttributeValue{
+			":one": {N: aws.String("1")},
+		},
+	}
+	UpdateItemTestCase14Output = `{"__type":"com.amazonaws.dynamodb.v20120810#ValidationException","message":"One or more parameter values were invalid: Cannot update attribute emp_id. This attribute is part of the key"}`
+
+	//400 bad request, none of the clauses is applied
+	UpdateItemTestCase15Name = "15: UpdateExpression with several clauses and a failing ConditionExpression"
+	UpdateItemTestCase15     = models.UpdateAttr{
+		TableName: "employee",
+		Key: map[string]*dynamodb.AttributeValue{
+			"emp_id": {N: aws.String("1")},
+		},
+		ConditionExpression: "age > :max",
+		UpdateExpression:    "SET age = :age REMOVE address ADD salaries :salaries",
+		ExpressionAttributeValues: map[string]*dynamodb.AttributeValue{
+			":age":      {N: aws.String("11")},
+			":max":      {N: aws.String("100")},
+			":salaries": {NS: aws.StringSlice([]string{"3000"})},
+		},
+	}
 )
 
 // Test Data for PutItem API
@@ -956,6 +1048,38 @@ var (
 		ConditionExpression: "age > 9",
 	}
 
+	//400 bad request
+	PutItemTestCase12Name = "12: ConditionExpression with a syntax error"
+	PutItemTestCase12     = models.Meta{
+		TableName: "employee",
+		Item: map[string]*dynamodb.AttributeValue{
+			"emp_id": {N: aws.String("1")},
+			"age":    {N: aws.String("11")},
+		},
+		ConditionExpression: "age = = :val2",
+		ExpressionAttributeValues: map[string]*dynamodb.AttributeValue{
+			":val2": {N: aws.String("11")},
+		},
+	}
+	PutItemTestCase12Output = `{"__type":"com.amazonaws.dynamodb.v20120810#ValidationException","message":"Invalid ConditionExpression: Syntax error; token: \"=\", near: \"= =\""}`
+
+	PutItemTestCase13Name = "13: ConditionExpression with BETWEEN and nested parentheses"
+	PutItemTestCase13     = models.Meta{
+		TableName: "employee",
+		Item: map[string]*dynamodb.AttributeValue{
+			"emp_id": {N: aws.String("1")},
+			"age":    {N: aws.String("11")},
+		},
+		ConditionExpression: "(age BETWEEN :lo AND :hi) AND (attribute_exists(first_name) OR (attribute_not_exists(#ln) AND age <> :lo))",
+		ExpressionAttributeValues: map[string]*dynamodb.AttributeValue{
+			":lo": {N: aws.String("10")},
+			":hi": {N: aws.String("20")},
+		},
+		ExpressionAttributeNames: map[string]string{
+			"#ln": "last_name",
+		},
+	}
+
 	//400 bad request
 	PutItemTestCase8Name = "Item is not present"
 	PutItemTestCase8     = models.Meta{
@@ -1719,6 +1843,28 @@ var (
 			},
 		},
 	}
+	BatchWriteItemTestCase12Name = "12: Batch Put & Delete Request for the same key"
+	BatchWriteItemTestCase12     = models.BatchWriteItem{
+		RequestItems: map[string][]models.BatchWriteSubItems{
+			"employee": {
+				{
+					PutReq: models.BatchPutItem{
+						Item: map[string]*dynamodb.AttributeValue{
+							"emp_id":     {N: aws.String("6")},
+							"first_name": {S: aws.String("David")},
+						},
+					},
+				},
+				{
+					DelReq: models.BatchDeleteItem{
+						Key: map[string]*dynamodb.AttributeValue{
+							"emp_id": {N: aws.String("6")},
+						},
+					},
+				},
+			},
+		},
+	}
 	BatchWriteItemTestCase1Output = `{
 		"UnprocessedItems": {},
 		"ConsumedCapacity": [
@@ -1821,6 +1967,44 @@ var (
 		},
 	}
 	TestTransactGet5Output = `{"Responses":[{"TableName":"employee","Item":{"L":[{"first_name":{"S":"Marc"},"last_name":{"S":"Richards"}}]}},{"TableName":"department","Item":{"L":[{"d_name":{"NULL":true},"d_specialization":{"S":"BA"}}]}}]}`
+
+	TestTransactGet6Name = "6: item which does not exist"
+	TestTransactGet6     = models.TransactGetItemsRequest{
+		TransactItems: []models.TransactGetItem{
+			{Get: models.GetItemRequest{
+				TableName: "employee",
+				Keys: map[string]*dynamodb.AttributeValue{
+					"emp_id": {N: aws.String("1000")},
+				},
+			}},
+			{Get: models.GetItemRequest{
+				TableName: "employee",
+				Keys: map[string]*dynamodb.AttributeValue{
+					"emp_id": {N: aws.String("1")},
+				},
+				ProjectionExpression: "first_name",
+			}},
+		},
+	}
+	TestTransactGet6Output = `{"Responses":[{},{"TableName":"employee","Item":{"L":[{"first_name":{"S":"Marc"}}]}}]}`
+
+	TestTransactGet7Name = "7: two gets of one item"
+	TestTransactGet7     = models.TransactGetItemsRequest{
+		TransactItems: []models.TransactGetItem{
+			{Get: models.GetItemRequest{
+				TableName: "employee",
+				Keys: map[string]*dynamodb.AttributeValue{
+					"emp_id": {N: aws.String("1")},
+				},
+			}},
+			{Get: models.GetItemRequest{
+				TableName: "employee",
+				Keys: map[string]*dynamodb.AttributeValue{
+					"emp_id": {N: aws.String("1")},
+				},
+			}},
+		},
+	}
 )
 
 var (
@@ -1912,6 +2096,76 @@ var (
 		},
 	}
 	TestTransactWrite4Output = `{"Responses":[{"Put":{"address":{"S":"789 Elm St"},"age":{"N":"40"},"emp_id":{"N":"7"},"first_name":{"S":"Bob"},"last_name":{"S":"Johnson"}}}]}`
+	TestTransactWrite5Name   = "5: ConditionCheck which fails cancels the transaction"
+	TestTransactWrite5       = models.TransactWriteItemsRequest{
+		TransactItems: []models.TransactWriteItem{
+			{Put: models.PutItemRequest{
+				TableName: "employee",
+				Item: map[string]*dynamodb.AttributeValue{
+					"emp_id":     {N: aws.String("8")},
+					"first_name": {S: aws.String("Carl")},
+				},
+			}},
+			{ConditionCheck: models.ConditionCheckRequest{
+				TableName: "employee",
+				Key: map[string]*dynamodb.AttributeValue{
+					"emp_id": {N: aws.String("5")},
+				},
+				ConditionExpression: "age = :age",
+				ExpressionAttributeValues: map[string]*dynamodb.AttributeValue{
+					":age": {N: aws.String("99")},
+				},
+			}},
+		},
+	}
+	TestTransactWrite5Output = `{"CancellationReasons":[{"Code":"None"},{"Code":"ConditionalCheckFailed","Message":"The conditional request failed"}],"__type":"com.amazonaws.dynamodb.v20120810#TransactionCanceledException","message":"Transaction cancelled, please refer cancellation reasons for specific reasons [None, ConditionalCheckFailed]"}`
+	TestTransactWrite6Name   = "6: two actions on one item"
+	TestTransactWrite6       = models.TransactWriteItemsRequest{
+		TransactItems: []models.TransactWriteItem{
+			{Put: models.PutItemRequest{
+				TableName: "employee",
+				Item: map[string]*dynamodb.AttributeValue{
+					"emp_id":     {N: aws.String("8")},
+					"first_name": {S: aws.String("Carl")},
+				},
+			}},
+			{Delete: models.DeleteItemRequest{
+				TableName: "employee",
+				Key: map[string]*dynamodb.AttributeValue{
+					"emp_id": {N: aws.String("8")},
+				},
+			}},
+		},
+	}
+	TestTransactWrite7Name = "7: request with a ClientRequestToken"
+	TestTransactWrite7     = models.TransactWriteItemsRequest{
+		TransactItems: []models.TransactWriteItem{
+			{Put: models.PutItemRequest{
+				TableName: "employee",
+				Item: map[string]*dynamodb.AttributeValue{
+					"emp_id":     {N: aws.String("8")},
+					"first_name": {S: aws.String("Carl")},
+				},
+			}},
+		},
+		ClientRequestToken: "transact-write-7",
+	}
+	TestTransactWrite7Output = `{"Responses":[{"Put":{"emp_id":{"N":"8"},"first_name":{"S":"Carl"}}}]}`
+	TestTransactWrite8Name   = "8: replay with the same ClientRequestToken returns the original result"
+	TestTransactWrite9Name   = "9: same ClientRequestToken with different parameters"
+	TestTransactWrite9       = models.TransactWriteItemsRequest{
+		TransactItems: []models.TransactWriteItem{
+			{Put: models.PutItemRequest{
+				TableName: "employee",
+				Item: map[string]*dynamodb.AttributeValue{
+					"emp_id":     {N: aws.String("8")},
+					"first_name": {S: aws.String("Dave")},
+				},
+			}},
+		},
+		ClientRequestToken: "transact-write-7",
+	}
+	TestTransactWrite9Output = `{"__type":"com.amazonaws.dynamodb.v20120810#IdempotentParameterMismatchException","message":"DynamoDB rejected the request because you retried a request with a different payload but with an idempotent token that was already used."}`
 )
 
 // test Data for ExecuteStatement API
@@ -2078,6 +2332,13 @@ func createPostTestCase(name, url, dynamoAction, outputString string, input inte
 	}
 }
 
+// createErrorPostTestCase checks both the status and the body of an error response
+func createErrorPostTestCase(name, url, dynamoAction string, httpStatus int, outputString string, input interface{}) apitesting.APITestCase {
+	tc := createPostTestCase(name, url, dynamoAction, outputString, input)
+	tc.ExpHTTPStatus = httpStatus
+	return tc
+}
+
 func createStatusCheckPostTestCase(name, url, dynamoAction string, httpStatus int, input interface{}) apitesting.APITestCase {
 	return apitesting.APITestCase{
 		Name:    name,
@@ -2293,6 +2554,9 @@ func testQueryAPI(t *testing.T) {
 		createPostTestCase("Select with other than count", "/v1", "Query", queryTestCaseOutput15, queryTestCase15),
 		createPostTestCase("all attributes", "/v1", "Query", queryTestCaseOutput16, queryTestCase16),
 		createPostTestCase("Query with NULL value in KeyConditionExpression", "/v1", "Query", queryTestCaseOutput17, queryTestCase17),
+		createStatusCheckPostTestCase("invalid Select", "/v1", "Query", http.StatusBadRequest, queryTestCaseInvalidSelect),
+		createStatusCheckPostTestCase("SPECIFIC_ATTRIBUTES without ProjectionExpression", "/v1", "Query", http.StatusBadRequest, queryTestCaseSpecificWithoutProjection),
+		createStatusCheckPostTestCase("ALL_PROJECTED_ATTRIBUTES without IndexName", "/v1", "Query", http.StatusBadRequest, queryTestCaseProjectedWithoutIndex),
 	}
 	apitest.RunTests(t, tests)
 }
@@ -2361,6 +2625,9 @@ func testScanAPI(t *testing.T) {
 		createPostTestCase(ScanTestCase13Name, "/v1", "Query", ScanTestCase13Output, ScanTestCase13),
 		createPostTestCase(ScanTestCase14Name, "/v1", "Scan", ScanTestCase14Output, ScanTestCase14),
 		createPostTestCase(ScanTestCaseListName, "/v1", "Query", ScanTestCaseListOutput, ScanTestCaseList),
+		createPostTestCase(ScanTestCase16Name, "/v1", "Scan", ScanTestCase16Output, ScanTestCase16),
+		createErrorPostTestCase(ScanTestCase17Name, "/v1", "Scan", http.StatusBadRequest, ScanTestCase17Output, ScanTestCase17),
+		createPostTestCase(ScanTestCase18Name, "/v1", "Scan", ScanTestCase18Output, ScanTestCase18),
 	}
 	apitest.RunTests(t, tests)
 }
@@ -2375,7 +2642,7 @@ func testUpdateItemAPI(t *testing.T) {
 		createStatusCheckPostTestCase(UpdateItemTestCase1Name, "/v1", "UpdateItem", http.StatusBadRequest, UpdateItemTestCase1),
 		createPostTestCase(UpdateItemTestCase2Name, "/v1", "UpdateItem", UpdateItemTestCase2Output, UpdateItemTestCase2),
 		createPostTestCase(UpdateItemTestCase3Name, "/v1", "UpdateItem", UpdateItemTestCase3Output, UpdateItemTestCase3),
-		createStatusCheckPostTestCase(UpdateItemTestCase4Name, "/v1", "UpdateItem", http.StatusOK, UpdateItemTestCase4),
+		createStatusCheckPostTestCase(UpdateItemTestCase4Name, "/v1", "UpdateItem", http.StatusBadRequest, UpdateItemTestCase4),
 		createStatusCheckPostTestCase(UpdateItemTestCase5Name, "/v1", "UpdateItem", http.StatusBadRequest, UpdateItemTestCase5),
 		createStatusCheckPostTestCase(UpdateItemTestCase6Name, "/v1", "UpdateItem", http.StatusOK, UpdateItemTestCase6),
 		createPostTestCase(UpdateItemTestCase7Name, "/v1", "UpdateItem", UpdateItemTestCase7Output, UpdateItemTestCase7),
@@ -2384,6 +2651,10 @@ func testUpdateItemAPI(t *testing.T) {
 		createStatusCheckPostTestCase(UpdateItemTestCase10Name, "/v1", "UpdateItem", http.StatusBadRequest, UpdateItemTestCase10),
 		createStatusCheckPostTestCase(UpdateItemTestCase11Name, "/v1", "UpdateItem", http.StatusOK, UpdateItemTestCase11),
 		createPostTestCase(UpdateItemTestCase12Name, "/v1", "UpdateItem", UpdateItemTestCase12Output, UpdateItemTestCase12),
+		createErrorPostTestCase(UpdateItemTestCase13Name, "/v1", "UpdateItem", http.StatusBadRequest, UpdateItemTestCase13Output, UpdateItemTestCase13),
+		createErrorPostTestCase(UpdateItemTestCase14Name, "/v1", "UpdateItem", http.StatusBadRequest, UpdateItemTestCase14Output, UpdateItemTestCase14),
+		createStatusCheckPostTestCase(UpdateItemTestCase15Name, "/v1", "UpdateItem", http.StatusBadRequest, UpdateItemTestCase15),
+		createPostTestCase(UpdateItemTestCase7Name, "/v1", "UpdateItem", UpdateItemTestCase7Output, UpdateItemTestCase7),
 	}
 	apitest.RunTests(t, tests)
 }
@@ -2404,6 +2675,8 @@ func testPutItemAPI(t *testing.T) {
 		createPostTestCase(PutItemTestCase2Name, "/v1", "PutItem", PutItemTestCase2Output, PutItemTestCase2),
 		createPostTestCase(PutItemTestCase3Name, "/v1", "PutItem", PutItemTestCase3Output, PutItemTestCase3),
 		createPostTestCase(PutItemTestCase4Name, "/v1", "PutItem", PutItemTestCase4Output, PutItemTestCase4),
+		createErrorPostTestCase(PutItemTestCase12Name, "/v1", "PutItem", http.StatusBadRequest, PutItemTestCase12Output, PutItemTestCase12),
+		createStatusCheckPostTestCase(PutItemTestCase13Name, "/v1", "PutItem", http.StatusOK, PutItemTestCase13),
 		createStatusCheckPostTestCase(PutItemTestCase9Name, "/v1", "PutItem", http.StatusOK, PutItemTestCase9),
 		// TODO: These fail due to lack of wrapping sub JSON maps with "M": for responses
 		// This comes from convertMapToDynamoObject. Initial attempts to wrap with "M" broke other tests.
@@ -2453,6 +2726,7 @@ func testBatchWriteItemAPI(t *testing.T) {
 		createStatusCheckPostTestCase(BatchWriteItemTestCase10Name, "/v1", "BatchWriteItem", http.StatusBadRequest, BatchWriteItemTestCase10),
 		createStatusCheckPostTestCase(BatchWriteItemTestCaseListName, "/v1", "BatchWriteItem", http.StatusOK, BatchWriteItemTestCaseList),
 		createStatusCheckPostTestCase(BatchWriteItemTestCase11Name, "/v1", "BatchWriteItem", http.StatusOK, BatchWriteItemTestCase11),
+		createStatusCheckPostTestCase(BatchWriteItemTestCase12Name, "/v1", "BatchWriteItem", http.StatusBadRequest, BatchWriteItemTestCase12),
 	}
 	apitest.RunTests(t, tests)
 }
@@ -2503,6 +2777,8 @@ func testTransactGetItemsAPI(t *testing.T) {
 		createPostTestCase(TestTransactGet3Name, "/v1", "TransactGetItems", TestTransactGet3Output, TestTransactGet3),
 		createPostTestCase(TestTransactGet4Name, "/v1", "TransactGetItems", TestTransactGet4Output, TestTransactGet4),
 		createPostTestCase(TestTransactGet5Name, "/v1", "TransactGetItems", TestTransactGet5Output, TestTransactGet5),
+		createPostTestCase(TestTransactGet6Name, "/v1", "TransactGetItems", TestTransactGet6Output, TestTransactGet6),
+		createStatusCheckPostTestCase(TestTransactGet7Name, "/v1", "TransactGetItems", http.StatusBadRequest, TestTransactGet7),
 	}
 	apitest.RunTests(t, tests)
 }
@@ -2532,6 +2808,11 @@ func testTransactWriteItemsAPI(t *testing.T) {
 		createPostTestCase(TestTransactWrite2Name, "/v1", "TransactWriteItems", TestTransactWrite2Output, TestTransactWrite2),
 		createPostTestCase(TestTransactWrite3Name, "/v1", "TransactWriteItems", TestTransactWrite3Output, TestTransactWrite3),
 		createPostTestCase(TestTransactWrite4Name, "/v1", "TransactWriteItems", TestTransactWrite4Output, TestTransactWrite4),
+		createErrorPostTestCase(TestTransactWrite5Name, "/v1", "TransactWriteItems", http.StatusBadRequest, TestTransactWrite5Output, TestTransactWrite5),
+		createStatusCheckPostTestCase(TestTransactWrite6Name, "/v1", "TransactWriteItems", http.StatusBadRequest, TestTransactWrite6),
+		createPostTestCase(TestTransactWrite7Name, "/v1", "TransactWriteItems", TestTransactWrite7Output, TestTransactWrite7),
+		createPostTestCase(TestTransactWrite8Name, "/v1", "TransactWriteItems", TestTransactWrite7Output, TestTransactWrite7),
+		createErrorPostTestCase(TestTransactWrite9Name, "/v1", "TransactWriteItems", http.StatusBadRequest, TestTransactWrite9Output, TestTransactWrite9),
 	}
 	apitest.RunTests(t, tests)
 }
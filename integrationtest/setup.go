@@ -128,6 +128,36 @@ func createDatabase(w io.Writer, db string) error {
 				enabledStream STRING(MAX),
 				uniqueValue   STRING(MAX),
 			) PRIMARY KEY (tableName)`,
+			`CREATE TABLE dynamodb_adapter_index_ddl (
+				tableName        STRING(MAX) NOT NULL,
+				indexName        STRING(MAX) NOT NULL,
+				spannerIndexName STRING(MAX),
+				indexType        STRING(MAX),
+				partitionKey     STRING(MAX),
+				sortKey          STRING(MAX),
+				projectionType   STRING(MAX),
+				nonKeyAttributes ARRAY<STRING(MAX)>,
+			) PRIMARY KEY (tableName, indexName)`,
+			`CREATE TABLE dynamodb_adapter_ttl (
+				tableName     STRING(MAX) NOT NULL,
+				attributeName STRING(MAX),
+			) PRIMARY KEY (tableName)`,
+			`CREATE TABLE dynamodb_adapter_tags (
+				tableName STRING(MAX) NOT NULL,
+				tagKey    STRING(MAX) NOT NULL,
+				tagValue  STRING(MAX),
+			) PRIMARY KEY (tableName, tagKey)`,
+			`CREATE TABLE dynamodb_adapter_lease (
+				name      STRING(MAX) NOT NULL,
+				owner     STRING(MAX),
+				expiresAt TIMESTAMP,
+			) PRIMARY KEY (name)`,
+			`CREATE TABLE dynamodb_adapter_client_tokens (
+				token       STRING(MAX) NOT NULL,
+				requestHash STRING(MAX),
+				responses   STRING(MAX),
+				createdAt   TIMESTAMP OPTIONS (allow_commit_timestamp=true),
+			) PRIMARY KEY (token), ROW DELETION POLICY (OLDER_THAN(createdAt, INTERVAL 1 DAY))`,
 			`CREATE TABLE employee (
 				emp_id          FLOAT64,
 				address         STRING(MAX),
@@ -157,6 +187,10 @@ func createDatabase(w io.Writer, db string) error {
 				name STRING(MAX),
 				address JSON
 			) PRIMARY KEY (guid)`,
+			`CREATE TABLE sessions (
+				session_id STRING(MAX),
+				expires_at FLOAT64,
+			) PRIMARY KEY (session_id)`,
 		},
 	})
 	if err != nil {
@@ -217,12 +251,40 @@ func initData(w io.Writer, db string) error {
 			('mapdynamo', 'context', 'S', 'context', 'guid', '', 'context', 'mapdynamo', 'STRING(MAX)'),
 			('mapdynamo', 'contact_ranking_list', 'S', 'contact_ranking_list', 'guid', '', 'contact_ranking_list', 'mapdynamo', 'STRING(MAX)'),
 			('mapdynamo', 'name', 'S', 'name', 'guid', '', 'name', 'mapdynamo', 'STRING(MAX)'),
-			('mapdynamo', 'address', 'M', 'address', 'guid', '', 'address', 'mapdynamo', 'JSON');`,
+			('mapdynamo', 'address', 'M', 'address', 'guid', '', 'address', 'mapdynamo', 'JSON'),
+			('sessions', 'session_id', 'S', 'session_id', 'session_id', '', 'session_id', 'sessions', 'STRING(MAX)'),
+			('sessions', 'expires_at', 'N', 'expires_at', 'session_id', '', 'expires_at', 'sessions', 'FLOAT64');`,
+		}
+		rowCount, err := txn.Update(ctx, stmt)
+		if err != nil {
+			return err
+		}
+		fmt.Fprintf(w, "%d record(s) inserted.\n", rowCount)
+		return err
+	})
+	if err != nil {
+		return err
+	}
+
+	// session2 has expired an hour ago, the other sessions expire in 2100
+	_, err = client.ReadWriteTransaction(ctx, func(ctx context.Context, txn *spanner.ReadWriteTransaction) error {
+		stmt := spanner.Statement{
+			SQL: `INSERT INTO sessions (session_id, expires_at) VALUES
+				('session1', 4102444800),
+				('session2', CAST(UNIX_SECONDS(CURRENT_TIMESTAMP()) - 3600 AS FLOAT64)),
+				('session3', 4102444800),
+				('session4', 4102444800)`,
 		}
 		rowCount, err := txn.Update(ctx, stmt)
 		if err != nil {
 			return err
 		}
+		_, err = txn.Update(ctx, spanner.Statement{
+			SQL: `INSERT INTO dynamodb_adapter_ttl (tableName, attributeName) VALUES ('sessions', 'expires_at')`,
+		})
+		if err != nil {
+			return err
+		}
 		fmt.Fprintf(w, "%d record(s) inserted.\n", rowCount)
 		return err
 	})
@@ -20,9 +20,9 @@ import (
 	"sync"
 
 	"cloud.google.com/go/spanner"
-	"github.com/antonmedv/expr/vm"
 	"github.com/aws/aws-sdk-go/service/dynamodb"
 	otelgo "github.com/cloudspannerecosystem/dynamodb-adapter/otel"
+	"github.com/cloudspannerecosystem/dynamodb-adapter/pkg/expression"
 )
 
 type SpannerConfig struct {
@@ -66,9 +66,49 @@ type OtelConfig struct {
 	} `mapstructure:"traces"`
 }
 
+// TTLConfig defines the settings of the TimeToLive expiry sweeper
+type TTLConfig struct {
+	SweepIntervalSeconds int  `mapstructure:"sweep_interval_seconds"`
+	BatchSize            int  `mapstructure:"batch_size"`
+	HideExpiredItems     bool `mapstructure:"hide_expired_items"`
+}
+
+// StalenessConfig defines the timestamp bound of the eventually consistent reads, ExactStalenessMs
+// takes precedence over MaxStalenessMs and the reads are strong when none is set
+type StalenessConfig struct {
+	MaxStalenessMs   int64 `mapstructure:"max_staleness_ms"`
+	ExactStalenessMs int64 `mapstructure:"exact_staleness_ms"`
+}
+
+// ReadsConfig defines the staleness of the eventually consistent reads for all the tables,
+// and the tables overriding it
+type ReadsConfig struct {
+	StalenessConfig `mapstructure:",squash"`
+	Tables          map[string]StalenessConfig `mapstructure:"tables"`
+}
+
+// EndpointConfig defines the address advertised by DescribeEndpoints
+type EndpointConfig struct {
+	Address              string `mapstructure:"address"`
+	CachePeriodInMinutes int64  `mapstructure:"cache_period_in_minutes"`
+}
+
+// LimitsConfig defines the capacity limits reported by DescribeLimits
+type LimitsConfig struct {
+	AccountMaxReadCapacityUnits  int64 `mapstructure:"account_max_read_capacity_units"`
+	AccountMaxWriteCapacityUnits int64 `mapstructure:"account_max_write_capacity_units"`
+	TableMaxReadCapacityUnits    int64 `mapstructure:"table_max_read_capacity_units"`
+	TableMaxWriteCapacityUnits   int64 `mapstructure:"table_max_write_capacity_units"`
+}
+
 type Config struct {
-	Spanner   SpannerConfig `mapstructure:"spanner"`
-	Otel      *OtelConfig   `mapstructure:"otel"`
+	Spanner   SpannerConfig  `mapstructure:"spanner"`
+	Otel      *OtelConfig    `mapstructure:"otel"`
+	TTL       TTLConfig      `mapstructure:"ttl"`
+	Reads     ReadsConfig    `mapstructure:"reads"`
+	Endpoint  EndpointConfig `mapstructure:"endpoint"`
+	Limits    LimitsConfig   `mapstructure:"limits"`
+	Operation Operation      `mapstructure:"operation"`
 	UserAgent string
 	GinMode   string `mapstructure:"gin_mode"`
 	LogLevel  string `mapstructure:"log_level"`
@@ -132,6 +172,7 @@ type GetItemMeta struct {
 	ProjectionExpression     string                              `json:"ProjectionExpression"`
 	ExpressionAttributeNames map[string]string                   `json:"ExpressionAttributeNames"`
 	Key                      map[string]*dynamodb.AttributeValue `json:"Key"`
+	ConsistentRead           bool                                `json:"ConsistentRead"`
 }
 
 // BatchGetMeta struct
@@ -146,6 +187,7 @@ type BatchGetWithProjectionMeta struct {
 	ProjectionExpression     string                                `json:"ProjectionExpression"`
 	ExpressionAttributeNames map[string]string                     `json:"ExpressionAttributeNames"`
 	Keys                     []map[string]*dynamodb.AttributeValue `json:"Keys"`
+	ConsistentRead           bool                                  `json:"ConsistentRead"`
 }
 
 // Delete struct
@@ -159,6 +201,15 @@ type Delete struct {
 	ExpressionAttributeNames  map[string]string                   `json:"ExpressionAttributeNames"`
 }
 
+// BatchWriteMeta struct, one put or delete of a BatchWriteItem: Item is set for a put and Key for a delete.
+// ExpressionAttributeMap is only set for the actions of a TransactWriteItems.
+type BatchWriteMeta struct {
+	TableName              string                 `json:"TableName"`
+	Item                   map[string]interface{} `json:"Item"`
+	Key                    map[string]interface{} `json:"Key"`
+	ExpressionAttributeMap map[string]interface{} `json:"ExpressionAttributeMap"`
+}
+
 // BulkDelete struct
 type BulkDelete struct {
 	TableName          string                                `json:"TableName"`
@@ -183,19 +234,25 @@ type Query struct {
 	ExpressionAttributeValues map[string]*dynamodb.AttributeValue `json:"ExpressionAttributeValues"`
 	ExclusiveStartKey         map[string]*dynamodb.AttributeValue `json:"ExclusiveStartKey"`
 	Select                    string                              `json:"Select"`
+	ConsistentRead            bool                                `json:"ConsistentRead"`
+	Segment                   int64                               `json:"-"`
+	TotalSegments             int64                               `json:"-"`
+	// Filter is the parsed FilterExp, it is evaluated on the items read
+	Filter *expression.Expression `json:"-"`
 }
 
 // UpdateAttr struct
 type UpdateAttr struct {
-	TableName                 string                              `json:"TableName"`
-	PrimaryKeyMap             map[string]interface{}              `json:"PrimaryKeyMap"`
-	ReturnValues              string                              `json:"ReturnValues"`
-	UpdateExpression          string                              `json:"UpdateExpression"`
-	ConditionExpression       string                              `json:"ConditionExpression"`
-	ExpressionAttributeMap    map[string]interface{}              `json:"AttrVals"`
-	ExpressionAttributeNames  map[string]string                   `json:"ExpressionAttributeNames"`
-	Key                       map[string]*dynamodb.AttributeValue `json:"Key"`
-	ExpressionAttributeValues map[string]*dynamodb.AttributeValue `json:"ExpressionAttributeValues"`
+	TableName                           string                              `json:"TableName"`
+	PrimaryKeyMap                       map[string]interface{}              `json:"PrimaryKeyMap"`
+	ReturnValues                        string                              `json:"ReturnValues"`
+	UpdateExpression                    string                              `json:"UpdateExpression"`
+	ConditionExpression                 string                              `json:"ConditionExpression"`
+	ExpressionAttributeMap              map[string]interface{}              `json:"AttrVals"`
+	ExpressionAttributeNames            map[string]string                   `json:"ExpressionAttributeNames"`
+	Key                                 map[string]*dynamodb.AttributeValue `json:"Key"`
+	ExpressionAttributeValues           map[string]*dynamodb.AttributeValue `json:"ExpressionAttributeValues"`
+	ReturnValuesOnConditionCheckFailure string                              `json:"ReturnValuesOnConditionCheckFailure"`
 }
 
 // ScanMeta for Scan request
@@ -212,6 +269,9 @@ type ScanMeta struct {
 	ExpressionAttributeNames  map[string]string                   `json:"ExpressionAttributeNames"`
 	ExpressionAttributeMap    map[string]interface{}              `json:"ExpressionAttributeMap"`
 	ExpressionAttributeValues map[string]*dynamodb.AttributeValue `json:"ExpressionAttributeValues"`
+	Segment                   *int64                              `json:"Segment"`
+	TotalSegments             *int64                              `json:"TotalSegments"`
+	ConsistentRead            bool                                `json:"ConsistentRead"`
 }
 
 // TableConfig for Configuration table
@@ -226,6 +286,10 @@ type TableConfig struct {
 	IsComplement     bool                   `json:"IsComplement,omitempty"`
 	TableSource      string                 `json:"TableSource,omitempty"`
 	ActualTable      string                 `json:"ActualTable,omitempty"`
+	IndexType        string                 `json:"IndexType,omitempty"`
+	ProjectionType   string                 `json:"ProjectionType,omitempty"`
+	NonKeyAttributes []string               `json:"NonKeyAttributes,omitempty"`
+	TTLAttribute     string                 `json:"TTLAttribute,omitempty"`
 }
 
 // BatchWriteItem for Batch Operation
@@ -278,12 +342,21 @@ func init() {
 	TableDDL = make(map[string]map[string]string)
 	TableDDL["dynamodb_adapter_table_ddl"] = map[string]string{"tableName": "S", "column": "S", "dynamoDataType": "S", "originalColumn": "S", "partitionKey": "S", "sortKey": "S", "spannerIndexName": "S", "actualTable": "S", "spannerDataType": "S"}
 	TableDDL["dynamodb_adapter_config_manager"] = map[string]string{"tableName": "STRING(MAX)", "config": "STRING(MAX)", "cronTime": "STRING(MAX)", "uniqueValue": "STRING(MAX)", "enabledStream": "STRING(MAX)"}
+	TableDDL["dynamodb_adapter_index_ddl"] = map[string]string{"tableName": "S", "indexName": "S", "spannerIndexName": "S", "indexType": "S", "partitionKey": "S", "sortKey": "S", "projectionType": "S", "nonKeyAttributes": "SS"}
+	TableDDL["dynamodb_adapter_ttl"] = map[string]string{"tableName": "S", "attributeName": "S"}
+	TableDDL["dynamodb_adapter_tags"] = map[string]string{"tableName": "S", "tagKey": "S", "tagValue": "S"}
 	TableSpannerDDL = make(map[string]map[string]string)
 	TableSpannerDDL["dynamodb_adapter_table_ddl"] = map[string]string{"tableName": "STRING(MAX)", "column": "STRING(MAX)", "dynamoDataType": "STRING(MAX)", "originalColumn": "STRING(MAX)", "partitionKey": "STRING(MAX)", "sortKey": "STRING(MAX)", "spannerIndexName": "STRING(MAX)", "actualTable": "STRING(MAX)", "spannerDataType": "STRING(MAX)"}
 	TableSpannerDDL["dynamodb_adapter_config_manager"] = map[string]string{"tableName": "STRING(MAX)", "config": "STRING(MAX)", "cronTime": "STRING(MAX)", "uniqueValue": "STRING(MAX)", "enabledStream": "STRING(MAX)"}
+	TableSpannerDDL["dynamodb_adapter_index_ddl"] = map[string]string{"tableName": "STRING(MAX)", "indexName": "STRING(MAX)", "spannerIndexName": "STRING(MAX)", "indexType": "STRING(MAX)", "partitionKey": "STRING(MAX)", "sortKey": "STRING(MAX)", "projectionType": "STRING(MAX)", "nonKeyAttributes": "ARRAY<STRING(MAX)>"}
+	TableSpannerDDL["dynamodb_adapter_ttl"] = map[string]string{"tableName": "STRING(MAX)", "attributeName": "STRING(MAX)"}
+	TableSpannerDDL["dynamodb_adapter_tags"] = map[string]string{"tableName": "STRING(MAX)", "tagKey": "STRING(MAX)", "tagValue": "STRING(MAX)"}
 	TableColumnMap = make(map[string][]string)
 	TableColumnMap["dynamodb_adapter_table_ddl"] = []string{"tableName", "column", "dynamoDataType", "originalColumn", "partitionKey", "sortKey", "spannerIndexName", "actualTable", "spannerDataType"}
 	TableColumnMap["dynamodb_adapter_config_manager"] = []string{"tableName", "config", "cronTime", "uniqueValue", "enabledStream"}
+	TableColumnMap["dynamodb_adapter_index_ddl"] = []string{"tableName", "indexName", "spannerIndexName", "indexType", "partitionKey", "sortKey", "projectionType", "nonKeyAttributes"}
+	TableColumnMap["dynamodb_adapter_ttl"] = []string{"tableName", "attributeName"}
+	TableColumnMap["dynamodb_adapter_tags"] = []string{"tableName", "tagKey", "tagValue"}
 	TableColChangeMap = make(map[string]struct{})
 	ColumnToOriginalCol = make(map[string]string)
 	OriginalColResponse = make(map[string]string)
@@ -291,11 +364,12 @@ func init() {
 
 // Eval for Evaluation expression
 type Eval struct {
-	Cond       *vm.Program
+	Cond *expression.Expression
+	// Attributes - the document paths used by the condition
 	Attributes []string
-	Cols       []string
-	Tokens     []string
-	ValueMap   map[string]interface{}
+	// Cols - the top-level attributes of the paths, read from Spanner to evaluate the condition
+	Cols     []string
+	ValueMap map[string]interface{}
 }
 
 // UpdateExpressionCondition for Update Condition
@@ -369,6 +443,7 @@ type GetItemRequest struct {
 // TransactWriteItemsRequest represents the input structure for TransactWriteItems API.
 type TransactWriteItemsRequest struct {
 	TransactItems               []TransactWriteItem `json:"TransactItems"`
+	ClientRequestToken          string              `json:"ClientRequestToken,omitempty"`
 	ReturnConsumedCapacity      string              `json:"ReturnConsumedCapacity,omitempty"`
 	ReturnItemCollectionMetrics string              `json:"ReturnItemCollectionMetrics,omitempty"` // Added for consistency with DynamoDB
 }
@@ -473,8 +548,8 @@ type TransactGetItemsResponse struct {
 }
 
 type ResponseItem struct {
-	TableName interface{}            `json:"TableName"`
-	Item      map[string]interface{} `json:"Item"`
+	TableName interface{}            `json:"TableName,omitempty"`
+	Item      map[string]interface{} `json:"Item,omitempty"`
 }
 
 type TransactWriteItemsResponse struct {
@@ -502,3 +577,211 @@ type KeyCondition struct {
 	AttributeValueList []*dynamodb.AttributeValue `json:"AttributeValueList"`
 	ComparisonOperator string                     `json:"ComparisonOperator"`
 }
+
+// KeySchemaElement represents a single element of a DynamoDB key schema.
+type KeySchemaElement struct {
+	AttributeName string `json:"AttributeName"`
+	KeyType       string `json:"KeyType"`
+}
+
+// AttributeDefinition represents a DynamoDB attribute definition.
+type AttributeDefinition struct {
+	AttributeName string `json:"AttributeName"`
+	AttributeType string `json:"AttributeType"`
+}
+
+// Projection represents the attributes projected into a secondary index.
+type Projection struct {
+	ProjectionType   string   `json:"ProjectionType,omitempty"`
+	NonKeyAttributes []string `json:"NonKeyAttributes,omitempty"`
+}
+
+// ProvisionedThroughput represents the provisioned throughput settings of a table or index.
+type ProvisionedThroughput struct {
+	ReadCapacityUnits  int64 `json:"ReadCapacityUnits"`
+	WriteCapacityUnits int64 `json:"WriteCapacityUnits"`
+}
+
+// SecondaryIndex represents a global or local secondary index in a CreateTable request.
+type SecondaryIndex struct {
+	IndexName             string                 `json:"IndexName"`
+	KeySchema             []KeySchemaElement     `json:"KeySchema"`
+	Projection            Projection             `json:"Projection"`
+	ProvisionedThroughput *ProvisionedThroughput `json:"ProvisionedThroughput,omitempty"`
+}
+
+// CreateTableMeta for CreateTable request
+type CreateTableMeta struct {
+	TableName              string                 `json:"TableName"`
+	KeySchema              []KeySchemaElement     `json:"KeySchema"`
+	AttributeDefinitions   []AttributeDefinition  `json:"AttributeDefinitions"`
+	GlobalSecondaryIndexes []SecondaryIndex       `json:"GlobalSecondaryIndexes"`
+	LocalSecondaryIndexes  []SecondaryIndex       `json:"LocalSecondaryIndexes"`
+	BillingMode            string                 `json:"BillingMode"`
+	ProvisionedThroughput  *ProvisionedThroughput `json:"ProvisionedThroughput"`
+	Tags                   []Tag                  `json:"Tags"`
+}
+
+// DeleteTableMeta for DeleteTable request
+type DeleteTableMeta struct {
+	TableName string `json:"TableName"`
+}
+
+// DescribeTableMeta for DescribeTable request
+type DescribeTableMeta struct {
+	TableName string `json:"TableName"`
+}
+
+// CreateGlobalSecondaryIndexAction represents a new global secondary index to be added to a table.
+type CreateGlobalSecondaryIndexAction struct {
+	IndexName             string                 `json:"IndexName"`
+	KeySchema             []KeySchemaElement     `json:"KeySchema"`
+	Projection            Projection             `json:"Projection"`
+	ProvisionedThroughput *ProvisionedThroughput `json:"ProvisionedThroughput,omitempty"`
+}
+
+// DeleteGlobalSecondaryIndexAction represents a global secondary index to be removed from a table.
+type DeleteGlobalSecondaryIndexAction struct {
+	IndexName string `json:"IndexName"`
+}
+
+// UpdateGlobalSecondaryIndexAction represents new provisioned throughput settings of a global
+// secondary index, which have no effect on Spanner.
+type UpdateGlobalSecondaryIndexAction struct {
+	IndexName             string                 `json:"IndexName"`
+	ProvisionedThroughput *ProvisionedThroughput `json:"ProvisionedThroughput,omitempty"`
+}
+
+// GlobalSecondaryIndexUpdate represents one of the create, update or delete index actions.
+type GlobalSecondaryIndexUpdate struct {
+	Create *CreateGlobalSecondaryIndexAction `json:"Create,omitempty"`
+	Update *UpdateGlobalSecondaryIndexAction `json:"Update,omitempty"`
+	Delete *DeleteGlobalSecondaryIndexAction `json:"Delete,omitempty"`
+}
+
+// UpdateTableMeta for UpdateTable request
+type UpdateTableMeta struct {
+	TableName                   string                       `json:"TableName"`
+	AttributeDefinitions        []AttributeDefinition        `json:"AttributeDefinitions"`
+	GlobalSecondaryIndexUpdates []GlobalSecondaryIndexUpdate `json:"GlobalSecondaryIndexUpdates"`
+	BillingMode                 string                       `json:"BillingMode"`
+	ProvisionedThroughput       *ProvisionedThroughput       `json:"ProvisionedThroughput"`
+}
+
+// TimeToLiveSpecification represents the TimeToLive settings of a table.
+type TimeToLiveSpecification struct {
+	Enabled       bool   `json:"Enabled"`
+	AttributeName string `json:"AttributeName"`
+}
+
+// UpdateTimeToLiveMeta for UpdateTimeToLive request
+type UpdateTimeToLiveMeta struct {
+	TableName               string                  `json:"TableName"`
+	TimeToLiveSpecification TimeToLiveSpecification `json:"TimeToLiveSpecification"`
+}
+
+// DescribeTimeToLiveMeta for DescribeTimeToLive request
+type DescribeTimeToLiveMeta struct {
+	TableName string `json:"TableName"`
+}
+
+// TimeToLiveDescription represents the TimeToLive status of a table.
+type TimeToLiveDescription struct {
+	TimeToLiveStatus string `json:"TimeToLiveStatus"`
+	AttributeName    string `json:"AttributeName,omitempty"`
+}
+
+// Tag represents a key-value pair attached to a table.
+type Tag struct {
+	Key   string `json:"Key"`
+	Value string `json:"Value"`
+}
+
+// TagResourceMeta for TagResource request
+type TagResourceMeta struct {
+	ResourceArn string `json:"ResourceArn"`
+	Tags        []Tag  `json:"Tags"`
+}
+
+// UntagResourceMeta for UntagResource request
+type UntagResourceMeta struct {
+	ResourceArn string   `json:"ResourceArn"`
+	TagKeys     []string `json:"TagKeys"`
+}
+
+// ListTagsOfResourceMeta for ListTagsOfResource request
+type ListTagsOfResourceMeta struct {
+	ResourceArn string `json:"ResourceArn"`
+	NextToken   string `json:"NextToken"`
+}
+
+// ListTagsOfResourceOutput for ListTagsOfResource response
+type ListTagsOfResourceOutput struct {
+	Tags      []Tag  `json:"Tags"`
+	NextToken string `json:"NextToken,omitempty"`
+}
+
+// Endpoint represents an endpoint returned by DescribeEndpoints
+type Endpoint struct {
+	Address              string `json:"Address"`
+	CachePeriodInMinutes int64  `json:"CachePeriodInMinutes"`
+}
+
+// DescribeEndpointsOutput for DescribeEndpoints response
+type DescribeEndpointsOutput struct {
+	Endpoints []Endpoint `json:"Endpoints"`
+}
+
+// DescribeLimitsOutput for DescribeLimits response
+type DescribeLimitsOutput struct {
+	AccountMaxReadCapacityUnits  int64 `json:"AccountMaxReadCapacityUnits"`
+	AccountMaxWriteCapacityUnits int64 `json:"AccountMaxWriteCapacityUnits"`
+	TableMaxReadCapacityUnits    int64 `json:"TableMaxReadCapacityUnits"`
+	TableMaxWriteCapacityUnits   int64 `json:"TableMaxWriteCapacityUnits"`
+}
+
+// ListTablesMeta for ListTables request
+type ListTablesMeta struct {
+	ExclusiveStartTableName string `json:"ExclusiveStartTableName"`
+	Limit                   int64  `json:"Limit"`
+}
+
+// ListTablesOutput for ListTables response
+type ListTablesOutput struct {
+	TableNames             []string `json:"TableNames"`
+	LastEvaluatedTableName string   `json:"LastEvaluatedTableName,omitempty"`
+}
+
+// BillingModeSummary represents the billing mode of a table.
+type BillingModeSummary struct {
+	BillingMode string `json:"BillingMode"`
+}
+
+// SecondaryIndexDescription represents a global or local secondary index in a TableDescription.
+type SecondaryIndexDescription struct {
+	IndexName             string                 `json:"IndexName"`
+	IndexArn              string                 `json:"IndexArn,omitempty"`
+	IndexStatus           string                 `json:"IndexStatus,omitempty"`
+	Backfilling           bool                   `json:"Backfilling,omitempty"`
+	KeySchema             []KeySchemaElement     `json:"KeySchema"`
+	Projection            Projection             `json:"Projection"`
+	ProvisionedThroughput *ProvisionedThroughput `json:"ProvisionedThroughput,omitempty"`
+	ItemCount             int64                  `json:"ItemCount"`
+	IndexSizeBytes        int64                  `json:"IndexSizeBytes"`
+}
+
+// TableDescription represents the DynamoDB description of a table.
+type TableDescription struct {
+	TableName              string                      `json:"TableName"`
+	TableArn               string                      `json:"TableArn,omitempty"`
+	TableStatus            string                      `json:"TableStatus"`
+	CreationDateTime       float64                     `json:"CreationDateTime,omitempty"`
+	KeySchema              []KeySchemaElement          `json:"KeySchema,omitempty"`
+	AttributeDefinitions   []AttributeDefinition       `json:"AttributeDefinitions,omitempty"`
+	GlobalSecondaryIndexes []SecondaryIndexDescription `json:"GlobalSecondaryIndexes,omitempty"`
+	LocalSecondaryIndexes  []SecondaryIndexDescription `json:"LocalSecondaryIndexes,omitempty"`
+	BillingModeSummary     *BillingModeSummary         `json:"BillingModeSummary,omitempty"`
+	ProvisionedThroughput  *ProvisionedThroughput      `json:"ProvisionedThroughput,omitempty"`
+	ItemCount              int64                       `json:"ItemCount"`
+	TableSizeBytes         int64                       `json:"TableSizeBytes"`
+}
@@ -22,17 +22,39 @@ import (
 	"github.com/cloudspannerecosystem/dynamodb-adapter/pkg/logger"
 )
 
+// errorTypePrefix - DynamoDB qualifies the error codes with the service and API version in __type
+const errorTypePrefix = "com.amazonaws.dynamodb.v20120810#"
+
 var errorMapping = map[string]string{
-	"Cancelled":          "ValidationError",
-	"DeadlineExceeded":   "ValidationError",
+	"Cancelled":          "ValidationException",
+	"DeadlineExceeded":   "InternalServerError",
 	"FailedPrecondition": "ConditionalCheckFailedException",
-	"Aborted":            "ValidationError",
+	"Aborted":            "TransactionConflictException",
+}
+
+// errorStatus - HTTP status of the server side errors, all the other errors are client errors
+// answered with 400 as in DynamoDB
+var errorStatus = map[string]int{
+	"InternalServerError":         http.StatusInternalServerError,
+	"ServiceUnavailableException": http.StatusServiceUnavailable,
 }
 
 // Error - this is the error response
 type Error struct {
 	ErrorCode    string `json:"errorCode"`
 	ErrorMessage string `json:"message"`
+	// CancellationReasons - the reason of every action of a cancelled transaction, in request order
+	CancellationReasons []CancellationReason `json:"-"`
+	// cause - the underlying error, so that e.g. the aborted Spanner transactions are still retried
+	cause error
+}
+
+// CancellationReason - why an action of a cancelled transaction failed, the Code is None for the actions
+// which did not fail. Item holds the item when ReturnValuesOnConditionCheckFailure is ALL_OLD.
+type CancellationReason struct {
+	Code    string                 `json:"Code"`
+	Message string                 `json:"Message,omitempty"`
+	Item    map[string]interface{} `json:"Item,omitempty"`
 }
 
 // Error - convert error into string
@@ -40,31 +62,71 @@ func (e Error) Error() string {
 	return e.ErrorCode
 }
 
+// Unwrap - returns the underlying error
+func (e Error) Unwrap() error {
+	return e.cause
+}
+
 // New - create new Error
 func New(errorCode string, logMessage ...interface{}) *Error {
 	err := new(Error)
 	err.ErrorCode = errorCode
 	err.ErrorMessage = fmt.Sprintln(logMessage...)
+	for _, m := range logMessage {
+		if cause, ok := m.(error); ok {
+			err.cause = cause
+			break
+		}
+	}
 	logger.Error(err, logMessage)
 	return err
 }
 
+// NewTransactionCanceled - creates the TransactionCanceledException of a transaction with the reasons of
+// all its actions
+func NewTransactionCanceled(reasons []CancellationReason) *Error {
+	codes := make([]string, len(reasons))
+	for i, reason := range reasons {
+		codes[i] = reason.Code
+	}
+	err := New("TransactionCanceledException", "Transaction cancelled, please refer cancellation reasons for specific reasons ["+strings.Join(codes, ", ")+"]")
+	err.CancellationReasons = reasons
+	return err
+}
+
 // HTTPResponse - this is used to set http response
 func HTTPResponse(err error, body interface{}) (int, interface{}) {
 	e, ok := err.(*Error)
 	if ok {
-		return http.StatusBadRequest, map[string]interface{}{"code": e.ErrorCode, "message": e.ErrorMessage}
+		return e.response()
 	}
 	logger.Error(err)
 	logger.Errorf("body: %+v\n ", body)
-	return http.StatusInternalServerError, map[string]interface{}{"code": "UncaughtException", "message": err.Error()}
+	return Error{ErrorCode: "InternalServerError", ErrorMessage: err.Error()}.response()
 }
 
 // HTTPResponse - this is used to set http response
 func (e Error) HTTPResponse(body interface{}) (int, interface{}) {
 	logger.Errorf("body: %+v\n ", body)
 
-	return http.StatusBadRequest, map[string]interface{}{"code": e.ErrorCode, "message": e.ErrorMessage}
+	return e.response()
+}
+
+// response - builds the status and the body of the error in the DynamoDB wire format,
+// e.g. {"__type":"com.amazonaws.dynamodb.v20120810#ResourceNotFoundException","message":"..."}
+func (e Error) response() (int, interface{}) {
+	status, ok := errorStatus[e.ErrorCode]
+	if !ok {
+		status = http.StatusBadRequest
+	}
+	body := map[string]interface{}{
+		"__type":  errorTypePrefix + e.ErrorCode,
+		"message": strings.TrimSpace(e.ErrorMessage),
+	}
+	if len(e.CancellationReasons) > 0 {
+		body["CancellationReasons"] = e.CancellationReasons
+	}
+	return status, body
 }
 
 // AssignError - this will assign error
@@ -78,6 +140,7 @@ func AssignError(err error) *Error {
 			e := new(Error)
 			e.ErrorCode = v
 			e.ErrorMessage = err.Error()
+			e.cause = err
 			return e
 		}
 	}
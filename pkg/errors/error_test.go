@@ -49,3 +49,49 @@ func TestNewForSystemErrors(t *testing.T) {
 	assert.Equal(t, http.StatusInternalServerError, code)
 
 }
+
+func TestHTTPResponseWireFormat(t *testing.T) {
+	tests := []struct {
+		err        error
+		wantStatus int
+		wantType   string
+	}{
+		{New("ConditionalCheckFailedException", "The conditional request failed"), http.StatusBadRequest, "com.amazonaws.dynamodb.v20120810#ConditionalCheckFailedException"},
+		{New("InternalServerError", "Internal server error"), http.StatusInternalServerError, "com.amazonaws.dynamodb.v20120810#InternalServerError"},
+		{errors.New("spanner: connection reset"), http.StatusInternalServerError, "com.amazonaws.dynamodb.v20120810#InternalServerError"},
+	}
+	for _, tc := range tests {
+		code, body := HTTPResponse(tc.err, nil)
+		assert.Equal(t, tc.wantStatus, code)
+		assert.Equal(t, tc.wantType, body.(map[string]interface{})["__type"])
+	}
+
+	_, body := New("ResourceNotFoundException", "Requested resource not found").HTTPResponse(nil)
+	assert.Equal(t, "Requested resource not found", body.(map[string]interface{})["message"])
+}
+
+func TestAssignError(t *testing.T) {
+	assert.Nil(t, AssignError(nil))
+	assert.Nil(t, AssignError(errors.New("spanner: code = NotFound")))
+	assert.Equal(t, "ConditionalCheckFailedException", AssignError(errors.New("spanner: code = FailedPrecondition")).ErrorCode)
+	assert.Equal(t, "TransactionConflictException", AssignError(errors.New("spanner: code = Aborted")).ErrorCode)
+}
+
+func TestNewTransactionCanceled(t *testing.T) {
+	e := NewTransactionCanceled([]CancellationReason{
+		{Code: "None"},
+		{Code: "ConditionalCheckFailed", Message: "The conditional request failed"},
+	})
+	code, body := e.HTTPResponse(nil)
+	assert.Equal(t, http.StatusBadRequest, code)
+	assert.Equal(t, "com.amazonaws.dynamodb.v20120810#TransactionCanceledException", body.(map[string]interface{})["__type"])
+	assert.Equal(t, "Transaction cancelled, please refer cancellation reasons for specific reasons [None, ConditionalCheckFailed]", body.(map[string]interface{})["message"])
+	assert.Equal(t, e.CancellationReasons, body.(map[string]interface{})["CancellationReasons"])
+}
+
+func TestUnwrap(t *testing.T) {
+	cause := errors.New("spanner: code = Aborted")
+	assert.True(t, errors.Is(New("ResourceNotFoundException", "employee", cause), cause))
+	assert.True(t, errors.Is(AssignError(cause), cause))
+	assert.Nil(t, errors.Unwrap(New("ValidationException", "wrong key")))
+}
@@ -0,0 +1,412 @@
+// Copyright 2020 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package expression
+
+import (
+	"bytes"
+	"errors"
+	"math"
+	"math/big"
+	"slices"
+	"strings"
+	"time"
+)
+
+// the errors of the updates which can not be applied to the item, with the messages of DynamoDB
+var (
+	errMissingAttribute = errors.New("The provided expression refers to an attribute that does not exist in the item")
+	errIncorrectType    = errors.New("An operand in the update expression has an incorrect data type")
+	errInvalidPath      = errors.New("The document path provided in the update expression is invalid for update")
+)
+
+// Apply applies the actions to a copy of the item and returns the updated item. Maps and lists of the
+// item are plain Go maps and slices. As in DynamoDB the values of the SET actions are evaluated on the
+// item before the update, and the list elements removed are identified by their index before the update.
+func (u *Update) Apply(item map[string]interface{}) (map[string]interface{}, error) {
+	ev := evaluator{item: item, values: u.values}
+	values := make([]interface{}, len(u.Actions))
+	for i, action := range u.Actions {
+		if set, ok := action.(*SetAction); ok {
+			v, err := ev.setValue(set.Value)
+			if err != nil {
+				return nil, err
+			}
+			values[i] = v
+		}
+	}
+
+	updated := make(map[string]interface{}, len(item))
+	for k, v := range item {
+		updated[k] = copyValue(v)
+	}
+	var removed []*Path
+	for i, action := range u.Actions {
+		var err error
+		switch a := action.(type) {
+		case *SetAction:
+			_, err = setPath(updated, a.Path.Elements, values[i])
+		case *AddAction:
+			err = u.update(updated, a.Path, a.Value, add)
+		case *DeleteAction:
+			err = u.update(updated, a.Path, a.Value, deleteElements)
+		case *RemoveAction:
+			removed = append(removed, a.Path)
+		}
+		if err != nil {
+			return nil, err
+		}
+	}
+	// the list elements are removed from the highest index, so that the other indexes do not shift
+	slices.SortFunc(removed, func(a, b *Path) int { return comparePaths(b, a) })
+	for _, path := range removed {
+		if _, err := removePath(updated, path.Elements); err != nil {
+			return nil, err
+		}
+	}
+	return updated, nil
+}
+
+// update replaces the value at the path with the result of op, the path is removed when op returns nil
+func (u *Update) update(item map[string]interface{}, path *Path, value *Value, op func(old interface{}, exists bool, v interface{}) (interface{}, error)) error {
+	old, exists := Resolve(item, path)
+	v, err := op(old, exists, u.values[value.Name])
+	if err != nil {
+		return err
+	}
+	if v == nil {
+		if exists {
+			_, err = removePath(item, path.Elements)
+		}
+		return err
+	}
+	_, err = setPath(item, path.Elements, v)
+	return err
+}
+
+// setValue evaluates the value of a SET action
+func (ev evaluator) setValue(o Operand) (interface{}, error) {
+	switch o := o.(type) {
+	case *Arithmetic:
+		left, err := ev.setValue(o.Left)
+		if err != nil {
+			return nil, err
+		}
+		right, err := ev.setValue(o.Right)
+		if err != nil {
+			return nil, err
+		}
+		x, xok := toNumber(left)
+		y, yok := toNumber(right)
+		if !xok || !yok {
+			return nil, errIncorrectType
+		}
+		if o.Op == "+" {
+			return numberLike(new(big.Rat).Add(x, y), left), nil
+		}
+		return numberLike(new(big.Rat).Sub(x, y), left), nil
+	case *IfNotExists:
+		if v, ok := Resolve(ev.item, o.Path); ok {
+			return copyValue(v), nil
+		}
+		return ev.setValue(o.Value)
+	case *ListAppend:
+		left, err := ev.setValue(o.Left)
+		if err != nil {
+			return nil, err
+		}
+		right, err := ev.setValue(o.Right)
+		if err != nil {
+			return nil, err
+		}
+		l, lok := left.([]interface{})
+		r, rok := right.([]interface{})
+		if !lok || !rok {
+			return nil, errIncorrectType
+		}
+		return append(l, r...), nil
+	}
+	v, ok := ev.operand(o)
+	if !ok {
+		return nil, errMissingAttribute
+	}
+	return uniqueSet(copyValue(v)), nil
+}
+
+// add adds a number to a number, or the elements of a set to a set of the same type
+func add(old interface{}, exists bool, v interface{}) (interface{}, error) {
+	if !exists {
+		return uniqueSet(copyValue(v)), nil
+	}
+	switch v := v.(type) {
+	case []string:
+		set, ok := old.([]string)
+		if !ok {
+			return nil, errIncorrectType
+		}
+		return union(set, v, func(a, b string) bool { return a == b }), nil
+	case [][]byte:
+		set, ok := old.([][]byte)
+		if !ok {
+			return nil, errIncorrectType
+		}
+		return union(set, v, bytes.Equal), nil
+	}
+	if set, ok := toNumberSet(v); ok {
+		oldSet, ok := toNumberSet(old)
+		if !ok {
+			return nil, errIncorrectType
+		}
+		return float64Set(union(oldSet, set, ratEqual)), nil
+	}
+	x, xok := toNumber(old)
+	y, yok := toNumber(v)
+	if !xok || !yok {
+		return nil, errIncorrectType
+	}
+	return numberLike(new(big.Rat).Add(x, y), old), nil
+}
+
+// deleteElements removes the elements of a set from a set of the same type, it returns nil when the set
+// does not exist or no element is left, as DynamoDB does not store empty sets
+func deleteElements(old interface{}, exists bool, v interface{}) (interface{}, error) {
+	if !exists {
+		return nil, nil
+	}
+	switch v := v.(type) {
+	case []string:
+		set, ok := old.([]string)
+		if !ok {
+			return nil, errIncorrectType
+		}
+		return nonEmpty(difference(set, v, func(a, b string) bool { return a == b })), nil
+	case [][]byte:
+		set, ok := old.([][]byte)
+		if !ok {
+			return nil, errIncorrectType
+		}
+		return nonEmpty(difference(set, v, bytes.Equal)), nil
+	}
+	set, ok := toNumberSet(v)
+	oldSet, oldOk := toNumberSet(old)
+	if !ok || !oldOk {
+		return nil, errIncorrectType
+	}
+	return nonEmpty(float64Set(difference(oldSet, set, ratEqual))), nil
+}
+
+// setPath sets the value at the path, an index past the end of a list appends the value to the list.
+// It returns the updated container, as appending to a list may reallocate it.
+func setPath(container interface{}, elements []PathElement, value interface{}) (interface{}, error) {
+	e := elements[0]
+	if e.IsIndex() {
+		list, ok := container.([]interface{})
+		if !ok {
+			return nil, errInvalidPath
+		}
+		if len(elements) == 1 {
+			if e.Index < len(list) {
+				list[e.Index] = value
+				return list, nil
+			}
+			return append(list, value), nil
+		}
+		if e.Index >= len(list) {
+			return nil, errInvalidPath
+		}
+		child, err := setPath(list[e.Index], elements[1:], value)
+		if err != nil {
+			return nil, err
+		}
+		list[e.Index] = child
+		return list, nil
+	}
+	m, ok := container.(map[string]interface{})
+	if !ok {
+		return nil, errInvalidPath
+	}
+	if len(elements) == 1 {
+		m[e.Name] = value
+		return m, nil
+	}
+	if m[e.Name] == nil {
+		return nil, errInvalidPath
+	}
+	child, err := setPath(m[e.Name], elements[1:], value)
+	if err != nil {
+		return nil, err
+	}
+	m[e.Name] = child
+	return m, nil
+}
+
+// removePath removes the attribute or the list element at the path, a missing one is ignored. It
+// returns the updated container, as removing a list element shifts the list.
+func removePath(container interface{}, elements []PathElement) (interface{}, error) {
+	e := elements[0]
+	if e.IsIndex() {
+		list, ok := container.([]interface{})
+		if !ok {
+			return nil, errInvalidPath
+		}
+		if e.Index >= len(list) {
+			return list, nil
+		}
+		if len(elements) == 1 {
+			return slices.Delete(list, e.Index, e.Index+1), nil
+		}
+		child, err := removePath(list[e.Index], elements[1:])
+		if err != nil {
+			return nil, err
+		}
+		list[e.Index] = child
+		return list, nil
+	}
+	m, ok := container.(map[string]interface{})
+	if !ok {
+		return nil, errInvalidPath
+	}
+	if len(elements) == 1 {
+		delete(m, e.Name)
+		return m, nil
+	}
+	if m[e.Name] == nil {
+		return m, nil
+	}
+	child, err := removePath(m[e.Name], elements[1:])
+	if err != nil {
+		return nil, err
+	}
+	m[e.Name] = child
+	return m, nil
+}
+
+// comparePaths orders the paths element by element, the indexes by their value
+func comparePaths(a, b *Path) int {
+	for i := 0; i < len(a.Elements) && i < len(b.Elements); i++ {
+		x, y := a.Elements[i], b.Elements[i]
+		if cmp := strings.Compare(x.Name, y.Name); cmp != 0 {
+			return cmp
+		}
+		if x.Index != y.Index {
+			return x.Index - y.Index
+		}
+	}
+	return len(a.Elements) - len(b.Elements)
+}
+
+// numberLike converts the result of an arithmetic operation into the type of the number it is computed
+// from, so that the number can be written to the column holding it
+func numberLike(r *big.Rat, like interface{}) interface{} {
+	switch like.(type) {
+	case int64:
+		if r.IsInt() && r.Num().IsInt64() {
+			return r.Num().Int64()
+		}
+	case big.Rat:
+		return *r
+	case *big.Rat:
+		return r
+	case time.Time:
+		// TIMESTAMP columns hold N attributes as epoch seconds
+		f, _ := r.Float64()
+		sec, frac := math.Modf(f)
+		return time.Unix(int64(sec), int64(frac*float64(time.Second))).UTC()
+	}
+	f, _ := r.Float64()
+	return f
+}
+
+// copyValue copies the maps, lists and sets of a value, so that the update does not modify the item read
+func copyValue(v interface{}) interface{} {
+	switch v := v.(type) {
+	case map[string]interface{}:
+		m := make(map[string]interface{}, len(v))
+		for k, e := range v {
+			m[k] = copyValue(e)
+		}
+		return m
+	case []interface{}:
+		list := make([]interface{}, len(v))
+		for i, e := range v {
+			list[i] = copyValue(e)
+		}
+		return list
+	case []string:
+		return slices.Clone(v)
+	case []float64:
+		return slices.Clone(v)
+	case [][]byte:
+		return slices.Clone(v)
+	case []byte:
+		return slices.Clone(v)
+	}
+	return v
+}
+
+// uniqueSet removes the duplicate elements of a set
+func uniqueSet(v interface{}) interface{} {
+	switch set := v.(type) {
+	case []string:
+		return union(nil, set, func(a, b string) bool { return a == b })
+	case []float64:
+		return union(nil, set, func(a, b float64) bool { return a == b })
+	case [][]byte:
+		return union(nil, set, bytes.Equal)
+	}
+	return v
+}
+
+// union returns the elements of a followed by the elements of b which are not in a
+func union[T any](a, b []T, eq func(T, T) bool) []T {
+	result := make([]T, 0, len(a)+len(b))
+	for _, x := range append(slices.Clone(a), b...) {
+		if !slices.ContainsFunc(result, func(y T) bool { return eq(x, y) }) {
+			result = append(result, x)
+		}
+	}
+	return result
+}
+
+// difference returns the elements of a which are not in b
+func difference[T any](a, b []T, eq func(T, T) bool) []T {
+	result := make([]T, 0, len(a))
+	for _, x := range a {
+		if !slices.ContainsFunc(b, func(y T) bool { return eq(x, y) }) {
+			result = append(result, x)
+		}
+	}
+	return result
+}
+
+// nonEmpty returns nil for an empty set
+func nonEmpty[T any](set []T) interface{} {
+	if len(set) == 0 {
+		return nil
+	}
+	return set
+}
+
+func ratEqual(a, b *big.Rat) bool {
+	return a.Cmp(b) == 0
+}
+
+// float64Set converts a number set into the type of the number set columns
+func float64Set(set []*big.Rat) []float64 {
+	result := make([]float64, len(set))
+	for i, n := range set {
+		result[i], _ = n.Float64()
+	}
+	return result
+}
@@ -0,0 +1,174 @@
+// Copyright 2020 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package expression
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func testItem() map[string]interface{} {
+	return map[string]interface{}{
+		"id":     int64(1),
+		"name":   "Marc",
+		"price":  *big.NewRat(25, 2),
+		"rate":   1.5,
+		"tags":   []string{"a", "b"},
+		"scores": []float64{1, 2},
+		"list":   []interface{}{"x", "y", "z"},
+		"address": map[string]interface{}{
+			"city":  "Paris",
+			"lines": []interface{}{"1 rue", map[string]interface{}{"zip": 75001.0}},
+		},
+	}
+}
+
+func TestApply(t *testing.T) {
+	values := map[string]interface{}{
+		":one":    1.0,
+		":v":      "v",
+		":l":      []interface{}{"w"},
+		":tags":   []string{"b", "c", "c"},
+		":a":      []string{"a"},
+		":scores": []float64{2, 3},
+	}
+
+	tests := []struct {
+		input string
+		want  map[string]interface{} // the attributes expected after the update, nil for a removed one
+	}{
+		{
+			"SET id = id + :one, price = price - :one, rate = rate + :one",
+			map[string]interface{}{"id": int64(2), "price": *big.NewRat(23, 2), "rate": 2.5},
+		},
+		{
+			"SET n = if_not_exists(n, :one) + :one, id = if_not_exists(id, :one)",
+			map[string]interface{}{"n": 2.0, "id": int64(1)},
+		},
+		{
+			"SET list = list_append(list, :l), other = list_append(:l, list)",
+			map[string]interface{}{
+				"list":  []interface{}{"x", "y", "z", "w"},
+				"other": []interface{}{"w", "x", "y", "z"},
+			},
+		},
+		{
+			"SET name = rate, rate = name",
+			map[string]interface{}{"name": 1.5, "rate": "Marc"},
+		},
+		{
+			"SET address.city = :v, address.lines[1].zip = :one, address.lines[7] = :v, tags = :tags",
+			map[string]interface{}{
+				"address": map[string]interface{}{
+					"city":  "v",
+					"lines": []interface{}{"1 rue", map[string]interface{}{"zip": 1.0}, "v"},
+				},
+				"tags": []string{"b", "c"},
+			},
+		},
+		{
+			"REMOVE list[0], list[2], list[5], address.lines[1].zip, name, missing",
+			map[string]interface{}{
+				"list": []interface{}{"y"},
+				"address": map[string]interface{}{
+					"city":  "Paris",
+					"lines": []interface{}{"1 rue", map[string]interface{}{}},
+				},
+				"name": nil,
+			},
+		},
+		{
+			"ADD id :one, price :one, n :one, tags :tags, scores :scores, newTags :tags",
+			map[string]interface{}{
+				"id":      int64(2),
+				"price":   *big.NewRat(27, 2),
+				"n":       1.0,
+				"tags":    []string{"a", "b", "c"},
+				"scores":  []float64{1, 2, 3},
+				"newTags": []string{"b", "c"},
+			},
+		},
+		{
+			"DELETE tags :tags, scores :scores, missing :a",
+			map[string]interface{}{"tags": []string{"a"}, "scores": []float64{1}, "missing": nil},
+		},
+		{
+			"DELETE tags :a SET name = :v",
+			map[string]interface{}{"tags": []string{"b"}, "name": "v"},
+		},
+	}
+
+	for _, tc := range tests {
+		u, err := ParseUpdate(tc.input, nil, values)
+		if !assert.NoError(t, err, tc.input) {
+			continue
+		}
+		item := testItem()
+		got, err := u.Apply(item)
+		if !assert.NoError(t, err, tc.input) {
+			continue
+		}
+		for attr, want := range tc.want {
+			v, ok := got[attr]
+			if want == nil {
+				assert.False(t, ok, "%s: %s", tc.input, attr)
+				continue
+			}
+			assert.Equal(t, want, v, "%s: %s", tc.input, attr)
+		}
+		assert.Equal(t, testItem(), item, tc.input)
+	}
+}
+
+func TestApplyRemovesEmptySets(t *testing.T) {
+	values := map[string]interface{}{":tags": []string{"b", "a"}}
+	u, err := ParseUpdate("DELETE tags :tags", nil, values)
+	assert.NoError(t, err)
+	got, err := u.Apply(testItem())
+	assert.NoError(t, err)
+	assert.NotContains(t, got, "tags")
+}
+
+func TestApplyErrors(t *testing.T) {
+	values := map[string]interface{}{":one": 1.0, ":v": "v", ":l": []interface{}{"w"}, ":a": []string{"a"}}
+
+	tests := []struct {
+		input string
+		want  error
+	}{
+		{"SET id = missing + :one", errMissingAttribute},
+		{"SET id = missing", errMissingAttribute},
+		{"SET id = name + :one", errIncorrectType},
+		{"SET list = list_append(name, :l)", errIncorrectType},
+		{"SET missing.city = :v", errInvalidPath},
+		{"SET name.city = :v", errInvalidPath},
+		{"SET address.lines[5].zip = :one", errInvalidPath},
+		{"REMOVE name[0]", errInvalidPath},
+		{"ADD name :one", errIncorrectType},
+		{"ADD tags :one", errIncorrectType},
+		{"DELETE scores :a", errIncorrectType},
+	}
+
+	for _, tc := range tests {
+		u, err := ParseUpdate(tc.input, nil, values)
+		if !assert.NoError(t, err, tc.input) {
+			continue
+		}
+		_, err = u.Apply(testItem())
+		assert.Equal(t, tc.want, err, tc.input)
+	}
+}
@@ -0,0 +1,243 @@
+// Copyright 2020 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package expression
+
+import (
+	"strconv"
+	"strings"
+)
+
+// Condition is a node of a condition or filter expression which evaluates to a boolean
+type Condition interface {
+	condition()
+}
+
+// Operand is a node of an expression which evaluates to an attribute value
+type Operand interface {
+	operand()
+}
+
+// PathElement is an element of a document path, either an attribute name or a list index
+type PathElement struct {
+	Name  string
+	Index int
+}
+
+// IsIndex reports whether the element is a list index
+func (e PathElement) IsIndex() bool {
+	return e.Name == ""
+}
+
+// Path is a document path like a.b[3].c, the ExpressionAttributeNames are already resolved
+type Path struct {
+	Elements []PathElement
+}
+
+// Attribute returns the top-level attribute of the path
+func (p *Path) Attribute() string {
+	return p.Elements[0].Name
+}
+
+// String returns the path in the expression syntax
+func (p *Path) String() string {
+	var sb strings.Builder
+	for i, e := range p.Elements {
+		switch {
+		case e.IsIndex():
+			sb.WriteString("[" + strconv.Itoa(e.Index) + "]")
+		case i > 0:
+			sb.WriteString("." + e.Name)
+		default:
+			sb.WriteString(e.Name)
+		}
+	}
+	return sb.String()
+}
+
+// Value is a placeholder of ExpressionAttributeValues, like :v
+type Value struct {
+	Name string
+}
+
+// Size is the size(path) function
+type Size struct {
+	Path *Path
+}
+
+// Comparison compares two operands with one of = <> < <= > >=
+type Comparison struct {
+	Op          string
+	Left, Right Operand
+}
+
+// Between is operand BETWEEN lower AND upper
+type Between struct {
+	Operand      Operand
+	Lower, Upper Operand
+}
+
+// In is operand IN (list...)
+type In struct {
+	Operand Operand
+	List    []Operand
+}
+
+// Function is one of the functions evaluating to a boolean, like attribute_exists(path)
+type Function struct {
+	Name string
+	Args []Operand
+}
+
+// And is left AND right
+type And struct {
+	Left, Right Condition
+}
+
+// Or is left OR right
+type Or struct {
+	Left, Right Condition
+}
+
+// Not is NOT condition
+type Not struct {
+	Cond Condition
+}
+
+// Arithmetic is left + right or left - right in the value of a SET action
+type Arithmetic struct {
+	Op          string
+	Left, Right Operand
+}
+
+// IfNotExists is the if_not_exists(path, value) function of the SET actions
+type IfNotExists struct {
+	Path  *Path
+	Value Operand
+}
+
+// ListAppend is the list_append(list1, list2) function of the SET actions
+type ListAppend struct {
+	Left, Right Operand
+}
+
+func (*Path) operand()        {}
+func (*Value) operand()       {}
+func (*Size) operand()        {}
+func (*Arithmetic) operand()  {}
+func (*IfNotExists) operand() {}
+func (*ListAppend) operand()  {}
+
+func (*Comparison) condition() {}
+func (*Between) condition()    {}
+func (*In) condition()         {}
+func (*Function) condition()   {}
+func (*And) condition()        {}
+func (*Or) condition()         {}
+func (*Not) condition()        {}
+
+// UpdateAction is an action of an update expression
+type UpdateAction interface {
+	updateAction()
+}
+
+// SetAction is path = value in the SET clause
+type SetAction struct {
+	Path  *Path
+	Value Operand
+}
+
+// RemoveAction is a path of the REMOVE clause
+type RemoveAction struct {
+	Path *Path
+}
+
+// AddAction is path value in the ADD clause, it adds the value to a number or its elements to a set
+type AddAction struct {
+	Path  *Path
+	Value *Value
+}
+
+// DeleteAction is path value in the DELETE clause, it removes the elements of the value from a set
+type DeleteAction struct {
+	Path  *Path
+	Value *Value
+}
+
+func (*SetAction) updateAction()    {}
+func (*RemoveAction) updateAction() {}
+func (*AddAction) updateAction()    {}
+func (*DeleteAction) updateAction() {}
+
+// actionPath returns the path updated by the action
+func actionPath(action UpdateAction) *Path {
+	switch a := action.(type) {
+	case *SetAction:
+		return a.Path
+	case *RemoveAction:
+		return a.Path
+	case *AddAction:
+		return a.Path
+	case *DeleteAction:
+		return a.Path
+	}
+	return nil
+}
+
+// Conjuncts splits the condition into the conditions joined by its top-level ANDs
+func Conjuncts(cond Condition) []Condition {
+	if c, ok := cond.(*And); ok {
+		return append(Conjuncts(c.Left), Conjuncts(c.Right)...)
+	}
+	return []Condition{cond}
+}
+
+// Paths returns the document paths used by the condition in the order they appear
+func Paths(cond Condition) []*Path {
+	var paths []*Path
+	addOperands := func(operands ...Operand) {
+		for _, o := range operands {
+			switch o := o.(type) {
+			case *Path:
+				paths = append(paths, o)
+			case *Size:
+				paths = append(paths, o.Path)
+			}
+		}
+	}
+	var walk func(Condition)
+	walk = func(cond Condition) {
+		switch c := cond.(type) {
+		case *Comparison:
+			addOperands(c.Left, c.Right)
+		case *Between:
+			addOperands(c.Operand, c.Lower, c.Upper)
+		case *In:
+			addOperands(c.Operand)
+			addOperands(c.List...)
+		case *Function:
+			addOperands(c.Args...)
+		case *And:
+			walk(c.Left)
+			walk(c.Right)
+		case *Or:
+			walk(c.Left)
+			walk(c.Right)
+		case *Not:
+			walk(c.Cond)
+		}
+	}
+	walk(cond)
+	return paths
+}
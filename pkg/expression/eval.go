@@ -0,0 +1,386 @@
+// Copyright 2020 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package expression
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"math/big"
+	"reflect"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Evaluate evaluates the condition against the item. Maps and lists of the item are plain Go maps
+// and slices, attributes holding nil are treated as missing: a NULL attribute is read from Spanner as
+// a NULL column, which can not be told apart from a column the item does not have.
+func (e *Expression) Evaluate(item map[string]interface{}) (bool, error) {
+	ev := evaluator{item: item, values: e.values}
+	return ev.condition(e.Cond)
+}
+
+type evaluator struct {
+	item   map[string]interface{}
+	values map[string]interface{}
+}
+
+func (ev evaluator) condition(cond Condition) (bool, error) {
+	switch c := cond.(type) {
+	case *And:
+		ok, err := ev.condition(c.Left)
+		if err != nil || !ok {
+			return false, err
+		}
+		return ev.condition(c.Right)
+	case *Or:
+		ok, err := ev.condition(c.Left)
+		if err != nil || ok {
+			return ok, err
+		}
+		return ev.condition(c.Right)
+	case *Not:
+		ok, err := ev.condition(c.Cond)
+		return !ok, err
+	case *Comparison:
+		left, lok := ev.operand(c.Left)
+		right, rok := ev.operand(c.Right)
+		if !lok || !rok {
+			// a missing attribute is different from every value
+			return c.Op == "<>", nil
+		}
+		switch c.Op {
+		case "=":
+			return equal(left, right), nil
+		case "<>":
+			return !equal(left, right), nil
+		}
+		cmp, ok := compare(left, right)
+		if !ok {
+			return false, nil
+		}
+		switch c.Op {
+		case "<":
+			return cmp < 0, nil
+		case "<=":
+			return cmp <= 0, nil
+		case ">":
+			return cmp > 0, nil
+		}
+		return cmp >= 0, nil
+	case *Between:
+		return ev.between(c)
+	case *In:
+		v, ok := ev.operand(c.Operand)
+		if !ok {
+			return false, nil
+		}
+		for _, o := range c.List {
+			if item, ok := ev.operand(o); ok && equal(v, item) {
+				return true, nil
+			}
+		}
+		return false, nil
+	case *Function:
+		return ev.function(c), nil
+	}
+	return false, fmt.Errorf("unsupported condition %T", cond)
+}
+
+// between checks lower <= operand <= upper, the bounds given as values are validated by the parser
+func (ev evaluator) between(c *Between) (bool, error) {
+	v, ok := ev.operand(c.Operand)
+	lower, lok := ev.operand(c.Lower)
+	upper, uok := ev.operand(c.Upper)
+	if !ok || !lok || !uok {
+		return false, nil
+	}
+	low, lowOk := compare(lower, v)
+	high, highOk := compare(v, upper)
+	return lowOk && highOk && low <= 0 && high <= 0, nil
+}
+
+func (ev evaluator) function(f *Function) bool {
+	v, ok := ev.operand(f.Args[0])
+	switch f.Name {
+	case "attribute_exists":
+		return ok
+	case "attribute_not_exists":
+		return !ok
+	}
+	arg, argOk := ev.operand(f.Args[1])
+	if !ok || !argOk {
+		return false
+	}
+	switch f.Name {
+	case "attribute_type":
+		t, isString := arg.(string)
+		return isString && typeOf(v) == t
+	case "begins_with":
+		switch v := v.(type) {
+		case string:
+			prefix, isString := arg.(string)
+			return isString && strings.HasPrefix(v, prefix)
+		case []byte:
+			prefix, isBytes := arg.([]byte)
+			return isBytes && bytes.HasPrefix(v, prefix)
+		}
+	case "contains":
+		return contains(v, arg)
+	}
+	return false
+}
+
+// operand returns the value of the operand, false when the attribute does not exist
+func (ev evaluator) operand(o Operand) (interface{}, bool) {
+	switch o := o.(type) {
+	case *Value:
+		v, ok := ev.values[o.Name]
+		return v, ok
+	case *Path:
+		return Resolve(ev.item, o)
+	case *Size:
+		v, ok := Resolve(ev.item, o.Path)
+		if !ok {
+			return nil, false
+		}
+		return size(v)
+	}
+	return nil, false
+}
+
+// Resolve returns the value at the path in the item, false when the path does not exist or holds nil,
+// so that NULL attributes are missing attributes as in the filters translated to SQL
+func Resolve(item map[string]interface{}, path *Path) (interface{}, bool) {
+	var v interface{} = item
+	for _, e := range path.Elements {
+		if e.IsIndex() {
+			list, ok := v.([]interface{})
+			if !ok || e.Index >= len(list) {
+				return nil, false
+			}
+			v = list[e.Index]
+			continue
+		}
+		m, ok := v.(map[string]interface{})
+		if !ok {
+			return nil, false
+		}
+		v = m[e.Name]
+	}
+	return v, v != nil
+}
+
+// size returns the size of a string, binary, set, list or map attribute
+func size(v interface{}) (interface{}, bool) {
+	switch v := v.(type) {
+	case string:
+		return int64(len(v)), true
+	case []byte:
+		return int64(len(v)), true
+	}
+	switch rv := reflect.ValueOf(v); rv.Kind() {
+	case reflect.Slice, reflect.Map:
+		return int64(rv.Len()), true
+	}
+	return nil, false
+}
+
+// typeOf returns the DynamoDB data type of a value
+func typeOf(v interface{}) string {
+	switch v.(type) {
+	case nil:
+		return "NULL"
+	case string:
+		return "S"
+	case []byte:
+		return "B"
+	case bool:
+		return "BOOL"
+	case []string:
+		return "SS"
+	case [][]byte:
+		return "BS"
+	case []interface{}:
+		return "L"
+	case map[string]interface{}:
+		return "M"
+	}
+	if _, ok := toNumber(v); ok {
+		return "N"
+	}
+	if _, ok := toNumberSet(v); ok {
+		return "NS"
+	}
+	return ""
+}
+
+// toNumber converts the numeric types used for the N attributes into an exact rational number
+func toNumber(v interface{}) (*big.Rat, bool) {
+	switch n := v.(type) {
+	case float64:
+		// SetFloat64 returns nil for NaN and infinities
+		r := new(big.Rat).SetFloat64(n)
+		return r, r != nil
+	case float32:
+		r := new(big.Rat).SetFloat64(float64(n))
+		return r, r != nil
+	case int:
+		return new(big.Rat).SetInt64(int64(n)), true
+	case int32:
+		return new(big.Rat).SetInt64(int64(n)), true
+	case int64:
+		return new(big.Rat).SetInt64(n), true
+	case big.Rat:
+		return &n, true
+	case *big.Rat:
+		return n, n != nil
+	case json.Number:
+		return new(big.Rat).SetString(string(n))
+	case time.Time:
+		// TIMESTAMP columns hold N attributes as epoch seconds
+		return new(big.Rat).SetFrac64(n.UnixNano(), int64(time.Second)), true
+	}
+	return nil, false
+}
+
+// toNumberSet converts a number set into rational numbers
+func toNumberSet(v interface{}) ([]*big.Rat, bool) {
+	rv := reflect.ValueOf(v)
+	if rv.Kind() != reflect.Slice {
+		return nil, false
+	}
+	switch rv.Type().Elem().Kind() {
+	case reflect.Float32, reflect.Float64, reflect.Int, reflect.Int32, reflect.Int64:
+	default:
+		return nil, false
+	}
+	set := make([]*big.Rat, rv.Len())
+	for i := range set {
+		n, ok := toNumber(rv.Index(i).Interface())
+		if !ok {
+			return nil, false
+		}
+		set[i] = n
+	}
+	return set, true
+}
+
+// compare orders two numbers, strings or binaries, false when they can not be compared
+func compare(a, b interface{}) (int, bool) {
+	if x, ok := toNumber(a); ok {
+		y, ok := toNumber(b)
+		if !ok {
+			return 0, false
+		}
+		return x.Cmp(y), true
+	}
+	switch x := a.(type) {
+	case string:
+		y, ok := b.(string)
+		return strings.Compare(x, y), ok
+	case []byte:
+		y, ok := b.([]byte)
+		return bytes.Compare(x, y), ok
+	}
+	return 0, false
+}
+
+// equal compares two values of the same type, sets are equal regardless of the order of their elements
+func equal(a, b interface{}) bool {
+	if a == nil || b == nil {
+		return a == nil && b == nil
+	}
+	if typeOf(a) != typeOf(b) {
+		return false
+	}
+	switch x := a.(type) {
+	case bool:
+		return x == b.(bool)
+	case []string:
+		return sameSet(x, b.([]string), func(s, t string) bool { return s == t })
+	case [][]byte:
+		return sameSet(x, b.([][]byte), bytes.Equal)
+	case []interface{}:
+		y := b.([]interface{})
+		return slices.EqualFunc(x, y, equal)
+	case map[string]interface{}:
+		y := b.(map[string]interface{})
+		if len(x) != len(y) {
+			return false
+		}
+		for k, v := range x {
+			if w, ok := y[k]; !ok || !equal(v, w) {
+				return false
+			}
+		}
+		return true
+	}
+	if x, ok := toNumberSet(a); ok {
+		y, _ := toNumberSet(b)
+		return sameSet(x, y, func(r, s *big.Rat) bool { return r.Cmp(s) == 0 })
+	}
+	cmp, ok := compare(a, b)
+	return ok && cmp == 0
+}
+
+// sameSet reports whether the sets hold the same elements
+func sameSet[T any](a, b []T, eq func(T, T) bool) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for _, x := range a {
+		if !slices.ContainsFunc(b, func(y T) bool { return eq(x, y) }) {
+			return false
+		}
+	}
+	return true
+}
+
+// contains checks whether a string contains a substring, or a set or a list contains an element
+func contains(v, arg interface{}) bool {
+	switch v := v.(type) {
+	case string:
+		s, ok := arg.(string)
+		return ok && strings.Contains(v, s)
+	case []byte:
+		b, ok := arg.([]byte)
+		return ok && bytes.Contains(v, b)
+	case []string:
+		return slices.ContainsFunc(v, func(s string) bool { return equal(s, arg) })
+	case [][]byte:
+		return slices.ContainsFunc(v, func(b []byte) bool { return equal(b, arg) })
+	case []interface{}:
+		return slices.ContainsFunc(v, func(item interface{}) bool { return equal(item, arg) })
+	}
+	if set, ok := toNumberSet(v); ok {
+		return slices.ContainsFunc(set, func(n *big.Rat) bool { return equal(n, arg) })
+	}
+	return false
+}
+
+// describe formats a value as in the DynamoDB error messages, e.g. {N:10}
+func describe(v interface{}) string {
+	if n, ok := toNumber(v); ok {
+		if n.IsInt() {
+			return "{N:" + n.RatString() + "}"
+		}
+		f, _ := n.Float64()
+		return "{N:" + strconv.FormatFloat(f, 'f', -1, 64) + "}"
+	}
+	return fmt.Sprintf("{%s:%v}", typeOf(v), v)
+}
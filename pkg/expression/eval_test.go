@@ -0,0 +1,107 @@
+// Copyright 2020 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package expression
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestEvaluate(t *testing.T) {
+	item := map[string]interface{}{
+		"id":      int64(1),
+		"name":    "Marc",
+		"price":   *big.NewRat(25, 2),
+		"tags":    []string{"a", "b"},
+		"scores":  []float64{1, 2},
+		"data":    []byte("abc"),
+		"active":  true,
+		"deleted": nil,
+		"address": map[string]interface{}{
+			"city":  "Paris",
+			"lines": []interface{}{"1 rue", map[string]interface{}{"zip": 75001.0}},
+		},
+	}
+	values := map[string]interface{}{
+		":one":    1.0,
+		":two":    int64(2),
+		":price":  12.5,
+		":marc":   "Marc",
+		":ma":     "Ma",
+		":tags":   []string{"b", "a"},
+		":a":      "a",
+		":ab":     []byte("ab"),
+		":zip":    75001.0,
+		":paris":  "Paris",
+		":rue":    "1 rue",
+		":S":      "S",
+		":M":      "M",
+		":NS":     "NS",
+		":twenty": 20.0,
+		":bytes":  []byte("bc"),
+		":BOOL":   "BOOL",
+		":L":      "L",
+		":NULL":   "NULL",
+	}
+
+	tests := []struct {
+		input string
+		want  bool
+	}{
+		{"id = :one", true},
+		{"id <> :one", false},
+		{"id < :two AND price = :price", true},
+		{"price BETWEEN :one AND :twenty", true},
+		{"price BETWEEN :twenty AND :twenty", false},
+		{"name IN (:ma, :marc)", true},
+		{"name > :ma AND name >= :marc AND NOT name < :ma", true},
+		{"name = :one", false},
+		{"tags = :tags", true},
+		{"missing = :one", false},
+		{"missing <> :one", true},
+		{"attribute_exists(name) AND attribute_not_exists(missing)", true},
+		// a NULL attribute can not be told apart from a missing one
+		{"attribute_exists(deleted)", false},
+		{"attribute_type(deleted, :NULL)", false},
+		{"attribute_exists(address.lines[1].zip) AND address.lines[1].zip = :zip", true},
+		{"attribute_exists(address.lines[2])", false},
+		{"address.city = :paris AND address.lines[0] = :rue", true},
+		{"begins_with(name, :ma) AND begins_with(data, :ab)", true},
+		{"begins_with(id, :ma)", false},
+		{"contains(tags, :a) AND contains(scores, :two) AND contains(address.lines, :rue)", true},
+		{"contains(name, :a) AND contains(data, :bytes)", true},
+		{"contains(tags, :ab) OR contains(scores, :a) OR contains(name, :one)", false},
+		{"size(name) = :two OR size(tags) = :two", true},
+		{"size(address) = :two AND size(id) = :two", false},
+		{"size(data) > :two AND size(address.lines) = :two", true},
+		{"size(active) = :one OR size(missing) = :one", false},
+		{"attribute_type(name, :S) AND attribute_type(address, :M) AND attribute_type(scores, :NS)", true},
+		{"attribute_type(active, :BOOL) AND attribute_type(address.lines, :L)", true},
+		{"attribute_type(id, :S) OR attribute_type(missing, :S)", false},
+		{"id = :two OR (active = active AND NOT missing = :one)", true},
+	}
+
+	for _, tc := range tests {
+		e, err := ParseCondition("ConditionExpression", tc.input, nil, values)
+		if !assert.NoError(t, err, tc.input) {
+			continue
+		}
+		got, err := e.Evaluate(item)
+		assert.NoError(t, err, tc.input)
+		assert.Equal(t, tc.want, got, tc.input)
+	}
+}
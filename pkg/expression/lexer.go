@@ -0,0 +1,119 @@
+// Copyright 2020 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package expression implements the DynamoDB expression grammar: it parses condition, filter and
+// update expressions into an AST, evaluates the conditions against the items read from Spanner and
+// applies the updates to them.
+package expression
+
+import (
+	"unicode"
+	"unicode/utf8"
+)
+
+type tokenKind int
+
+const (
+	tokEOF tokenKind = iota
+	tokIllegal
+	tokIdent    // attribute name, keyword, function name or list index
+	tokName     // #name placeholder of ExpressionAttributeNames
+	tokValue    // :value placeholder of ExpressionAttributeValues
+	tokLParen   // (
+	tokRParen   // )
+	tokComma    // ,
+	tokDot      // .
+	tokLBracket // [
+	tokRBracket // ]
+	tokEq       // =
+	tokNe       // <>
+	tokLt       // <
+	tokLe       // <=
+	tokGt       // >
+	tokGe       // >=
+	tokPlus     // +
+	tokMinus    // -
+)
+
+// token is a lexical token of an expression, pos is its byte offset in the expression
+type token struct {
+	kind tokenKind
+	text string
+	pos  int
+}
+
+var punctuation = map[string]tokenKind{
+	"(": tokLParen, ")": tokRParen, ",": tokComma, ".": tokDot, "[": tokLBracket, "]": tokRBracket,
+	"=": tokEq, "<>": tokNe, "<": tokLt, "<=": tokLe, ">": tokGt, ">=": tokGe, "+": tokPlus, "-": tokMinus,
+}
+
+// lex splits the expression into tokens, the last token is always tokEOF. Characters which
+// can not start a token are returned as tokIllegal and reported by the parser.
+func lex(input string) []token {
+	var tokens []token
+	for i := 0; i < len(input); {
+		r, size := utf8.DecodeRuneInString(input[i:])
+		switch {
+		case unicode.IsSpace(r):
+			i += size
+		case r == '#' || r == ':':
+			end := scanIdent(input, i+1)
+			kind := tokName
+			if r == ':' {
+				kind = tokValue
+			}
+			if end == i+1 {
+				kind = tokIllegal
+			}
+			tokens = append(tokens, token{kind: kind, text: input[i:end], pos: i})
+			i = end
+		case isIdentRune(r):
+			end := scanIdent(input, i)
+			tokens = append(tokens, token{kind: tokIdent, text: input[i:end], pos: i})
+			i = end
+		default:
+			if i+2 <= len(input) {
+				if kind, ok := punctuation[input[i:i+2]]; ok {
+					tokens = append(tokens, token{kind: kind, text: input[i : i+2], pos: i})
+					i += 2
+					continue
+				}
+			}
+			kind, ok := punctuation[input[i:i+size]]
+			if !ok {
+				kind = tokIllegal
+			}
+			tokens = append(tokens, token{kind: kind, text: input[i : i+size], pos: i})
+			i += size
+		}
+	}
+	return append(tokens, token{kind: tokEOF, text: "<EOF>", pos: len(input)})
+}
+
+// scanIdent returns the end of the identifier starting at start
+func scanIdent(input string, start int) int {
+	i := start
+	for i < len(input) {
+		r, size := utf8.DecodeRuneInString(input[i:])
+		if !isIdentRune(r) {
+			break
+		}
+		i += size
+	}
+	return i
+}
+
+func isIdentRune(r rune) bool {
+	return r == '_' || unicode.IsLetter(r) || unicode.IsDigit(r)
+}
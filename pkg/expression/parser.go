@@ -0,0 +1,482 @@
+// Copyright 2020 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package expression
+
+import (
+	"fmt"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+// maxInOperands is the maximum number of operands on the right side of IN
+const maxInOperands = 100
+
+// functions are the functions of the condition and filter expressions with their number of
+// operands, size is the only one which evaluates to an operand instead of a boolean
+var functions = map[string]int{
+	"attribute_exists":     1,
+	"attribute_not_exists": 1,
+	"attribute_type":       2,
+	"begins_with":          2,
+	"contains":             2,
+	"size":                 1,
+}
+
+// pathFunctions are the functions whose first operand has to be a document path
+var pathFunctions = map[string]bool{
+	"attribute_exists":     true,
+	"attribute_not_exists": true,
+	"attribute_type":       true,
+	"size":                 true,
+}
+
+var (
+	// orderedTypes are the types which can be compared with < <= > >= and BETWEEN
+	orderedTypes = []string{"N", "S", "B"}
+	// attributeTypes are the type names accepted by attribute_type
+	attributeTypes = []string{"B", "NULL", "SS", "BOOL", "L", "BS", "N", "NS", "S", "M"}
+	// functionOperandTypes are the types of the value operands accepted by the functions
+	functionOperandTypes = map[string][][]string{
+		"attribute_type": {nil, {"S"}},
+		"begins_with":    {{"S", "B"}, {"S", "B"}},
+		"contains":       {{"S", "B", "SS", "NS", "BS", "L"}, nil},
+	}
+)
+
+var keywords = map[string]bool{"AND": true, "OR": true, "NOT": true, "BETWEEN": true, "IN": true}
+
+var comparators = map[tokenKind]bool{tokEq: true, tokNe: true, tokLt: true, tokLe: true, tokGt: true, tokGe: true}
+
+// Error is a validation error of an expression, Msg follows the message of the DynamoDB ValidationException
+type Error struct {
+	Kind string // name of the request parameter holding the expression, e.g. ConditionExpression
+	Pos  int    // byte offset of the error in the expression
+	Msg  string
+}
+
+func (e *Error) Error() string {
+	return "Invalid " + e.Kind + ": " + e.Msg
+}
+
+// Expression is a parsed condition or filter expression
+type Expression struct {
+	Kind   string
+	Cond   Condition
+	values map[string]interface{}
+}
+
+// Value returns the ExpressionAttributeValue of a value placeholder
+func (e *Expression) Value(v *Value) interface{} {
+	return e.values[v.Name]
+}
+
+// Restrict returns the expression of a part of the condition, nil when there is no condition left
+func (e *Expression) Restrict(cond Condition) *Expression {
+	if cond == nil {
+		return nil
+	}
+	return &Expression{Kind: e.Kind, Cond: cond, values: e.values}
+}
+
+// ParseCondition parses a ConditionExpression or FilterExpression, kind is the name of the request
+// parameter used in the error messages. names and values are the ExpressionAttributeNames and
+// ExpressionAttributeValues of the request, every placeholder of the expression has to be defined.
+// The types of the values are checked against the operators and functions they are used with.
+func ParseCondition(kind, input string, names map[string]string, values map[string]interface{}) (*Expression, error) {
+	if strings.TrimSpace(input) == "" {
+		return nil, &Error{Kind: kind, Msg: "The expression can not be empty;"}
+	}
+	p := &parser{kind: kind, input: input, tokens: lex(input), names: names, values: values, wrapped: map[Condition]bool{}}
+	cond, err := p.parseOr()
+	if err != nil {
+		return nil, err
+	}
+	if p.peek().kind != tokEOF {
+		return nil, p.syntaxError()
+	}
+	return &Expression{Kind: kind, Cond: cond, values: values}, nil
+}
+
+type parser struct {
+	kind   string
+	input  string
+	tokens []token
+	pos    int
+	names  map[string]string
+	values map[string]interface{}
+	// wrapped holds the conditions already enclosed in parentheses
+	wrapped map[Condition]bool
+}
+
+func (p *parser) peek() token {
+	return p.tokens[p.pos]
+}
+
+func (p *parser) next() token {
+	tok := p.tokens[p.pos]
+	if tok.kind != tokEOF {
+		p.pos++
+	}
+	return tok
+}
+
+// keyword reports whether the current token is the keyword, keywords are case insensitive
+func (p *parser) keyword(kw string) bool {
+	tok := p.peek()
+	return tok.kind == tokIdent && strings.EqualFold(tok.text, kw)
+}
+
+func (p *parser) errorAt(pos int, format string, args ...interface{}) *Error {
+	return &Error{Kind: p.kind, Pos: pos, Msg: fmt.Sprintf(format, args...)}
+}
+
+// syntaxError reports the current token, near is the text from the previous token to the current one
+func (p *parser) syntaxError() *Error {
+	tok := p.peek()
+	start, end := tok.pos, tok.pos+len(tok.text)
+	if tok.kind == tokEOF {
+		end = len(p.input)
+	}
+	if p.pos > 0 {
+		start = p.tokens[p.pos-1].pos
+	}
+	return p.errorAt(tok.pos, "Syntax error; token: %q, near: %q", tok.text, p.input[start:end])
+}
+
+func (p *parser) expect(kind tokenKind) error {
+	if p.peek().kind != kind {
+		return p.syntaxError()
+	}
+	p.next()
+	return nil
+}
+
+// parseOr parses condition OR condition, OR has the lowest precedence
+func (p *parser) parseOr() (Condition, error) {
+	left, err := p.parseAnd()
+	if err != nil {
+		return nil, err
+	}
+	for p.keyword("OR") {
+		p.next()
+		right, err := p.parseAnd()
+		if err != nil {
+			return nil, err
+		}
+		left = &Or{Left: left, Right: right}
+	}
+	return left, nil
+}
+
+// parseAnd parses condition AND condition
+func (p *parser) parseAnd() (Condition, error) {
+	left, err := p.parseNot()
+	if err != nil {
+		return nil, err
+	}
+	for p.keyword("AND") {
+		p.next()
+		right, err := p.parseNot()
+		if err != nil {
+			return nil, err
+		}
+		left = &And{Left: left, Right: right}
+	}
+	return left, nil
+}
+
+// parseNot parses NOT condition
+func (p *parser) parseNot() (Condition, error) {
+	if !p.keyword("NOT") {
+		return p.parsePrimary()
+	}
+	p.next()
+	cond, err := p.parseNot()
+	if err != nil {
+		return nil, err
+	}
+	return &Not{Cond: cond}, nil
+}
+
+// parsePrimary parses a parenthesized condition, a function or a comparison
+func (p *parser) parsePrimary() (Condition, error) {
+	tok := p.peek()
+	if tok.kind == tokLParen {
+		p.next()
+		cond, err := p.parseOr()
+		if err != nil {
+			return nil, err
+		}
+		if err := p.expect(tokRParen); err != nil {
+			return nil, err
+		}
+		if p.wrapped[cond] {
+			return nil, p.errorAt(tok.pos, "The expression has redundant parentheses;")
+		}
+		p.wrapped[cond] = true
+		return cond, nil
+	}
+
+	var left Operand
+	if p.isFunctionCall() {
+		size, cond, err := p.parseFunction()
+		if err != nil || cond != nil {
+			return cond, err
+		}
+		left = size
+	} else {
+		var err error
+		if left, err = p.parseOperand(); err != nil {
+			return nil, err
+		}
+	}
+	return p.parseComparison(left)
+}
+
+// parseComparison parses the comparator, BETWEEN or IN following the left operand
+func (p *parser) parseComparison(left Operand) (Condition, error) {
+	tok := p.peek()
+	switch {
+	case comparators[tok.kind]:
+		p.next()
+		right, err := p.parseOperand()
+		if err != nil {
+			return nil, err
+		}
+		if tok.kind != tokEq && tok.kind != tokNe {
+			if err := p.checkOperandTypes(tok.pos, tok.text, orderedTypes, left, right); err != nil {
+				return nil, err
+			}
+		}
+		return &Comparison{Op: tok.text, Left: left, Right: right}, nil
+	case p.keyword("BETWEEN"):
+		p.next()
+		lower, err := p.parseOperand()
+		if err != nil {
+			return nil, err
+		}
+		if !p.keyword("AND") {
+			return nil, p.syntaxError()
+		}
+		p.next()
+		upper, err := p.parseOperand()
+		if err != nil {
+			return nil, err
+		}
+		if err := p.checkBetween(tok.pos, left, lower, upper); err != nil {
+			return nil, err
+		}
+		return &Between{Operand: left, Lower: lower, Upper: upper}, nil
+	case p.keyword("IN"):
+		p.next()
+		if err := p.expect(tokLParen); err != nil {
+			return nil, err
+		}
+		list, err := p.parseOperandList()
+		if err != nil {
+			return nil, err
+		}
+		if len(list) > maxInOperands {
+			return nil, p.errorAt(tok.pos, "The IN operator is provided with too many operands; number of operands: %d", len(list))
+		}
+		return &In{Operand: left, List: list}, nil
+	}
+	return nil, p.syntaxError()
+}
+
+// parseOperandList parses operand, operand... up to the closing parenthesis
+func (p *parser) parseOperandList() ([]Operand, error) {
+	var list []Operand
+	for {
+		o, err := p.parseOperand()
+		if err != nil {
+			return nil, err
+		}
+		list = append(list, o)
+		if p.peek().kind != tokComma {
+			break
+		}
+		p.next()
+	}
+	if err := p.expect(tokRParen); err != nil {
+		return nil, err
+	}
+	return list, nil
+}
+
+func (p *parser) isFunctionCall() bool {
+	tok := p.peek()
+	return tok.kind == tokIdent && !keywords[strings.ToUpper(tok.text)] && p.tokens[p.pos+1].kind == tokLParen
+}
+
+// parseFunction parses a function call, it returns the operand for size and the condition otherwise
+func (p *parser) parseFunction() (Operand, Condition, error) {
+	name := p.next()
+	p.next()
+	arity, ok := functions[name.text]
+	if !ok {
+		return nil, nil, p.errorAt(name.pos, "Invalid function name; function: %s", name.text)
+	}
+	var args []Operand
+	if p.peek().kind == tokRParen {
+		p.next()
+	} else {
+		var err error
+		if args, err = p.parseOperandList(); err != nil {
+			return nil, nil, err
+		}
+	}
+	if len(args) != arity {
+		return nil, nil, p.errorAt(name.pos, "Incorrect number of operands for operator or function; operator or function: %s, number of operands: %d", name.text, len(args))
+	}
+	if _, isPath := args[0].(*Path); pathFunctions[name.text] && !isPath {
+		return nil, nil, p.errorAt(name.pos, "Operator or function requires a document path; operator or function: %s", name.text)
+	}
+	if name.text == "size" {
+		return &Size{Path: args[0].(*Path)}, nil, nil
+	}
+	for i, allowed := range functionOperandTypes[name.text] {
+		if allowed == nil {
+			continue
+		}
+		if err := p.checkOperandTypes(name.pos, name.text, allowed, args[i]); err != nil {
+			return nil, nil, err
+		}
+	}
+	if v, ok := args[len(args)-1].(*Value); ok && name.text == "attribute_type" {
+		if typeName, _ := p.values[v.Name].(string); !slices.Contains(attributeTypes, typeName) {
+			return nil, nil, p.errorAt(name.pos, "Invalid attribute type name found in type: %s, valid types: {%s}", typeName, strings.Join(attributeTypes, ","))
+		}
+	}
+	return nil, &Function{Name: name.text, Args: args}, nil
+}
+
+// valueType returns the type of a value operand, the type of the other operands is only known when evaluated
+func (p *parser) valueType(o Operand) string {
+	if v, ok := o.(*Value); ok {
+		return typeOf(p.values[v.Name])
+	}
+	return ""
+}
+
+// checkOperandTypes reports the first value operand whose type is not allowed by the operator or function
+func (p *parser) checkOperandTypes(pos int, op string, allowed []string, operands ...Operand) error {
+	for _, o := range operands {
+		if t := p.valueType(o); t != "" && !slices.Contains(allowed, t) {
+			return p.errorAt(pos, "Incorrect operand type for operator or function; operator or function: %s, operand type: %s", op, t)
+		}
+	}
+	return nil
+}
+
+// checkBetween validates the operands of BETWEEN, the bounds given as values have to be ordered
+func (p *parser) checkBetween(pos int, operand, lower, upper Operand) error {
+	if err := p.checkOperandTypes(pos, "BETWEEN", orderedTypes, operand, lower, upper); err != nil {
+		return err
+	}
+	lowerValue, lok := lower.(*Value)
+	upperValue, uok := upper.(*Value)
+	if !lok || !uok {
+		return nil
+	}
+	low, high := p.values[lowerValue.Name], p.values[upperValue.Name]
+	if typeOf(low) != typeOf(high) {
+		return p.errorAt(pos, "The BETWEEN operator requires same data type for lower and upper bounds; lower bound operand: AttributeValue: %s, upper bound operand: AttributeValue: %s",
+			describe(low), describe(high))
+	}
+	if cmp, ok := compare(low, high); ok && cmp > 0 {
+		return p.errorAt(pos, "The BETWEEN operator requires upper bound to be greater than or equal to lower bound; lower bound operand: AttributeValue: %s, upper bound operand: AttributeValue: %s",
+			describe(low), describe(high))
+	}
+	return nil
+}
+
+// parseOperand parses a document path, a value placeholder or the size function
+func (p *parser) parseOperand() (Operand, error) {
+	tok := p.peek()
+	switch {
+	case tok.kind == tokValue:
+		p.next()
+		if _, ok := p.values[tok.text]; !ok {
+			return nil, p.errorAt(tok.pos, "An expression attribute value used in expression is not defined; attribute value: %s", tok.text)
+		}
+		return &Value{Name: tok.text}, nil
+	case p.isFunctionCall():
+		size, _, err := p.parseFunction()
+		if err != nil {
+			return nil, err
+		}
+		if size == nil {
+			return nil, p.errorAt(tok.pos, "The function is not allowed to be used this way in an expression; function: %s", tok.text)
+		}
+		return size, nil
+	case tok.kind == tokName, tok.kind == tokIdent && !keywords[strings.ToUpper(tok.text)]:
+		return p.parsePath()
+	}
+	return nil, p.syntaxError()
+}
+
+// parsePath parses a document path like a.#b[3].c
+func (p *parser) parsePath() (*Path, error) {
+	name, err := p.parsePathName()
+	if err != nil {
+		return nil, err
+	}
+	path := &Path{Elements: []PathElement{{Name: name}}}
+	for {
+		switch p.peek().kind {
+		case tokDot:
+			p.next()
+			name, err := p.parsePathName()
+			if err != nil {
+				return nil, err
+			}
+			path.Elements = append(path.Elements, PathElement{Name: name})
+		case tokLBracket:
+			p.next()
+			tok := p.peek()
+			index, err := strconv.Atoi(tok.text)
+			if tok.kind != tokIdent || err != nil {
+				return nil, p.syntaxError()
+			}
+			p.next()
+			if err := p.expect(tokRBracket); err != nil {
+				return nil, err
+			}
+			path.Elements = append(path.Elements, PathElement{Index: index})
+		default:
+			return path, nil
+		}
+	}
+}
+
+// parsePathName parses an attribute name of a path, resolving the #name placeholders
+func (p *parser) parsePathName() (string, error) {
+	tok := p.peek()
+	switch {
+	case tok.kind == tokName:
+		p.next()
+		name, ok := p.names[tok.text]
+		if !ok {
+			return "", p.errorAt(tok.pos, "An expression attribute name used in the document path is not defined; attribute name: %s", tok.text)
+		}
+		return name, nil
+	case tok.kind == tokIdent && !keywords[strings.ToUpper(tok.text)]:
+		p.next()
+		return tok.text, nil
+	}
+	return "", p.syntaxError()
+}
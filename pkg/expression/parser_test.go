@@ -0,0 +1,220 @@
+// Copyright 2020 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package expression
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func path(names ...interface{}) *Path {
+	p := &Path{}
+	for _, n := range names {
+		switch n := n.(type) {
+		case string:
+			p.Elements = append(p.Elements, PathElement{Name: n})
+		case int:
+			p.Elements = append(p.Elements, PathElement{Index: n})
+		}
+	}
+	return p
+}
+
+func TestLex(t *testing.T) {
+	kinds := func(input string) []tokenKind {
+		var got []tokenKind
+		for _, tok := range lex(input) {
+			got = append(got, tok.kind)
+		}
+		return got
+	}
+	// a dash is never part of an attribute name, so that a-b is a subtraction
+	assert.Equal(t, []tokenKind{tokIdent, tokMinus, tokIdent, tokEOF}, kinds("a-b"))
+	assert.Equal(t, []tokenKind{tokIdent, tokGe, tokValue, tokEOF}, kinds("a>=:v"))
+	assert.Equal(t, []tokenKind{tokName, tokLBracket, tokIdent, tokRBracket, tokDot, tokIdent, tokEOF}, kinds("#n[1].b"))
+	assert.Equal(t, []tokenKind{tokIdent, tokIllegal, tokIllegal, tokEOF}, kinds("a $:"))
+}
+
+func TestParseCondition(t *testing.T) {
+	names := map[string]string{"#n": "name", "#d": "first-name"}
+	values := map[string]interface{}{":v": "x", ":lo": 1.0, ":hi": 5.0, ":t": "S"}
+
+	tests := []struct {
+		testName string
+		input    string
+		want     Condition
+	}{
+		{
+			"comparison",
+			"age >= :lo",
+			&Comparison{Op: ">=", Left: path("age"), Right: &Value{Name: ":lo"}},
+		},
+		{
+			"document path with placeholders",
+			"#n.b[3].#d = :v",
+			&Comparison{Op: "=", Left: path("name", "b", 3, "first-name"), Right: &Value{Name: ":v"}},
+		},
+		{
+			"precedence of NOT, AND and OR",
+			"NOT a = :v AND b = :v OR c = :v",
+			&Or{
+				Left: &And{
+					Left:  &Not{Cond: &Comparison{Op: "=", Left: path("a"), Right: &Value{Name: ":v"}}},
+					Right: &Comparison{Op: "=", Left: path("b"), Right: &Value{Name: ":v"}},
+				},
+				Right: &Comparison{Op: "=", Left: path("c"), Right: &Value{Name: ":v"}},
+			},
+		},
+		{
+			"nested parentheses and lowercase keywords",
+			"a = :v and (b = :v or (c = :v))",
+			&And{
+				Left: &Comparison{Op: "=", Left: path("a"), Right: &Value{Name: ":v"}},
+				Right: &Or{
+					Left:  &Comparison{Op: "=", Left: path("b"), Right: &Value{Name: ":v"}},
+					Right: &Comparison{Op: "=", Left: path("c"), Right: &Value{Name: ":v"}},
+				},
+			},
+		},
+		{
+			"between",
+			"price BETWEEN :lo AND :hi AND attribute_exists(a)",
+			&And{
+				Left:  &Between{Operand: path("price"), Lower: &Value{Name: ":lo"}, Upper: &Value{Name: ":hi"}},
+				Right: &Function{Name: "attribute_exists", Args: []Operand{path("a")}},
+			},
+		},
+		{
+			"in",
+			"a IN (:lo, :hi,b)",
+			&In{Operand: path("a"), List: []Operand{&Value{Name: ":lo"}, &Value{Name: ":hi"}, path("b")}},
+		},
+		{
+			"functions with spaces",
+			"begins_with ( #n , :v ) OR attribute_type(a, :t)",
+			&Or{
+				Left:  &Function{Name: "begins_with", Args: []Operand{path("name"), &Value{Name: ":v"}}},
+				Right: &Function{Name: "attribute_type", Args: []Operand{path("a"), &Value{Name: ":t"}}},
+			},
+		},
+		{
+			"size",
+			"size(a.b) > :lo",
+			&Comparison{Op: ">", Left: &Size{Path: path("a", "b")}, Right: &Value{Name: ":lo"}},
+		},
+	}
+
+	for _, tc := range tests {
+		got, err := ParseCondition("ConditionExpression", tc.input, names, values)
+		assert.NoError(t, err, tc.testName)
+		if assert.NotNil(t, got, tc.testName) {
+			assert.Equal(t, tc.want, got.Cond, tc.testName)
+		}
+	}
+}
+
+func TestParseConditionErrors(t *testing.T) {
+	values := map[string]interface{}{":v": "x"}
+
+	tests := []struct {
+		input string
+		want  string
+		pos   int
+	}{
+		{" ", "Invalid ConditionExpression: The expression can not be empty;", 0},
+		{"a == :v", `Invalid ConditionExpression: Syntax error; token: "=", near: "=="`, 3},
+		{"a = :v AND", `Invalid ConditionExpression: Syntax error; token: "<EOF>", near: "AND"`, 10},
+		{"a = :v $", `Invalid ConditionExpression: Syntax error; token: "$", near: ":v $"`, 7},
+		{"a[x] = :v", `Invalid ConditionExpression: Syntax error; token: "x", near: "[x"`, 2},
+		{"a-b = :v", `Invalid ConditionExpression: Syntax error; token: "-", near: "a-"`, 1},
+		{"a - :v = :v", `Invalid ConditionExpression: Syntax error; token: "-", near: "a -"`, 2},
+		{"#n = :v", "Invalid ConditionExpression: An expression attribute name used in the document path is not defined; attribute name: #n", 0},
+		{"a = :w", "Invalid ConditionExpression: An expression attribute value used in expression is not defined; attribute value: :w", 4},
+		{"exists(a)", "Invalid ConditionExpression: Invalid function name; function: exists", 0},
+		{"begins_with(a)", "Invalid ConditionExpression: Incorrect number of operands for operator or function; operator or function: begins_with, number of operands: 1", 0},
+		{"attribute_exists(:v)", "Invalid ConditionExpression: Operator or function requires a document path; operator or function: attribute_exists", 0},
+		{"size(a)", `Invalid ConditionExpression: Syntax error; token: "<EOF>", near: ")"`, 7},
+		{"a = attribute_exists(b)", "Invalid ConditionExpression: The function is not allowed to be used this way in an expression; function: attribute_exists", 4},
+		{"((a = :v))", "Invalid ConditionExpression: The expression has redundant parentheses;", 0},
+	}
+
+	for _, tc := range tests {
+		_, err := ParseCondition("ConditionExpression", tc.input, nil, values)
+		if assert.Error(t, err, tc.input) {
+			assert.Equal(t, tc.want, err.Error(), tc.input)
+			assert.Equal(t, tc.pos, err.(*Error).Pos, tc.input)
+		}
+	}
+}
+
+func TestParseConditionOperandTypes(t *testing.T) {
+	values := map[string]interface{}{
+		":s":    "x",
+		":n":    10.0,
+		":n5":   int64(5),
+		":bool": true,
+		":ss":   []string{"x"},
+		":l":    []interface{}{"x"},
+		":t":    "STRING",
+	}
+
+	tests := []struct {
+		input string
+		want  string
+		pos   int
+	}{
+		{"a < :bool", "Invalid ConditionExpression: Incorrect operand type for operator or function; operator or function: <, operand type: BOOL", 2},
+		{"size(a) > :l", "Invalid ConditionExpression: Incorrect operand type for operator or function; operator or function: >, operand type: L", 8},
+		{"a BETWEEN :ss AND :s", "Invalid ConditionExpression: Incorrect operand type for operator or function; operator or function: BETWEEN, operand type: SS", 2},
+		{"a BETWEEN :s AND :n", "Invalid ConditionExpression: The BETWEEN operator requires same data type for lower and upper bounds; lower bound operand: AttributeValue: {S:x}, upper bound operand: AttributeValue: {N:10}", 2},
+		{"a BETWEEN :n AND :n5", "Invalid ConditionExpression: The BETWEEN operator requires upper bound to be greater than or equal to lower bound; lower bound operand: AttributeValue: {N:10}, upper bound operand: AttributeValue: {N:5}", 2},
+		{"attribute_type(a, :n)", "Invalid ConditionExpression: Incorrect operand type for operator or function; operator or function: attribute_type, operand type: N", 0},
+		{"attribute_type(a, :t)", "Invalid ConditionExpression: Invalid attribute type name found in type: STRING, valid types: {B,NULL,SS,BOOL,L,BS,N,NS,S,M}", 0},
+		{"begins_with(a, :n)", "Invalid ConditionExpression: Incorrect operand type for operator or function; operator or function: begins_with, operand type: N", 0},
+		{"contains(:bool, a)", "Invalid ConditionExpression: Incorrect operand type for operator or function; operator or function: contains, operand type: BOOL", 0},
+	}
+
+	for _, tc := range tests {
+		_, err := ParseCondition("ConditionExpression", tc.input, nil, values)
+		if assert.Error(t, err, tc.input) {
+			assert.Equal(t, tc.want, err.Error(), tc.input)
+			assert.Equal(t, tc.pos, err.(*Error).Pos, tc.input)
+		}
+	}
+}
+
+func TestParseConditionTooManyInOperands(t *testing.T) {
+	values := map[string]interface{}{":v": "x"}
+	input := "a IN (:v"
+	for i := 0; i < maxInOperands; i++ {
+		input += ", :v"
+	}
+	_, err := ParseCondition("FilterExpression", input+")", nil, values)
+	assert.EqualError(t, err, "Invalid FilterExpression: The IN operator is provided with too many operands; number of operands: 101")
+}
+
+func TestPaths(t *testing.T) {
+	values := map[string]interface{}{":v": "x"}
+	e, err := ParseCondition("ConditionExpression", "attribute_not_exists(a.b) OR (size(c[1]) > :v AND NOT d IN (:v, e))", nil, values)
+	assert.NoError(t, err)
+
+	var got []string
+	for _, p := range Paths(e.Cond) {
+		got = append(got, p.String())
+	}
+	assert.Equal(t, []string{"a.b", "c[1]", "d", "e"}, got)
+	assert.Equal(t, "a", Paths(e.Cond)[0].Attribute())
+}
@@ -0,0 +1,251 @@
+// Copyright 2020 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package expression
+
+import (
+	"slices"
+	"strconv"
+	"strings"
+)
+
+// updateClauses are the clauses of an update expression, each of them can be used once
+var updateClauses = []string{"SET", "REMOVE", "ADD", "DELETE"}
+
+// updateFunctions are the functions of the SET actions with their number of operands
+var updateFunctions = map[string]int{
+	"if_not_exists": 2,
+	"list_append":   2,
+}
+
+var (
+	// addOperandTypes are the types of the values accepted by ADD
+	addOperandTypes = []string{"N", "SS", "NS", "BS"}
+	// deleteOperandTypes are the types of the values accepted by DELETE
+	deleteOperandTypes = []string{"SS", "NS", "BS"}
+)
+
+// Update is a parsed update expression
+type Update struct {
+	Actions []UpdateAction
+	values  map[string]interface{}
+}
+
+// ParseUpdate parses an UpdateExpression, names and values are the ExpressionAttributeNames and
+// ExpressionAttributeValues of the request. As in DynamoDB every clause can be used once, and the
+// paths updated by the actions can not overlap.
+func ParseUpdate(input string, names map[string]string, values map[string]interface{}) (*Update, error) {
+	const kind = "UpdateExpression"
+	if strings.TrimSpace(input) == "" {
+		return nil, &Error{Kind: kind, Msg: "The expression can not be empty;"}
+	}
+	p := &parser{kind: kind, input: input, tokens: lex(input), names: names, values: values}
+	update := &Update{values: values}
+	// positions holds the offset of the path of every action, for the errors on overlapping paths
+	var positions []int
+	used := map[string]bool{}
+	for p.peek().kind != tokEOF {
+		tok := p.peek()
+		clause := strings.ToUpper(tok.text)
+		if tok.kind != tokIdent || !slices.Contains(updateClauses, clause) {
+			return nil, p.syntaxError()
+		}
+		if used[clause] {
+			return nil, p.errorAt(tok.pos, "The %q section can only be used once in an update expression;", clause)
+		}
+		used[clause] = true
+		p.next()
+		for {
+			positions = append(positions, p.peek().pos)
+			action, err := p.parseUpdateAction(clause)
+			if err != nil {
+				return nil, err
+			}
+			update.Actions = append(update.Actions, action)
+			if p.peek().kind != tokComma {
+				break
+			}
+			p.next()
+		}
+	}
+	if err := p.checkOverlaps(update.Actions, positions); err != nil {
+		return nil, err
+	}
+	return update, nil
+}
+
+// Attributes returns the top-level attributes updated by the actions in the order they appear
+func (u *Update) Attributes() []string {
+	var attrs []string
+	for _, action := range u.Actions {
+		if attr := actionPath(action).Attribute(); !slices.Contains(attrs, attr) {
+			attrs = append(attrs, attr)
+		}
+	}
+	return attrs
+}
+
+// parseUpdateAction parses an action of the clause
+func (p *parser) parseUpdateAction(clause string) (UpdateAction, error) {
+	path, err := p.parsePath()
+	if err != nil {
+		return nil, err
+	}
+	switch clause {
+	case "SET":
+		if err := p.expect(tokEq); err != nil {
+			return nil, err
+		}
+		value, err := p.parseSetValue()
+		if err != nil {
+			return nil, err
+		}
+		return &SetAction{Path: path, Value: value}, nil
+	case "REMOVE":
+		return &RemoveAction{Path: path}, nil
+	}
+
+	tok := p.peek()
+	if tok.kind != tokValue {
+		return nil, p.syntaxError()
+	}
+	operand, err := p.parseOperand()
+	if err != nil {
+		return nil, err
+	}
+	value := operand.(*Value)
+	if clause == "ADD" {
+		if err := p.checkOperandTypes(tok.pos, clause, addOperandTypes, value); err != nil {
+			return nil, err
+		}
+		return &AddAction{Path: path, Value: value}, nil
+	}
+	if err := p.checkOperandTypes(tok.pos, clause, deleteOperandTypes, value); err != nil {
+		return nil, err
+	}
+	return &DeleteAction{Path: path, Value: value}, nil
+}
+
+// parseSetValue parses the value of a SET action: an operand, or the sum or difference of two operands
+func (p *parser) parseSetValue() (Operand, error) {
+	left, err := p.parseSetOperand()
+	if err != nil {
+		return nil, err
+	}
+	tok := p.peek()
+	if tok.kind != tokPlus && tok.kind != tokMinus {
+		return left, nil
+	}
+	p.next()
+	right, err := p.parseSetOperand()
+	if err != nil {
+		return nil, err
+	}
+	if err := p.checkOperandTypes(tok.pos, tok.text, []string{"N"}, left, right); err != nil {
+		return nil, err
+	}
+	return &Arithmetic{Op: tok.text, Left: left, Right: right}, nil
+}
+
+// parseSetOperand parses a document path, a value placeholder or one of the functions of the SET actions
+func (p *parser) parseSetOperand() (Operand, error) {
+	if !p.isFunctionCall() {
+		return p.parseOperand()
+	}
+	name := p.next()
+	p.next()
+	arity, ok := updateFunctions[name.text]
+	if !ok {
+		if _, isCondition := functions[name.text]; isCondition {
+			return nil, p.errorAt(name.pos, "The function is not allowed in an update expression; function: %s", name.text)
+		}
+		return nil, p.errorAt(name.pos, "Invalid function name; function: %s", name.text)
+	}
+	var args []Operand
+	for p.peek().kind != tokRParen {
+		if len(args) > 0 {
+			if err := p.expect(tokComma); err != nil {
+				return nil, err
+			}
+		}
+		arg, err := p.parseSetOperand()
+		if err != nil {
+			return nil, err
+		}
+		args = append(args, arg)
+	}
+	p.next()
+	if len(args) != arity {
+		return nil, p.errorAt(name.pos, "Incorrect number of operands for operator or function; operator or function: %s, number of operands: %d", name.text, len(args))
+	}
+	if name.text == "if_not_exists" {
+		path, isPath := args[0].(*Path)
+		if !isPath {
+			return nil, p.errorAt(name.pos, "Operator or function requires a document path; operator or function: %s", name.text)
+		}
+		return &IfNotExists{Path: path, Value: args[1]}, nil
+	}
+	if err := p.checkOperandTypes(name.pos, name.text, []string{"L"}, args...); err != nil {
+		return nil, err
+	}
+	return &ListAppend{Left: args[0], Right: args[1]}, nil
+}
+
+// checkOverlaps reports the first action whose path overlaps with the path of a previous action: the
+// paths are the same or one of them is a prefix of the other, or they conflict: the same attribute is
+// used both as a map and as a list
+func (p *parser) checkOverlaps(actions []UpdateAction, positions []int) error {
+	for j := range actions {
+		two := actionPath(actions[j])
+		for i := 0; i < j; i++ {
+			one := actionPath(actions[i])
+			overlap, conflict := comparePathPrefix(one.Elements, two.Elements)
+			switch {
+			case conflict:
+				return p.errorAt(positions[j], "Two document paths conflict with each other; must remove or rewrite one of these paths; path one: %s, path two: %s",
+					pathElements(one), pathElements(two))
+			case overlap:
+				return p.errorAt(positions[j], "Two document paths overlap with each other; must remove or rewrite one of these paths; path one: %s, path two: %s",
+					pathElements(one), pathElements(two))
+			}
+		}
+	}
+	return nil
+}
+
+// comparePathPrefix compares the common elements of two paths
+func comparePathPrefix(a, b []PathElement) (overlap, conflict bool) {
+	for i := 0; i < len(a) && i < len(b); i++ {
+		switch {
+		case a[i].IsIndex() != b[i].IsIndex():
+			return false, true
+		case a[i] != b[i]:
+			return false, false
+		}
+	}
+	return true, false
+}
+
+// pathElements formats the elements of a path as in the DynamoDB error messages, e.g. [a, b, [2]]
+func pathElements(path *Path) string {
+	elements := make([]string, len(path.Elements))
+	for i, e := range path.Elements {
+		if e.IsIndex() {
+			elements[i] = "[" + strconv.Itoa(e.Index) + "]"
+		} else {
+			elements[i] = e.Name
+		}
+	}
+	return "[" + strings.Join(elements, ", ") + "]"
+}
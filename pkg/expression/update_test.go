@@ -0,0 +1,113 @@
+// Copyright 2020 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package expression
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseUpdate(t *testing.T) {
+	names := map[string]string{"#n": "name"}
+	values := map[string]interface{}{":v": "x", ":one": 1.0, ":l": []interface{}{"x"}, ":ss": []string{"x"}}
+
+	tests := []struct {
+		testName string
+		input    string
+		want     []UpdateAction
+	}{
+		{
+			"SET with arithmetic and functions",
+			"SET a = b - :one, #n.c[2] = if_not_exists(#n.c[2], :one) + :one, l = list_append(:l, if_not_exists(l, :l))",
+			[]UpdateAction{
+				&SetAction{Path: path("a"), Value: &Arithmetic{Op: "-", Left: path("b"), Right: &Value{Name: ":one"}}},
+				&SetAction{Path: path("name", "c", 2), Value: &Arithmetic{
+					Op:    "+",
+					Left:  &IfNotExists{Path: path("name", "c", 2), Value: &Value{Name: ":one"}},
+					Right: &Value{Name: ":one"},
+				}},
+				&SetAction{Path: path("l"), Value: &ListAppend{
+					Left:  &Value{Name: ":l"},
+					Right: &IfNotExists{Path: path("l"), Value: &Value{Name: ":l"}},
+				}},
+			},
+		},
+		{
+			"all the clauses in any order and case",
+			"remove a[1], a[0] add n :one set s = :v delete ss :ss",
+			[]UpdateAction{
+				&RemoveAction{Path: path("a", 1)},
+				&RemoveAction{Path: path("a", 0)},
+				&AddAction{Path: path("n"), Value: &Value{Name: ":one"}},
+				&SetAction{Path: path("s"), Value: &Value{Name: ":v"}},
+				&DeleteAction{Path: path("ss"), Value: &Value{Name: ":ss"}},
+			},
+		},
+	}
+
+	for _, tc := range tests {
+		got, err := ParseUpdate(tc.input, names, values)
+		if assert.NoError(t, err, tc.testName) {
+			assert.Equal(t, tc.want, got.Actions, tc.testName)
+		}
+	}
+}
+
+func TestParseUpdateErrors(t *testing.T) {
+	values := map[string]interface{}{":v": "x", ":one": 1.0, ":l": []interface{}{"x"}, ":ss": []string{"x"}}
+
+	tests := []struct {
+		input string
+		want  string
+		pos   int
+	}{
+		{"", "Invalid UpdateExpression: The expression can not be empty;", 0},
+		{"a = :v", `Invalid UpdateExpression: Syntax error; token: "a", near: "a"`, 0},
+		{"SET a = :v SET b = :v", `Invalid UpdateExpression: The "SET" section can only be used once in an update expression;`, 11},
+		{"SET a = :missing", "Invalid UpdateExpression: An expression attribute value used in expression is not defined; attribute value: :missing", 8},
+		{"SET a = :one + :one + :one", `Invalid UpdateExpression: Syntax error; token: "+", near: ":one +"`, 20},
+		{"SET a = :v + :one", "Invalid UpdateExpression: Incorrect operand type for operator or function; operator or function: +, operand type: S", 11},
+		{"SET a = list_append(:l, :v)", "Invalid UpdateExpression: Incorrect operand type for operator or function; operator or function: list_append, operand type: S", 8},
+		{"SET a = if_not_exists(:v, a)", "Invalid UpdateExpression: Operator or function requires a document path; operator or function: if_not_exists", 8},
+		{"SET a = if_not_exists(a)", "Invalid UpdateExpression: Incorrect number of operands for operator or function; operator or function: if_not_exists, number of operands: 1", 8},
+		{"SET a = size(b)", "Invalid UpdateExpression: The function is not allowed in an update expression; function: size", 8},
+		{"SET a = f(b)", "Invalid UpdateExpression: Invalid function name; function: f", 8},
+		{"ADD a :v", "Invalid UpdateExpression: Incorrect operand type for operator or function; operator or function: ADD, operand type: S", 6},
+		{"DELETE a :one", "Invalid UpdateExpression: Incorrect operand type for operator or function; operator or function: DELETE, operand type: N", 9},
+		{"ADD a b", `Invalid UpdateExpression: Syntax error; token: "b", near: "a b"`, 6},
+		{"SET a.b = :v REMOVE a", "Invalid UpdateExpression: Two document paths overlap with each other; must remove or rewrite one of these paths; path one: [a, b], path two: [a]", 20},
+		{"SET a[0] = :v, a.b = :v", "Invalid UpdateExpression: Two document paths conflict with each other; must remove or rewrite one of these paths; path one: [a, [0]], path two: [a, b]", 15},
+		{"REMOVE a, a", "Invalid UpdateExpression: Two document paths overlap with each other; must remove or rewrite one of these paths; path one: [a], path two: [a]", 10},
+	}
+
+	for _, tc := range tests {
+		_, err := ParseUpdate(tc.input, nil, values)
+		if assert.Error(t, err, tc.input) {
+			assert.Equal(t, tc.want, err.Error(), tc.input)
+			assert.Equal(t, tc.pos, err.(*Error).Pos, tc.input)
+		}
+	}
+
+	_, err := ParseUpdate("SET a[0] = :v, a[1] = :v, b.c = :v, b.d = :v", nil, values)
+	assert.NoError(t, err)
+}
+
+func TestUpdateAttributes(t *testing.T) {
+	values := map[string]interface{}{":v": "x"}
+	u, err := ParseUpdate("SET a.b = :v, c = :v REMOVE a.d, e", nil, values)
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"a", "c", "e"}, u.Attributes())
+}
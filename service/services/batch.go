@@ -0,0 +1,293 @@
+// Copyright 2020 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package services
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"sync"
+
+	"github.com/cloudspannerecosystem/dynamodb-adapter/config"
+	"github.com/cloudspannerecosystem/dynamodb-adapter/models"
+	"github.com/cloudspannerecosystem/dynamodb-adapter/pkg/errors"
+	"github.com/cloudspannerecosystem/dynamodb-adapter/storage"
+)
+
+const (
+	maxBatchGetKeys = 100
+	// maxBatchGetBytes is the size of the items after which BatchGetItem returns the other keys as unprocessed
+	maxBatchGetBytes = 16 << 20
+	// maxBatchGetParallelism is the number of tables of a BatchGetItem read concurrently
+	maxBatchGetParallelism = 8
+
+	maxBatchWriteItems = 25
+	maxBatchWriteBytes = 16 << 20
+)
+
+// BatchGetOutput - the result of a BatchGetItem: the items read per table, and the keys which were not
+// processed per table, as indexes in the KeyArray of the table
+type BatchGetOutput struct {
+	Responses       map[string][]map[string]interface{}
+	UnprocessedKeys map[string][]int
+}
+
+// batchGetTableResult - the items read for one table, with the index of the key of each item
+type batchGetTableResult struct {
+	items   []map[string]interface{}
+	keyIdxs []int
+	err     error
+}
+
+// ValidateBatchGet validates the keys of a BatchGetItem in the same way DynamoDB does
+func ValidateBatchGet(requests []models.BatchGetWithProjectionMeta) error {
+	if len(requests) == 0 {
+		return errors.New("ValidationException", "1 validation error detected: Value null at 'requestItems' failed to satisfy constraint: Member must have length greater than or equal to 1")
+	}
+	total := 0
+	for _, req := range requests {
+		total += len(req.KeyArray)
+	}
+	if total > maxBatchGetKeys {
+		return errors.New("ValidationException", "Too many items requested for the BatchGetItem call")
+	}
+	for _, req := range requests {
+		tableConf, err := config.GetTableConf(req.TableName)
+		if err != nil {
+			return err
+		}
+		seen := make(map[string]struct{}, len(req.KeyArray))
+		for _, key := range req.KeyArray {
+			k := batchGetKey(tableConf, key)
+			if _, ok := seen[k]; ok {
+				return errors.New("ValidationException", "Provided list of item keys contains duplicates")
+			}
+			seen[k] = struct{}{}
+		}
+	}
+	return nil
+}
+
+// BatchGetItems reads the keys of the tables of a BatchGetItem concurrently. The reads of all the tables
+// share one strong read-only transaction, so that they see a consistent snapshot, the staleness of the
+// eventually consistent reads does not apply to BatchGetItem. The keys of the tables which failed with
+// a server error, and the keys read after the first 16 MB of items, are returned as unprocessed. When no
+// key could be processed, the error of the first table is returned.
+func BatchGetItems(ctx context.Context, requests []models.BatchGetWithProjectionMeta) (*BatchGetOutput, error) {
+	ctx, closeSnapshot := storage.GetStorageInstance().WithSnapshot(ctx)
+	defer closeSnapshot()
+
+	results := make([]batchGetTableResult, len(requests))
+	sem := make(chan struct{}, maxBatchGetParallelism)
+	var wg sync.WaitGroup
+	for i, req := range requests {
+		wg.Add(1)
+		go func(i int, req models.BatchGetWithProjectionMeta) {
+			defer wg.Done()
+			sem <- struct{}{}
+			defer func() { <-sem }()
+			tableCtx := storage.WithConsistentRead(ctx, req.ConsistentRead)
+			results[i].items, results[i].keyIdxs, results[i].err = batchGetTable(tableCtx, req)
+		}(i, req)
+	}
+	wg.Wait()
+
+	output := &BatchGetOutput{
+		Responses:       make(map[string][]map[string]interface{}, len(requests)),
+		UnprocessedKeys: make(map[string][]int),
+	}
+	var firstErr error
+	var size int64
+	for i, req := range requests {
+		res := results[i]
+		if res.err != nil {
+			if !isRetryableError(res.err) {
+				return nil, res.err
+			}
+			if firstErr == nil {
+				firstErr = res.err
+			}
+			output.UnprocessedKeys[req.TableName] = allKeyIdxs(len(req.KeyArray))
+			continue
+		}
+		items := make([]map[string]interface{}, 0, len(res.items))
+		for j, item := range res.items {
+			if size >= maxBatchGetBytes {
+				output.UnprocessedKeys[req.TableName] = append(output.UnprocessedKeys[req.TableName], res.keyIdxs[j])
+				continue
+			}
+			size += storage.ItemSize(item)
+			items = append(items, item)
+		}
+		output.Responses[req.TableName] = items
+	}
+	if firstErr != nil && len(output.Responses) == 0 {
+		return nil, firstErr
+	}
+	return output, nil
+}
+
+// batchGetTable reads the keys of one table of a BatchGetItem. The keys are always read to match the
+// items with the requested keys, and removed again when they are not part of the projection.
+func batchGetTable(ctx context.Context, req models.BatchGetWithProjectionMeta) ([]map[string]interface{}, []int, error) {
+	if len(req.KeyArray) == 0 {
+		return []map[string]interface{}{}, nil, nil
+	}
+	tableConf, err := config.GetTableConf(req.TableName)
+	if err != nil {
+		return nil, nil, err
+	}
+	tableName := tableConf.ActualTable
+	keyCols := []string{tableConf.PartitionKey}
+	if tableConf.SortKey != "" {
+		keyCols = append(keyCols, tableConf.SortKey)
+	}
+
+	projectionCols := getSpannerProjections(req.ProjectionExpression, tableName, req.ExpressionAttributeNames)
+	var addedCols []string
+	if len(projectionCols) > 0 {
+		for _, col := range keyCols {
+			if !slices.Contains(projectionCols, col) {
+				projectionCols = append(projectionCols, col)
+				addedCols = append(addedCols, col)
+			}
+		}
+	}
+	keyIdx := make(map[string]int, len(req.KeyArray))
+	var pValues, sValues []interface{}
+	for i, key := range req.KeyArray {
+		keyIdx[batchGetKey(tableConf, key)] = i
+		pValues = append(pValues, key[tableConf.PartitionKey])
+		if tableConf.SortKey != "" {
+			sValues = append(sValues, key[tableConf.SortKey])
+		}
+	}
+	items, err := storage.GetStorageInstance().SpannerBatchGet(ctx, tableName, pValues, sValues, projectionCols)
+	if err != nil {
+		return nil, nil, err
+	}
+	keyIdxs := make([]int, len(items))
+	for i, item := range items {
+		keyIdxs[i] = keyIdx[batchGetKey(tableConf, item)]
+		for _, col := range addedCols {
+			delete(item, col)
+		}
+	}
+	return items, keyIdxs, nil
+}
+
+// ValidateBatchWrite validates the writes of a BatchWriteItem, made to the given tables, in the same way DynamoDB does
+func ValidateBatchWrite(tableNames []string, writes []models.BatchWriteMeta) error {
+	if len(tableNames) == 0 {
+		return errors.New("ValidationException", "RequestItems must contain at least one table")
+	}
+	tableWrites := make(map[string]int, len(tableNames))
+	for _, w := range writes {
+		tableWrites[w.TableName]++
+	}
+	for _, tableName := range tableNames {
+		if tableWrites[tableName] == 0 {
+			return errors.New("ValidationException", "The write requests of "+tableName+" must contain at least one item")
+		}
+	}
+	if len(writes) > maxBatchWriteItems {
+		return errors.New("ValidationException", "Too many items requested for the BatchWriteItem call")
+	}
+	seen := make(map[string]struct{}, len(writes))
+	var size int64
+	for _, w := range writes {
+		tableConf, err := config.GetTableConf(w.TableName)
+		if err != nil {
+			return err
+		}
+		item := w.Item
+		if item == nil {
+			item = w.Key
+		}
+		if (w.Item == nil) == (w.Key == nil) {
+			return errors.New("ValidationException", "Supplied WriteRequest must have exactly one of PutRequest or DeleteRequest set")
+		}
+		if _, ok := item[tableConf.PartitionKey]; !ok {
+			return errors.New("ValidationException", "The provided key element does not match the schema")
+		}
+		if _, ok := item[tableConf.SortKey]; tableConf.SortKey != "" && !ok {
+			return errors.New("ValidationException", "The provided key element does not match the schema")
+		}
+		k := tableConf.ActualTable + "\x00" + batchGetKey(tableConf, item)
+		if _, ok := seen[k]; ok {
+			return errors.New("ValidationException", "Provided list of item keys contains duplicates")
+		}
+		seen[k] = struct{}{}
+		size += storage.ItemSize(item)
+	}
+	if size > maxBatchWriteBytes {
+		return errors.New("ValidationException", "Item collection size limit exceeded: the size of the BatchWriteItem request exceeds 16 MB")
+	}
+	return nil
+}
+
+// BatchWriteItems applies the puts and deletes of a BatchWriteItem and returns the indexes of the writes
+// which were not processed. When no write could be processed, the error of the first write is returned.
+func BatchWriteItems(ctx context.Context, writes []models.BatchWriteMeta) ([]int, error) {
+	batch := make([]storage.BatchWrite, len(writes))
+	for i, w := range writes {
+		tableConf, err := config.GetTableConf(w.TableName)
+		if err != nil {
+			return nil, err
+		}
+		batch[i] = storage.BatchWrite{Table: tableConf.ActualTable, Item: w.Item, Key: w.Key}
+	}
+	writeErrs, err := storage.GetStorageInstance().SpannerBatchWrite(ctx, batch)
+	if err != nil {
+		return nil, err
+	}
+	var unprocessed []int
+	var firstErr error
+	for i, err := range writeErrs {
+		if err == nil {
+			continue
+		}
+		unprocessed = append(unprocessed, i)
+		if firstErr == nil {
+			firstErr = err
+		}
+	}
+	if len(writes) > 0 && len(unprocessed) == len(writes) {
+		return nil, firstErr
+	}
+	return unprocessed, nil
+}
+
+// batchGetKey identifies the item of a key of a BatchGetItem
+func batchGetKey(tableConf models.TableConfig, item map[string]interface{}) string {
+	if tableConf.SortKey == "" {
+		return fmt.Sprintf("%v", item[tableConf.PartitionKey])
+	}
+	return fmt.Sprintf("%v\x00%v", item[tableConf.PartitionKey], item[tableConf.SortKey])
+}
+
+// isRetryableError checks whether the error is a server error, which the client can retry
+func isRetryableError(err error) bool {
+	e, ok := err.(*errors.Error)
+	return !ok || e.ErrorCode == "InternalServerError"
+}
+
+func allKeyIdxs(n int) []int {
+	idxs := make([]int, n)
+	for i := range idxs {
+		idxs[i] = i
+	}
+	return idxs
+}
@@ -0,0 +1,173 @@
+// Copyright 2020 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package services
+
+import (
+	"testing"
+
+	"github.com/cloudspannerecosystem/dynamodb-adapter/models"
+	"github.com/cloudspannerecosystem/dynamodb-adapter/pkg/errors"
+	"gopkg.in/go-playground/assert.v1"
+)
+
+func TestValidateBatchGet(t *testing.T) {
+	tableConf, columns := buildTableMetadata("orders_v1", ordersCreateTableMeta())
+	registerTable("orders_v1", tableConf, columns)
+	defer unregisterTable("orders_v1")
+
+	key := func(customer string, date float64) map[string]interface{} {
+		return map[string]interface{}{"customer_id": customer, "order_date": date}
+	}
+	tooManyKeys := make([]map[string]interface{}, 0, maxBatchGetKeys+1)
+	for i := 0; i <= maxBatchGetKeys; i++ {
+		tooManyKeys = append(tooManyKeys, key("c1", float64(i)))
+	}
+
+	tests := []struct {
+		testName string
+		requests []models.BatchGetWithProjectionMeta
+		wantCode string
+	}{
+		{
+			"valid keys",
+			[]models.BatchGetWithProjectionMeta{{TableName: "orders-v1", KeyArray: []map[string]interface{}{key("c1", 1), key("c1", 2), key("c2", 1)}}},
+			"",
+		},
+		{
+			"no table",
+			nil,
+			"ValidationException",
+		},
+		{
+			"too many keys",
+			[]models.BatchGetWithProjectionMeta{{TableName: "orders-v1", KeyArray: tooManyKeys}},
+			"ValidationException",
+		},
+		{
+			"duplicate keys",
+			[]models.BatchGetWithProjectionMeta{{TableName: "orders-v1", KeyArray: []map[string]interface{}{key("c1", 1), key("c1", 1)}}},
+			"ValidationException",
+		},
+		{
+			"unknown table",
+			[]models.BatchGetWithProjectionMeta{{TableName: "payments", KeyArray: []map[string]interface{}{key("c1", 1)}}},
+			"ResourceNotFoundException",
+		},
+	}
+
+	for _, tc := range tests {
+		err := ValidateBatchGet(tc.requests)
+		if tc.wantCode == "" {
+			assert.Equal(t, err, nil)
+			continue
+		}
+		assert.NotEqual(t, err, nil)
+		assert.Equal(t, err.(*errors.Error).ErrorCode, tc.wantCode)
+	}
+}
+
+func TestValidateBatchWrite(t *testing.T) {
+	tableConf, columns := buildTableMetadata("orders_v1", ordersCreateTableMeta())
+	registerTable("orders_v1", tableConf, columns)
+	defer unregisterTable("orders_v1")
+
+	key := func(customer string, date float64) map[string]interface{} {
+		return map[string]interface{}{"customer_id": customer, "order_date": date}
+	}
+	put := func(customer string, date float64) models.BatchWriteMeta {
+		item := key(customer, date)
+		item["status"] = "NEW"
+		return models.BatchWriteMeta{TableName: "orders-v1", Item: item}
+	}
+	del := func(customer string, date float64) models.BatchWriteMeta {
+		return models.BatchWriteMeta{TableName: "orders-v1", Key: key(customer, date)}
+	}
+	tooManyWrites := make([]models.BatchWriteMeta, 0, maxBatchWriteItems+1)
+	for i := 0; i <= maxBatchWriteItems; i++ {
+		tooManyWrites = append(tooManyWrites, put("c1", float64(i)))
+	}
+
+	tests := []struct {
+		testName   string
+		tableNames []string
+		writes     []models.BatchWriteMeta
+		wantCode   string
+	}{
+		{
+			"puts and deletes",
+			[]string{"orders-v1"},
+			[]models.BatchWriteMeta{put("c1", 1), put("c1", 2), del("c2", 1)},
+			"",
+		},
+		{
+			"too many writes",
+			[]string{"orders-v1"},
+			tooManyWrites,
+			"ValidationException",
+		},
+		{
+			"put and delete of the same key",
+			[]string{"orders-v1"},
+			[]models.BatchWriteMeta{put("c1", 1), del("c1", 1)},
+			"ValidationException",
+		},
+		{
+			"missing sort key",
+			[]string{"orders-v1"},
+			[]models.BatchWriteMeta{{TableName: "orders-v1", Key: map[string]interface{}{"customer_id": "c1"}}},
+			"ValidationException",
+		},
+		{
+			"put and delete in one request",
+			[]string{"orders-v1"},
+			[]models.BatchWriteMeta{{TableName: "orders-v1", Item: key("c1", 1), Key: key("c1", 1)}},
+			"ValidationException",
+		},
+		{
+			"unknown table",
+			[]string{"payments"},
+			[]models.BatchWriteMeta{{TableName: "payments", Key: key("c1", 1)}},
+			"ResourceNotFoundException",
+		},
+		{
+			"no tables",
+			nil,
+			nil,
+			"ValidationException",
+		},
+		{
+			"table without writes",
+			[]string{"orders-v1"},
+			nil,
+			"ValidationException",
+		},
+	}
+
+	for _, tc := range tests {
+		err := ValidateBatchWrite(tc.tableNames, tc.writes)
+		if tc.wantCode == "" {
+			assert.Equal(t, err, nil)
+			continue
+		}
+		assert.NotEqual(t, err, nil)
+		assert.Equal(t, err.(*errors.Error).ErrorCode, tc.wantCode)
+	}
+}
+
+func Test_isRetryableError(t *testing.T) {
+	assert.Equal(t, isRetryableError(errors.New("InternalServerError", "deadline exceeded")), true)
+	assert.Equal(t, isRetryableError(errors.New("ValidationException", "wrong key type")), false)
+	assert.Equal(t, allKeyIdxs(3), []int{0, 1, 2})
+}
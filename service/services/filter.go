@@ -0,0 +1,457 @@
+// Copyright 2020 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package services
+
+import (
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/cloudspannerecosystem/dynamodb-adapter/models"
+	"github.com/cloudspannerecosystem/dynamodb-adapter/pkg/expression"
+	"github.com/cloudspannerecosystem/dynamodb-adapter/storage"
+	"github.com/cloudspannerecosystem/dynamodb-adapter/utils"
+)
+
+// sqlKind is the type of a translated operand
+type sqlKind int
+
+const (
+	sqlUnknown sqlKind = iota
+	sqlString
+	sqlNumber
+	sqlBool
+)
+
+// jsonPathNameRegex - the attribute names which can be used in a JSONPath without quoting
+var jsonPathNameRegex = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_]*$`)
+
+// jsonTypes maps the attribute_type names to the types returned by JSON_TYPE
+var jsonTypes = map[string]string{"S": "string", "N": "number", "BOOL": "boolean", "L": "array", "M": "object"}
+
+// filterTranslator translates the FilterExpression of a Query or a Scan into Spanner SQL. Every translated
+// condition is either TRUE or FALSE, never NULL, so that NOT behaves as in the evaluation of the filter:
+// the operands of a missing attribute or of another type than expected translate to NULL, and the
+// predicates using them to FALSE.
+type filterTranslator struct {
+	table  string
+	filter *expression.Expression
+	params map[string]interface{}
+	count  int
+}
+
+// translateFilter translates the top-level conditions of the filter joined by AND into a SQL condition
+// with its parameters added to params. The conditions which can not be translated are returned as the
+// residual filter evaluated on the items read, it is nil when the whole filter is translated.
+func translateFilter(table string, filter *expression.Expression, params map[string]interface{}) (string, *expression.Expression) {
+	if filter == nil {
+		return "", nil
+	}
+	t := &filterTranslator{table: table, filter: filter, params: params}
+	var translated []string
+	var residual expression.Condition
+	for _, cond := range expression.Conjuncts(filter.Cond) {
+		count := t.count
+		sql, ok := t.condition(cond)
+		if !ok {
+			t.drop(count)
+			if residual == nil {
+				residual = cond
+			} else {
+				residual = &expression.And{Left: residual, Right: cond}
+			}
+			continue
+		}
+		translated = append(translated, sql)
+	}
+	return strings.Join(translated, " AND "), filter.Restrict(residual)
+}
+
+func (t *filterTranslator) condition(cond expression.Condition) (string, bool) {
+	switch c := cond.(type) {
+	case *expression.And:
+		return t.binary(c.Left, c.Right, " AND ")
+	case *expression.Or:
+		return t.binary(c.Left, c.Right, " OR ")
+	case *expression.Not:
+		sql, ok := t.condition(c.Cond)
+		return "NOT (" + sql + ")", ok
+	case *expression.Comparison:
+		return t.comparison(c)
+	case *expression.Between:
+		kind := t.kindOf(c.Operand, c.Lower, c.Upper)
+		if kind != sqlString && kind != sqlNumber {
+			return "", false
+		}
+		count := t.count
+		operand, ok1 := t.operand(c.Operand, kind)
+		lower, ok2 := t.operand(c.Lower, kind)
+		upper, ok3 := t.operand(c.Upper, kind)
+		if operand == "NULL" || lower == "NULL" || upper == "NULL" {
+			t.drop(count)
+			return "FALSE", ok1 && ok2 && ok3
+		}
+		return "IFNULL(" + operand + " BETWEEN " + lower + " AND " + upper + ", FALSE)", ok1 && ok2 && ok3
+	case *expression.In:
+		return t.in(c)
+	case *expression.Function:
+		return t.function(c)
+	}
+	return "", false
+}
+
+func (t *filterTranslator) binary(left, right expression.Condition, op string) (string, bool) {
+	l, ok := t.condition(left)
+	if !ok {
+		return "", false
+	}
+	r, ok := t.condition(right)
+	return "(" + l + op + r + ")", ok
+}
+
+func (t *filterTranslator) comparison(c *expression.Comparison) (string, bool) {
+	kind := t.kindOf(c.Left, c.Right)
+	if kind == sqlUnknown || (kind == sqlBool && c.Op != "=" && c.Op != "<>") {
+		return "", false
+	}
+	count := t.count
+	left, ok1 := t.operand(c.Left, kind)
+	right, ok2 := t.operand(c.Right, kind)
+	if !ok1 || !ok2 {
+		return "", false
+	}
+	if left == "NULL" || right == "NULL" {
+		// a missing attribute or a value of another type is different from every value
+		t.drop(count)
+		if c.Op == "<>" {
+			return "TRUE", true
+		}
+		return "FALSE", true
+	}
+	if c.Op == "<>" {
+		return "NOT IFNULL(" + left + " = " + right + ", FALSE)", true
+	}
+	return "IFNULL(" + left + " " + c.Op + " " + right + ", FALSE)", true
+}
+
+func (t *filterTranslator) in(c *expression.In) (string, bool) {
+	kind := t.kindOf(c.Operand)
+	for _, o := range c.List {
+		v, isValue := o.(*expression.Value)
+		if !isValue {
+			return "", false
+		}
+		if kind == sqlUnknown {
+			kind = valueKind(t.filter.Value(v))
+		}
+		if valueKind(t.filter.Value(v)) != kind {
+			return "", false
+		}
+	}
+	if kind == sqlUnknown {
+		return "", false
+	}
+	operand, ok := t.operand(c.Operand, kind)
+	if operand == "NULL" {
+		return "FALSE", ok
+	}
+	list := make([]string, 0, len(c.List))
+	for _, o := range c.List {
+		sql, _ := t.operand(o, kind)
+		list = append(list, sql)
+	}
+	return "IFNULL(" + operand + " IN (" + strings.Join(list, ", ") + "), FALSE)", ok
+}
+
+func (t *filterTranslator) function(f *expression.Function) (string, bool) {
+	path, isPath := f.Args[0].(*expression.Path)
+	if !isPath {
+		return "", false
+	}
+	col, ddlType, nested, ok := t.column(path)
+	if !ok {
+		return "", false
+	}
+	switch f.Name {
+	case "attribute_exists", "attribute_not_exists":
+		exists, ok := t.exists(col, ddlType, nested)
+		if f.Name == "attribute_not_exists" {
+			exists = "NOT " + exists
+		}
+		return exists, ok
+	}
+	v, isValue := f.Args[1].(*expression.Value)
+	if !isValue {
+		return "", false
+	}
+	value := t.filter.Value(v)
+	switch f.Name {
+	case "attribute_type":
+		typeName, _ := value.(string)
+		if nested != "" || ddlType == "M" || ddlType == "L" {
+			jsonType, ok := jsonTypes[typeName]
+			if !ok {
+				return "FALSE", true
+			}
+			return "IFNULL(JSON_TYPE(" + jsonQuery(col, nested) + ") = '" + jsonType + "', FALSE)", true
+		}
+		if typeName != ddlType {
+			return "FALSE", true
+		}
+		return t.exists(col, ddlType, nested)
+	case "begins_with":
+		if valueKind(value) != sqlString {
+			return "", false
+		}
+		s, ok := t.operand(path, sqlString)
+		if s == "NULL" {
+			return "FALSE", ok
+		}
+		return "IFNULL(STARTS_WITH(" + s + ", " + t.param(value) + "), FALSE)", ok
+	case "contains":
+		return t.contains(col, ddlType, nested, value)
+	}
+	return "", false
+}
+
+// exists checks that the attribute is present, JSON nulls are missing attributes as in the evaluation
+func (t *filterTranslator) exists(col, ddlType, nested string) (string, bool) {
+	switch {
+	case ddlType == "":
+		return "FALSE", true
+	case nested != "" || ddlType == "M" || ddlType == "L":
+		return "IFNULL(JSON_TYPE(" + jsonQuery(col, nested) + ") <> 'null', FALSE)", true
+	case ddlType == "B" || ddlType == "NULL":
+		// empty binaries and NULL attributes are read as missing attributes
+		return "", false
+	}
+	return col + " IS NOT NULL", true
+}
+
+func (t *filterTranslator) contains(col, ddlType, nested string, value interface{}) (string, bool) {
+	kind := valueKind(value)
+	if kind != sqlString && kind != sqlNumber {
+		return "", false
+	}
+	switch {
+	case ddlType == "B" || ddlType == "BS":
+		return "", false
+	case ddlType == "S" && kind == sqlString:
+		return "IFNULL(STRPOS(" + col + ", " + t.param(value) + ") > 0, FALSE)", true
+	case (ddlType == "SS" && kind == sqlString) || (ddlType == "NS" && kind == sqlNumber):
+		return "IFNULL(" + t.param(value) + " IN UNNEST(" + col + "), FALSE)", true
+	case nested != "" || ddlType == "M" || ddlType == "L":
+		p := t.param(value)
+		j := jsonQuery(col, nested)
+		element := "IF(JSON_TYPE(e) = 'string', STRING(e), NULL)"
+		if kind == sqlNumber {
+			element = "IF(JSON_TYPE(e) = 'number', FLOAT64(e), NULL)"
+		}
+		inList := "EXISTS(SELECT 1 FROM UNNEST(JSON_QUERY_ARRAY(" + j + ")) AS e WHERE " + element + " = " + p + ")"
+		if kind == sqlString {
+			inList = "IF(JSON_TYPE(" + j + ") = 'string', STRPOS(STRING(" + j + "), " + p + ") > 0, " + inList + ")"
+		}
+		return "IFNULL(" + inList + ", FALSE)", true
+	}
+	// the scalar attributes of another type never contain the value
+	return "FALSE", true
+}
+
+// operand translates an operand into a SQL expression of the kind, which is NULL when the attribute
+// is missing or holds a value of another kind
+func (t *filterTranslator) operand(o expression.Operand, kind sqlKind) (string, bool) {
+	switch o := o.(type) {
+	case *expression.Value:
+		value := t.filter.Value(o)
+		if valueKind(value) != kind {
+			return "NULL", true
+		}
+		return t.param(value), true
+	case *expression.Path:
+		col, ddlType, nested, ok := t.column(o)
+		if !ok {
+			return "", false
+		}
+		if nested != "" {
+			return jsonScalar(jsonQuery(col, nested), kind), true
+		}
+		colKind, ok := columnKind(ddlType)
+		if !ok {
+			return "", false
+		}
+		if colKind != kind {
+			return "NULL", true
+		}
+		if models.TableSpannerDDL[t.table][o.Attribute()] == "TIMESTAMP" {
+			// TIMESTAMP columns hold N attributes as epoch seconds
+			return "UNIX_MICROS(" + col + ") / 1000000", true
+		}
+		return col, true
+	case *expression.Size:
+		if kind != sqlNumber {
+			return "NULL", true
+		}
+		col, ddlType, nested, ok := t.column(o.Path)
+		if !ok || nested != "" {
+			return "", false
+		}
+		switch ddlType {
+		case "S":
+			return "BYTE_LENGTH(" + col + ")", true
+		case "SS", "NS", "BS":
+			return "ARRAY_LENGTH(" + col + ")", true
+		case "L":
+			return "ARRAY_LENGTH(JSON_QUERY_ARRAY(" + col + "))", true
+		case "N", "BOOL", "NULL", "":
+			return "NULL", true
+		}
+	}
+	return "", false
+}
+
+// column returns the column of the top-level attribute of the path, its DynamoDB type and the JSONPath
+// of the nested attribute when the path has more elements. An attribute which can not exist is read as
+// NULL with an empty type.
+func (t *filterTranslator) column(path *expression.Path) (string, string, string, bool) {
+	attr := path.Attribute()
+	ddlType, ok := models.TableDDL[t.table][attr]
+	if !ok {
+		return "NULL", "", "", true
+	}
+	col := t.table + ".`" + attr + "`"
+	if len(path.Elements) == 1 {
+		return col, ddlType, "", true
+	}
+	if ddlType != "M" && ddlType != "L" {
+		// the scalar and set attributes have no nested attribute
+		return "NULL", "", "", true
+	}
+	var sb strings.Builder
+	sb.WriteString("$")
+	for _, e := range path.Elements[1:] {
+		switch {
+		case e.IsIndex():
+			sb.WriteString("[" + strconv.Itoa(e.Index) + "]")
+		case jsonPathNameRegex.MatchString(e.Name):
+			sb.WriteString("." + e.Name)
+		default:
+			return "", "", "", false
+		}
+	}
+	return col, ddlType, sb.String(), true
+}
+
+// columnKind returns the kind of the scalar columns, the other columns are never equal to a value of
+// the kinds translated, apart from the binary columns which are encoded in the column
+func columnKind(ddlType string) (sqlKind, bool) {
+	switch ddlType {
+	case "S":
+		return sqlString, true
+	case "N":
+		return sqlNumber, true
+	case "BOOL":
+		return sqlBool, true
+	case "B", "BS":
+		return sqlUnknown, false
+	}
+	return sqlUnknown, true
+}
+
+// kindOf returns the kind of the first value operand, or of the first scalar column when there is none
+func (t *filterTranslator) kindOf(operands ...expression.Operand) sqlKind {
+	for _, o := range operands {
+		switch o := o.(type) {
+		case *expression.Value:
+			return valueKind(t.filter.Value(o))
+		case *expression.Size:
+			return sqlNumber
+		}
+	}
+	for _, o := range operands {
+		if path, ok := o.(*expression.Path); ok && len(path.Elements) == 1 {
+			if kind, ok := columnKind(models.TableDDL[t.table][path.Attribute()]); ok && kind != sqlUnknown {
+				return kind
+			}
+		}
+	}
+	return sqlUnknown
+}
+
+// drop removes the parameters added after count, when the SQL using them is discarded
+func (t *filterTranslator) drop(count int) {
+	for ; t.count > count; t.count-- {
+		delete(t.params, "filterExp"+strconv.Itoa(t.count))
+	}
+}
+
+// param adds the value as a query parameter
+func (t *filterTranslator) param(value interface{}) string {
+	t.count++
+	name := "filterExp" + strconv.Itoa(t.count)
+	t.params[name] = value
+	return "@" + name
+}
+
+// valueKind returns the kind of an ExpressionAttributeValue, sqlUnknown for the values which are not translated
+func valueKind(value interface{}) sqlKind {
+	switch value.(type) {
+	case string:
+		return sqlString
+	case float64, int64:
+		return sqlNumber
+	case bool:
+		return sqlBool
+	}
+	return sqlUnknown
+}
+
+func jsonQuery(col, nested string) string {
+	if nested == "" {
+		return col
+	}
+	return "JSON_QUERY(" + col + ", '" + nested + "')"
+}
+
+// jsonScalar converts a JSON value into the kind, NULL when it holds another type
+func jsonScalar(j string, kind sqlKind) string {
+	switch kind {
+	case sqlString:
+		return "IF(JSON_TYPE(" + j + ") = 'string', STRING(" + j + "), NULL)"
+	case sqlNumber:
+		return "IF(JSON_TYPE(" + j + ") = 'number', FLOAT64(" + j + "), NULL)"
+	case sqlBool:
+		return "IF(JSON_TYPE(" + j + ") = 'boolean', BOOL(" + j + "), NULL)"
+	}
+	return "NULL"
+}
+
+// filterColumns selects the columns read by the subquery evaluating the filter. The attributes of the
+// items filtered out are not transferred: only their keys, used for the LastEvaluatedKey, and their
+// TimeToLive attribute, used to hide the expired items, are read.
+func filterColumns(query *models.Query, cols, keys []string) string {
+	ttlColumn := models.DbConfigMap[utils.ChangeTableNameForSpanner(query.TableName)].TTLAttribute
+	outer := make([]string, 0, len(cols)+1)
+	for _, col := range selectColumns(query, cols) {
+		switch {
+		case col == "commit_timestamp":
+		case slices.Contains(keys, col) || col == ttlColumn:
+			outer = append(outer, "`"+col+"`")
+		default:
+			outer = append(outer, "IF("+storage.QueryFilterColumn+", `"+col+"`, NULL) AS `"+col+"`")
+		}
+	}
+	return strings.Join(outer, ",") + "," + storage.QueryFilterColumn
+}
@@ -0,0 +1,106 @@
+// Copyright 2020 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package services
+
+import (
+	"testing"
+
+	"github.com/cloudspannerecosystem/dynamodb-adapter/models"
+	"github.com/cloudspannerecosystem/dynamodb-adapter/pkg/expression"
+	"gopkg.in/go-playground/assert.v1"
+)
+
+func Test_translateFilter(t *testing.T) {
+	tableDDL := models.TableDDL
+	defer func() { models.TableDDL = tableDDL }()
+	models.TableDDL = map[string]map[string]string{
+		"filterTable": {"id": "S", "age": "N", "active": "BOOL", "tags": "SS", "scores": "NS", "photo": "B", "address": "M", "items": "L"},
+	}
+	values := map[string]interface{}{":n": 10.0, ":n2": 20.0, ":s": "Paris", ":t": "Lyon", ":M": "M"}
+
+	tests := []struct {
+		testName     string
+		filter       string
+		want         string
+		wantParams   map[string]interface{}
+		wantResidual []string
+	}{
+		{
+			"comparison",
+			"age > :n",
+			"IFNULL(filterTable.`age` > @filterExp1, FALSE)",
+			map[string]interface{}{"filterExp1": 10.0},
+			nil,
+		},
+		{
+			"nested path and contains on a set",
+			"address.city = :s AND contains(tags, :s)",
+			"IFNULL(IF(JSON_TYPE(JSON_QUERY(filterTable.`address`, '$.city')) = 'string', STRING(JSON_QUERY(filterTable.`address`, '$.city')), NULL) = @filterExp1, FALSE) AND IFNULL(@filterExp2 IN UNNEST(filterTable.`tags`), FALSE)",
+			map[string]interface{}{"filterExp1": "Paris", "filterExp2": "Paris"},
+			nil,
+		},
+		{
+			"attribute_exists",
+			"attribute_exists(items[0]) OR NOT attribute_exists(id)",
+			"(IFNULL(JSON_TYPE(JSON_QUERY(filterTable.`items`, '$[0]')) <> 'null', FALSE) OR NOT (filterTable.`id` IS NOT NULL))",
+			map[string]interface{}{},
+			nil,
+		},
+		{
+			"size and begins_with on a binary",
+			"size(scores) >= :n AND begins_with(photo, :s)",
+			"IFNULL(ARRAY_LENGTH(filterTable.`scores`) >= @filterExp1, FALSE)",
+			map[string]interface{}{"filterExp1": 10.0},
+			[]string{"photo"},
+		},
+		{
+			"missing attribute and type mismatch",
+			"missing = :n OR age <> :s",
+			"(FALSE OR TRUE)",
+			map[string]interface{}{},
+			nil,
+		},
+		{
+			"contains on a binary and between",
+			"contains(photo, :s) AND age BETWEEN :n AND :n2",
+			"IFNULL(filterTable.`age` BETWEEN @filterExp1 AND @filterExp2, FALSE)",
+			map[string]interface{}{"filterExp1": 10.0, "filterExp2": 20.0},
+			[]string{"photo"},
+		},
+		{
+			"attribute_type and in",
+			"attribute_type(address, :M) AND id IN (:s, :t)",
+			"IFNULL(JSON_TYPE(filterTable.`address`) = 'object', FALSE) AND IFNULL(filterTable.`id` IN (@filterExp1, @filterExp2), FALSE)",
+			map[string]interface{}{"filterExp1": "Paris", "filterExp2": "Lyon"},
+			nil,
+		},
+	}
+
+	for _, tc := range tests {
+		filter, err := expression.ParseCondition("FilterExpression", tc.filter, nil, values)
+		assert.Equal(t, err, nil)
+		params := map[string]interface{}{}
+		got, residual := translateFilter("filterTable", filter, params)
+		assert.Equal(t, got, tc.want)
+		assert.Equal(t, params, tc.wantParams)
+		var residualPaths []string
+		if residual != nil {
+			for _, path := range expression.Paths(residual.Cond) {
+				residualPaths = append(residualPaths, path.String())
+			}
+		}
+		assert.Equal(t, residualPaths, tc.wantResidual)
+	}
+}
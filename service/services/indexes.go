@@ -0,0 +1,546 @@
+// Copyright 2020 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package services
+
+import (
+	"context"
+	"fmt"
+	"maps"
+	"slices"
+	"sync"
+	"time"
+
+	"cloud.google.com/go/spanner"
+	"github.com/cloudspannerecosystem/dynamodb-adapter/models"
+	"github.com/cloudspannerecosystem/dynamodb-adapter/pkg/errors"
+	"github.com/cloudspannerecosystem/dynamodb-adapter/pkg/logger"
+	"github.com/cloudspannerecosystem/dynamodb-adapter/storage"
+	"github.com/cloudspannerecosystem/dynamodb-adapter/utils"
+	"github.com/googleapis/gax-go/v2"
+	"google.golang.org/grpc/codes"
+)
+
+const (
+	tableStatusUpdating = "UPDATING"
+
+	indexStatusCreating = "CREATING"
+	indexStatusDeleting = "DELETING"
+
+	// Spanner index states from INFORMATION_SCHEMA.INDEXES
+	spannerIndexStateWriteOnly = "WRITE_ONLY"
+	spannerIndexStateReadWrite = "READ_WRITE"
+
+	// indexStatePollInterval is the interval at which the state of an index build started before
+	// a restart is read again
+	indexStatePollInterval = 10 * time.Second
+)
+
+// ddlOperation is a running Spanner schema change
+type ddlOperation interface {
+	Wait(ctx context.Context, opts ...gax.CallOption) error
+}
+
+// indexOperation is an index build or drop started by this adapter instance, or an index build
+// found running when the adapter started
+type indexOperation struct {
+	spannerTable string
+	status       string
+}
+
+var (
+	indexOperationsMux sync.Mutex
+	// indexOperations holds the running index operations keyed by Spanner index name
+	indexOperations = make(map[string]indexOperation)
+)
+
+// startIndexOperation records a running index build or drop
+func startIndexOperation(spannerTable, spannerIndex, status string) {
+	indexOperationsMux.Lock()
+	defer indexOperationsMux.Unlock()
+	indexOperations[spannerIndex] = indexOperation{spannerTable: spannerTable, status: status}
+}
+
+// finishIndexOperation removes a completed index build or drop
+func finishIndexOperation(spannerIndex string) {
+	indexOperationsMux.Lock()
+	defer indexOperationsMux.Unlock()
+	delete(indexOperations, spannerIndex)
+}
+
+// indexOperationStatus returns CREATING or DELETING while an index operation is running, otherwise empty
+func indexOperationStatus(spannerIndex string) string {
+	indexOperationsMux.Lock()
+	defer indexOperationsMux.Unlock()
+	return indexOperations[spannerIndex].status
+}
+
+// tableHasIndexOperation checks whether an index of the table is being built or dropped
+func tableHasIndexOperation(spannerTable string) bool {
+	indexOperationsMux.Lock()
+	defer indexOperationsMux.Unlock()
+	for _, op := range indexOperations {
+		if op.spannerTable == spannerTable {
+			return true
+		}
+	}
+	return false
+}
+
+// checkIndexReadable rejects reads from an index which is being built or dropped, as DynamoDB does
+func checkIndexReadable(indexName, spannerIndex string) error {
+	switch indexOperationStatus(spannerIndex) {
+	case indexStatusCreating:
+		return errors.New("ValidationException", "Cannot read from backfilling global secondary index: "+indexName)
+	case indexStatusDeleting:
+		return errors.New("ValidationException", "The table does not have the specified index: "+indexName)
+	}
+	return nil
+}
+
+// setIndexStatuses reports the global secondary indexes which are being built or dropped. states holds
+// the Spanner index states by index name, the index builds are reported from them. When states is nil
+// only the operations of this instance are used. Index drops are not visible in the index states.
+func setIndexStatuses(desc *models.TableDescription, tableConf models.TableConfig, states map[string]string) {
+	for i := range desc.GlobalSecondaryIndexes {
+		gsi := &desc.GlobalSecondaryIndexes[i]
+		spannerIndex := tableConf.Indices[gsi.IndexName].SpannerIndexName
+		if status := indexOperationStatus(spannerIndex); status == indexStatusDeleting || (states == nil && status != "") {
+			gsi.IndexStatus = status
+			continue
+		}
+		if states == nil {
+			continue
+		}
+		state, ok := states[spannerIndex]
+		switch {
+		case !ok:
+			// the schema change has been submitted but has not started yet
+			gsi.IndexStatus = indexStatusCreating
+		case state == spannerIndexStateWriteOnly:
+			gsi.IndexStatus = indexStatusCreating
+			gsi.Backfilling = true
+		case state != spannerIndexStateReadWrite:
+			gsi.IndexStatus = indexStatusCreating
+		}
+	}
+}
+
+// UpdateTable applies the global secondary index updates of the request. The Spanner index is built or
+// dropped by a long-running operation, the response reports the index as CREATING or DELETING and
+// DescribeTable follows its progress. Billing mode and throughput settings have no effect on Spanner.
+func UpdateTable(ctx context.Context, meta models.UpdateTableMeta) (*models.TableDescription, error) {
+	spannerTable := utils.ChangeTableNameForSpanner(meta.TableName)
+
+	tableMetaMux.Lock()
+	defer tableMetaMux.Unlock()
+
+	tableConf, ok := models.DbConfigMap[spannerTable]
+	if !ok {
+		return nil, errors.New("ResourceNotFoundException", "Requested resource not found: Table: "+meta.TableName+" not found")
+	}
+	create, del, err := validateUpdateTable(meta, spannerTable, tableConf)
+	if err != nil {
+		return nil, err
+	}
+	if (create != nil || del != nil) && tableHasIndexOperation(spannerTable) {
+		return nil, errors.New("ResourceInUseException", "Attempt to change a resource which is still in use: Table is being updated: "+meta.TableName)
+	}
+	switch {
+	case create != nil:
+		err = createGlobalSecondaryIndex(ctx, meta, spannerTable, tableConf, *create)
+	case del != nil:
+		err = deleteGlobalSecondaryIndex(ctx, spannerTable, tableConf, del.IndexName)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	tableConf = models.DbConfigMap[spannerTable]
+	desc := describeTableConf(meta.TableName, tableConf)
+	setIndexStatuses(&desc, tableConf, nil)
+	desc.TableStatus = tableStatusActive
+	if create != nil || del != nil {
+		desc.TableStatus = tableStatusUpdating
+	}
+	if meta.BillingMode != "" {
+		desc.BillingModeSummary = &models.BillingModeSummary{BillingMode: meta.BillingMode}
+	}
+	desc.ProvisionedThroughput = meta.ProvisionedThroughput
+	return &desc, nil
+}
+
+// validateUpdateTable validates the index updates of the UpdateTable request in the same way DynamoDB
+// does and returns the index to be created or deleted, only one of them can be changed per request
+func validateUpdateTable(meta models.UpdateTableMeta, spannerTable string, tableConf models.TableConfig) (*models.SecondaryIndex, *models.DeleteGlobalSecondaryIndexAction, error) {
+	attrTypes := make(map[string]string)
+	for _, attr := range meta.AttributeDefinitions {
+		switch attr.AttributeType {
+		case "S", "N", "B":
+		default:
+			return nil, nil, errors.New("ValidationException", "Invalid AttributeType "+attr.AttributeType+" for attribute "+attr.AttributeName)
+		}
+		if existing, ok := models.TableDDL[spannerTable][spannerColumnName(attr.AttributeName)]; ok && existing != attr.AttributeType {
+			return nil, nil, errors.New("ValidationException", "One or more parameter values were invalid: Type mismatch for attribute to update: "+attr.AttributeName)
+		}
+		attrTypes[attr.AttributeName] = attr.AttributeType
+	}
+
+	var create *models.SecondaryIndex
+	var del *models.DeleteGlobalSecondaryIndexAction
+	changes := 0
+	for _, update := range meta.GlobalSecondaryIndexUpdates {
+		actions := 0
+		if update.Create != nil {
+			actions++
+			create = &models.SecondaryIndex{
+				IndexName:             update.Create.IndexName,
+				KeySchema:             update.Create.KeySchema,
+				Projection:            update.Create.Projection,
+				ProvisionedThroughput: update.Create.ProvisionedThroughput,
+			}
+		}
+		if update.Delete != nil {
+			actions++
+			del = update.Delete
+		}
+		if update.Update != nil {
+			actions++
+			if _, ok := tableConf.Indices[update.Update.IndexName]; !ok {
+				return nil, nil, errors.New("ResourceNotFoundException", "Requested resource not found: Index: "+update.Update.IndexName+" not found")
+			}
+		}
+		if actions != 1 {
+			return nil, nil, errors.New("ValidationException", "One or more parameter values were invalid: One of GlobalSecondaryIndexUpdate.Create, Update or Delete must be specified")
+		}
+		if update.Update == nil {
+			changes++
+		}
+	}
+	if changes > 1 {
+		return nil, nil, errors.New("LimitExceededException", "Subscriber limit exceeded: Only 1 online index can be created or deleted simultaneously per table")
+	}
+
+	if create != nil {
+		if _, ok := tableConf.Indices[create.IndexName]; ok {
+			return nil, nil, errors.New("ValidationException", "One or more parameter values were invalid: Index already exists: "+create.IndexName)
+		}
+		globalIndexes := 0
+		for _, idx := range tableConf.Indices {
+			if idx.IndexType != indexTypeLocal {
+				globalIndexes++
+			}
+		}
+		if globalIndexes >= maxGlobalSecondaryIndexes {
+			return nil, nil, errors.New("LimitExceededException", fmt.Sprintf("GlobalSecondaryIndexes count exceeds the per-table limit of %d", maxGlobalSecondaryIndexes))
+		}
+		used := make(map[string]struct{})
+		if err := validateSecondaryIndex(*create, make(map[string]struct{}), attrTypes, used); err != nil {
+			return nil, nil, err
+		}
+	}
+	if del != nil {
+		idx, ok := tableConf.Indices[del.IndexName]
+		if !ok {
+			return nil, nil, errors.New("ResourceNotFoundException", "Requested resource not found: Index: "+del.IndexName+" not found")
+		}
+		if idx.IndexType == indexTypeLocal {
+			return nil, nil, errors.New("ValidationException", "One or more parameter values were invalid: Cannot delete a local secondary index: "+del.IndexName)
+		}
+	}
+	return create, del, nil
+}
+
+// createGlobalSecondaryIndex adds the key columns missing from the table, records the index in the
+// adapter metadata and starts the Spanner index build. The index is removed again if the build fails.
+func createGlobalSecondaryIndex(ctx context.Context, meta models.UpdateTableMeta, spannerTable string, tableConf models.TableConfig, idx models.SecondaryIndex) error {
+	var newColumns []tableColumn
+	for _, attr := range meta.AttributeDefinitions {
+		col := spannerColumnName(attr.AttributeName)
+		if _, ok := models.TableDDL[spannerTable][col]; ok {
+			continue
+		}
+		newColumns = append(newColumns, tableColumn{
+			Column:         col,
+			OriginalColumn: attr.AttributeName,
+			DynamoDataType: attr.AttributeType,
+			SpannerType:    utils.ConvertDynamoTypeToSpannerType(attr.AttributeType),
+		})
+	}
+	statements := []string{adapterIndexTableDDL}
+	for _, col := range newColumns {
+		statements = append(statements, fmt.Sprintf("ALTER TABLE `%s` ADD COLUMN `%s` %s", spannerTable, col.Column, col.SpannerType))
+	}
+	if err := storage.GetStorageInstance().SpannerUpdateDDL(ctx, statements); err != nil {
+		return errors.New("InternalServerError", err)
+	}
+
+	conf := buildIndexConf(spannerTable, idx, indexTypeGlobal)
+	indexTableConf := tableConf
+	indexTableConf.Indices = map[string]models.TableConfig{idx.IndexName: conf}
+	err := storage.GetStorageInstance().SpannerApply(ctx, buildTableMetadataMutations(meta.TableName, spannerTable, indexTableConf, newColumns))
+	if err != nil {
+		return errors.New("InternalServerError", err)
+	}
+	registerIndex(spannerTable, idx.IndexName, conf, newColumns)
+
+	columns := make([]tableColumn, 0, len(models.TableColumnMap[spannerTable]))
+	for _, col := range models.TableColumnMap[spannerTable] {
+		columns = append(columns, tableColumn{Column: col})
+	}
+	op, err := storage.GetStorageInstance().SpannerStartUpdateDDL(ctx, []string{buildCreateIndexDDL(spannerTable, tableConf, conf, columns)})
+	if err != nil {
+		removeIndexMetadata(context.Background(), spannerTable, idx.IndexName)
+		removeIndexColumns(context.Background(), spannerTable, newColumns)
+		return errors.New("InternalServerError", err)
+	}
+	startIndexOperation(spannerTable, conf.SpannerIndexName, indexStatusCreating)
+	go waitIndexCreation(spannerTable, idx.IndexName, conf.SpannerIndexName, newColumns, op)
+	return nil
+}
+
+// waitIndexCreation waits for the index build, a failed build removes the index from the metadata
+// and drops the columns which were added for the index
+func waitIndexCreation(spannerTable, indexName, spannerIndex string, newColumns []tableColumn, op ddlOperation) {
+	defer finishIndexOperation(spannerIndex)
+	err := op.Wait(context.Background())
+	if err == nil {
+		logger.Info("index created: ", spannerIndex)
+		return
+	}
+	logger.Error("index creation failed: ", spannerIndex, err)
+
+	tableMetaMux.Lock()
+	defer tableMetaMux.Unlock()
+	removeIndexMetadata(context.Background(), spannerTable, indexName)
+	removeIndexColumns(context.Background(), spannerTable, newColumns)
+}
+
+// RestoreIndexOperations follows the global secondary indexes which are still being built by Spanner
+// when the adapter starts, so that they are reported and rejected for reads as in the instance which
+// started the build. The state of the builds is read from INFORMATION_SCHEMA.INDEXES.
+func RestoreIndexOperations(ctx context.Context) {
+	for spannerTable, tableConf := range models.DbConfigMap {
+		if len(tableConf.Indices) == 0 {
+			continue
+		}
+		indexes, err := storage.GetStorageInstance().SpannerIndexSchemas(ctx, spannerTable)
+		if err != nil {
+			logger.Error("failed to read the index states: ", spannerTable, err)
+			continue
+		}
+		for _, schema := range indexes {
+			if schema.State != spannerIndexStateWriteOnly {
+				continue
+			}
+			for indexName, conf := range tableConf.Indices {
+				if conf.SpannerIndexName == schema.Name {
+					startIndexOperation(spannerTable, schema.Name, indexStatusCreating)
+					go pollIndexCreation(spannerTable, indexName, schema.Name)
+				}
+			}
+		}
+	}
+}
+
+// pollIndexCreation reads the state of an index build started before a restart until the index is
+// readable, an index which disappears has failed to build and is removed from the metadata
+func pollIndexCreation(spannerTable, indexName, spannerIndex string) {
+	defer finishIndexOperation(spannerIndex)
+	for {
+		time.Sleep(indexStatePollInterval)
+		indexes, err := storage.GetStorageInstance().SpannerIndexSchemas(context.Background(), spannerTable)
+		if err != nil {
+			logger.Error("failed to read the index state: ", spannerIndex, err)
+			continue
+		}
+		idx := slices.IndexFunc(indexes, func(schema storage.IndexSchema) bool { return schema.Name == spannerIndex })
+		switch {
+		case idx < 0:
+			logger.Error("index creation failed: ", spannerIndex)
+			tableMetaMux.Lock()
+			removeIndexMetadata(context.Background(), spannerTable, indexName)
+			tableMetaMux.Unlock()
+			return
+		case indexes[idx].State != spannerIndexStateWriteOnly:
+			logger.Info("index created: ", spannerIndex)
+			return
+		}
+	}
+}
+
+// deleteGlobalSecondaryIndex starts dropping the Spanner index, the index is removed from the
+// adapter metadata once the drop has completed
+func deleteGlobalSecondaryIndex(ctx context.Context, spannerTable string, tableConf models.TableConfig, indexName string) error {
+	spannerIndex := tableConf.Indices[indexName].SpannerIndexName
+	op, err := storage.GetStorageInstance().SpannerStartUpdateDDL(ctx, []string{"DROP INDEX `" + spannerIndex + "`"})
+	if err != nil {
+		return errors.New("InternalServerError", err)
+	}
+	startIndexOperation(spannerTable, spannerIndex, indexStatusDeleting)
+	go waitIndexDeletion(spannerTable, indexName, spannerIndex, op)
+	return nil
+}
+
+// waitIndexDeletion waits for the index drop and removes the index from the metadata
+func waitIndexDeletion(spannerTable, indexName, spannerIndex string, op ddlOperation) {
+	defer finishIndexOperation(spannerIndex)
+	err := op.Wait(context.Background())
+	if err != nil && spanner.ErrCode(err) != codes.NotFound {
+		logger.Error("index deletion failed: ", spannerIndex, err)
+		return
+	}
+	logger.Info("index deleted: ", spannerIndex)
+
+	tableMetaMux.Lock()
+	defer tableMetaMux.Unlock()
+	removeIndexMetadata(context.Background(), spannerTable, indexName)
+}
+
+// removeIndexMetadata deletes the index from dynamodb_adapter_index_ddl and the in-memory metadata,
+// the caller must hold tableMetaMux
+func removeIndexMetadata(ctx context.Context, spannerTable, indexName string) {
+	err := storage.GetStorageInstance().SpannerApply(ctx, []*spanner.Mutation{
+		spanner.Delete("dynamodb_adapter_index_ddl", spanner.Key{spannerTable, indexName}),
+	})
+	if err != nil {
+		logger.Error("failed to remove the index metadata: ", spannerTable, indexName, err)
+	}
+	unregisterIndex(spannerTable, indexName)
+}
+
+// removeIndexColumns drops the columns added for an index which could not be built and removes them
+// from dynamodb_adapter_table_ddl and the in-memory metadata, the caller must hold tableMetaMux
+func removeIndexColumns(ctx context.Context, spannerTable string, columns []tableColumn) {
+	if len(columns) == 0 {
+		return
+	}
+	statements := make([]string, 0, len(columns))
+	mutations := make([]*spanner.Mutation, 0, len(columns))
+	for _, col := range columns {
+		statements = append(statements, fmt.Sprintf("ALTER TABLE `%s` DROP COLUMN `%s`", spannerTable, col.Column))
+		mutations = append(mutations, spanner.Delete("dynamodb_adapter_table_ddl", spanner.Key{spannerTable, col.Column}))
+	}
+	if err := storage.GetStorageInstance().SpannerUpdateDDL(ctx, statements); err != nil {
+		logger.Error("failed to drop the index columns: ", spannerTable, err)
+		return
+	}
+	if err := storage.GetStorageInstance().SpannerApply(ctx, mutations); err != nil {
+		logger.Error("failed to remove the index columns metadata: ", spannerTable, err)
+	}
+	unregisterColumns(spannerTable, columns)
+}
+
+// registerIndex adds the index and the new columns of the table to the in-memory metadata,
+// the maps are swapped instead of being updated in place, see registerTable
+func registerIndex(spannerTable, indexName string, conf models.TableConfig, columns []tableColumn) {
+	dbConfigMap := maps.Clone(models.DbConfigMap)
+	tableConf, ok := dbConfigMap[spannerTable]
+	if !ok {
+		return
+	}
+	tableConf.Indices = maps.Clone(tableConf.Indices)
+	if tableConf.Indices == nil {
+		tableConf.Indices = make(map[string]models.TableConfig)
+	}
+	tableConf.Indices[indexName] = conf
+	dbConfigMap[spannerTable] = tableConf
+	models.DbConfigMap = dbConfigMap
+	registerColumns(spannerTable, columns)
+}
+
+// registerColumns adds new columns of the table to the in-memory metadata, see registerTable
+func registerColumns(spannerTable string, columns []tableColumn) {
+	if len(columns) == 0 {
+		return
+	}
+	tableDDL := maps.Clone(models.TableDDL)
+	tableSpannerDDL := maps.Clone(models.TableSpannerDDL)
+	tableColumnMap := maps.Clone(models.TableColumnMap)
+	tableColChangeMap := maps.Clone(models.TableColChangeMap)
+	columnToOriginalCol := maps.Clone(models.ColumnToOriginalCol)
+	originalColResponse := maps.Clone(models.OriginalColResponse)
+
+	tableDDL[spannerTable] = maps.Clone(tableDDL[spannerTable])
+	tableSpannerDDL[spannerTable] = maps.Clone(tableSpannerDDL[spannerTable])
+	tableColumnMap[spannerTable] = slices.Clone(tableColumnMap[spannerTable])
+	for _, col := range columns {
+		tableColumnMap[spannerTable] = append(tableColumnMap[spannerTable], col.Column)
+		tableDDL[spannerTable][col.Column] = col.DynamoDataType
+		tableSpannerDDL[spannerTable][col.Column] = col.SpannerType
+		if col.Column != col.OriginalColumn {
+			tableColChangeMap[spannerTable] = struct{}{}
+			columnToOriginalCol[col.OriginalColumn] = col.Column
+			originalColResponse[col.Column] = col.OriginalColumn
+		}
+	}
+
+	models.TableDDL = tableDDL
+	models.TableSpannerDDL = tableSpannerDDL
+	models.TableColumnMap = tableColumnMap
+	models.TableColChangeMap = tableColChangeMap
+	models.ColumnToOriginalCol = columnToOriginalCol
+	models.OriginalColResponse = originalColResponse
+}
+
+// unregisterIndex removes the index from the in-memory metadata, the columns of the table are kept
+func unregisterIndex(spannerTable, indexName string) {
+	dbConfigMap := maps.Clone(models.DbConfigMap)
+	tableConf, ok := dbConfigMap[spannerTable]
+	if !ok {
+		return
+	}
+	tableConf.Indices = maps.Clone(tableConf.Indices)
+	delete(tableConf.Indices, indexName)
+	dbConfigMap[spannerTable] = tableConf
+	models.DbConfigMap = dbConfigMap
+}
+
+// unregisterColumns removes columns of the table from the in-memory metadata, see unregisterTable
+func unregisterColumns(spannerTable string, columns []tableColumn) {
+	tableDDL := maps.Clone(models.TableDDL)
+	tableSpannerDDL := maps.Clone(models.TableSpannerDDL)
+	tableColumnMap := maps.Clone(models.TableColumnMap)
+	columnToOriginalCol := maps.Clone(models.ColumnToOriginalCol)
+	originalColResponse := maps.Clone(models.OriginalColResponse)
+
+	tableDDL[spannerTable] = maps.Clone(tableDDL[spannerTable])
+	tableSpannerDDL[spannerTable] = maps.Clone(tableSpannerDDL[spannerTable])
+	tableColumnMap[spannerTable] = slices.DeleteFunc(slices.Clone(tableColumnMap[spannerTable]), func(col string) bool {
+		return slices.ContainsFunc(columns, func(c tableColumn) bool { return c.Column == col })
+	})
+	inUse := make(map[string]struct{})
+	for _, cols := range tableColumnMap {
+		for _, col := range cols {
+			inUse[col] = struct{}{}
+		}
+	}
+	for _, col := range columns {
+		delete(tableDDL[spannerTable], col.Column)
+		delete(tableSpannerDDL[spannerTable], col.Column)
+		if _, ok := inUse[col.Column]; ok {
+			continue
+		}
+		if original, ok := originalColResponse[col.Column]; ok {
+			delete(columnToOriginalCol, original)
+			delete(originalColResponse, col.Column)
+		}
+	}
+
+	models.TableDDL = tableDDL
+	models.TableSpannerDDL = tableSpannerDDL
+	models.TableColumnMap = tableColumnMap
+	models.ColumnToOriginalCol = columnToOriginalCol
+	models.OriginalColResponse = originalColResponse
+}
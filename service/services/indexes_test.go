@@ -0,0 +1,176 @@
+// Copyright 2020 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package services
+
+import (
+	"context"
+	"testing"
+
+	"github.com/cloudspannerecosystem/dynamodb-adapter/models"
+	"github.com/cloudspannerecosystem/dynamodb-adapter/pkg/errors"
+	"github.com/googleapis/gax-go/v2"
+	"gopkg.in/go-playground/assert.v1"
+)
+
+type fakeDDLOperation struct {
+	err error
+}
+
+func (op fakeDDLOperation) Wait(ctx context.Context, opts ...gax.CallOption) error {
+	return op.err
+}
+
+func Test_validateUpdateTable(t *testing.T) {
+	tableConf, columns := buildTableMetadata("orders_v1", ordersCreateTableMeta())
+	registerTable("orders_v1", tableConf, columns)
+	defer unregisterTable("orders_v1")
+
+	createIndex := models.GlobalSecondaryIndexUpdate{Create: &models.CreateGlobalSecondaryIndexAction{
+		IndexName:  "by-total",
+		KeySchema:  []models.KeySchemaElement{{AttributeName: "total", KeyType: "HASH"}},
+		Projection: models.Projection{ProjectionType: "ALL"},
+	}}
+	deleteIndex := models.GlobalSecondaryIndexUpdate{Delete: &models.DeleteGlobalSecondaryIndexAction{IndexName: "by-status"}}
+	totalAttr := []models.AttributeDefinition{{AttributeName: "total", AttributeType: "N"}}
+
+	tests := []struct {
+		testName  string
+		meta      models.UpdateTableMeta
+		wantCode  string
+		wantIndex string
+	}{
+		{
+			"create index",
+			models.UpdateTableMeta{AttributeDefinitions: totalAttr, GlobalSecondaryIndexUpdates: []models.GlobalSecondaryIndexUpdate{createIndex}},
+			"",
+			"by-total",
+		},
+		{
+			"delete index",
+			models.UpdateTableMeta{GlobalSecondaryIndexUpdates: []models.GlobalSecondaryIndexUpdate{deleteIndex}},
+			"",
+			"by-status",
+		},
+		{
+			"key attribute not defined",
+			models.UpdateTableMeta{GlobalSecondaryIndexUpdates: []models.GlobalSecondaryIndexUpdate{createIndex}},
+			"ValidationException",
+			"",
+		},
+		{
+			"attribute type mismatch",
+			models.UpdateTableMeta{AttributeDefinitions: []models.AttributeDefinition{{AttributeName: "status", AttributeType: "N"}}},
+			"ValidationException",
+			"",
+		},
+		{
+			"two index changes",
+			models.UpdateTableMeta{AttributeDefinitions: totalAttr, GlobalSecondaryIndexUpdates: []models.GlobalSecondaryIndexUpdate{createIndex, deleteIndex}},
+			"LimitExceededException",
+			"",
+		},
+		{
+			"unknown index",
+			models.UpdateTableMeta{GlobalSecondaryIndexUpdates: []models.GlobalSecondaryIndexUpdate{
+				{Delete: &models.DeleteGlobalSecondaryIndexAction{IndexName: "by-date"}},
+			}},
+			"ResourceNotFoundException",
+			"",
+		},
+	}
+
+	for _, tc := range tests {
+		create, del, err := validateUpdateTable(tc.meta, "orders_v1", models.DbConfigMap["orders_v1"])
+		if tc.wantCode != "" {
+			assert.NotEqual(t, err, nil)
+			assert.Equal(t, err.(*errors.Error).ErrorCode, tc.wantCode)
+			continue
+		}
+		assert.Equal(t, err, nil)
+		switch {
+		case create != nil:
+			assert.Equal(t, create.IndexName, tc.wantIndex)
+		case del != nil:
+			assert.Equal(t, del.IndexName, tc.wantIndex)
+		}
+	}
+}
+
+func Test_registerIndex(t *testing.T) {
+	tableConf, columns := buildTableMetadata("orders_v1", ordersCreateTableMeta())
+	registerTable("orders_v1", tableConf, columns)
+	defer unregisterTable("orders_v1")
+
+	idx := models.SecondaryIndex{
+		IndexName:  "by-total",
+		KeySchema:  []models.KeySchemaElement{{AttributeName: "total", KeyType: "HASH"}},
+		Projection: models.Projection{ProjectionType: "ALL"},
+	}
+	newColumns := []tableColumn{{Column: "total", OriginalColumn: "total", DynamoDataType: "N", SpannerType: "FLOAT64"}}
+	registerIndex("orders_v1", "by-total", buildIndexConf("orders_v1", idx, indexTypeGlobal), newColumns)
+
+	assert.Equal(t, models.DbConfigMap["orders_v1"].Indices["by-total"].SpannerIndexName, "orders_v1_by_total")
+	assert.Equal(t, models.TableDDL["orders_v1"]["total"], "N")
+	assert.Equal(t, models.TableColumnMap["orders_v1"], []string{"customer_id", "order_date", "status", "total"})
+	assert.Equal(t, len(tableConf.Indices), 1)
+
+	unregisterIndex("orders_v1", "by-total")
+	_, ok := models.DbConfigMap["orders_v1"].Indices["by-total"]
+	assert.Equal(t, ok, false)
+	assert.Equal(t, models.TableDDL["orders_v1"]["total"], "N")
+
+	unregisterColumns("orders_v1", newColumns)
+	_, ok = models.TableDDL["orders_v1"]["total"]
+	assert.Equal(t, ok, false)
+	assert.Equal(t, models.TableColumnMap["orders_v1"], []string{"customer_id", "order_date", "status"})
+}
+
+func Test_setIndexStatuses(t *testing.T) {
+	meta := ordersCreateTableMeta()
+	meta.GlobalSecondaryIndexes = append(meta.GlobalSecondaryIndexes, models.SecondaryIndex{
+		IndexName:  "by-date",
+		KeySchema:  []models.KeySchemaElement{{AttributeName: "order-date", KeyType: "HASH"}},
+		Projection: models.Projection{ProjectionType: "KEYS_ONLY"},
+	})
+	tableConf, _ := buildTableMetadata("orders_v1", meta)
+
+	desc := describeTableConf("orders-v1", tableConf)
+	setIndexStatuses(&desc, tableConf, map[string]string{
+		"orders_v1_by_date":   spannerIndexStateWriteOnly,
+		"orders_v1_by_status": spannerIndexStateReadWrite,
+	})
+	assert.Equal(t, desc.GlobalSecondaryIndexes[0].IndexName, "by-date")
+	assert.Equal(t, desc.GlobalSecondaryIndexes[0].IndexStatus, indexStatusCreating)
+	assert.Equal(t, desc.GlobalSecondaryIndexes[0].Backfilling, true)
+	assert.Equal(t, desc.GlobalSecondaryIndexes[1].IndexStatus, tableStatusActive)
+	assert.Equal(t, desc.GlobalSecondaryIndexes[1].Backfilling, false)
+
+	startIndexOperation("orders_v1", "orders_v1_by_status", indexStatusDeleting)
+	defer finishIndexOperation("orders_v1_by_status")
+	desc = describeTableConf("orders-v1", tableConf)
+	setIndexStatuses(&desc, tableConf, nil)
+	assert.Equal(t, desc.GlobalSecondaryIndexes[0].IndexStatus, tableStatusActive)
+	assert.Equal(t, desc.GlobalSecondaryIndexes[1].IndexStatus, indexStatusDeleting)
+}
+
+func Test_waitIndexCreation(t *testing.T) {
+	startIndexOperation("orders_v1", "orders_v1_by_total", indexStatusCreating)
+	assert.Equal(t, tableHasIndexOperation("orders_v1"), true)
+	assert.Equal(t, checkIndexReadable("by-total", "orders_v1_by_total").(*errors.Error).ErrorCode, "ValidationException")
+
+	waitIndexCreation("orders_v1", "by-total", "orders_v1_by_total", nil, fakeDDLOperation{})
+	assert.Equal(t, tableHasIndexOperation("orders_v1"), false)
+	assert.Equal(t, checkIndexReadable("by-total", "orders_v1_by_total"), nil)
+}
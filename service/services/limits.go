@@ -0,0 +1,65 @@
+// Copyright 2020 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package services
+
+import (
+	"github.com/cloudspannerecosystem/dynamodb-adapter/models"
+)
+
+// Defaults used when the endpoint and limits config blocks are not set, they match
+// the values reported by DynamoDB for a new account
+const (
+	defaultEndpointCachePeriod     = 1440
+	defaultAccountMaxCapacityUnits = 80000
+	defaultTableMaxCapacityUnits   = 40000
+)
+
+// DescribeEndpoints returns the address of the adapter used by the SDKs for endpoint discovery.
+// The configured endpoint.address is advertised, falling back to the host the request was sent to.
+func DescribeEndpoints(host string) *models.DescribeEndpointsOutput {
+	endpoint := models.Endpoint{Address: host, CachePeriodInMinutes: defaultEndpointCachePeriod}
+	if models.GlobalConfig != nil {
+		conf := models.GlobalConfig.Endpoint
+		if conf.Address != "" {
+			endpoint.Address = conf.Address
+		}
+		if conf.CachePeriodInMinutes > 0 {
+			endpoint.CachePeriodInMinutes = conf.CachePeriodInMinutes
+		}
+	}
+	return &models.DescribeEndpointsOutput{Endpoints: []models.Endpoint{endpoint}}
+}
+
+// DescribeLimits returns the capacity limits configured in the limits config block. Spanner does
+// not provision capacity per table, the limits are only reported for the clients checking them.
+func DescribeLimits() *models.DescribeLimitsOutput {
+	var conf models.LimitsConfig
+	if models.GlobalConfig != nil {
+		conf = models.GlobalConfig.Limits
+	}
+	return &models.DescribeLimitsOutput{
+		AccountMaxReadCapacityUnits:  limitOrDefault(conf.AccountMaxReadCapacityUnits, defaultAccountMaxCapacityUnits),
+		AccountMaxWriteCapacityUnits: limitOrDefault(conf.AccountMaxWriteCapacityUnits, defaultAccountMaxCapacityUnits),
+		TableMaxReadCapacityUnits:    limitOrDefault(conf.TableMaxReadCapacityUnits, defaultTableMaxCapacityUnits),
+		TableMaxWriteCapacityUnits:   limitOrDefault(conf.TableMaxWriteCapacityUnits, defaultTableMaxCapacityUnits),
+	}
+}
+
+func limitOrDefault(limit, defaultLimit int64) int64 {
+	if limit > 0 {
+		return limit
+	}
+	return defaultLimit
+}
@@ -0,0 +1,56 @@
+// Copyright 2020 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package services
+
+import (
+	"testing"
+
+	"github.com/cloudspannerecosystem/dynamodb-adapter/models"
+	"gopkg.in/go-playground/assert.v1"
+)
+
+func TestDescribeEndpoints(t *testing.T) {
+	globalConfig := models.GlobalConfig
+	defer func() { models.GlobalConfig = globalConfig }()
+
+	models.GlobalConfig = &models.Config{}
+	output := DescribeEndpoints("localhost:9050")
+	assert.Equal(t, output.Endpoints, []models.Endpoint{{Address: "localhost:9050", CachePeriodInMinutes: 1440}})
+
+	models.GlobalConfig = &models.Config{Endpoint: models.EndpointConfig{Address: "dynamodb.internal:443", CachePeriodInMinutes: 60}}
+	output = DescribeEndpoints("localhost:9050")
+	assert.Equal(t, output.Endpoints, []models.Endpoint{{Address: "dynamodb.internal:443", CachePeriodInMinutes: 60}})
+}
+
+func TestDescribeLimits(t *testing.T) {
+	globalConfig := models.GlobalConfig
+	defer func() { models.GlobalConfig = globalConfig }()
+
+	models.GlobalConfig = &models.Config{}
+	assert.Equal(t, *DescribeLimits(), models.DescribeLimitsOutput{
+		AccountMaxReadCapacityUnits:  80000,
+		AccountMaxWriteCapacityUnits: 80000,
+		TableMaxReadCapacityUnits:    40000,
+		TableMaxWriteCapacityUnits:   40000,
+	})
+
+	models.GlobalConfig = &models.Config{Limits: models.LimitsConfig{AccountMaxReadCapacityUnits: 1000, TableMaxWriteCapacityUnits: 500}}
+	assert.Equal(t, *DescribeLimits(), models.DescribeLimitsOutput{
+		AccountMaxReadCapacityUnits:  1000,
+		AccountMaxWriteCapacityUnits: 80000,
+		TableMaxReadCapacityUnits:    40000,
+		TableMaxWriteCapacityUnits:   500,
+	})
+}
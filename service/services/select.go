@@ -0,0 +1,102 @@
+// Copyright 2020 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package services
+
+import (
+	"slices"
+	"strings"
+
+	"github.com/cloudspannerecosystem/dynamodb-adapter/models"
+	"github.com/cloudspannerecosystem/dynamodb-adapter/pkg/errors"
+)
+
+const (
+	selectAllAttributes          = "ALL_ATTRIBUTES"
+	selectAllProjectedAttributes = "ALL_PROJECTED_ATTRIBUTES"
+	selectSpecificAttributes     = "SPECIFIC_ATTRIBUTES"
+	selectCount                  = "COUNT"
+)
+
+// applySelect validates the Select of a Query or a Scan in the same way DynamoDB does and sets the
+// attributes to read accordingly. When Select is not set, it defaults to SPECIFIC_ATTRIBUTES with a
+// ProjectionExpression, to ALL_PROJECTED_ATTRIBUTES for an index and to ALL_ATTRIBUTES otherwise.
+func applySelect(query *models.Query, tableConf models.TableConfig) error {
+	if query.Select == "" {
+		switch {
+		case query.OnlyCount:
+			query.Select = selectCount
+		case query.ProjectionExpression != "":
+			query.Select = selectSpecificAttributes
+		case query.IndexName != "":
+			query.Select = selectAllProjectedAttributes
+		default:
+			query.Select = selectAllAttributes
+		}
+	}
+	indexConf, isIndex := tableConf.Indices[query.IndexName]
+
+	switch query.Select {
+	case selectCount:
+		// the ProjectionExpression is ignored, only the keys are read to paginate
+		query.OnlyCount = true
+	case selectSpecificAttributes:
+		if query.ProjectionExpression == "" {
+			return errors.New("ValidationException", "One or more parameter values were invalid: Select type SPECIFIC_ATTRIBUTES requires a ProjectionExpression")
+		}
+	case selectAllAttributes:
+		if query.ProjectionExpression != "" {
+			return errors.New("ValidationException", "Cannot specify the ProjectionExpression when choosing to get ALL_ATTRIBUTES")
+		}
+		// a local secondary index fetches the other attributes from the table, a global one cannot
+		if isIndex && indexConf.IndexType == indexTypeGlobal && !projectsAllAttributes(indexConf) {
+			return errors.New("ValidationException", "One or more parameter values were invalid: Select type ALL_ATTRIBUTES is not supported for global secondary index "+query.IndexName+" because its projection type is not ALL")
+		}
+	case selectAllProjectedAttributes:
+		if query.IndexName == "" {
+			return errors.New("ValidationException", "ALL_PROJECTED_ATTRIBUTES can be used only when Querying using an IndexName")
+		}
+		if query.ProjectionExpression != "" {
+			return errors.New("ValidationException", "Cannot specify the ProjectionExpression when choosing to get ALL_PROJECTED_ATTRIBUTES")
+		}
+		if isIndex && !projectsAllAttributes(indexConf) {
+			query.ProjectionExpression = strings.Join(projectedColumns(tableConf, indexConf), ", ")
+		}
+	default:
+		return errors.New("ValidationException", "1 validation error detected: Value '"+query.Select+"' at 'select' failed to satisfy constraint: Member must satisfy enum value set: [SPECIFIC_ATTRIBUTES, COUNT, ALL_ATTRIBUTES, ALL_PROJECTED_ATTRIBUTES]")
+	}
+	return nil
+}
+
+// projectsAllAttributes checks whether the index holds all the attributes of the items, the
+// indexes configured before the projection type was recorded are treated as ALL
+func projectsAllAttributes(indexConf models.TableConfig) bool {
+	return indexConf.ProjectionType == "" || indexConf.ProjectionType == "ALL"
+}
+
+// projectedColumns returns the columns held by an index with a KEYS_ONLY or INCLUDE projection:
+// the keys of the table and of the index, followed by the NonKeyAttributes
+func projectedColumns(tableConf, indexConf models.TableConfig) []string {
+	cols := paginationKeys(tableConf.PartitionKey, tableConf.SortKey, indexConf.PartitionKey, indexConf.SortKey)
+	if indexConf.ProjectionType != "INCLUDE" {
+		return cols
+	}
+	for _, attr := range indexConf.NonKeyAttributes {
+		col := spannerColumnName(attr)
+		if !slices.Contains(cols, col) {
+			cols = append(cols, col)
+		}
+	}
+	return cols
+}
@@ -0,0 +1,72 @@
+// Copyright 2020 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package services
+
+import (
+	"testing"
+
+	"github.com/cloudspannerecosystem/dynamodb-adapter/models"
+	"github.com/cloudspannerecosystem/dynamodb-adapter/pkg/errors"
+	"gopkg.in/go-playground/assert.v1"
+)
+
+func Test_applySelect(t *testing.T) {
+	tableConf := models.TableConfig{
+		PartitionKey: "customer_id",
+		SortKey:      "order_date",
+		Indices: map[string]models.TableConfig{
+			"by-status": {PartitionKey: "status", IndexType: indexTypeGlobal, ProjectionType: "INCLUDE", NonKeyAttributes: []string{"total-amount"}},
+			"by-date":   {PartitionKey: "customer_id", SortKey: "created", IndexType: indexTypeLocal, ProjectionType: "KEYS_ONLY"},
+			"legacy":    {PartitionKey: "status"},
+		},
+	}
+
+	tests := []struct {
+		testName       string
+		query          models.Query
+		wantCode       string
+		wantSelect     string
+		wantProjection string
+	}{
+		{"default for a table", models.Query{}, "", selectAllAttributes, ""},
+		{"default with ProjectionExpression", models.Query{ProjectionExpression: "status"}, "", selectSpecificAttributes, "status"},
+		{"default for an index", models.Query{IndexName: "by-status"}, "", selectAllProjectedAttributes, "status, customer_id, order_date, total_amount"},
+		{"OnlyCount", models.Query{OnlyCount: true}, "", selectCount, ""},
+		{"COUNT ignores ProjectionExpression", models.Query{Select: selectCount, ProjectionExpression: "status"}, "", selectCount, "status"},
+		{"SPECIFIC_ATTRIBUTES without ProjectionExpression", models.Query{Select: selectSpecificAttributes}, "ValidationException", "", ""},
+		{"ALL_ATTRIBUTES with ProjectionExpression", models.Query{Select: selectAllAttributes, ProjectionExpression: "status"}, "ValidationException", "", ""},
+		{"ALL_ATTRIBUTES on a global index", models.Query{Select: selectAllAttributes, IndexName: "by-status"}, "ValidationException", "", ""},
+		{"ALL_ATTRIBUTES on a local index", models.Query{Select: selectAllAttributes, IndexName: "by-date"}, "", selectAllAttributes, ""},
+		{"ALL_PROJECTED_ATTRIBUTES without IndexName", models.Query{Select: selectAllProjectedAttributes}, "ValidationException", "", ""},
+		{"ALL_PROJECTED_ATTRIBUTES with ProjectionExpression", models.Query{Select: selectAllProjectedAttributes, IndexName: "by-date", ProjectionExpression: "status"}, "ValidationException", "", ""},
+		{"ALL_PROJECTED_ATTRIBUTES on a KEYS_ONLY index", models.Query{Select: selectAllProjectedAttributes, IndexName: "by-date"}, "", selectAllProjectedAttributes, "customer_id, created, order_date"},
+		{"ALL_PROJECTED_ATTRIBUTES on an index without projection type", models.Query{Select: selectAllProjectedAttributes, IndexName: "legacy"}, "", selectAllProjectedAttributes, ""},
+		{"unknown Select", models.Query{Select: "ALL"}, "ValidationException", "", ""},
+	}
+
+	for _, tc := range tests {
+		query := tc.query
+		err := applySelect(&query, tableConf)
+		if tc.wantCode != "" {
+			assert.NotEqual(t, err, nil)
+			assert.Equal(t, err.(*errors.Error).ErrorCode, tc.wantCode)
+			continue
+		}
+		assert.Equal(t, err, nil)
+		assert.Equal(t, query.Select, tc.wantSelect)
+		assert.Equal(t, query.ProjectionExpression, tc.wantProjection)
+		assert.Equal(t, query.OnlyCount, tc.wantSelect == selectCount)
+	}
+}
@@ -19,10 +19,13 @@ import (
 	"context"
 	"fmt"
 	"hash/fnv"
+	"maps"
 	"regexp"
+	"slices"
 	"strconv"
 	"strings"
 	"sync"
+	"time"
 
 	"cloud.google.com/go/spanner"
 	"github.com/ahmetb/go-linq"
@@ -30,6 +33,7 @@ import (
 	"github.com/cloudspannerecosystem/dynamodb-adapter/config"
 	"github.com/cloudspannerecosystem/dynamodb-adapter/models"
 	"github.com/cloudspannerecosystem/dynamodb-adapter/pkg/errors"
+	"github.com/cloudspannerecosystem/dynamodb-adapter/pkg/expression"
 	"github.com/cloudspannerecosystem/dynamodb-adapter/pkg/logger"
 	"github.com/cloudspannerecosystem/dynamodb-adapter/storage"
 	translator "github.com/cloudspannerecosystem/dynamodb-adapter/translator/utils"
@@ -38,22 +42,18 @@ import (
 
 type Storage interface {
 	GetSpannerClient() (*spanner.Client, error)
-	SpannerTransactGetItems(ctx context.Context, tableProjectionCols map[string][]string, pValues map[string]interface{}, sValues map[string]interface{}) ([]map[string]interface{}, error)
+	SpannerTransactGetItems(ctx context.Context, gets []storage.TransactGet) ([]map[string]interface{}, error)
 	SpannerTransactWritePut(ctx context.Context, table string, m map[string]interface{}, eval *models.Eval, expr *models.UpdateExpressionCondition, txn *spanner.ReadWriteTransaction, oldRes map[string]interface{}) (map[string]interface{}, *spanner.Mutation, error)
 	SpannerGet(ctx context.Context, tableName string, pKeys, sKeys interface{}, projectionCols []string) (map[string]interface{}, map[string]interface{}, error)
 	TransactWriteSpannerDel(ctx context.Context, table string, m map[string]interface{}, eval *models.Eval, expr *models.UpdateExpressionCondition, txn *spanner.ReadWriteTransaction) (*spanner.Mutation, error)
-	TransactWriteSpannerAdd(ctx context.Context, table string, n map[string]interface{}, eval *models.Eval, expr *models.UpdateExpressionCondition, txn *spanner.ReadWriteTransaction) (map[string]interface{}, *spanner.Mutation, error)
-	TransactWriteSpannerRemove(ctx context.Context, table string, m map[string]interface{}, eval *models.Eval, expr *models.UpdateExpressionCondition, colsToRemove []string, txn *spanner.ReadWriteTransaction) (*spanner.Mutation, error)
 }
 type Service interface {
 	MayIReadOrWrite(tableName string, isWrite bool, user string) bool
-	TransactGetItem(ctx context.Context, tableProjectionCols map[string][]string, pValues map[string]interface{}, sValues map[string]interface{}) ([]map[string]interface{}, error)
-	TransactGetProjectionCols(ctx context.Context, transactGetMeta models.GetItemRequest) ([]string, []interface{}, []interface{}, error)
-	TransactWritePut(ctx context.Context, tableName string, putObj map[string]interface{}, expr *models.UpdateExpressionCondition, conditionExp string, expressionAttr, oldRes map[string]interface{}, txn *spanner.ReadWriteTransaction) (map[string]interface{}, *spanner.Mutation, error)
-	TransactWriteDel(ctx context.Context, tableName string, attrMap map[string]interface{}, condExpression string, expressionAttr map[string]interface{}, expr *models.UpdateExpressionCondition, txn *spanner.ReadWriteTransaction) (map[string]interface{}, *spanner.Mutation, error)
-	TransactWriteAdd(ctx context.Context, tableName string, attrMap map[string]interface{}, condExpression string, m, expressionAttr map[string]interface{}, expr *models.UpdateExpressionCondition, oldRes map[string]interface{}, txn *spanner.ReadWriteTransaction) (map[string]interface{}, *spanner.Mutation, error)
-	TransactWriteRemove(ctx context.Context, tableName string, updateAttr models.UpdateAttr, actionValue string, expr *models.UpdateExpressionCondition, oldRes map[string]interface{}, txn *spanner.ReadWriteTransaction) (map[string]interface{}, *spanner.Mutation, error)
+	TransactGetItems(ctx context.Context, gets []models.GetItemMeta) ([]map[string]interface{}, error)
+	TransactWritePut(ctx context.Context, tableName string, putObj map[string]interface{}, expr *models.UpdateExpressionCondition, conditionExp string, expressionAttributeNames map[string]string, expressionAttr, oldRes map[string]interface{}, txn *spanner.ReadWriteTransaction) (map[string]interface{}, *spanner.Mutation, error)
+	TransactWriteDel(ctx context.Context, tableName string, attrMap map[string]interface{}, condExpression string, expressionAttributeNames map[string]string, expressionAttr map[string]interface{}, expr *models.UpdateExpressionCondition, txn *spanner.ReadWriteTransaction) (map[string]interface{}, *spanner.Mutation, error)
 	GetWithProjection(ctx context.Context, tableName string, primaryKeyMap map[string]interface{}, projectionExpression string, expressionAttributeNames map[string]string) (map[string]interface{}, map[string]interface{}, error)
+	ReadWriteTransaction(ctx context.Context, f func(ctx context.Context, txn *spanner.ReadWriteTransaction) error) error
 }
 
 type spannerService struct {
@@ -95,6 +95,10 @@ func (s *spannerService) MayIReadOrWrite(table string, IsMutation bool, operatio
 
 const (
 	regexPattern = `^[a-zA-Z_][a-zA-Z0-9_.]*(\.[a-zA-Z_][a-zA-Z0-9_.]*)+\s*=\s*@\w+$`
+	// maxScanSegments is the maximum TotalSegments of a parallel Scan accepted by DynamoDB
+	maxScanSegments = 1000000
+	// maxPageBytes is the size of the items after which a Query or a Scan page stops, as in DynamoDB
+	maxPageBytes = 1 << 20
 )
 
 var (
@@ -132,14 +136,14 @@ func getSpannerProjections(projectionExpression, table string, expressionAttribu
 }
 
 // Put writes an object to Spanner
-func Put(ctx context.Context, tableName string, putObj map[string]interface{}, expr *models.UpdateExpressionCondition, conditionExp string, expressionAttr, oldRes map[string]interface{}, spannerRow map[string]interface{}) (map[string]interface{}, error) {
+func Put(ctx context.Context, tableName string, putObj map[string]interface{}, expr *models.UpdateExpressionCondition, conditionExp string, expressionAttributeNames map[string]string, expressionAttr, oldRes map[string]interface{}, spannerRow map[string]interface{}) (map[string]interface{}, error) {
 	tableConf, err := config.GetTableConf(tableName)
 	if err != nil {
 		return nil, err
 	}
 
 	tableName = tableConf.ActualTable
-	e, err := utils.CreateConditionExpression(conditionExp, expressionAttr)
+	e, err := utils.CreateConditionExpression(conditionExp, expressionAttributeNames, expressionAttr)
 	if err != nil {
 		return nil, err
 	}
@@ -161,65 +165,6 @@ func Put(ctx context.Context, tableName string, putObj map[string]interface{}, e
 	return updateResp, nil
 }
 
-// Add checks the expression for converting the data
-func Add(ctx context.Context, tableName string, attrMap map[string]interface{}, condExpression string, m, expressionAttr map[string]interface{}, expr *models.UpdateExpressionCondition, oldRes map[string]interface{}) (map[string]interface{}, error) {
-	tableConf, err := config.GetTableConf(tableName)
-	if err != nil {
-		return nil, err
-	}
-	tableName = tableConf.ActualTable
-
-	e, err := utils.CreateConditionExpression(condExpression, expressionAttr)
-	if err != nil {
-		return nil, err
-	}
-
-	newResp, err := storage.GetStorageInstance().SpannerAdd(ctx, tableName, m, e, expr)
-	if err != nil {
-		return nil, err
-	}
-	if oldRes == nil {
-		return newResp, nil
-	}
-	updateResp := map[string]interface{}{}
-	for k, v := range oldRes {
-		updateResp[k] = v
-	}
-	for k, v := range newResp {
-		updateResp[k] = v
-	}
-
-	return updateResp, nil
-}
-
-// Del checks the expression for saving the data
-func Del(ctx context.Context, tableName string, attrMap map[string]interface{}, condExpression string, expressionAttr map[string]interface{}, expr *models.UpdateExpressionCondition) (map[string]interface{}, error) {
-	logger.Debug(expressionAttr)
-	tableConf, err := config.GetTableConf(tableName)
-	if err != nil {
-		return nil, err
-	}
-
-	tableName = tableConf.ActualTable
-
-	e, err := utils.CreateConditionExpression(condExpression, expressionAttr)
-	if err != nil {
-		return nil, err
-	}
-
-	err = storage.GetStorageInstance().SpannerDel(ctx, tableName, expressionAttr, e, expr)
-	if err != nil {
-		return nil, err
-	}
-	sKey := tableConf.SortKey
-	pKey := tableConf.PartitionKey
-	res, _, err := storage.GetStorageInstance().SpannerGet(ctx, tableName, attrMap[pKey], attrMap[sKey], nil)
-	if err != nil {
-		return nil, err
-	}
-	return res, nil
-}
-
 // BatchGet for batch operation for getting data
 func BatchGet(ctx context.Context, tableName string, keyMapArray []map[string]interface{}) ([]map[string]interface{}, error) {
 	if len(keyMapArray) == 0 {
@@ -245,23 +190,6 @@ func BatchGet(ctx context.Context, tableName string, keyMapArray []map[string]in
 	return storage.GetStorageInstance().SpannerBatchGet(ctx, tableName, pValues, sValues, nil)
 }
 
-// BatchPut writes bulk records to Spanner
-func BatchPut(ctx context.Context, tableName string, arrAttrMap []map[string]interface{}, spannerRow []map[string]interface{}) error {
-	if len(arrAttrMap) <= 0 {
-		return errors.New("ValidationException")
-	}
-	tableConf, err := config.GetTableConf(tableName)
-	if err != nil {
-		return err
-	}
-	tableName = tableConf.ActualTable
-	err = storage.GetStorageInstance().SpannerBatchPut(ctx, tableName, arrAttrMap, spannerRow)
-	if err != nil {
-		return err
-	}
-	return nil
-}
-
 // GetWithProjection get table data with projection
 func (s *spannerService) GetWithProjection(ctx context.Context, tableName string, primaryKeyMap map[string]interface{}, projectionExpression string, expressionAttributeNames map[string]string) (map[string]interface{}, map[string]interface{}, error) {
 	if primaryKeyMap == nil {
@@ -289,13 +217,25 @@ func QueryAttributes(ctx context.Context, query models.Query) (map[string]interf
 	if err != nil {
 		return nil, "", err
 	}
+	if err := applySelect(&query, tableConf); err != nil {
+		return nil, "", err
+	}
 	var sKey string
 	var pKey string
 	tPKey := tableConf.PartitionKey
 	tSKey := tableConf.SortKey
 	if query.IndexName != "" {
 		conf := tableConf.Indices[query.IndexName]
+		if err := checkIndexReadable(query.IndexName, conf.SpannerIndexName); err != nil {
+			return nil, "", err
+		}
+		if query.ConsistentRead && conf.IndexType == indexTypeGlobal {
+			return nil, "", errors.New("ValidationException", "Consistent reads are not supported on global secondary indexes")
+		}
 		query.IndexName = strings.Replace(query.IndexName, "-", "_", -1)
+		if conf.SpannerIndexName != "" {
+			query.IndexName = conf.SpannerIndexName
+		}
 
 		if tableConf.ActualTable != query.TableName {
 			query.TableName = tableConf.ActualTable
@@ -312,134 +252,163 @@ func QueryAttributes(ctx context.Context, query models.Query) (map[string]interf
 		sKey = tSKey
 	}
 
+	keys := paginationKeys(tPKey, tSKey, pKey, sKey)
+	if sKey == "" {
+		// ScanIndexForward only applies to the sort key, the items are otherwise in ascending key order
+		query.SortAscending = true
+	}
 	originalLimit := query.Limit
 	query.Limit = originalLimit + 1
 
-	stmt, cols, isCountQuery, offset, hash, err := createSpannerQuery(&query, tPKey, pKey, sKey)
+	stmt, cols, hash, err := createSpannerQuery(&query, tPKey, sKey, keys)
 	if err != nil {
 		return nil, hash, err
 	}
 	logger.Debug(stmt)
-	resp, err := storage.GetStorageInstance().ExecuteSpannerQuery(ctx, query.TableName, cols, isCountQuery, stmt)
+	ctx = storage.WithConsistentRead(ctx, query.ConsistentRead)
+	// the Limit applies to the evaluated items, the items filtered out by the FilterExpression are counted
+	page, err := storage.GetStorageInstance().SpannerQueryPage(ctx, query.TableName, stmt, originalLimit, maxPageBytes, queryFilter(&query, cols))
 	if err != nil {
 		return nil, hash, err
 	}
-	if isCountQuery {
-		return resp[0], hash, nil
+	finalResp := map[string]interface{}{
+		"Count":            len(page.Items),
+		"ScannedCount":     page.ScannedCount,
+		"Items":            page.Items,
+		"LastEvaluatedKey": nil,
 	}
-	finalResp := make(map[string]interface{})
-	length := len(resp)
-	if length == 0 {
-		finalResp["Count"] = 0
+	if query.OnlyCount {
 		finalResp["Items"] = []map[string]interface{}{}
-		finalResp["LastEvaluatedKey"] = nil
-		return finalResp, hash, nil
-	}
-	if int64(length) > originalLimit {
-		finalResp["Count"] = length - 1
-		last := resp[length-2]
-		if sKey != "" {
-			finalResp["LastEvaluatedKey"] = map[string]interface{}{"offset": originalLimit + offset, pKey: last[pKey], tPKey: last[tPKey], sKey: last[sKey], tSKey: last[tSKey]}
-		} else {
-			finalResp["LastEvaluatedKey"] = map[string]interface{}{"offset": originalLimit + offset, pKey: last[pKey], tPKey: last[tPKey]}
-		}
-		finalResp["Items"] = resp[:length-1]
-	} else {
-		if query.StartFrom != nil && length-1 == 1 {
-			finalResp["Items"] = resp
-		} else {
-			finalResp["Items"] = resp
-		}
-		finalResp["Count"] = length
-		finalResp["Items"] = resp
-		finalResp["LastEvaluatedKey"] = nil
+	}
+	if page.LastEvaluated != nil {
+		finalResp["LastEvaluatedKey"] = lastEvaluatedKey(page.LastEvaluated, keys)
 	}
 	return finalResp, hash, nil
 }
 
-func createSpannerQuery(query *models.Query, tPkey, pKey, sKey string) (spanner.Statement, []string, bool, int64, string, error) {
+// paginationKeys returns the columns identifying an item in the order of the table or the index: the index
+// keys followed by the table keys, which are the attributes of the LastEvaluatedKey returned by DynamoDB
+func paginationKeys(tPKey, tSKey, pKey, sKey string) []string {
+	keys := make([]string, 0, 4)
+	for _, key := range []string{pKey, sKey, tPKey, tSKey} {
+		if key != "" && !slices.Contains(keys, key) {
+			keys = append(keys, key)
+		}
+	}
+	return keys
+}
+
+// lastEvaluatedKey returns the key attributes of the last item of a page
+func lastEvaluatedKey(last map[string]interface{}, keys []string) map[string]interface{} {
+	lastKey := make(map[string]interface{}, len(keys))
+	for _, key := range keys {
+		lastKey[key] = last[key]
+	}
+	return lastKey
+}
+
+// queryFilter returns the filter evaluated on the items read, the columns only selected for the
+// filter are removed from the items returned
+func queryFilter(query *models.Query, cols []string) *storage.QueryFilter {
+	if query.Filter == nil {
+		return nil
+	}
+	filter := &storage.QueryFilter{Expr: query.Filter}
+	if query.OnlyCount || query.ProjectionExpression != "" {
+		filter.Projection = cols
+	}
+	return filter
+}
+
+func createSpannerQuery(query *models.Query, tPKey, sKey string, keys []string) (spanner.Statement, []string, string, error) {
 	stmt := spanner.Statement{}
-	cols, colstr, isCountQuery, err := parseSpannerColumns(query, tPkey, pKey, sKey)
+	if err := parseQueryFilter(query, keys[0], sKey); err != nil {
+		return stmt, nil, "", err
+	}
+	filter, filterParams := parseSpannerFilter(query)
+	cols, colstr, err := parseSpannerColumns(query, keys)
 	if err != nil {
-		return stmt, cols, isCountQuery, 0, "", err
+		return stmt, cols, "", err
 	}
 	tableName := parseSpannerTableName(query)
-	whereCondition, m, err := parseSpannerCondition(query, pKey, sKey)
+	whereCondition, m, err := parseSpannerCondition(query, keys[0], sKey)
 	if err != nil {
-		return stmt, cols, isCountQuery, 0, "", err
+		return stmt, cols, "", err
 	}
+	maps.Copy(m, filterParams)
+	startKeyCondition, err := parseExclusiveStartKey(query, keys, m)
+	if err != nil {
+		return stmt, cols, "", err
+	}
+	// the expired items are excluded in the WHERE clause, so that they do not use up the items read for the Limit
+	notExpired := storage.NotExpiredCondition(utils.ChangeTableNameForSpanner(query.TableName), time.Now(), m)
+	whereCondition = addWhereConditions(whereCondition, startKeyCondition, parseSegment(query, tPKey, m), notExpired)
 	logger.Debug("whereCondition: ", whereCondition)
-	offsetString, offset := parseOffset(query)
-	orderBy := parseSpannerSorting(query, isCountQuery, pKey, sKey)
-	limitClause := parseLimit(query, isCountQuery)
-	finalQuery := "SELECT " + colstr + " FROM " + tableName + " " + whereCondition + orderBy + limitClause + offsetString
+	orderBy := parseSpannerSorting(query, keys)
+	limitClause := parseLimit(query)
+	finalQuery := "SELECT " + colstr + " FROM " + tableName + " " + whereCondition + orderBy + limitClause
+	if filter != "" {
+		// the filter is selected instead of being part of the WHERE clause, as the items filtered out
+		// still count towards the Limit and the ScannedCount of the page
+		finalQuery = "SELECT " + filterColumns(query, cols, keys) + " FROM (SELECT " + colstr + ", (" + filter + ") AS " + storage.QueryFilterColumn +
+			" FROM " + tableName + " " + whereCondition + orderBy + limitClause + ")" + orderBy
+	}
 	stmt.SQL = finalQuery
 	h := fnv.New64a()
 	h.Write([]byte(finalQuery))
 	val := h.Sum64()
 	rs := strconv.FormatUint(val, 10)
 	stmt.Params = m
-	return stmt, cols, isCountQuery, offset, rs, nil
+	return stmt, cols, rs, nil
 }
 
-func parseSpannerColumns(query *models.Query, tPkey, pKey, sKey string) ([]string, string, bool, error) {
+func parseSpannerColumns(query *models.Query, keys []string) ([]string, string, error) {
 	if query == nil {
-		return []string{}, "", false, errors.New("Query is not present")
+		return []string{}, "", errors.New("Query is not present")
 	}
 	colStr := ""
-	if query.OnlyCount {
-		return []string{"count"}, "COUNT(" + pKey + ") AS count", true, nil
-	}
 	table := utils.ChangeTableNameForSpanner(query.TableName)
 	var cols []string
-	if query.ProjectionExpression != "" {
+	if query.OnlyCount {
+		// the items are still read to apply the Limit and build the LastEvaluatedKey, the keys are enough
+		cols = slices.Clone(keys)
+	} else if query.ProjectionExpression != "" {
 		cols = getSpannerProjections(query.ProjectionExpression, query.TableName, query.ExpressionAttributeNames)
-		insertPKey := true
-		for i := 0; i < len(cols); i++ {
-			if cols[i] == pKey {
-				insertPKey = false
-				break
+		// the keys are always read to build the LastEvaluatedKey
+		for _, key := range keys {
+			if !slices.Contains(cols, key) {
+				cols = append(cols, key)
 			}
 		}
-		if insertPKey {
-			cols = append(cols, pKey)
-		}
-		if sKey != "" {
-			insertSKey := true
-			for i := 0; i < len(cols); i++ {
-				if cols[i] == sKey {
-					insertSKey = false
-					break
-				}
-			}
-			if insertSKey {
-				cols = append(cols, sKey)
-			}
-		}
-		if tPkey != pKey {
-			insertSKey := true
-			for i := 0; i < len(cols); i++ {
-				if cols[i] == tPkey {
-					insertSKey = false
-					break
-				}
-			}
-			if insertSKey {
-				cols = append(cols, tPkey)
-			}
-		}
-
 	} else {
 		cols = models.TableColumnMap[table]
 	}
-	for i := 0; i < len(cols); i++ {
-		if cols[i] == "commit_timestamp" {
+	selected := selectColumns(query, cols)
+	for i := 0; i < len(selected); i++ {
+		if selected[i] == "commit_timestamp" {
 			continue
 		}
-		colStr += table + ".`" + cols[i] + "`,"
+		colStr += table + ".`" + selected[i] + "`,"
 	}
 	colStr = strings.Trim(colStr, ",")
-	return cols, colStr, false, nil
+	return cols, colStr, nil
+}
+
+// selectColumns returns the columns selected by the query: the columns returned and, when the query
+// has a projection, the attributes of the filter which are read to evaluate it but are not returned
+func selectColumns(query *models.Query, cols []string) []string {
+	if query.Filter == nil || (!query.OnlyCount && query.ProjectionExpression == "") {
+		return cols
+	}
+	table := utils.ChangeTableNameForSpanner(query.TableName)
+	selected := slices.Clone(cols)
+	for _, path := range expression.Paths(query.Filter.Cond) {
+		col := path.Attribute()
+		if slices.Contains(models.TableColumnMap[table], col) && !slices.Contains(selected, col) {
+			selected = append(selected, col)
+		}
+	}
+	return selected
 }
 
 func parseSpannerTableName(query *models.Query) string {
@@ -452,7 +421,7 @@ func parseSpannerTableName(query *models.Query) string {
 
 // parseSpannerCondition builds the WHERE clause and parameter map for a Spanner SQL query from a DynamoDB Query object.
 //
-// It processes the RangeExp, FilterExp, and KeyConditions fields of the query, applying each to the WHERE clause and
+// It processes the RangeExp and KeyConditions fields of the query, applying each to the WHERE clause and
 // collecting parameters as needed. If no conditions are present, returns an empty WHERE clause.
 //
 // Parameters:
@@ -477,11 +446,6 @@ func parseSpannerCondition(query *models.Query, pKey, sKey string) (string, map[
 		whereClause, query.RangeExp = createWhereClause(whereClause, query.RangeExp, "rangeExp", query.RangeValMap, params)
 	}
 
-	// Parse FilterExpression
-	if query.FilterExp != "" {
-		whereClause, query.FilterExp = createWhereClause(whereClause, query.FilterExp, "filterExp", query.RangeValMap, params)
-	}
-
 	// Parse KeyConditions
 	if len(query.KeyConditions) > 0 {
 		var err error
@@ -497,6 +461,39 @@ func parseSpannerCondition(query *models.Query, pKey, sKey string) (string, map[
 	return whereClause, params, nil
 }
 
+// parseQueryFilter parses the FilterExpression of a Query or a Scan into query.Filter. The filter of a
+// Query can not use the key attributes, which are matched by the key condition.
+func parseQueryFilter(query *models.Query, pKey, sKey string) error {
+	if query == nil || strings.TrimSpace(query.FilterExp) == "" {
+		return nil
+	}
+	filter, err := expression.ParseCondition("FilterExpression", query.FilterExp, query.ExpressionAttributeNames, query.RangeValMap)
+	if err != nil {
+		return errors.New("ValidationException", err)
+	}
+	if query.RangeExp != "" || len(query.KeyConditions) > 0 {
+		for _, path := range expression.Paths(filter.Cond) {
+			if attr := path.Attribute(); attr == pKey || (sKey != "" && attr == sKey) {
+				return errors.New("ValidationException", "Filter Expression can only contain non-primary key attributes: Primary key attribute: "+attr)
+			}
+		}
+	}
+	query.Filter = filter
+	return nil
+}
+
+// parseSpannerFilter translates the FilterExpression into a SQL condition and its parameters, see
+// translateFilter. query.Filter is left with the conditions which are evaluated on the items read.
+func parseSpannerFilter(query *models.Query) (string, map[string]interface{}) {
+	if query == nil || query.Filter == nil {
+		return "", nil
+	}
+	params := map[string]interface{}{}
+	filter, residual := translateFilter(utils.ChangeTableNameForSpanner(query.TableName), query.Filter, params)
+	query.Filter = residual
+	return filter, params
+}
+
 func createWhereClause(whereClause string, expression string, queryVar string, RangeValueMap map[string]interface{}, params map[string]interface{}) (string, string) {
 	_, _, expression = utils.ParseBeginsWith(expression)
 	expression = strings.ReplaceAll(expression, "begins_with", "STARTS_WITH")
@@ -683,94 +680,106 @@ func addAndIfNeeded(where string) string {
 	return where
 }
 
-func parseOffset(query *models.Query) (string, int64) { // TODO: Support timestamp and big.rat
-	logger.Debug(query)
-	if query.StartFrom != nil {
-		offset, ok := query.StartFrom["offset"].(float64)
-		if ok {
-			return " OFFSET " + strconv.FormatInt(int64(offset), 10), int64(offset)
-		}
+// parseExclusiveStartKey builds the keyset condition resuming a Query or a Scan after its ExclusiveStartKey,
+// following the sort order. Spanner does not support row value comparisons, so (k1, k2) > (@k1, @k2) is
+// expanded to (k1 > @k1) OR (k1 = @k1 AND k2 > @k2). The parameters are added to params.
+func parseExclusiveStartKey(query *models.Query, keys []string, params map[string]interface{}) (string, error) {
+	if len(query.StartFrom) == 0 {
+		return "", nil
 	}
-	return "", 0
-}
-
-func parseSpannerSorting(query *models.Query, isCountQuery bool, pKey, sKey string) string {
-	if isCountQuery {
-		return " "
+	if len(query.StartFrom) != len(keys) {
+		return "", errors.New("ValidationException", "The provided starting key is invalid: The provided key element does not match the schema")
 	}
-	if sKey == "" {
-		return " "
-	}
-
+	op := " < @"
 	if query.SortAscending {
-		return " ORDER BY " + sKey + " ASC "
+		op = " > @"
+	}
+	clauses := make([]string, 0, len(keys))
+	equal := make([]string, 0, len(keys))
+	for i, key := range keys {
+		value, ok := query.StartFrom[key]
+		if !ok || value == nil {
+			return "", errors.New("ValidationException", "The provided starting key is invalid: The provided key element does not match the schema")
+		}
+		param := "startKey" + strconv.Itoa(i+1)
+		params[param] = value
+		clause := append(slices.Clone(equal), key+op+param)
+		clauses = append(clauses, "("+strings.Join(clause, " AND ")+")")
+		equal = append(equal, key+" = @"+param)
 	}
-	return " ORDER BY " + sKey + " DESC "
+	return strings.Join(clauses, " OR "), nil
 }
 
-func parseLimit(query *models.Query, isCountQuery bool) string {
-	if isCountQuery {
+// parseSegment builds the condition selecting the items of one segment of a parallel Scan. The items are
+// assigned to the segments by a hash of their partition key, so that the segments are disjoint and complete.
+// The parameters are added to params.
+func parseSegment(query *models.Query, tPKey string, params map[string]interface{}) string {
+	if query.TotalSegments <= 1 {
 		return ""
 	}
-	if query.Limit == 0 {
-		return " LIMIT 5000 "
+	hash := "FARM_FINGERPRINT(CAST(" + tPKey + " AS STRING))"
+	switch models.TableDDL[utils.ChangeTableNameForSpanner(query.TableName)][tPKey] {
+	case "S", "B":
+		hash = "FARM_FINGERPRINT(" + tPKey + ")"
 	}
-	return " LIMIT " + strconv.FormatInt(query.Limit, 10)
+	params["segment"] = query.Segment
+	params["totalSegments"] = query.TotalSegments
+	// the fingerprint can be negative, MOD keeps the sign of the dividend
+	return "MOD(MOD(" + hash + ", @totalSegments) + @totalSegments, @totalSegments) = @segment"
 }
 
-// BatchGetWithProjection from Spanner
-func BatchGetWithProjection(ctx context.Context, tableName string, keyMapArray []map[string]interface{}, projectionExpression string, expressionAttributeNames map[string]string) ([]map[string]interface{}, error) {
-	if len(keyMapArray) == 0 {
-		var resp = make([]map[string]interface{}, 0)
-		return resp, nil
-	}
-	tableConf, err := config.GetTableConf(tableName)
-	if err != nil {
-		return nil, err
-	}
-	tableName = tableConf.ActualTable
-
-	projectionCols := getSpannerProjections(projectionExpression, tableName, expressionAttributeNames)
-	var pValues []interface{}
-	var sValues []interface{}
-	for i := 0; i < len(keyMapArray); i++ {
-		pValue := keyMapArray[i][tableConf.PartitionKey]
-		if tableConf.SortKey != "" {
-			sValue := keyMapArray[i][tableConf.SortKey]
-			sValues = append(sValues, sValue)
+// addWhereConditions adds the conditions to the WHERE clause built by parseSpannerCondition, the clause is
+// put in parentheses as it can contain an OR from the FilterExpression
+func addWhereConditions(whereCondition string, conditions ...string) string {
+	for _, condition := range conditions {
+		if condition == "" {
+			continue
 		}
-		pValues = append(pValues, pValue)
+		if whereCondition == " " {
+			whereCondition = "WHERE (" + condition + ") "
+			continue
+		}
+		whereCondition = "WHERE (" + strings.TrimPrefix(whereCondition, "WHERE ") + ") AND (" + condition + ") "
 	}
-	return storage.GetStorageInstance().SpannerBatchGet(ctx, tableName, pValues, sValues, projectionCols)
+	return whereCondition
 }
 
-// Delete service
-func Delete(ctx context.Context, tableName string, primaryKeyMap map[string]interface{}, condExpression string, attrMap map[string]interface{}, expr *models.UpdateExpressionCondition) error {
-	tableConf, err := config.GetTableConf(tableName)
-	if err != nil {
-		return err
+// parseSpannerSorting orders the items by their keys, so that the pages of a Query or a Scan
+// can be resumed after the LastEvaluatedKey
+func parseSpannerSorting(query *models.Query, keys []string) string {
+	if len(keys) == 0 {
+		return " "
 	}
-	tableName = tableConf.ActualTable
-	e, err := utils.CreateConditionExpression(condExpression, attrMap)
-	if err != nil {
-		return err
+	direction := " DESC"
+	if query.SortAscending {
+		direction = " ASC"
 	}
-	return storage.GetStorageInstance().SpannerDelete(ctx, tableName, primaryKeyMap, e, expr)
+	orderBy := make([]string, 0, len(keys))
+	for _, key := range keys {
+		orderBy = append(orderBy, key+direction)
+	}
+	return " ORDER BY " + strings.Join(orderBy, ", ") + " "
+}
+
+func parseLimit(query *models.Query) string {
+	if query.Limit == 0 {
+		return " LIMIT 5000 "
+	}
+	return " LIMIT " + strconv.FormatInt(query.Limit, 10)
 }
 
-// BatchDelete service
-func BatchDelete(ctx context.Context, tableName string, keyMapArray []map[string]interface{}) error {
+// Delete service
+func Delete(ctx context.Context, tableName string, primaryKeyMap map[string]interface{}, condExpression string, expressionAttributeNames map[string]string, attrMap map[string]interface{}, expr *models.UpdateExpressionCondition) error {
 	tableConf, err := config.GetTableConf(tableName)
 	if err != nil {
 		return err
 	}
-
 	tableName = tableConf.ActualTable
-	err = storage.GetStorageInstance().SpannerBatchDelete(ctx, tableName, keyMapArray)
+	e, err := utils.CreateConditionExpression(condExpression, expressionAttributeNames, attrMap)
 	if err != nil {
 		return err
 	}
-	return nil
+	return storage.GetStorageInstance().SpannerDelete(ctx, tableName, primaryKeyMap, e, expr)
 }
 
 // Scan service
@@ -782,97 +791,41 @@ func Scan(ctx context.Context, scanData models.ScanMeta) (map[string]interface{}
 		query.Limit = models.GlobalConfig.Spanner.QueryLimit
 	}
 	query.StartFrom = scanData.StartFrom
+	// a Scan returns the items in ascending key order
+	query.SortAscending = true
+	if scanData.Segment != nil || scanData.TotalSegments != nil {
+		if err := validateScanSegment(scanData.Segment, scanData.TotalSegments); err != nil {
+			return nil, err
+		}
+		query.Segment = *scanData.Segment
+		query.TotalSegments = *scanData.TotalSegments
+	}
 	query.RangeValMap = scanData.ExpressionAttributeMap
 	query.IndexName = scanData.IndexName
 	query.FilterExp = scanData.FilterExpression
 	query.ExpressionAttributeNames = scanData.ExpressionAttributeNames
 	query.OnlyCount = scanData.OnlyCount
+	query.Select = scanData.Select
+	query.ConsistentRead = scanData.ConsistentRead
 	query.ProjectionExpression = scanData.ProjectionExpression
 
-	for k, v := range query.ExpressionAttributeNames {
-		query.FilterExp = strings.ReplaceAll(query.FilterExp, k, v)
-	}
-
 	rs, _, err := QueryAttributes(ctx, query)
 	return rs, err
 }
 
-// Remove for remove operation in update
-func Remove(ctx context.Context, tableName string, updateAttr models.UpdateAttr, actionValue string, expr *models.UpdateExpressionCondition, oldRes map[string]interface{}) (map[string]interface{}, error) {
-	actionValue = strings.ReplaceAll(actionValue, " ", "")
-	colsToRemove := strings.Split(actionValue, ",")
-	tableConf, err := config.GetTableConf(tableName)
-	if err != nil {
-		return nil, err
-	}
-	tableName = tableConf.ActualTable
-	e, err := utils.CreateConditionExpression(updateAttr.ConditionExpression, updateAttr.ExpressionAttributeMap)
-	if err != nil {
-		return nil, err
-	}
-	err = storage.GetStorageInstance().SpannerRemove(ctx, tableName, updateAttr.PrimaryKeyMap, e, expr, colsToRemove, oldRes)
-	if err != nil {
-		return nil, err
-	}
-	if oldRes == nil {
-		return oldRes, nil
-	}
-	updateResp := map[string]interface{}{}
-	for k, v := range oldRes {
-		updateResp[k] = v
-	}
-	for _, target := range colsToRemove {
-		if strings.Contains(target, "[") && strings.Contains(target, "]") {
-			// Handle list index removal
-			listAttr, idx := utils.ParseListRemoveTarget(target)
-			if idx != -1 {
-				if list, ok := oldRes[listAttr].([]interface{}); ok {
-					oldRes[listAttr] = utils.RemoveListElement(list, idx)
-				}
-			} else {
-				// Handle invalid list index format
-				return nil, fmt.Errorf("invalid list index format for target %q", target)
-			}
-		} else {
-			// Handle direct column removal
-			delete(updateResp, target)
-		}
-	}
-	return updateResp, nil
-}
-
-// TransactGetProjectionCols gets the projection columns from the TransactGet request
-func (s *spannerService) TransactGetProjectionCols(ctx context.Context, getRequest models.GetItemRequest) ([]string, []interface{}, []interface{}, error) {
-	// Get the table configuration
-	tableConf, err := config.GetTableConf(getRequest.TableName)
-	if err != nil {
-		return nil, nil, nil, err
-	}
-
-	// Get the projection columns
-	projectionCols := getSpannerProjections(getRequest.ProjectionExpression, tableConf.ActualTable, getRequest.ExpressionAttributeNames)
-
-	// Get the partition and sort keys
-	var pValues []interface{}
-	var sValues []interface{}
-	for i := 0; i < len(getRequest.KeyArray); i++ {
-		pValue := getRequest.KeyArray[i][tableConf.PartitionKey]
-		if tableConf.SortKey != "" {
-			sValue := getRequest.KeyArray[i][tableConf.SortKey]
-			sValues = append(sValues, sValue)
-		}
-		pValues = append(pValues, pValue)
+// validateScanSegment validates the Segment and TotalSegments of a parallel Scan in the same way DynamoDB does
+func validateScanSegment(segment, totalSegments *int64) error {
+	switch {
+	case segment == nil:
+		return errors.New("ValidationException", "The Segment parameter is required but was not present in the request when parameter TotalSegments is present")
+	case totalSegments == nil:
+		return errors.New("ValidationException", "The TotalSegments parameter is required but was not present in the request when Segment parameter is present")
+	case *totalSegments < 1 || *totalSegments > maxScanSegments:
+		return errors.New("ValidationException", fmt.Sprintf("1 validation error detected: Value '%d' at 'totalSegments' failed to satisfy constraint: Member must have value between 1 and %d", *totalSegments, maxScanSegments))
+	case *segment < 0 || *segment >= *totalSegments:
+		return errors.New("ValidationException", fmt.Sprintf("The Segment parameter is zero-based and must be less than parameter TotalSegments: Segment: %d is not less than TotalSegments: %d", *segment, *totalSegments))
 	}
-
-	// Return the projection columns and the keys
-	return projectionCols, pValues, sValues, nil
-}
-
-func (s *spannerService) TransactGetItem(ctx context.Context, tableProjectionCols map[string][]string, pValues map[string]interface{}, sValues map[string]interface{}) ([]map[string]interface{}, error) {
-	// Call the SpannerTransactGetItems method on the Storage interface
-	// This method fetches data from Spanner based on the provided table projection columns,
-	// partition key values, and sort key values.
-	return s.st.SpannerTransactGetItems(ctx, tableProjectionCols, pValues, sValues)
+	return nil
 }
 
 // ExecuteStatement service API handler function
@@ -903,8 +856,9 @@ func ExecuteStatement(ctx context.Context, executeStatement models.ExecuteStatem
 //
 // Returns:
 // - spanner.Statement: A Google Cloud Spanner statement ready to be executed.
+// - []string: The columns projected by the statement, empty when all the columns are selected.
 // - error: An error object, if an error occurs during translation or parameter conversion.
-func parsePartiQlToSpannerforSelect(ctx context.Context, executeStatement models.ExecuteStatement) (spanner.Statement, error) {
+func parsePartiQlToSpannerforSelect(ctx context.Context, executeStatement models.ExecuteStatement) (spanner.Statement, []string, error) {
 	stmt := spanner.Statement{}
 	paramMap := make(map[string]interface{})
 	var err error
@@ -913,7 +867,14 @@ func parsePartiQlToSpannerforSelect(ctx context.Context, executeStatement models
 
 	queryMap, err := translatorObj.ToSpannerSelect(executeStatement.Statement)
 	if err != nil {
-		return stmt, err
+		return stmt, nil, err
+	}
+	cols := slices.Clone(queryMap.ProjectionColumns)
+	// the TimeToLive attribute is read to hide the expired items, it is only returned when projected
+	if ttlColumn := storage.TTLColumn(utils.ChangeTableNameForSpanner(queryMap.Table)); ttlColumn != "" {
+		if err := queryMap.AddProjectionColumn(ttlColumn); err != nil {
+			return stmt, nil, err
+		}
 	}
 
 	queryStmt := queryMap.SpannerQuery
@@ -923,12 +884,12 @@ func parsePartiQlToSpannerforSelect(ctx context.Context, executeStatement models
 
 	err = handleParameters(executeStatement.Parameters, queryMap.Where, &paramMap, &queryStmt)
 	if err != nil {
-		return stmt, err
+		return stmt, nil, err
 	}
 
 	stmt.SQL = queryMap.SpannerQuery
 	stmt.Params = paramMap
-	return stmt, nil
+	return stmt, cols, nil
 }
 func handleParameters(parameters []*dynamodb.AttributeValue, whereConditions []translator.Condition, paramMap *map[string]interface{}, queryStmt *string) error {
 	for i, val := range parameters {
@@ -969,12 +930,12 @@ func handleParameters(parameters []*dynamodb.AttributeValue, whereConditions []t
 // - map[string]interface{}: A map containing the fetched items under the key "Items".
 // - error: An error object, if any issues arise during the execution process.
 func ExecuteStatementForSelect(ctx context.Context, executeStatement models.ExecuteStatement) (map[string]interface{}, error) {
-	spannerStatement, err := parsePartiQlToSpannerforSelect(ctx, executeStatement)
+	spannerStatement, cols, err := parsePartiQlToSpannerforSelect(ctx, executeStatement)
 	if err != nil {
 		return nil, err
 
 	}
-	resp, err := storage.GetStorageInstance().ExecuteSpannerQuery(ctx, executeStatement.TableName, []string{}, false, spannerStatement)
+	resp, err := storage.GetStorageInstance().ExecuteSpannerQuery(ctx, executeStatement.TableName, cols, false, spannerStatement)
 	if err != nil {
 		return nil, err
 	}
@@ -1036,7 +997,7 @@ func ExecuteStatementForInsert(ctx context.Context, executeStatement models.Exec
 		}
 		newMap[columnName] = convertedValue
 	}
-	result, err := Put(ctx, executeStatement.TableName, newMap, nil, "", nil, nil, nil)
+	result, err := Put(ctx, executeStatement.TableName, newMap, nil, "", nil, nil, nil, nil)
 	if err != nil {
 		return result, err
 	}
@@ -1172,7 +1133,7 @@ func convertType(columnName string, val interface{}, columntype string) (interfa
 }
 
 // TransactWritePut manages a transactional put operation in Spanner, ensuring old data is fetched and conditions are evaluated.
-func (s *spannerService) TransactWritePut(ctx context.Context, tableName string, putObj map[string]interface{}, expr *models.UpdateExpressionCondition, conditionExp string, expressionAttr, oldRes map[string]interface{}, txn *spanner.ReadWriteTransaction) (map[string]interface{}, *spanner.Mutation, error) {
+func (s *spannerService) TransactWritePut(ctx context.Context, tableName string, putObj map[string]interface{}, expr *models.UpdateExpressionCondition, conditionExp string, expressionAttributeNames map[string]string, expressionAttr, oldRes map[string]interface{}, txn *spanner.ReadWriteTransaction) (map[string]interface{}, *spanner.Mutation, error) {
 	// Fetch the table configuration to retrieve partition and sort keys
 	tableConf, err := config.GetTableConf(tableName)
 	if err != nil {
@@ -1183,7 +1144,7 @@ func (s *spannerService) TransactWritePut(ctx context.Context, tableName string,
 	tableName = tableConf.ActualTable
 
 	// Create the condition expression for the transaction
-	e, err := utils.CreateConditionExpression(conditionExp, expressionAttr)
+	e, err := utils.CreateConditionExpression(conditionExp, expressionAttributeNames, expressionAttr)
 	if err != nil {
 		return nil, nil, err
 	}
@@ -1213,7 +1174,7 @@ func (s *spannerService) TransactWritePut(ctx context.Context, tableName string,
 }
 
 // TransactWriteDel performs a transactional delete on Spanner
-func (s *spannerService) TransactWriteDel(ctx context.Context, tableName string, attrMap map[string]interface{}, condExpression string, expressionAttr map[string]interface{}, expr *models.UpdateExpressionCondition, txn *spanner.ReadWriteTransaction) (map[string]interface{}, *spanner.Mutation, error) {
+func (s *spannerService) TransactWriteDel(ctx context.Context, tableName string, attrMap map[string]interface{}, condExpression string, expressionAttributeNames map[string]string, expressionAttr map[string]interface{}, expr *models.UpdateExpressionCondition, txn *spanner.ReadWriteTransaction) (map[string]interface{}, *spanner.Mutation, error) {
 	// Fetch the table configuration and update the table name
 	tableConf, err := config.GetTableConf(tableName)
 	if err != nil {
@@ -1223,7 +1184,7 @@ func (s *spannerService) TransactWriteDel(ctx context.Context, tableName string,
 	tableName = tableConf.ActualTable
 
 	// Create the condition expression for the transaction
-	e, err := utils.CreateConditionExpression(condExpression, expressionAttr)
+	e, err := utils.CreateConditionExpression(condExpression, expressionAttributeNames, expressionAttr)
 	if err != nil {
 		return nil, nil, err
 	}
@@ -1244,99 +1205,18 @@ func (s *spannerService) TransactWriteDel(ctx context.Context, tableName string,
 	return res, mut, nil
 }
 
-// TransactWriteAdd performs a transactional add operation in Spanner, ensuring old data is fetched and conditions are evaluated.
-func (s *spannerService) TransactWriteAdd(ctx context.Context, tableName string, attrMap map[string]interface{}, condExpression string, m, expressionAttr map[string]interface{}, expr *models.UpdateExpressionCondition, oldRes map[string]interface{}, txn *spanner.ReadWriteTransaction) (map[string]interface{}, *spanner.Mutation, error) {
-	// Fetch the table configuration to retrieve the actual table name
-	tableConf, err := config.GetTableConf(tableName)
-	if err != nil {
-		return nil, nil, err
-	}
-	tableName = tableConf.ActualTable
-
-	// Create the condition expression for the transaction
-	e, err := utils.CreateConditionExpression(condExpression, expressionAttr)
-	if err != nil {
-		return nil, nil, err
-	}
-
-	// Perform the transactional add operation
-	newResp, mut, err := s.st.TransactWriteSpannerAdd(ctx, tableName, m, e, expr, txn)
-	if err != nil {
-		return nil, nil, err
-	}
-
-	// If there is no old response, return early with nil mutation
-	if oldRes == nil {
-		return newResp, nil, nil
-	}
-
-	// Combine the old response with the new response
-	updateResp := map[string]interface{}{}
-	for k, v := range oldRes {
-		updateResp[k] = v
-	}
-	for k, v := range newResp {
-		updateResp[k] = v
-	}
-
-	// Return the updated response and the mutation
-	return updateResp, mut, nil
-}
-
-// TransactWriteRemove performs a transactional remove operation in Spanner, ensuring old data is fetched and conditions are evaluated.
-//
-// It takes the following parameters:
-// - ctx: the context of the request
-// - tableName: the name of the table
-// - updateAttr: the update attribute
-// - actionValue: the action value
-// - expr: the expression
-// - oldRes: the old response
-// - txn: the transaction
-//
-// It returns a map of the updated response, a mutation, and an error.
-func (s *spannerService) TransactWriteRemove(ctx context.Context, tableName string, updateAttr models.UpdateAttr, actionValue string, expr *models.UpdateExpressionCondition, oldRes map[string]interface{}, txn *spanner.ReadWriteTransaction) (map[string]interface{}, *spanner.Mutation, error) {
-	actionValue = strings.ReplaceAll(actionValue, " ", "")
-	colsToRemove := strings.Split(actionValue, ",")
-	tableConf, err := config.GetTableConf(tableName)
-	if err != nil {
-		return nil, nil, err
-	}
-	tableName = tableConf.ActualTable
-	e, err := utils.CreateConditionExpression(updateAttr.ConditionExpression, updateAttr.ExpressionAttributeMap)
-	if err != nil {
-		return nil, nil, err
-	}
-	mut, err := s.st.TransactWriteSpannerRemove(ctx, tableName, updateAttr.PrimaryKeyMap, e, expr, colsToRemove, txn)
-	if err != nil {
-		return nil, nil, err
-	}
-	if oldRes == nil {
-		return oldRes, nil, nil
-	}
-	updateResp := map[string]interface{}{}
-	for k, v := range oldRes {
-		updateResp[k] = v
-	}
-
-	// remove the columns from the old response
-	for i := 0; i < len(colsToRemove); i++ {
-		delete(updateResp, colsToRemove[i])
-	}
-	return updateResp, mut, nil
-}
-
 // TransactWriteDelete - This function is used to delete an item in a table.
 // It takes the context of the request, the name of the table, the primary key map,
-// the condition expression, the attribute map, the expression, and the transaction.
+// the condition expression, the expression attribute names, the attribute map, the expression,
+// and the transaction.
 // It returns a mutation and an error.
-func TransactWriteDelete(ctx context.Context, tableName string, primaryKeyMap map[string]interface{}, condExpression string, attrMap map[string]interface{}, expr *models.UpdateExpressionCondition, txn *spanner.ReadWriteTransaction) (*spanner.Mutation, error) {
+func TransactWriteDelete(ctx context.Context, tableName string, primaryKeyMap map[string]interface{}, condExpression string, expressionAttributeNames map[string]string, attrMap map[string]interface{}, expr *models.UpdateExpressionCondition, txn *spanner.ReadWriteTransaction) (*spanner.Mutation, error) {
 	tableConf, err := config.GetTableConf(tableName)
 	if err != nil {
 		return nil, err
 	}
 	tableName = tableConf.ActualTable
-	e, err := utils.CreateConditionExpression(condExpression, attrMap)
+	e, err := utils.CreateConditionExpression(condExpression, expressionAttributeNames, attrMap)
 	if err != nil {
 		return nil, err
 	}
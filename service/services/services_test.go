@@ -23,6 +23,7 @@ import (
 	"github.com/aws/aws-sdk-go/aws"
 	"github.com/aws/aws-sdk-go/service/dynamodb"
 	"github.com/cloudspannerecosystem/dynamodb-adapter/models"
+	"github.com/cloudspannerecosystem/dynamodb-adapter/pkg/errors"
 	"github.com/cloudspannerecosystem/dynamodb-adapter/storage"
 	"github.com/cloudspannerecosystem/dynamodb-adapter/utils"
 	"github.com/stretchr/testify/mock"
@@ -33,7 +34,11 @@ func init() {
 	models.TableColumnMap = map[string][]string{
 		"testTable": {"first", "second", "third", "fourth"},
 	}
+	if models.GlobalConfig == nil {
+		models.GlobalConfig = &models.Config{Spanner: models.SpannerConfig{ProjectID: "test-project", InstanceID: "test-instance"}}
+	}
 }
+
 func Test_getSpannerProjections(t *testing.T) {
 
 	tests := []struct {
@@ -101,44 +106,40 @@ func Test_getSpannerProjections(t *testing.T) {
 }
 
 func Test_createSpannerQuery(t *testing.T) {
+	tableDDL := models.TableDDL
+	defer func() { models.TableDDL = tableDDL }()
+	models.TableDDL = map[string]map[string]string{
+		"testTable": {"first": "S", "second": "N", "third": "SS", "fourth": "N"},
+	}
 
 	tests := []struct {
-		testName     string
-		queryModel   *models.Query
-		partionkey   string
-		primaryKey   string
-		secondaryKey string
-		want1        spanner.Statement
-		want2        []string
-		want3        bool
-		want4        int64
+		testName   string
+		queryModel *models.Query
+		keys       []string
+		sKey       string
+		want1      spanner.Statement
+		want2      []string
 	}{
 		{
 			"empty queryModel",
 			nil,
-			"first",
-			"first",
+			[]string{"first", "second"},
 			"second",
 			spanner.Statement{},
 			[]string{},
-			false,
-			0,
 		},
 		{
 			"queryModel is present but without projectionExpression",
 			&models.Query{
 				TableName: "testTable",
 			},
-			"first",
-			"first",
+			[]string{"first", "second"},
 			"second",
 			spanner.Statement{
-				SQL:    "SELECT testTable.`first`,testTable.`second`,testTable.`third`,testTable.`fourth` FROM testTable WHERE second is not null  ORDER BY second DESC  LIMIT 5000 ",
+				SQL:    "SELECT testTable.`first`,testTable.`second`,testTable.`third`,testTable.`fourth` FROM testTable WHERE second is not null  ORDER BY first DESC, second DESC  LIMIT 5000 ",
 				Params: make(map[string]interface{}),
 			},
 			[]string{"first", "second", "third", "fourth"},
-			false,
-			0,
 		},
 		{
 			"queryModel is present but with projectionExpression",
@@ -146,16 +147,13 @@ func Test_createSpannerQuery(t *testing.T) {
 				TableName:            "testTable",
 				ProjectionExpression: "first, second",
 			},
-			"first",
-			"first",
+			[]string{"first", "second"},
 			"second",
 			spanner.Statement{
-				SQL:    "SELECT testTable.`first`,testTable.`second` FROM testTable WHERE second is not null  ORDER BY second DESC  LIMIT 5000 ",
+				SQL:    "SELECT testTable.`first`,testTable.`second` FROM testTable WHERE second is not null  ORDER BY first DESC, second DESC  LIMIT 5000 ",
 				Params: make(map[string]interface{}),
 			},
 			[]string{"first", "second"},
-			false,
-			0,
 		},
 		{
 			"queryModel is present but with projectionExpression & ExpressionAttributeNames",
@@ -164,16 +162,13 @@ func Test_createSpannerQuery(t *testing.T) {
 				ProjectionExpression:     "#f, second",
 				ExpressionAttributeNames: map[string]string{"#f": "first"},
 			},
-			"first",
-			"first",
+			[]string{"first", "second"},
 			"second",
 			spanner.Statement{
-				SQL:    "SELECT testTable.`first`,testTable.`second` FROM testTable WHERE second is not null  ORDER BY second DESC  LIMIT 5000 ",
+				SQL:    "SELECT testTable.`first`,testTable.`second` FROM testTable WHERE second is not null  ORDER BY first DESC, second DESC  LIMIT 5000 ",
 				Params: make(map[string]interface{}),
 			},
 			[]string{"first", "second"},
-			false,
-			0,
 		},
 		{
 			"queryModel is present but with projectionExpression & wrong ExpressionAttributeNames",
@@ -182,16 +177,13 @@ func Test_createSpannerQuery(t *testing.T) {
 				ProjectionExpression:     "#f, second",
 				ExpressionAttributeNames: map[string]string{"#fir": "first"},
 			},
-			"first",
-			"first",
+			[]string{"first", "second"},
 			"second",
 			spanner.Statement{
-				SQL:    "SELECT testTable.`second`,testTable.`first` FROM testTable WHERE second is not null  ORDER BY second DESC  LIMIT 5000 ",
+				SQL:    "SELECT testTable.`second`,testTable.`first` FROM testTable WHERE second is not null  ORDER BY first DESC, second DESC  LIMIT 5000 ",
 				Params: make(map[string]interface{}),
 			},
 			[]string{"second", "first"},
-			false,
-			0,
 		},
 		{
 			"only count",
@@ -201,58 +193,71 @@ func Test_createSpannerQuery(t *testing.T) {
 				ExpressionAttributeNames: map[string]string{"#f": "first"},
 				OnlyCount:                true,
 			},
-			"first",
-			"first",
+			[]string{"first", "second"},
 			"second",
 			spanner.Statement{
-				SQL:    "SELECT COUNT(first) AS count FROM testTable WHERE second is not null  ",
+				SQL:    "SELECT testTable.`first`,testTable.`second` FROM testTable WHERE second is not null  ORDER BY first DESC, second DESC  LIMIT 5000 ",
 				Params: make(map[string]interface{}),
 			},
-			[]string{"count"},
-			true,
-			0,
+			[]string{"first", "second"},
 		},
 		{
-			"with offset",
+			"with exclusive start key",
 			&models.Query{
 				TableName:                "testTable",
 				ProjectionExpression:     "#f, second",
 				ExpressionAttributeNames: map[string]string{"#f": "first"},
 				StartFrom: map[string]interface{}{
-					"offset": float64(10),
+					"first":  float64(10),
+					"second": "b",
 				},
 			},
-			"first",
-			"first",
+			[]string{"first", "second"},
 			"second",
 			spanner.Statement{
-				SQL:    "SELECT testTable.`first`,testTable.`second` FROM testTable WHERE second is not null  ORDER BY second DESC  LIMIT 5000  OFFSET 10",
-				Params: make(map[string]interface{}),
+				SQL: "SELECT testTable.`first`,testTable.`second` FROM testTable WHERE (second is not null ) AND ((first < @startKey1) OR (first = @startKey1 AND second < @startKey2))  ORDER BY first DESC, second DESC  LIMIT 5000 ",
+				Params: map[string]interface{}{
+					"startKey1": float64(10),
+					"startKey2": "b",
+				},
 			},
 			[]string{"first", "second"},
-			false,
-			10,
 		},
 		{
-			"with offset other than float64",
+			"with exclusive start key in ascending order",
+			&models.Query{
+				TableName:     "testTable",
+				SortAscending: true,
+				StartFrom: map[string]interface{}{
+					"first":  float64(10),
+					"second": "b",
+				},
+			},
+			[]string{"first", "second"},
+			"second",
+			spanner.Statement{
+				SQL: "SELECT testTable.`first`,testTable.`second`,testTable.`third`,testTable.`fourth` FROM testTable WHERE (second is not null ) AND ((first > @startKey1) OR (first = @startKey1 AND second > @startKey2))  ORDER BY first ASC, second ASC  LIMIT 5000 ",
+				Params: map[string]interface{}{
+					"startKey1": float64(10),
+					"startKey2": "b",
+				},
+			},
+			[]string{"first", "second", "third", "fourth"},
+		},
+		{
+			"with offset instead of the key attributes",
 			&models.Query{
 				TableName:                "testTable",
 				ProjectionExpression:     "#f, second",
 				ExpressionAttributeNames: map[string]string{"#f": "first"},
 				StartFrom: map[string]interface{}{
-					"offset": 10,
+					"offset": float64(10),
 				},
 			},
-			"first",
-			"first",
+			[]string{"first", "second"},
 			"second",
-			spanner.Statement{
-				SQL:    "SELECT testTable.`first`,testTable.`second` FROM testTable WHERE second is not null  ORDER BY second DESC  LIMIT 5000 ",
-				Params: make(map[string]interface{}),
-			},
+			spanner.Statement{},
 			[]string{"first", "second"},
-			false,
-			0,
 		},
 		{
 			"range expression present",
@@ -265,18 +270,15 @@ func Test_createSpannerQuery(t *testing.T) {
 					":val1": float64(5),
 				},
 			},
-			"first",
-			"first",
+			[]string{"first", "second"},
 			"second",
 			spanner.Statement{
-				SQL: "SELECT testTable.`first`,testTable.`second` FROM testTable WHERE second is not null  AND first > @rangeExp1 ORDER BY second DESC  LIMIT 5000 ",
+				SQL: "SELECT testTable.`first`,testTable.`second` FROM testTable WHERE second is not null  AND first > @rangeExp1 ORDER BY first DESC, second DESC  LIMIT 5000 ",
 				Params: map[string]interface{}{
 					"rangeExp1": float64(5),
 				},
 			},
 			[]string{"first", "second"},
-			false,
-			0,
 		},
 		{
 			"filter expression present",
@@ -289,18 +291,15 @@ func Test_createSpannerQuery(t *testing.T) {
 					":val1": float64(5),
 				},
 			},
-			"first",
-			"first",
+			[]string{"first", "second"},
 			"second",
 			spanner.Statement{
-				SQL: "SELECT testTable.`first`,testTable.`second` FROM testTable WHERE second is not null  AND fourth > @filterExp1 ORDER BY second DESC  LIMIT 5000 ",
+				SQL: "SELECT `first`,`second`,dynamodb_adapter_filter FROM (SELECT testTable.`first`,testTable.`second`, (IFNULL(testTable.`fourth` > @filterExp1, FALSE)) AS dynamodb_adapter_filter FROM testTable WHERE second is not null  ORDER BY first DESC, second DESC  LIMIT 5000 ) ORDER BY first DESC, second DESC ",
 				Params: map[string]interface{}{
 					"filterExp1": float64(5),
 				},
 			},
 			[]string{"first", "second"},
-			false,
-			0,
 		},
 		{
 			"filter & range expression both present",
@@ -315,19 +314,16 @@ func Test_createSpannerQuery(t *testing.T) {
 					":val2": float64(4),
 				},
 			},
-			"first",
-			"first",
+			[]string{"first", "second"},
 			"second",
 			spanner.Statement{
-				SQL: "SELECT testTable.`first`,testTable.`second` FROM testTable WHERE second is not null  AND first > @rangeExp1 AND fourth > @filterExp1 ORDER BY second DESC  LIMIT 5000 ",
+				SQL: "SELECT `first`,`second`,dynamodb_adapter_filter FROM (SELECT testTable.`first`,testTable.`second`, (IFNULL(testTable.`fourth` > @filterExp1, FALSE)) AS dynamodb_adapter_filter FROM testTable WHERE second is not null  AND first > @rangeExp1 ORDER BY first DESC, second DESC  LIMIT 5000 ) ORDER BY first DESC, second DESC ",
 				Params: map[string]interface{}{
 					"filterExp1": float64(5),
 					"rangeExp1":  float64(4),
 				},
 			},
 			[]string{"first", "second"},
-			false,
-			0,
 		},
 		{
 			"limit present",
@@ -343,86 +339,100 @@ func Test_createSpannerQuery(t *testing.T) {
 				},
 				Limit: 100,
 			},
-			"first",
-			"first",
+			[]string{"first", "second"},
 			"second",
 			spanner.Statement{
-				SQL: "SELECT testTable.`first`,testTable.`second` FROM testTable WHERE second is not null  AND first > @rangeExp1 AND fourth > @filterExp1 ORDER BY second DESC  LIMIT 100",
+				SQL: "SELECT `first`,`second`,dynamodb_adapter_filter FROM (SELECT testTable.`first`,testTable.`second`, (IFNULL(testTable.`fourth` > @filterExp1, FALSE)) AS dynamodb_adapter_filter FROM testTable WHERE second is not null  AND first > @rangeExp1 ORDER BY first DESC, second DESC  LIMIT 100) ORDER BY first DESC, second DESC ",
 				Params: map[string]interface{}{
 					"filterExp1": float64(5),
 					"rangeExp1":  float64(4),
 				},
 			},
 			[]string{"first", "second"},
-			false,
-			0,
+		},
+		{
+			"filter expression without projection",
+			&models.Query{
+				TableName:   "testTable",
+				FilterExp:   "contains(third, :val1)",
+				RangeValMap: map[string]interface{}{":val1": "x"},
+			},
+			[]string{"first", "second"},
+			"second",
+			spanner.Statement{
+				SQL: "SELECT `first`,`second`,IF(dynamodb_adapter_filter, `third`, NULL) AS `third`,IF(dynamodb_adapter_filter, `fourth`, NULL) AS `fourth`,dynamodb_adapter_filter FROM (SELECT testTable.`first`,testTable.`second`,testTable.`third`,testTable.`fourth`, (IFNULL(@filterExp1 IN UNNEST(testTable.`third`), FALSE)) AS dynamodb_adapter_filter FROM testTable WHERE second is not null  ORDER BY first DESC, second DESC  LIMIT 5000 ) ORDER BY first DESC, second DESC ",
+				Params: map[string]interface{}{
+					"filterExp1": "x",
+				},
+			},
+			[]string{"first", "second", "third", "fourth"},
+		},
+		{
+			"filter expression evaluated on the items read",
+			&models.Query{
+				TableName:            "testTable",
+				ProjectionExpression: "first",
+				FilterExp:            "fourth IN (:val1, :val2)",
+				RangeValMap:          map[string]interface{}{":val1": "x", ":val2": float64(5)},
+			},
+			[]string{"first", "second"},
+			"second",
+			spanner.Statement{
+				SQL:    "SELECT testTable.`first`,testTable.`second`,testTable.`fourth` FROM testTable WHERE second is not null  ORDER BY first DESC, second DESC  LIMIT 5000 ",
+				Params: map[string]interface{}{},
+			},
+			[]string{"first", "second"},
 		},
 	}
 
 	for _, tc := range tests {
-		got1, got2, got3, got4, _, _ := createSpannerQuery(tc.queryModel, tc.partionkey, tc.primaryKey, tc.secondaryKey)
+		got1, got2, _, _ := createSpannerQuery(tc.queryModel, tc.keys[0], tc.sKey, tc.keys)
 
 		assert.Equal(t, got1, tc.want1)
 		assert.Equal(t, got2, tc.want2)
-		assert.Equal(t, got3, tc.want3)
-		assert.Equal(t, got4, tc.want4)
 	}
 }
 
 func Test_parseSpannerColumns(t *testing.T) {
 	tests := []struct {
-		testName     string
-		queryModel   *models.Query
-		partitionkey string
-		primaryKey   string
-		secondaryKey string
-		want1        []string
-		want2        string
-		want3        bool
+		testName   string
+		queryModel *models.Query
+		keys       []string
+		want1      []string
+		want2      string
 	}{
 		{
 			"empty queryModel",
 			nil,
-			"",
-			"",
-			"",
+			nil,
 			[]string{},
 			"",
-			false,
 		},
 		{
 			"onlyCount present",
 			&models.Query{
+				TableName: "testTable",
 				OnlyCount: true,
 			},
-			"first",
-			"first",
-			"second",
-			[]string{"count"},
-			"COUNT(first) AS count",
-			true,
+			[]string{"first", "second"},
+			[]string{"first", "second"},
+			"testTable.`first`,testTable.`second`",
 		},
 		{
 			"Empty Query Model",
 			&models.Query{},
-			"first",
-			"first",
-			"second",
+			[]string{"first", "second"},
 			nil,
 			"",
-			false,
 		},
 		{
 			"Only table Name present",
 			&models.Query{
 				TableName: "testTable",
 			},
-			"first",
-			"first",
-			"second",
+			[]string{"first", "second"},
 			[]string{"first", "second", "third", "fourth"},
 			"testTable.`first`,testTable.`second`,testTable.`third`,testTable.`fourth`",
-			false,
 		},
 		{
 			"table with projection expression",
@@ -430,12 +440,9 @@ func Test_parseSpannerColumns(t *testing.T) {
 				TableName:            "testTable",
 				ProjectionExpression: "first, third, fourth",
 			},
-			"first",
-			"first",
-			"second",
+			[]string{"first", "second"},
 			[]string{"first", "third", "fourth", "second"},
 			"testTable.`first`,testTable.`third`,testTable.`fourth`,testTable.`second`",
-			false,
 		},
 		{
 			"table with wrong projection expression",
@@ -443,12 +450,9 @@ func Test_parseSpannerColumns(t *testing.T) {
 				TableName:            "testTable",
 				ProjectionExpression: "first, second , third, four",
 			},
-			"first",
-			"first",
-			"second",
+			[]string{"first", "second"},
 			[]string{"first", "second", "third"},
 			"testTable.`first`,testTable.`second`,testTable.`third`",
-			false,
 		},
 		{
 			"projectionexpression & ExpressionAttributeNames both present",
@@ -459,12 +463,9 @@ func Test_parseSpannerColumns(t *testing.T) {
 					"#s": "second",
 				},
 			},
-			"first",
-			"first",
-			"second",
+			[]string{"first", "second"},
 			[]string{"first", "second", "third"},
 			"testTable.`first`,testTable.`second`,testTable.`third`",
-			false,
 		},
 		{
 			"projectionexpression & ExpressionAttributeNames both present",
@@ -476,21 +477,17 @@ func Test_parseSpannerColumns(t *testing.T) {
 					"#fr": "fourth",
 				},
 			},
-			"first",
-			"first",
-			"second",
+			[]string{"first", "second"},
 			[]string{"first", "second", "third", "fourth"},
 			"testTable.`first`,testTable.`second`,testTable.`third`,testTable.`fourth`",
-			false,
 		},
 	}
 
 	for _, tc := range tests {
-		got1, got2, got3, _ := parseSpannerColumns(tc.queryModel, tc.partitionkey, tc.primaryKey, tc.secondaryKey)
+		got1, got2, _ := parseSpannerColumns(tc.queryModel, tc.keys)
 
 		assert.Equal(t, got1, tc.want1)
 		assert.Equal(t, got2, tc.want2)
-		assert.Equal(t, got3, tc.want3)
 	}
 }
 
@@ -582,10 +579,8 @@ func Test_parseSpannerCondition(t *testing.T) {
 			},
 			"first",
 			"second",
-			"WHERE second is not null  AND fourth = @filterExp1",
-			map[string]interface{}{
-				"filterExp1": float64(61),
-			},
+			"WHERE second is not null ",
+			make(map[string]interface{}),
 		},
 		{
 			"FilterExpression & range both present",
@@ -600,10 +595,9 @@ func Test_parseSpannerCondition(t *testing.T) {
 			},
 			"first",
 			"second",
-			"WHERE second is not null  AND fourth = @rangeExp1 AND fourth = @filterExp1",
+			"WHERE second is not null  AND fourth = @rangeExp1",
 			map[string]interface{}{
-				"filterExp1": float64(34),
-				"rangeExp1":  float64(61),
+				"rangeExp1": float64(61),
 			},
 		},
 	}
@@ -615,97 +609,242 @@ func Test_parseSpannerCondition(t *testing.T) {
 	}
 }
 
-func Test_parseOffset(t *testing.T) {
+func Test_parseQueryFilter(t *testing.T) {
 	tests := []struct {
 		testName   string
 		queryModel *models.Query
-		want1      string
-		want2      int64
+		wantFilter bool
+		wantErr    string
 	}{
 		{
-			"Empty Query Model",
-			&models.Query{},
+			"FilterExpression not present",
+			&models.Query{TableName: "testTable"},
+			false,
 			"",
-			0,
 		},
 		{
-			"StartFrom object present",
+			"FilterExpression present",
 			&models.Query{
-				StartFrom: map[string]interface{}{
-					"offset": float64(10),
+				TableName: "testTable",
+				RangeExp:  "first = :val1",
+				FilterExp: "fourth = :val2 OR contains(third, :val2)",
+				RangeValMap: map[string]interface{}{
+					":val1": float64(61),
+					":val2": float64(34),
 				},
 			},
-			" OFFSET 10",
-			10,
+			true,
+			"",
 		},
 		{
-			"StartFrom without float64 value",
+			"key attribute in the FilterExpression of a Scan",
 			&models.Query{
-				StartFrom: map[string]interface{}{
-					"offset": 10,
-				},
+				TableName:   "testTable",
+				FilterExp:   "second = :val1",
+				RangeValMap: map[string]interface{}{":val1": float64(61)},
 			},
+			true,
 			"",
-			0,
+		},
+		{
+			"key attribute in the FilterExpression of a Query",
+			&models.Query{
+				TableName:   "testTable",
+				RangeExp:    "first = :val1",
+				FilterExp:   "attribute_exists(second.inner)",
+				RangeValMap: map[string]interface{}{":val1": float64(61)},
+			},
+			false,
+			"Filter Expression can only contain non-primary key attributes: Primary key attribute: second\n",
+		},
+		{
+			"invalid FilterExpression",
+			&models.Query{
+				TableName:   "testTable",
+				FilterExp:   "begins_with(fourth, :val1)",
+				RangeValMap: map[string]interface{}{":val1": float64(61)},
+			},
+			false,
+			"Invalid FilterExpression: Incorrect operand type for operator or function; operator or function: begins_with, operand type: N\n",
 		},
 	}
 
 	for _, tc := range tests {
-		got1, got2 := parseOffset(tc.queryModel)
-		assert.Equal(t, got1, tc.want1)
-		assert.Equal(t, got2, tc.want2)
+		err := parseQueryFilter(tc.queryModel, "first", "second")
+		if tc.wantErr != "" {
+			assert.Equal(t, err.(*errors.Error).ErrorCode, "ValidationException")
+			assert.Equal(t, err.(*errors.Error).ErrorMessage, tc.wantErr)
+		} else {
+			assert.Equal(t, err, nil)
+		}
+		assert.Equal(t, tc.queryModel.Filter != nil, tc.wantFilter)
 	}
 }
 
-func Test_parseSpannerSorting(t *testing.T) {
+func Test_parseExclusiveStartKey(t *testing.T) {
 	tests := []struct {
-		testName     string
-		query        *models.Query
-		isCountQuery bool
-		pKey         string
-		sKey         string
-		want         string
+		testName   string
+		queryModel *models.Query
+		keys       []string
+		want1      string
+		want2      map[string]interface{}
+		wantErr    bool
 	}{
 		{
-			"empty Query & skey",
+			"no exclusive start key",
 			&models.Query{},
+			[]string{"first"},
+			"",
+			map[string]interface{}{},
 			false,
+		},
+		{
+			"partition key only",
+			&models.Query{
+				SortAscending: true,
+				StartFrom:     map[string]interface{}{"first": "a"},
+			},
+			[]string{"first"},
+			"(first > @startKey1)",
+			map[string]interface{}{"startKey1": "a"},
+			false,
+		},
+		{
+			"index and table keys in descending order",
+			&models.Query{
+				StartFrom: map[string]interface{}{"third": "c", "first": "a", "second": int64(2)},
+			},
+			[]string{"third", "first", "second"},
+			"(third < @startKey1) OR (third = @startKey1 AND first < @startKey2) OR (third = @startKey1 AND first = @startKey2 AND second < @startKey3)",
+			map[string]interface{}{"startKey1": "c", "startKey2": "a", "startKey3": int64(2)},
+			false,
+		},
+		{
+			"missing key attribute",
+			&models.Query{
+				StartFrom: map[string]interface{}{"first": "a"},
+			},
+			[]string{"first", "second"},
 			"",
+			map[string]interface{}{},
+			true,
+		},
+		{
+			"attribute which is not a key",
+			&models.Query{
+				StartFrom: map[string]interface{}{"first": "a", "offset": float64(10)},
+			},
+			[]string{"first", "second"},
 			"",
+			map[string]interface{}{"startKey1": "a"},
+			true,
+		},
+	}
+
+	for _, tc := range tests {
+		params := map[string]interface{}{}
+		got, err := parseExclusiveStartKey(tc.queryModel, tc.keys, params)
+		assert.Equal(t, err != nil, tc.wantErr)
+		assert.Equal(t, got, tc.want1)
+		assert.Equal(t, params, tc.want2)
+	}
+}
+
+func Test_parseSegment(t *testing.T) {
+	tableDDL := models.TableDDL
+	defer func() { models.TableDDL = tableDDL }()
+	models.TableDDL = map[string]map[string]string{
+		"testTable": {"first": "S", "second": "N"},
+	}
+
+	params := map[string]interface{}{}
+	assert.Equal(t, parseSegment(&models.Query{TableName: "testTable"}, "first", params), "")
+	assert.Equal(t, parseSegment(&models.Query{TableName: "testTable", TotalSegments: 1}, "first", params), "")
+	assert.Equal(t, params, map[string]interface{}{})
+
+	got := parseSegment(&models.Query{TableName: "testTable", Segment: 2, TotalSegments: 4}, "first", params)
+	assert.Equal(t, got, "MOD(MOD(FARM_FINGERPRINT(first), @totalSegments) + @totalSegments, @totalSegments) = @segment")
+	assert.Equal(t, params, map[string]interface{}{"segment": int64(2), "totalSegments": int64(4)})
+
+	got = parseSegment(&models.Query{TableName: "testTable", Segment: 0, TotalSegments: 4}, "second", params)
+	assert.Equal(t, got, "MOD(MOD(FARM_FINGERPRINT(CAST(second AS STRING)), @totalSegments) + @totalSegments, @totalSegments) = @segment")
+}
+
+func Test_addWhereConditions(t *testing.T) {
+	assert.Equal(t, addWhereConditions(" ", "", ""), " ")
+	assert.Equal(t, addWhereConditions(" ", "a = @a"), "WHERE (a = @a) ")
+	assert.Equal(t, addWhereConditions("WHERE b = 1 OR c = 2", "a = @a", "d = @d"), "WHERE ((b = 1 OR c = 2) AND (a = @a) ) AND (d = @d) ")
+}
+
+func Test_validateScanSegment(t *testing.T) {
+	tests := []struct {
+		testName      string
+		segment       *int64
+		totalSegments *int64
+		wantErr       bool
+	}{
+		{"valid segment", aws.Int64(3), aws.Int64(4), false},
+		{"first segment", aws.Int64(0), aws.Int64(1), false},
+		{"missing segment", nil, aws.Int64(4), true},
+		{"missing total segments", aws.Int64(0), nil, true},
+		{"segment out of range", aws.Int64(4), aws.Int64(4), true},
+		{"negative segment", aws.Int64(-1), aws.Int64(4), true},
+		{"too many segments", aws.Int64(0), aws.Int64(maxScanSegments + 1), true},
+	}
+
+	for _, tc := range tests {
+		err := validateScanSegment(tc.segment, tc.totalSegments)
+		assert.Equal(t, err != nil, tc.wantErr)
+	}
+}
+
+func Test_paginationKeys(t *testing.T) {
+	assert.Equal(t, paginationKeys("first", "", "first", ""), []string{"first"})
+	assert.Equal(t, paginationKeys("first", "second", "first", "second"), []string{"first", "second"})
+	assert.Equal(t, paginationKeys("first", "second", "third", ""), []string{"third", "first", "second"})
+	assert.Equal(t, paginationKeys("first", "", "third", "first"), []string{"third", "first"})
+}
+
+func Test_parseSpannerSorting(t *testing.T) {
+	tests := []struct {
+		testName string
+		query    *models.Query
+		keys     []string
+		want     string
+	}{
+		{
+			"empty Query & keys",
+			&models.Query{},
+			nil,
 			" ",
 		},
 		{
-			"empty Query but skey present",
+			"empty Query but keys present",
 			&models.Query{},
-			false,
-			"first",
-			"second",
-			" ORDER BY second DESC ",
+			[]string{"first", "second"},
+			" ORDER BY first DESC, second DESC ",
 		},
 		{
-			"empty Query but skey present",
+			"ascending order",
 			&models.Query{
 				SortAscending: true,
 			},
-			false,
-			"first",
-			"second",
-			" ORDER BY second ASC ",
+			[]string{"first", "second"},
+			" ORDER BY first ASC, second ASC ",
 		},
 		{
-			"isCountQuery is true",
+			"only count",
 			&models.Query{
 				SortAscending: true,
+				OnlyCount:     true,
 			},
-			true,
-			"first",
-			"second",
-			" ",
+			[]string{"first", "second"},
+			" ORDER BY first ASC, second ASC ",
 		},
 	}
 
 	for _, tc := range tests {
-		got := parseSpannerSorting(tc.query, tc.isCountQuery, tc.pKey, tc.sKey)
+		got := parseSpannerSorting(tc.query, tc.keys)
 		assert.Equal(t, got, tc.want)
 	}
 
@@ -713,43 +852,34 @@ func Test_parseSpannerSorting(t *testing.T) {
 
 func Test_parseLimit(t *testing.T) {
 	tests := []struct {
-		testName     string
-		queryModel   *models.Query
-		isCountQuery bool
-		want         string
+		testName   string
+		queryModel *models.Query
+		want       string
 	}{
 		{
 			"Empty Query Model",
 			&models.Query{},
-			false,
 			" LIMIT 5000 ",
 		},
-		{
-			"isCountQuery is true",
-			&models.Query{},
-			true,
-			"",
-		},
 		{
 			"custom Limit testcase",
 			&models.Query{
 				Limit: 100,
 			},
-			false,
 			" LIMIT 100",
 		},
 		{
-			"custom Limit with isCountQuery is true testcase",
+			"custom Limit with only count testcase",
 			&models.Query{
-				Limit: 100,
+				Limit:     100,
+				OnlyCount: true,
 			},
-			true,
-			"",
+			" LIMIT 100",
 		},
 	}
 
 	for _, tc := range tests {
-		got := parseLimit(tc.queryModel, tc.isCountQuery)
+		got := parseLimit(tc.queryModel)
 		assert.Equal(t, got, tc.want)
 	}
 
@@ -758,17 +888,6 @@ func Test_parseLimit(t *testing.T) {
 func TestTransactGetItems(t *testing.T) {
 	ctx := context.Background()
 
-	mockStorage := new(MockStorage)
-	mockStorage.On("SpannerTransactGetItems",
-		mock.Anything,
-		map[string][]string{"test_table": {"emp_id", "name"}},
-		mock.Anything,
-		mock.Anything,
-	).Return([]map[string]interface{}{
-		{"emp_id": 1, "name": "John Doe"},
-		{"emp_id": 2, "name": "Jane Doe"},
-	}, nil)
-
 	models.DbConfigMap = make(map[string]models.TableConfig)
 	models.DbConfigMap["test_table"] = models.TableConfig{
 		ActualTable:  "test_table",
@@ -778,21 +897,34 @@ func TestTransactGetItems(t *testing.T) {
 	models.TableColumnMap = make(map[string][]string)
 	models.TableColumnMap[utils.ChangeTableNameForSpanner("test_table")] = []string{"emp_id", "name"}
 
-	s := &spannerService{st: mockStorage}
-	tableProjectionCols := map[string][]string{"test_table": {"emp_id", "name"}}
-	pValues := map[string]interface{}{"emp_id": 1}
-	sValues := map[string]interface{}{}
-
-	result, _ := s.TransactGetItem(ctx, tableProjectionCols, pValues, sValues)
+	// the key is read with the projection of the first get, and removed again
+	mockStorage := new(MockStorage)
+	mockStorage.On("SpannerTransactGetItems", mock.Anything, []storage.TransactGet{
+		{Table: "test_table", PartitionKey: 1, ProjectionCols: []string{"name", "emp_id"}},
+		{Table: "test_table", PartitionKey: 2},
+		{Table: "test_table", PartitionKey: 3},
+	}).Return([]map[string]interface{}{
+		{"emp_id": 1, "name": "John Doe"},
+		{"emp_id": 2, "name": "Jane Doe"},
+		nil,
+	}, nil)
 
-	assert.Equal(t, 1, result[0]["emp_id"])
-	assert.Equal(t, "John Doe", result[0]["name"])
+	s := &spannerService{st: mockStorage}
+	result, err := s.TransactGetItems(ctx, []models.GetItemMeta{
+		{TableName: "test_table", PrimaryKeyMap: map[string]interface{}{"emp_id": 1}, ProjectionExpression: "name"},
+		{TableName: "test_table", PrimaryKeyMap: map[string]interface{}{"emp_id": 2}},
+		{TableName: "test_table", PrimaryKeyMap: map[string]interface{}{"emp_id": 3}},
+	})
+
+	assert.Equal(t, nil, err)
+	assert.Equal(t, map[string]interface{}{"name": "John Doe"}, result[0])
 	assert.Equal(t, 2, result[1]["emp_id"])
 	assert.Equal(t, "Jane Doe", result[1]["name"])
+	assert.Equal(t, true, result[2] == nil)
 }
 
-func (m *MockStorage) SpannerTransactGetItems(ctx context.Context, tableProjectionCols map[string][]string, pValues map[string]interface{}, sValues map[string]interface{}) ([]map[string]interface{}, error) {
-	args := m.Called(ctx, tableProjectionCols, pValues, sValues)
+func (m *MockStorage) SpannerTransactGetItems(ctx context.Context, gets []storage.TransactGet) ([]map[string]interface{}, error) {
+	args := m.Called(ctx, gets)
 	return args.Get(0).([]map[string]interface{}), args.Error(1)
 }
 
@@ -815,23 +947,13 @@ func (m *MockStorage) TransactWriteSpannerDel(ctx context.Context, table string,
 	return args.Get(0).(*spanner.Mutation), args.Error(1)
 }
 
-func (m *MockStorage) TransactWriteSpannerAdd(ctx context.Context, table string, n map[string]interface{}, eval *models.Eval, expr *models.UpdateExpressionCondition, txn *spanner.ReadWriteTransaction) (map[string]interface{}, *spanner.Mutation, error) {
-	args := m.Called(ctx, table, n, eval, expr, txn)
-	return args.Get(0).(map[string]interface{}), args.Get(1).(*spanner.Mutation), args.Error(2)
-}
-
-func (m *MockStorage) TransactWriteSpannerRemove(ctx context.Context, table string, n map[string]interface{}, eval *models.Eval, expr *models.UpdateExpressionCondition, colsToRemove []string, txn *spanner.ReadWriteTransaction) (*spanner.Mutation, error) {
-	args := m.Called(ctx, table, n, eval, expr, txn)
-	return args.Get(0).(*spanner.Mutation), args.Error(1)
-}
-
 type MockConfig struct{}
 
 func (m *MockConfig) GetTableConf(tableName string) (models.TableConfig, error) {
 	return models.TableConfig{ActualTable: tableName}, nil
 }
 
-func mockCreateConditionExpression(conditionExp string, expressionAttr map[string]interface{}) (*models.Eval, error) {
+func mockCreateConditionExpression(conditionExp string, names map[string]string, expressionAttr map[string]interface{}) (*models.Eval, error) {
 	return &models.Eval{}, nil
 }
 
@@ -858,6 +980,7 @@ func TestTransactWritePut(t *testing.T) {
 	oldRes := map[string]interface{}{"Age": 30}
 	expr := &models.UpdateExpressionCondition{}
 	conditionExp := "#age > :minAge"
+	expressionAttributeNames := map[string]string{"#age": "Age"}
 	expressionAttr := map[string]interface{}{":minAge": 18}
 	mockStorage := new(MockStorage)
 
@@ -883,7 +1006,7 @@ func TestTransactWritePut(t *testing.T) {
 	svc := &spannerService{
 		st: mockStorage, // Assign the mock storage to the struct field
 	}
-	result, _, _ := svc.TransactWritePut(ctx, tableName, putObj, expr, conditionExp, expressionAttr, oldRes, mockTxn)
+	result, _, _ := svc.TransactWritePut(ctx, tableName, putObj, expr, conditionExp, expressionAttributeNames, expressionAttr, oldRes, mockTxn)
 
 	expected := map[string]interface{}{
 		"Name": "John",
@@ -906,6 +1029,7 @@ func TestTransactWriteDel(t *testing.T) {
 	tableName := "TestTable"
 	attrMap := map[string]interface{}{"id": 1}
 	conditionExp := "#age > :minAge"
+	expressionAttributeNames := map[string]string{"#age": "Age"}
 	expressionAttr := map[string]interface{}{":minAge": 18}
 	expr := &models.UpdateExpressionCondition{}
 	mockStorage := new(MockStorage)
@@ -923,7 +1047,7 @@ func TestTransactWriteDel(t *testing.T) {
 		Return(map[string]interface{}{"Age": 30, "Name": "John"}, map[string]interface{}{}, nil)
 
 	svc := &spannerService{st: mockStorage}
-	result, _, _ := svc.TransactWriteDel(ctx, tableName, attrMap, conditionExp, expressionAttr, expr, mockTxn)
+	result, _, _ := svc.TransactWriteDel(ctx, tableName, attrMap, conditionExp, expressionAttributeNames, expressionAttr, expr, mockTxn)
 
 	expected := map[string]interface{}{"Age": 30, "Name": "John"}
 	if !reflect.DeepEqual(result, expected) {
@@ -933,60 +1057,6 @@ func TestTransactWriteDel(t *testing.T) {
 	mockStorage.AssertExpectations(t)
 }
 
-func TestTransactWriteAdd(t *testing.T) {
-	ctx := context.Background()
-	mockTxn := &spanner.ReadWriteTransaction{}
-	utils.CreateConditionExpressionFunc = mockCreateConditionExpression
-	defer func() { utils.CreateConditionExpressionFunc = utils.CreateConditionExpression }()
-
-	tableName := "TestTable"
-	attrMap := map[string]interface{}{"Name": "John"}
-	oldRes := map[string]interface{}{"Age": 30}
-	conditionExp := "#age > :minAge"
-	expressionAttr := map[string]interface{}{":minAge": 18}
-	expr := &models.UpdateExpressionCondition{}
-	mockStorage := new(MockStorage)
-
-	mockStorage.On("TransactWriteSpannerAdd", mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything, mockTxn).
-		Return(map[string]interface{}{"Name": "John"}, &spanner.Mutation{}, nil)
-
-	svc := &spannerService{st: mockStorage}
-	result, _, _ := svc.TransactWriteAdd(ctx, tableName, attrMap, conditionExp, attrMap, expressionAttr, expr, oldRes, mockTxn)
-
-	expected := map[string]interface{}{"Name": "John", "Age": 30}
-	if !reflect.DeepEqual(result, expected) {
-		t.Errorf("Expected result %+v, got %+v", expected, result)
-	}
-
-	mockStorage.AssertExpectations(t)
-}
-
-func TestTransactWriteRemove(t *testing.T) {
-	ctx := context.Background()
-	mockTxn := &spanner.ReadWriteTransaction{}
-	utils.CreateConditionExpressionFunc = mockCreateConditionExpression
-	defer func() { utils.CreateConditionExpressionFunc = utils.CreateConditionExpression }()
-
-	tableName := "TestTable"
-	oldRes := map[string]interface{}{"Age": 30, "Name": "John"}
-	expr := &models.UpdateExpressionCondition{}
-	actionValue := "Name"
-	updateAttr := models.UpdateAttr{PrimaryKeyMap: map[string]interface{}{}}
-	mockStorage := new(MockStorage)
-
-	mockStorage.On("TransactWriteSpannerRemove", mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything, mockTxn).
-		Return(&spanner.Mutation{}, nil)
-
-	svc := &spannerService{st: mockStorage}
-	result, _, _ := svc.TransactWriteRemove(ctx, tableName, updateAttr, actionValue, expr, oldRes, mockTxn)
-
-	expected := map[string]interface{}{"Age": 30}
-	if !reflect.DeepEqual(result, expected) {
-		t.Errorf("Expected result %+v, got %+v", expected, result)
-	}
-
-	mockStorage.AssertExpectations(t)
-}
 func TestParsePartiQlToSpannerforSelect(t *testing.T) {
 
 	// Set up the ExecuteStatement for the test case
@@ -1005,7 +1075,7 @@ func TestParsePartiQlToSpannerforSelect(t *testing.T) {
 
 	// Call the function to test
 	ctx := context.Background()
-	stmt, err := parsePartiQlToSpannerforSelect(ctx, executeStatement)
+	stmt, _, err := parsePartiQlToSpannerforSelect(ctx, executeStatement)
 
 	// Validate results
 	if err != nil {
@@ -0,0 +1,776 @@
+// Copyright 2020 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package services
+
+import (
+	"context"
+	"fmt"
+	"maps"
+	"regexp"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"cloud.google.com/go/spanner"
+	"github.com/cloudspannerecosystem/dynamodb-adapter/models"
+	"github.com/cloudspannerecosystem/dynamodb-adapter/pkg/errors"
+	"github.com/cloudspannerecosystem/dynamodb-adapter/pkg/logger"
+	"github.com/cloudspannerecosystem/dynamodb-adapter/storage"
+	"github.com/cloudspannerecosystem/dynamodb-adapter/utils"
+	"google.golang.org/grpc/codes"
+)
+
+const (
+	tableStatusActive   = "ACTIVE"
+	tableStatusDeleting = "DELETING"
+
+	indexTypeGlobal = "GLOBAL"
+	indexTypeLocal  = "LOCAL"
+
+	maxGlobalSecondaryIndexes = 20
+	maxLocalSecondaryIndexes  = 5
+
+	maxListTablesLimit = 100
+)
+
+// adapterIndexTableDDL creates the metadata table holding the secondary index definitions
+// of the tables created through the CreateTable API, when the init code has not created it
+const adapterIndexTableDDL = `CREATE TABLE IF NOT EXISTS dynamodb_adapter_index_ddl (
+	tableName STRING(MAX) NOT NULL,
+	indexName STRING(MAX) NOT NULL,
+	spannerIndexName STRING(MAX),
+	indexType STRING(MAX),
+	partitionKey STRING(MAX),
+	sortKey STRING(MAX),
+	projectionType STRING(MAX),
+	nonKeyAttributes ARRAY<STRING(MAX)>,
+) PRIMARY KEY (tableName, indexName)`
+
+var (
+	adapterTableDDLColumns = []string{"column", "tableName", "dynamoDataType", "originalColumn", "partitionKey", "sortKey", "spannerIndexName", "actualTable", "spannerDataType"}
+	adapterIndexDDLColumns = []string{"tableName", "indexName", "spannerIndexName", "indexType", "partitionKey", "sortKey", "projectionType", "nonKeyAttributes"}
+
+	tableNameRegex     = regexp.MustCompile(`^[a-zA-Z0-9_.-]{3,255}$`)
+	invalidColumnRegex = regexp.MustCompile(`[^a-zA-Z0-9_]`)
+)
+
+// tableMetaMux serializes the table management APIs while they swap the in-memory table metadata
+var tableMetaMux sync.Mutex
+
+// tableColumn is a single column of a table managed by the adapter
+type tableColumn struct {
+	Column         string
+	OriginalColumn string
+	DynamoDataType string
+	SpannerType    string
+}
+
+// CreateTable creates the Spanner table and indexes for a DynamoDB table definition,
+// stores the adapter metadata and makes the table available without a restart
+func CreateTable(ctx context.Context, meta models.CreateTableMeta) (*models.TableDescription, error) {
+	if err := validateCreateTable(meta); err != nil {
+		return nil, err
+	}
+	if err := validateTags(meta.Tags); err != nil {
+		return nil, err
+	}
+	spannerTable := utils.ChangeTableNameForSpanner(meta.TableName)
+
+	tableMetaMux.Lock()
+	defer tableMetaMux.Unlock()
+
+	if _, ok := models.DbConfigMap[spannerTable]; ok {
+		return nil, errors.New("ResourceInUseException", "Table already exists: "+meta.TableName)
+	}
+	tableConf, columns := buildTableMetadata(spannerTable, meta)
+	if len(meta.Tags) > 0 {
+		// the tags are stored with the metadata of the table, so that the table is never created without them
+		if err := createTagsTable(ctx); err != nil {
+			return nil, err
+		}
+	}
+
+	err := storage.GetStorageInstance().SpannerUpdateDDL(ctx, buildCreateTableDDL(spannerTable, tableConf, columns))
+	if err != nil {
+		if spanner.ErrCode(err) == codes.AlreadyExists {
+			return nil, errors.New("ResourceInUseException", "Table already exists: "+meta.TableName)
+		}
+		return nil, errors.New("InternalServerError", err)
+	}
+
+	mutations := buildTableMetadataMutations(meta.TableName, spannerTable, tableConf, columns)
+	err = storage.GetStorageInstance().SpannerApply(ctx, append(mutations, tagMutations(spannerTable, meta.Tags)...))
+	if err != nil {
+		// the table is dropped again, so that it is not left in Spanner without its metadata
+		indexes := make([]string, 0, len(tableConf.Indices))
+		for _, name := range sortedIndexNames(tableConf) {
+			indexes = append(indexes, tableConf.Indices[name].SpannerIndexName)
+		}
+		if dropErr := storage.GetStorageInstance().SpannerUpdateDDL(ctx, buildDropTableDDL(spannerTable, indexes)); dropErr != nil {
+			logger.Error("table created in Spanner but its metadata could not be stored nor the table dropped: ", spannerTable, dropErr)
+		}
+		return nil, errors.New("InternalServerError", err)
+	}
+	registerTable(spannerTable, tableConf, columns)
+
+	desc := describeTableConf(meta.TableName, tableConf)
+	desc.TableStatus = tableStatusActive
+	if meta.BillingMode != "" {
+		desc.BillingModeSummary = &models.BillingModeSummary{BillingMode: meta.BillingMode}
+	}
+	desc.ProvisionedThroughput = meta.ProvisionedThroughput
+	return &desc, nil
+}
+
+// DeleteTable drops the Spanner table with its indexes, removes the adapter metadata
+// of the table and evicts it from the in-memory metadata
+func DeleteTable(ctx context.Context, tableName string) (*models.TableDescription, error) {
+	spannerTable := utils.ChangeTableNameForSpanner(tableName)
+
+	tableMetaMux.Lock()
+	defer tableMetaMux.Unlock()
+
+	tableConf, ok := models.DbConfigMap[spannerTable]
+	if !ok {
+		return nil, errors.New("ResourceNotFoundException", "Requested resource not found: Table: "+tableName+" not found")
+	}
+	if tableHasIndexOperation(spannerTable) {
+		return nil, errors.New("ResourceInUseException", "Attempt to change a resource which is still in use: Table is being updated: "+tableName)
+	}
+	desc := describeTableConf(tableName, tableConf)
+	desc.TableStatus = tableStatusDeleting
+
+	indexes, err := storage.GetStorageInstance().SpannerTableIndexes(ctx, spannerTable)
+	if err != nil {
+		return nil, errors.New("InternalServerError", err)
+	}
+	if err := storage.GetStorageInstance().SpannerUpdateDDL(ctx, buildDropTableDDL(spannerTable, indexes)); err != nil {
+		if spanner.ErrCode(err) == codes.NotFound {
+			return nil, errors.New("ResourceNotFoundException", "Requested resource not found: Table: "+tableName+" not found")
+		}
+		return nil, errors.New("InternalServerError", err)
+	}
+
+	mutations := []*spanner.Mutation{
+		spanner.Delete("dynamodb_adapter_table_ddl", spanner.Key{spannerTable}.AsPrefix()),
+		spanner.Delete("dynamodb_adapter_config_manager", spanner.KeySetFromKeys(spanner.Key{spannerTable}, spanner.Key{tableName})),
+	}
+	// the index, TimeToLive and tags metadata tables only exist once they have been used
+	if len(tableConf.Indices) > 0 {
+		mutations = append(mutations, spanner.Delete("dynamodb_adapter_index_ddl", spanner.Key{spannerTable}.AsPrefix()))
+	}
+	if tableConf.TTLAttribute != "" {
+		mutations = append(mutations, spanner.Delete("dynamodb_adapter_ttl", spanner.Key{spannerTable}))
+	}
+	if exists, err := tagsTableExists(ctx); err == nil && exists {
+		mutations = append(mutations, spanner.Delete("dynamodb_adapter_tags", spanner.Key{spannerTable}.AsPrefix()))
+	}
+	if err := storage.GetStorageInstance().SpannerApply(ctx, mutations); err != nil {
+		logger.Error("table dropped in Spanner but its metadata could not be removed: ", spannerTable, err)
+		return nil, errors.New("InternalServerError", err)
+	}
+	unregisterTable(spannerTable)
+
+	tableStatsMux.Lock()
+	delete(tableStatsCache, spannerTable)
+	tableStatsMux.Unlock()
+	return &desc, nil
+}
+
+// DescribeTable returns the DynamoDB TableDescription of a table, built from the in-memory
+// metadata and the indexes found in the Spanner INFORMATION_SCHEMA
+func DescribeTable(ctx context.Context, tableName string) (*models.TableDescription, error) {
+	spannerTable := utils.ChangeTableNameForSpanner(tableName)
+	tableConf, ok := models.DbConfigMap[spannerTable]
+	if !ok {
+		return nil, errors.New("ResourceNotFoundException", "Requested resource not found: Table: "+tableName+" not found")
+	}
+	indexes, err := storage.GetStorageInstance().SpannerIndexSchemas(ctx, spannerTable)
+	if err != nil {
+		return nil, errors.New("InternalServerError", err)
+	}
+
+	tableConf = addSpannerIndexes(tableConf, indexes)
+	states := make(map[string]string, len(indexes))
+	for _, idx := range indexes {
+		states[idx.Name] = idx.State
+	}
+	desc := describeTableConf(tableName, tableConf)
+	setIndexStatuses(&desc, tableConf, states)
+	desc.TableStatus = tableStatusActive
+	desc.CreationDateTime = tableCreationDateTime(ctx)
+	desc.BillingModeSummary = &models.BillingModeSummary{BillingMode: "PAY_PER_REQUEST"}
+	desc.ProvisionedThroughput = &models.ProvisionedThroughput{}
+	desc.ItemCount, desc.TableSizeBytes = tableStatistics(spannerTable)
+	return &desc, nil
+}
+
+// ListTables returns the DynamoDB names of the tables managed by the adapter in ascending order.
+// LastEvaluatedTableName is set when the page is full and more tables may follow.
+func ListTables(ctx context.Context, meta models.ListTablesMeta) (*models.ListTablesOutput, error) {
+	if err := validateListTables(meta); err != nil {
+		return nil, err
+	}
+	limit := meta.Limit
+	if limit == 0 {
+		limit = maxListTablesLimit
+	}
+	// one extra name tells whether there is a next page
+	names, err := storage.GetStorageInstance().SpannerListTables(ctx, meta.ExclusiveStartTableName, limit+1)
+	if err != nil {
+		return nil, errors.New("InternalServerError", err)
+	}
+	output := &models.ListTablesOutput{TableNames: []string{}}
+	if int64(len(names)) > limit {
+		names = names[:limit]
+		output.LastEvaluatedTableName = names[limit-1]
+	}
+	output.TableNames = append(output.TableNames, names...)
+	return output, nil
+}
+
+// validateListTables validates the ListTables request in the same way DynamoDB does
+func validateListTables(meta models.ListTablesMeta) error {
+	if meta.Limit < 0 || meta.Limit > maxListTablesLimit {
+		return errors.New("ValidationException", fmt.Sprintf("1 validation error detected: Value '%d' at 'limit' failed to satisfy constraint: Member must have value between 1 and %d", meta.Limit, maxListTablesLimit))
+	}
+	if meta.ExclusiveStartTableName != "" && !tableNameRegex.MatchString(meta.ExclusiveStartTableName) {
+		return errors.New("ValidationException", "ExclusiveStartTableName must be between 3 and 255 characters long and match the pattern [a-zA-Z0-9_.-]+")
+	}
+	return nil
+}
+
+// addSpannerIndexes adds the Spanner indexes of the table which have no adapter index metadata,
+// e.g. the indexes of the tables configured through dynamodb_adapter_table_ddl, as global
+// secondary indexes. Only the first two index columns can be described as the index key, and the
+// projection is ALL as queries on these indexes read the items from the base table.
+func addSpannerIndexes(tableConf models.TableConfig, indexes []storage.IndexSchema) models.TableConfig {
+	known := make(map[string]struct{}, len(tableConf.Indices))
+	for name, idx := range tableConf.Indices {
+		known[name] = struct{}{}
+		known[idx.SpannerIndexName] = struct{}{}
+	}
+	indices := maps.Clone(tableConf.Indices)
+	for _, idx := range indexes {
+		if _, ok := known[idx.Name]; ok || len(idx.KeyColumns) == 0 {
+			continue
+		}
+		conf := models.TableConfig{
+			PartitionKey:     idx.KeyColumns[0],
+			SpannerIndexName: idx.Name,
+			ActualTable:      tableConf.ActualTable,
+			IndexType:        indexTypeGlobal,
+			ProjectionType:   "ALL",
+		}
+		if len(idx.KeyColumns) > 1 {
+			conf.SortKey = idx.KeyColumns[1]
+		}
+		if indices == nil {
+			indices = make(map[string]models.TableConfig)
+		}
+		indices[idx.Name] = conf
+	}
+	tableConf.Indices = indices
+	return tableConf
+}
+
+var (
+	creationTimeMux      sync.Mutex
+	databaseCreationTime float64
+)
+
+// tableCreationDateTime returns the CreationDateTime of the tables in seconds since the epoch.
+// Spanner does not record when a table was created, so the creation time of the database is used.
+func tableCreationDateTime(ctx context.Context) float64 {
+	creationTimeMux.Lock()
+	defer creationTimeMux.Unlock()
+	if databaseCreationTime != 0 {
+		return databaseCreationTime
+	}
+	createTime, err := storage.GetStorageInstance().SpannerDatabaseCreateTime(ctx)
+	if err != nil {
+		logger.Error("failed to read the database creation time: ", err)
+		return 0
+	}
+	databaseCreationTime = float64(createTime.UnixMilli()) / 1000
+	return databaseCreationTime
+}
+
+// tableStatsRefreshInterval is how long the ItemCount and TableSizeBytes estimates are
+// kept, DynamoDB itself only updates them approximately every six hours
+const tableStatsRefreshInterval = 6 * time.Hour
+
+// tableStats holds the cached ItemCount and TableSizeBytes estimates of a table
+type tableStats struct {
+	itemCount   int64
+	sizeBytes   int64
+	refreshedAt time.Time
+	refreshing  bool
+}
+
+var (
+	tableStatsMux   sync.Mutex
+	tableStatsCache = make(map[string]tableStats)
+)
+
+// tableStatistics returns the cached estimates of the table. Counting the rows of a large table
+// is expensive, so stale estimates are refreshed in the background instead of in the request.
+func tableStatistics(spannerTable string) (int64, int64) {
+	tableStatsMux.Lock()
+	defer tableStatsMux.Unlock()
+	stats := tableStatsCache[spannerTable]
+	if !stats.refreshing && time.Since(stats.refreshedAt) > tableStatsRefreshInterval {
+		stats.refreshing = true
+		tableStatsCache[spannerTable] = stats
+		go refreshTableStatistics(spannerTable)
+	}
+	return stats.itemCount, stats.sizeBytes
+}
+
+// refreshTableStatistics reads the estimates of the table from Spanner into the cache
+func refreshTableStatistics(spannerTable string) {
+	ctx, cancel := context.WithTimeout(context.Background(), time.Minute)
+	defer cancel()
+	itemCount, sizeBytes, err := storage.GetStorageInstance().SpannerTableStatistics(ctx, spannerTable)
+
+	tableStatsMux.Lock()
+	defer tableStatsMux.Unlock()
+	if err != nil {
+		logger.Error("failed to read the table statistics: ", spannerTable, err)
+		stats := tableStatsCache[spannerTable]
+		stats.refreshing = false
+		tableStatsCache[spannerTable] = stats
+		return
+	}
+	tableStatsCache[spannerTable] = tableStats{itemCount: itemCount, sizeBytes: sizeBytes, refreshedAt: time.Now()}
+}
+
+// buildDropTableDDL generates the Spanner DDL statements dropping the table, its indexes have to be dropped first
+func buildDropTableDDL(spannerTable string, indexes []string) []string {
+	statements := make([]string, 0, len(indexes)+1)
+	for _, idx := range indexes {
+		statements = append(statements, "DROP INDEX `"+idx+"`")
+	}
+	return append(statements, "DROP TABLE `"+spannerTable+"`")
+}
+
+// validateCreateTable validates the CreateTable request in the same way DynamoDB does
+func validateCreateTable(meta models.CreateTableMeta) error {
+	if !tableNameRegex.MatchString(meta.TableName) {
+		return errors.New("ValidationException", "TableName must be between 3 and 255 characters long and match the pattern [a-zA-Z0-9_.-]+")
+	}
+	if strings.Contains(meta.TableName, ".") {
+		return errors.New("ValidationException", "TableName containing '.' is not supported by the adapter")
+	}
+	attrTypes := make(map[string]string)
+	for _, attr := range meta.AttributeDefinitions {
+		switch attr.AttributeType {
+		case "S", "N", "B":
+		default:
+			return errors.New("ValidationException", "Invalid AttributeType "+attr.AttributeType+" for attribute "+attr.AttributeName)
+		}
+		if _, ok := attrTypes[attr.AttributeName]; ok {
+			return errors.New("ValidationException", "Duplicate AttributeName in AttributeDefinitions: "+attr.AttributeName)
+		}
+		attrTypes[attr.AttributeName] = attr.AttributeType
+	}
+
+	used := make(map[string]struct{})
+	if err := validateKeySchema("table", meta.KeySchema, attrTypes, used); err != nil {
+		return err
+	}
+	if len(meta.GlobalSecondaryIndexes) > maxGlobalSecondaryIndexes {
+		return errors.New("ValidationException", fmt.Sprintf("One or more parameter values were invalid: GlobalSecondaryIndexes count exceeds the per-table limit of %d", maxGlobalSecondaryIndexes))
+	}
+	if len(meta.LocalSecondaryIndexes) > maxLocalSecondaryIndexes {
+		return errors.New("ValidationException", fmt.Sprintf("One or more parameter values were invalid: LocalSecondaryIndexes count exceeds the per-table limit of %d", maxLocalSecondaryIndexes))
+	}
+	if len(meta.LocalSecondaryIndexes) > 0 && len(meta.KeySchema) < 2 {
+		return errors.New("ValidationException", "One or more parameter values were invalid: Table KeySchema does not have a range key, which is required when specifying a LocalSecondaryIndex")
+	}
+
+	indexNames := make(map[string]struct{})
+	for _, idx := range meta.GlobalSecondaryIndexes {
+		if err := validateSecondaryIndex(idx, indexNames, attrTypes, used); err != nil {
+			return err
+		}
+	}
+	for _, idx := range meta.LocalSecondaryIndexes {
+		if err := validateSecondaryIndex(idx, indexNames, attrTypes, used); err != nil {
+			return err
+		}
+		if len(idx.KeySchema) != 2 || idx.KeySchema[0].AttributeName != meta.KeySchema[0].AttributeName {
+			return errors.New("ValidationException", "One or more parameter values were invalid: Index KeySchema does not have the same leading hash key as table KeySchema for index: "+idx.IndexName)
+		}
+	}
+	if len(used) != len(attrTypes) {
+		return errors.New("ValidationException", "One or more parameter values were invalid: Number of attributes in KeySchema does not exactly match number of attributes defined in AttributeDefinitions")
+	}
+	return nil
+}
+
+// validateKeySchema checks a table or index key schema and records the attributes it uses
+func validateKeySchema(owner string, keySchema []models.KeySchemaElement, attrTypes map[string]string, used map[string]struct{}) error {
+	if len(keySchema) < 1 || len(keySchema) > 2 {
+		return errors.New("ValidationException", "KeySchema of "+owner+" must have 1 or 2 elements")
+	}
+	if keySchema[0].KeyType != "HASH" {
+		return errors.New("ValidationException", "Invalid KeySchema of "+owner+": The first KeySchemaElement is not a HASH key type")
+	}
+	if len(keySchema) == 2 {
+		if keySchema[1].KeyType != "RANGE" {
+			return errors.New("ValidationException", "Invalid KeySchema of "+owner+": The second KeySchemaElement is not a RANGE key type")
+		}
+		if keySchema[0].AttributeName == keySchema[1].AttributeName {
+			return errors.New("ValidationException", "Invalid KeySchema of "+owner+": Both the Hash Key and the Range Key element in the KeySchema have the same name")
+		}
+	}
+	for _, key := range keySchema {
+		if _, ok := attrTypes[key.AttributeName]; !ok {
+			return errors.New("ValidationException", "One or more parameter values were invalid: Some index key attributes are not defined in AttributeDefinitions. Keys: ["+key.AttributeName+"]")
+		}
+		used[key.AttributeName] = struct{}{}
+	}
+	return nil
+}
+
+// validateSecondaryIndex checks the name, key schema and projection of a secondary index
+func validateSecondaryIndex(idx models.SecondaryIndex, indexNames map[string]struct{}, attrTypes map[string]string, used map[string]struct{}) error {
+	if !tableNameRegex.MatchString(idx.IndexName) {
+		return errors.New("ValidationException", "IndexName must be between 3 and 255 characters long and match the pattern [a-zA-Z0-9_.-]+")
+	}
+	if _, ok := indexNames[idx.IndexName]; ok {
+		return errors.New("ValidationException", "One or more parameter values were invalid: Duplicate index name: "+idx.IndexName)
+	}
+	indexNames[idx.IndexName] = struct{}{}
+	if err := validateKeySchema("index "+idx.IndexName, idx.KeySchema, attrTypes, used); err != nil {
+		return err
+	}
+	switch idx.Projection.ProjectionType {
+	case "INCLUDE":
+		if len(idx.Projection.NonKeyAttributes) == 0 {
+			return errors.New("ValidationException", "One or more parameter values were invalid: NonKeyAttributes must be specified for ProjectionType INCLUDE on index: "+idx.IndexName)
+		}
+	case "ALL", "KEYS_ONLY":
+		if len(idx.Projection.NonKeyAttributes) > 0 {
+			return errors.New("ValidationException", "One or more parameter values were invalid: ProjectionType is "+idx.Projection.ProjectionType+", but NonKeyAttributes is specified on index: "+idx.IndexName)
+		}
+	default:
+		return errors.New("ValidationException", "Invalid ProjectionType "+idx.Projection.ProjectionType+" on index: "+idx.IndexName)
+	}
+	return nil
+}
+
+// spannerColumnName converts a DynamoDB attribute name into a valid Spanner column name
+func spannerColumnName(attributeName string) string {
+	return invalidColumnRegex.ReplaceAllString(attributeName, "_")
+}
+
+// spannerIndexName makes the Spanner index name for a DynamoDB index, index names
+// are scoped to the database in Spanner so they are prefixed with the table name
+func spannerIndexName(spannerTable, indexName string) string {
+	return spannerTable + "_" + spannerColumnName(indexName)
+}
+
+// buildTableMetadata converts the CreateTable request into the adapter table config and columns
+func buildTableMetadata(spannerTable string, meta models.CreateTableMeta) (models.TableConfig, []tableColumn) {
+	tableConf := models.TableConfig{
+		PartitionKey: spannerColumnName(meta.KeySchema[0].AttributeName),
+		ActualTable:  spannerTable,
+	}
+	if len(meta.KeySchema) == 2 {
+		tableConf.SortKey = spannerColumnName(meta.KeySchema[1].AttributeName)
+	}
+
+	addIndex := func(idx models.SecondaryIndex, indexType string) {
+		if tableConf.Indices == nil {
+			tableConf.Indices = make(map[string]models.TableConfig)
+		}
+		tableConf.Indices[idx.IndexName] = buildIndexConf(spannerTable, idx, indexType)
+	}
+	for _, idx := range meta.GlobalSecondaryIndexes {
+		addIndex(idx, indexTypeGlobal)
+	}
+	for _, idx := range meta.LocalSecondaryIndexes {
+		addIndex(idx, indexTypeLocal)
+	}
+
+	columns := make([]tableColumn, 0, len(meta.AttributeDefinitions))
+	for _, attr := range meta.AttributeDefinitions {
+		columns = append(columns, tableColumn{
+			Column:         spannerColumnName(attr.AttributeName),
+			OriginalColumn: attr.AttributeName,
+			DynamoDataType: attr.AttributeType,
+			SpannerType:    utils.ConvertDynamoTypeToSpannerType(attr.AttributeType),
+		})
+	}
+	// keep the key columns first so that the table layout follows the key schema
+	isKey := func(col string) bool { return col == tableConf.PartitionKey || col == tableConf.SortKey }
+	sort.SliceStable(columns, func(i, j int) bool {
+		return isKey(columns[i].Column) && !isKey(columns[j].Column)
+	})
+	return tableConf, columns
+}
+
+// buildIndexConf converts a secondary index definition into the adapter index config
+func buildIndexConf(spannerTable string, idx models.SecondaryIndex, indexType string) models.TableConfig {
+	conf := models.TableConfig{
+		PartitionKey:     spannerColumnName(idx.KeySchema[0].AttributeName),
+		DDBIndexName:     idx.IndexName,
+		SpannerIndexName: spannerIndexName(spannerTable, idx.IndexName),
+		ActualTable:      spannerTable,
+		IndexType:        indexType,
+		ProjectionType:   idx.Projection.ProjectionType,
+		NonKeyAttributes: idx.Projection.NonKeyAttributes,
+	}
+	if len(idx.KeySchema) == 2 {
+		conf.SortKey = spannerColumnName(idx.KeySchema[1].AttributeName)
+	}
+	return conf
+}
+
+// buildCreateTableDDL generates the Spanner DDL statements for the table and its indexes
+func buildCreateTableDDL(spannerTable string, tableConf models.TableConfig, columns []tableColumn) []string {
+	columnDefs := make([]string, 0, len(columns))
+	for _, col := range columns {
+		def := "`" + col.Column + "` " + col.SpannerType
+		if col.Column == tableConf.PartitionKey || col.Column == tableConf.SortKey {
+			def += " NOT NULL"
+		}
+		columnDefs = append(columnDefs, def)
+	}
+	primaryKey := "`" + tableConf.PartitionKey + "`"
+	if tableConf.SortKey != "" {
+		primaryKey += ", `" + tableConf.SortKey + "`"
+	}
+	statements := []string{fmt.Sprintf("CREATE TABLE `%s` (\n\t%s\n) PRIMARY KEY (%s)", spannerTable, strings.Join(columnDefs, ",\n\t"), primaryKey)}
+
+	if len(tableConf.Indices) == 0 {
+		return statements
+	}
+	statements = append(statements, adapterIndexTableDDL)
+	for _, name := range sortedIndexNames(tableConf) {
+		statements = append(statements, buildCreateIndexDDL(spannerTable, tableConf, tableConf.Indices[name], columns))
+	}
+	return statements
+}
+
+// buildCreateIndexDDL generates the Spanner DDL for a secondary index. Indexes are null filtered,
+// so that items without the index key attributes are not part of the index, as in DynamoDB.
+func buildCreateIndexDDL(spannerTable string, tableConf, idx models.TableConfig, columns []tableColumn) string {
+	keys := "`" + idx.PartitionKey + "`"
+	if idx.SortKey != "" {
+		keys += ", `" + idx.SortKey + "`"
+	}
+	ddl := fmt.Sprintf("CREATE NULL_FILTERED INDEX `%s` ON `%s` (%s)", idx.SpannerIndexName, spannerTable, keys)
+
+	// Non key attributes can only be stored when they exist as columns of the table,
+	// other projections are served from the base table.
+	var storing []string
+	for _, attr := range idx.NonKeyAttributes {
+		col := spannerColumnName(attr)
+		if col == tableConf.PartitionKey || col == tableConf.SortKey || col == idx.PartitionKey || col == idx.SortKey {
+			continue
+		}
+		for _, c := range columns {
+			if c.Column == col {
+				storing = append(storing, "`"+col+"`")
+				break
+			}
+		}
+	}
+	if len(storing) > 0 {
+		ddl += " STORING (" + strings.Join(storing, ", ") + ")"
+	}
+	return ddl
+}
+
+// buildTableMetadataMutations generates the rows for the adapter metadata tables
+func buildTableMetadataMutations(tableName, spannerTable string, tableConf models.TableConfig, columns []tableColumn) []*spanner.Mutation {
+	mutations := make([]*spanner.Mutation, 0, len(columns)+len(tableConf.Indices))
+	for _, col := range columns {
+		mutations = append(mutations, spanner.InsertOrUpdate("dynamodb_adapter_table_ddl", adapterTableDDLColumns,
+			[]interface{}{col.Column, spannerTable, col.DynamoDataType, col.OriginalColumn, tableConf.PartitionKey, tableConf.SortKey, col.Column, tableName, col.SpannerType}))
+	}
+	for _, name := range sortedIndexNames(tableConf) {
+		idx := tableConf.Indices[name]
+		mutations = append(mutations, spanner.InsertOrUpdate("dynamodb_adapter_index_ddl", adapterIndexDDLColumns,
+			[]interface{}{spannerTable, name, idx.SpannerIndexName, idx.IndexType, idx.PartitionKey, idx.SortKey, idx.ProjectionType, idx.NonKeyAttributes}))
+	}
+	return mutations
+}
+
+// registerTable adds the table to the in-memory metadata. The maps are copied and swapped
+// instead of being updated in place, as they are read without locks by the request handlers.
+func registerTable(spannerTable string, tableConf models.TableConfig, columns []tableColumn) {
+	dbConfigMap := maps.Clone(models.DbConfigMap)
+	if dbConfigMap == nil {
+		dbConfigMap = make(map[string]models.TableConfig)
+	}
+	tableDDL := maps.Clone(models.TableDDL)
+	tableSpannerDDL := maps.Clone(models.TableSpannerDDL)
+	tableColumnMap := maps.Clone(models.TableColumnMap)
+	tableColChangeMap := maps.Clone(models.TableColChangeMap)
+	columnToOriginalCol := maps.Clone(models.ColumnToOriginalCol)
+	originalColResponse := maps.Clone(models.OriginalColResponse)
+
+	dbConfigMap[spannerTable] = tableConf
+	tableDDL[spannerTable] = make(map[string]string)
+	tableSpannerDDL[spannerTable] = make(map[string]string)
+	tableColumnMap[spannerTable] = []string{}
+	for _, col := range columns {
+		tableColumnMap[spannerTable] = append(tableColumnMap[spannerTable], col.Column)
+		tableDDL[spannerTable][col.Column] = col.DynamoDataType
+		tableSpannerDDL[spannerTable][col.Column] = col.SpannerType
+		if col.Column != col.OriginalColumn {
+			tableColChangeMap[spannerTable] = struct{}{}
+			columnToOriginalCol[col.OriginalColumn] = col.Column
+			originalColResponse[col.Column] = col.OriginalColumn
+		}
+	}
+
+	models.DbConfigMap = dbConfigMap
+	models.TableDDL = tableDDL
+	models.TableSpannerDDL = tableSpannerDDL
+	models.TableColumnMap = tableColumnMap
+	models.TableColChangeMap = tableColChangeMap
+	models.ColumnToOriginalCol = columnToOriginalCol
+	models.OriginalColResponse = originalColResponse
+}
+
+// unregisterTable removes the table from the in-memory metadata, see registerTable
+func unregisterTable(spannerTable string) {
+	dbConfigMap := maps.Clone(models.DbConfigMap)
+	tableDDL := maps.Clone(models.TableDDL)
+	tableSpannerDDL := maps.Clone(models.TableSpannerDDL)
+	tableColumnMap := maps.Clone(models.TableColumnMap)
+	tableColChangeMap := maps.Clone(models.TableColChangeMap)
+	columnToOriginalCol := maps.Clone(models.ColumnToOriginalCol)
+	originalColResponse := maps.Clone(models.OriginalColResponse)
+
+	columns := tableColumnMap[spannerTable]
+	delete(dbConfigMap, spannerTable)
+	delete(tableDDL, spannerTable)
+	delete(tableSpannerDDL, spannerTable)
+	delete(tableColumnMap, spannerTable)
+	delete(tableColChangeMap, spannerTable)
+
+	// the column rename maps are shared by all the tables, so only the columns
+	// which are not used by any other table are removed
+	inUse := make(map[string]struct{})
+	for _, cols := range tableColumnMap {
+		for _, col := range cols {
+			inUse[col] = struct{}{}
+		}
+	}
+	for _, col := range columns {
+		if _, ok := inUse[col]; ok {
+			continue
+		}
+		if original, ok := originalColResponse[col]; ok {
+			delete(columnToOriginalCol, original)
+			delete(originalColResponse, col)
+		}
+	}
+
+	models.DbConfigMap = dbConfigMap
+	models.TableDDL = tableDDL
+	models.TableSpannerDDL = tableSpannerDDL
+	models.TableColumnMap = tableColumnMap
+	models.TableColChangeMap = tableColChangeMap
+	models.ColumnToOriginalCol = columnToOriginalCol
+	models.OriginalColResponse = originalColResponse
+}
+
+// sortedIndexNames returns the DynamoDB index names of the table in a stable order
+func sortedIndexNames(tableConf models.TableConfig) []string {
+	names := make([]string, 0, len(tableConf.Indices))
+	for name := range tableConf.Indices {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// originalColumnName returns the DynamoDB attribute name of a Spanner column
+func originalColumnName(column string) string {
+	if original, ok := models.OriginalColResponse[column]; ok {
+		return original
+	}
+	return column
+}
+
+// keySchema builds the DynamoDB key schema for a partition key and an optional sort key column
+func keySchema(partitionKey, sortKey string) []models.KeySchemaElement {
+	keys := []models.KeySchemaElement{{AttributeName: originalColumnName(partitionKey), KeyType: "HASH"}}
+	if sortKey != "" {
+		keys = append(keys, models.KeySchemaElement{AttributeName: originalColumnName(sortKey), KeyType: "RANGE"})
+	}
+	return keys
+}
+
+// tableArn returns a stable ARN for the table, built from the Spanner instance and project
+func tableArn(tableName string) string {
+	return fmt.Sprintf("arn:aws:dynamodb:%s:%s:table/%s", models.GlobalConfig.Spanner.InstanceID, models.GlobalConfig.Spanner.ProjectID, tableName)
+}
+
+// describeTableConf builds the DynamoDB TableDescription from the in-memory table metadata
+func describeTableConf(tableName string, tableConf models.TableConfig) models.TableDescription {
+	spannerTable := utils.ChangeTableNameForSpanner(tableName)
+	arn := tableArn(tableName)
+	desc := models.TableDescription{
+		TableName: tableName,
+		TableArn:  arn,
+		KeySchema: keySchema(tableConf.PartitionKey, tableConf.SortKey),
+	}
+
+	keyColumns := []string{tableConf.PartitionKey, tableConf.SortKey}
+	for _, name := range sortedIndexNames(tableConf) {
+		idx := tableConf.Indices[name]
+		indexDesc := models.SecondaryIndexDescription{
+			IndexName: name,
+			IndexArn:  arn + "/index/" + name,
+			KeySchema: keySchema(idx.PartitionKey, idx.SortKey),
+			Projection: models.Projection{
+				ProjectionType:   idx.ProjectionType,
+				NonKeyAttributes: idx.NonKeyAttributes,
+			},
+		}
+		if indexDesc.Projection.ProjectionType == "" {
+			indexDesc.Projection.ProjectionType = "ALL"
+		}
+		if idx.IndexType == indexTypeLocal {
+			desc.LocalSecondaryIndexes = append(desc.LocalSecondaryIndexes, indexDesc)
+		} else {
+			indexDesc.IndexStatus = tableStatusActive
+			desc.GlobalSecondaryIndexes = append(desc.GlobalSecondaryIndexes, indexDesc)
+		}
+		keyColumns = append(keyColumns, idx.PartitionKey, idx.SortKey)
+	}
+
+	seen := make(map[string]struct{})
+	for _, col := range keyColumns {
+		if _, ok := seen[col]; ok || col == "" {
+			continue
+		}
+		seen[col] = struct{}{}
+		desc.AttributeDefinitions = append(desc.AttributeDefinitions, models.AttributeDefinition{
+			AttributeName: originalColumnName(col),
+			AttributeType: models.TableDDL[spannerTable][col],
+		})
+	}
+	return desc
+}
@@ -0,0 +1,226 @@
+// Copyright 2020 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package services
+
+import (
+	"testing"
+
+	"github.com/cloudspannerecosystem/dynamodb-adapter/models"
+	"github.com/cloudspannerecosystem/dynamodb-adapter/pkg/errors"
+	"github.com/cloudspannerecosystem/dynamodb-adapter/storage"
+	"gopkg.in/go-playground/assert.v1"
+)
+
+func ordersCreateTableMeta() models.CreateTableMeta {
+	return models.CreateTableMeta{
+		TableName: "orders-v1",
+		KeySchema: []models.KeySchemaElement{
+			{AttributeName: "customer_id", KeyType: "HASH"},
+			{AttributeName: "order-date", KeyType: "RANGE"},
+		},
+		AttributeDefinitions: []models.AttributeDefinition{
+			{AttributeName: "status", AttributeType: "S"},
+			{AttributeName: "customer_id", AttributeType: "S"},
+			{AttributeName: "order-date", AttributeType: "N"},
+		},
+		GlobalSecondaryIndexes: []models.SecondaryIndex{
+			{
+				IndexName:  "by-status",
+				KeySchema:  []models.KeySchemaElement{{AttributeName: "status", KeyType: "HASH"}},
+				Projection: models.Projection{ProjectionType: "ALL"},
+			},
+		},
+	}
+}
+
+func Test_validateCreateTable(t *testing.T) {
+	tests := []struct {
+		testName string
+		modify   func(meta *models.CreateTableMeta)
+		wantErr  bool
+	}{
+		{
+			"valid request",
+			func(meta *models.CreateTableMeta) {},
+			false,
+		},
+		{
+			"invalid table name",
+			func(meta *models.CreateTableMeta) { meta.TableName = "a" },
+			true,
+		},
+		{
+			"range key first",
+			func(meta *models.CreateTableMeta) {
+				meta.KeySchema[0].KeyType, meta.KeySchema[1].KeyType = "RANGE", "HASH"
+			},
+			true,
+		},
+		{
+			"key attribute not defined",
+			func(meta *models.CreateTableMeta) { meta.AttributeDefinitions = meta.AttributeDefinitions[:2] },
+			true,
+		},
+		{
+			"unused attribute definition",
+			func(meta *models.CreateTableMeta) {
+				meta.AttributeDefinitions = append(meta.AttributeDefinitions, models.AttributeDefinition{AttributeName: "total", AttributeType: "N"})
+			},
+			true,
+		},
+		{
+			"invalid attribute type",
+			func(meta *models.CreateTableMeta) { meta.AttributeDefinitions[0].AttributeType = "M" },
+			true,
+		},
+		{
+			"include projection without attributes",
+			func(meta *models.CreateTableMeta) {
+				meta.GlobalSecondaryIndexes[0].Projection.ProjectionType = "INCLUDE"
+			},
+			true,
+		},
+		{
+			"local index with different hash key",
+			func(meta *models.CreateTableMeta) {
+				meta.LocalSecondaryIndexes = []models.SecondaryIndex{{
+					IndexName: "by-status-local",
+					KeySchema: []models.KeySchemaElement{
+						{AttributeName: "status", KeyType: "HASH"},
+						{AttributeName: "order-date", KeyType: "RANGE"},
+					},
+					Projection: models.Projection{ProjectionType: "KEYS_ONLY"},
+				}}
+			},
+			true,
+		},
+		{
+			"duplicate index name",
+			func(meta *models.CreateTableMeta) {
+				meta.LocalSecondaryIndexes = []models.SecondaryIndex{{
+					IndexName: "by-status",
+					KeySchema: []models.KeySchemaElement{
+						{AttributeName: "customer_id", KeyType: "HASH"},
+						{AttributeName: "status", KeyType: "RANGE"},
+					},
+					Projection: models.Projection{ProjectionType: "KEYS_ONLY"},
+				}}
+			},
+			true,
+		},
+	}
+
+	for _, tc := range tests {
+		meta := ordersCreateTableMeta()
+		tc.modify(&meta)
+		err := validateCreateTable(meta)
+		assert.Equal(t, err != nil, tc.wantErr)
+		if err != nil {
+			assert.Equal(t, err.(*errors.Error).ErrorCode, "ValidationException")
+		}
+	}
+}
+
+func Test_buildCreateTableDDL(t *testing.T) {
+	meta := ordersCreateTableMeta()
+	tableConf, columns := buildTableMetadata("orders_v1", meta)
+
+	assert.Equal(t, tableConf.PartitionKey, "customer_id")
+	assert.Equal(t, tableConf.SortKey, "order_date")
+	assert.Equal(t, tableConf.Indices["by-status"].SpannerIndexName, "orders_v1_by_status")
+	assert.Equal(t, tableConf.Indices["by-status"].IndexType, indexTypeGlobal)
+	assert.Equal(t, columns[0].Column, "customer_id")
+	assert.Equal(t, columns[1].Column, "order_date")
+	assert.Equal(t, columns[1].OriginalColumn, "order-date")
+	assert.Equal(t, columns[2].Column, "status")
+
+	want := []string{
+		"CREATE TABLE `orders_v1` (\n\t`customer_id` STRING(MAX) NOT NULL,\n\t`order_date` FLOAT64 NOT NULL,\n\t`status` STRING(MAX)\n) PRIMARY KEY (`customer_id`, `order_date`)",
+		adapterIndexTableDDL,
+		"CREATE NULL_FILTERED INDEX `orders_v1_by_status` ON `orders_v1` (`status`)",
+	}
+	assert.Equal(t, buildCreateTableDDL("orders_v1", tableConf, columns), want)
+}
+
+func Test_registerTable(t *testing.T) {
+	tableConf, columns := buildTableMetadata("orders_v1", ordersCreateTableMeta())
+	registerTable("orders_v1", tableConf, columns)
+
+	assert.Equal(t, models.DbConfigMap["orders_v1"].PartitionKey, "customer_id")
+	assert.Equal(t, models.TableColumnMap["orders_v1"], []string{"customer_id", "order_date", "status"})
+	assert.Equal(t, models.TableDDL["orders_v1"]["order_date"], "N")
+	assert.Equal(t, models.TableSpannerDDL["orders_v1"]["order_date"], "FLOAT64")
+	assert.Equal(t, models.ColumnToOriginalCol["order-date"], "order_date")
+	assert.Equal(t, models.OriginalColResponse["order_date"], "order-date")
+}
+
+func Test_buildDropTableDDL(t *testing.T) {
+	want := []string{"DROP INDEX `orders_v1_by_status`", "DROP TABLE `orders_v1`"}
+	assert.Equal(t, buildDropTableDDL("orders_v1", []string{"orders_v1_by_status"}), want)
+	assert.Equal(t, buildDropTableDDL("orders_v1", nil), []string{"DROP TABLE `orders_v1`"})
+}
+
+func Test_unregisterTable(t *testing.T) {
+	tableConf, columns := buildTableMetadata("orders_v1", ordersCreateTableMeta())
+	registerTable("orders_v1", tableConf, columns)
+	unregisterTable("orders_v1")
+
+	_, ok := models.DbConfigMap["orders_v1"]
+	assert.Equal(t, ok, false)
+	_, ok = models.TableDDL["orders_v1"]
+	assert.Equal(t, ok, false)
+	_, ok = models.TableColumnMap["orders_v1"]
+	assert.Equal(t, ok, false)
+	_, ok = models.ColumnToOriginalCol["order-date"]
+	assert.Equal(t, ok, false)
+	_, ok = models.OriginalColResponse["order_date"]
+	assert.Equal(t, ok, false)
+}
+
+func Test_addSpannerIndexes(t *testing.T) {
+	tableConf, _ := buildTableMetadata("orders_v1", ordersCreateTableMeta())
+	indexes := []storage.IndexSchema{
+		{Name: "orders_v1_by_status", KeyColumns: []string{"status"}},
+		{Name: "orders_by_date", KeyColumns: []string{"order_date", "customer_id", "status"}},
+	}
+	got := addSpannerIndexes(tableConf, indexes)
+
+	assert.Equal(t, len(got.Indices), 2)
+	assert.Equal(t, got.Indices["by-status"], tableConf.Indices["by-status"])
+	assert.Equal(t, got.Indices["orders_by_date"].PartitionKey, "order_date")
+	assert.Equal(t, got.Indices["orders_by_date"].SortKey, "customer_id")
+	assert.Equal(t, got.Indices["orders_by_date"].IndexType, indexTypeGlobal)
+	assert.Equal(t, got.Indices["orders_by_date"].ProjectionType, "ALL")
+	assert.Equal(t, len(tableConf.Indices), 1)
+}
+
+func Test_validateListTables(t *testing.T) {
+	tests := []struct {
+		testName string
+		meta     models.ListTablesMeta
+		wantErr  bool
+	}{
+		{"default limit", models.ListTablesMeta{}, false},
+		{"limit and start table", models.ListTablesMeta{Limit: 100, ExclusiveStartTableName: "orders-v1"}, false},
+		{"negative limit", models.ListTablesMeta{Limit: -1}, true},
+		{"limit too large", models.ListTablesMeta{Limit: 101}, true},
+		{"invalid start table", models.ListTablesMeta{ExclusiveStartTableName: "a"}, true},
+	}
+
+	for _, tc := range tests {
+		err := validateListTables(tc.meta)
+		assert.Equal(t, err != nil, tc.wantErr)
+	}
+}
@@ -0,0 +1,249 @@
+// Copyright 2020 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package services
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"strings"
+	"sync/atomic"
+	"unicode/utf8"
+
+	"cloud.google.com/go/spanner"
+	"github.com/cloudspannerecosystem/dynamodb-adapter/models"
+	"github.com/cloudspannerecosystem/dynamodb-adapter/pkg/errors"
+	"github.com/cloudspannerecosystem/dynamodb-adapter/storage"
+	"github.com/cloudspannerecosystem/dynamodb-adapter/utils"
+)
+
+const (
+	maxTagsPerResource = 50
+	maxTagKeyLength    = 128
+	maxTagValueLength  = 256
+	reservedTagPrefix  = "aws:"
+)
+
+// adapterTagsTableDDL creates the metadata table holding the tags of the tables
+const adapterTagsTableDDL = `CREATE TABLE IF NOT EXISTS dynamodb_adapter_tags (
+	tableName STRING(MAX) NOT NULL,
+	tagKey STRING(MAX) NOT NULL,
+	tagValue STRING(MAX),
+) PRIMARY KEY (tableName, tagKey)`
+
+var (
+	tagRegex = regexp.MustCompile(`^[\p{L}\p{Z}\p{N}_.:/=+\-@]*$`)
+	// tagsTableReady is set once dynamodb_adapter_tags is known to exist
+	tagsTableReady atomic.Bool
+)
+
+// TagResource adds the tags to the table identified by its TableArn, existing tags with the same key are overwritten
+func TagResource(ctx context.Context, meta models.TagResourceMeta) error {
+	if len(meta.Tags) == 0 {
+		return errors.New("ValidationException", "1 validation error detected: Value null at 'tags' failed to satisfy constraint: Member must not be null")
+	}
+	if err := validateTags(meta.Tags); err != nil {
+		return err
+	}
+
+	tableMetaMux.Lock()
+	defer tableMetaMux.Unlock()
+
+	spannerTable, err := tableFromArn(meta.ResourceArn)
+	if err != nil {
+		return err
+	}
+	existing, err := readTags(ctx, spannerTable)
+	if err != nil {
+		return errors.New("InternalServerError", err)
+	}
+	keys := make(map[string]struct{}, len(existing)+len(meta.Tags))
+	for _, tag := range existing {
+		keys[tag.Key] = struct{}{}
+	}
+	for _, tag := range meta.Tags {
+		keys[tag.Key] = struct{}{}
+	}
+	if len(keys) > maxTagsPerResource {
+		return errors.New("ValidationException", fmt.Sprintf("One or more parameter values were invalid: The number of tags of the resource exceeds the limit of %d", maxTagsPerResource))
+	}
+	return putTags(ctx, spannerTable, meta.Tags)
+}
+
+// UntagResource removes the tags with the given keys from the table identified by its TableArn
+func UntagResource(ctx context.Context, meta models.UntagResourceMeta) error {
+	if len(meta.TagKeys) == 0 {
+		return errors.New("ValidationException", "1 validation error detected: Value null at 'tagKeys' failed to satisfy constraint: Member must not be null")
+	}
+	for _, key := range meta.TagKeys {
+		if err := validateTagKey(key); err != nil {
+			return err
+		}
+	}
+
+	tableMetaMux.Lock()
+	defer tableMetaMux.Unlock()
+
+	spannerTable, err := tableFromArn(meta.ResourceArn)
+	if err != nil {
+		return err
+	}
+	exists, err := tagsTableExists(ctx)
+	if err != nil {
+		return errors.New("InternalServerError", err)
+	}
+	if !exists {
+		return nil
+	}
+	keys := make([]spanner.Key, 0, len(meta.TagKeys))
+	for _, key := range meta.TagKeys {
+		keys = append(keys, spanner.Key{spannerTable, key})
+	}
+	err = storage.GetStorageInstance().SpannerApply(ctx, []*spanner.Mutation{
+		spanner.Delete("dynamodb_adapter_tags", spanner.KeySetFromKeys(keys...)),
+	})
+	if err != nil {
+		return errors.New("InternalServerError", err)
+	}
+	return nil
+}
+
+// ListTagsOfResource returns all the tags of the table identified by its TableArn. A table has
+// at most 50 tags, so they are always returned in a single page.
+func ListTagsOfResource(ctx context.Context, meta models.ListTagsOfResourceMeta) (*models.ListTagsOfResourceOutput, error) {
+	spannerTable, err := tableFromArn(meta.ResourceArn)
+	if err != nil {
+		return nil, err
+	}
+	tags, err := readTags(ctx, spannerTable)
+	if err != nil {
+		return nil, errors.New("InternalServerError", err)
+	}
+	return &models.ListTagsOfResourceOutput{Tags: tags}, nil
+}
+
+// tableFromArn returns the Spanner table of a TableArn reported by the adapter, see tableArn
+func tableFromArn(arn string) (string, error) {
+	prefix := tableArn("")
+	tableName := strings.TrimPrefix(arn, prefix)
+	if !strings.HasPrefix(arn, prefix) || tableName == "" || strings.Contains(tableName, "/") {
+		return "", errors.New("ValidationException", "Invalid TableArn: "+arn)
+	}
+	spannerTable := utils.ChangeTableNameForSpanner(tableName)
+	if _, ok := models.DbConfigMap[spannerTable]; !ok {
+		return "", errors.New("ResourceNotFoundException", "Requested resource not found: ResourcArn: "+arn+" not found")
+	}
+	return spannerTable, nil
+}
+
+// validateTags validates the tags in the same way DynamoDB does
+func validateTags(tags []models.Tag) error {
+	if len(tags) > maxTagsPerResource {
+		return errors.New("ValidationException", fmt.Sprintf("One or more parameter values were invalid: The number of tags exceeds the limit of %d", maxTagsPerResource))
+	}
+	for _, tag := range tags {
+		if err := validateTagKey(tag.Key); err != nil {
+			return err
+		}
+		if utf8.RuneCountInString(tag.Value) > maxTagValueLength || !tagRegex.MatchString(tag.Value) {
+			return errors.New("ValidationException", fmt.Sprintf("One or more parameter values were invalid: Tag value of key %s must be at most %d characters long and match the pattern [\\p{L}\\p{Z}\\p{N}_.:/=+\\-@]*", tag.Key, maxTagValueLength))
+		}
+	}
+	return nil
+}
+
+// validateTagKey validates a tag key, the aws: prefix is reserved for tags created by AWS
+func validateTagKey(key string) error {
+	length := utf8.RuneCountInString(key)
+	if length < 1 || length > maxTagKeyLength || !tagRegex.MatchString(key) {
+		return errors.New("ValidationException", fmt.Sprintf("One or more parameter values were invalid: Tag key %s must be between 1 and %d characters long and match the pattern [\\p{L}\\p{Z}\\p{N}_.:/=+\\-@]*", key, maxTagKeyLength))
+	}
+	if strings.HasPrefix(strings.ToLower(key), reservedTagPrefix) {
+		return errors.New("ValidationException", "One or more parameter values were invalid: Tag keys starting with the aws: prefix are reserved: "+key)
+	}
+	return nil
+}
+
+// tagsTableExists checks whether dynamodb_adapter_tags has been created
+func tagsTableExists(ctx context.Context) (bool, error) {
+	if tagsTableReady.Load() {
+		return true, nil
+	}
+	exists, err := storage.GetStorageInstance().SpannerTableExists(ctx, "dynamodb_adapter_tags")
+	if err != nil {
+		return false, err
+	}
+	tagsTableReady.Store(exists)
+	return exists, nil
+}
+
+// readTags reads the tags of the table ordered by key
+func readTags(ctx context.Context, spannerTable string) ([]models.Tag, error) {
+	tags := []models.Tag{}
+	exists, err := tagsTableExists(ctx)
+	if err != nil || !exists {
+		return tags, err
+	}
+	stmt := spanner.Statement{
+		SQL:    "SELECT tableName, tagKey, tagValue FROM dynamodb_adapter_tags WHERE tableName = @tableName ORDER BY tagKey",
+		Params: map[string]interface{}{"tableName": spannerTable},
+	}
+	rows, err := storage.GetStorageInstance().ExecuteSpannerQuery(ctx, "dynamodb_adapter_tags", models.TableColumnMap["dynamodb_adapter_tags"], false, stmt)
+	if err != nil {
+		return nil, err
+	}
+	for _, row := range rows {
+		key, _ := row["tagKey"].(string)
+		value, _ := row["tagValue"].(string)
+		tags = append(tags, models.Tag{Key: key, Value: value})
+	}
+	return tags, nil
+}
+
+// putTags stores the tags of the table, creating dynamodb_adapter_tags on first use
+func putTags(ctx context.Context, spannerTable string, tags []models.Tag) error {
+	if err := createTagsTable(ctx); err != nil {
+		return err
+	}
+	if err := storage.GetStorageInstance().SpannerApply(ctx, tagMutations(spannerTable, tags)); err != nil {
+		return errors.New("InternalServerError", err)
+	}
+	return nil
+}
+
+// createTagsTable creates dynamodb_adapter_tags when it does not exist yet
+func createTagsTable(ctx context.Context) error {
+	exists, err := tagsTableExists(ctx)
+	if err != nil {
+		return errors.New("InternalServerError", err)
+	}
+	if !exists {
+		if err := storage.GetStorageInstance().SpannerUpdateDDL(ctx, []string{adapterTagsTableDDL}); err != nil {
+			return errors.New("InternalServerError", err)
+		}
+		tagsTableReady.Store(true)
+	}
+	return nil
+}
+
+// tagMutations returns the mutations storing the tags of the table in dynamodb_adapter_tags
+func tagMutations(spannerTable string, tags []models.Tag) []*spanner.Mutation {
+	mutations := make([]*spanner.Mutation, 0, len(tags))
+	for _, tag := range tags {
+		mutations = append(mutations, spanner.InsertOrUpdate("dynamodb_adapter_tags", models.TableColumnMap["dynamodb_adapter_tags"],
+			[]interface{}{spannerTable, tag.Key, tag.Value}))
+	}
+	return mutations
+}
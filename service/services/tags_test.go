@@ -0,0 +1,90 @@
+// Copyright 2020 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package services
+
+import (
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/cloudspannerecosystem/dynamodb-adapter/models"
+	"github.com/cloudspannerecosystem/dynamodb-adapter/pkg/errors"
+	"gopkg.in/go-playground/assert.v1"
+)
+
+func Test_validateTags(t *testing.T) {
+	tooMany := make([]models.Tag, maxTagsPerResource+1)
+	for i := range tooMany {
+		tooMany[i] = models.Tag{Key: "key" + strconv.Itoa(i), Value: "value"}
+	}
+
+	tests := []struct {
+		testName string
+		tags     []models.Tag
+		wantErr  bool
+	}{
+		{"valid tags", []models.Tag{{Key: "team", Value: "payments"}, {Key: "cost-center", Value: ""}}, false},
+		{"empty key", []models.Tag{{Key: "", Value: "payments"}}, true},
+		{"key too long", []models.Tag{{Key: strings.Repeat("k", maxTagKeyLength+1), Value: "payments"}}, true},
+		{"value too long", []models.Tag{{Key: "team", Value: strings.Repeat("v", maxTagValueLength+1)}}, true},
+		{"invalid character", []models.Tag{{Key: "team", Value: "pay#ments"}}, true},
+		{"reserved prefix", []models.Tag{{Key: "AWS:team", Value: "payments"}}, true},
+		{"too many tags", tooMany, true},
+	}
+
+	for _, tc := range tests {
+		err := validateTags(tc.tags)
+		assert.Equal(t, err != nil, tc.wantErr)
+		if err != nil {
+			assert.Equal(t, err.(*errors.Error).ErrorCode, "ValidationException")
+		}
+	}
+}
+
+func Test_tableFromArn(t *testing.T) {
+	tableConf, columns := buildTableMetadata("orders_v1", ordersCreateTableMeta())
+	registerTable("orders_v1", tableConf, columns)
+	defer unregisterTable("orders_v1")
+
+	tests := []struct {
+		testName string
+		arn      string
+		want     string
+		wantCode string
+	}{
+		{"registered table", tableArn("orders-v1"), "orders_v1", ""},
+		{"unknown table", tableArn("customers"), "", "ResourceNotFoundException"},
+		{"missing table name", tableArn(""), "", "ValidationException"},
+		{"other resource", "arn:aws:dynamodb:us-east-1:123456789012:table/orders-v1", "", "ValidationException"},
+		{"index arn", tableArn("orders-v1") + "/index/by-status", "", "ValidationException"},
+	}
+
+	for _, tc := range tests {
+		got, err := tableFromArn(tc.arn)
+		if tc.wantCode != "" {
+			assert.NotEqual(t, err, nil)
+			assert.Equal(t, err.(*errors.Error).ErrorCode, tc.wantCode)
+			continue
+		}
+		assert.Equal(t, err, nil)
+		assert.Equal(t, got, tc.want)
+	}
+}
+
+func Test_tagMutations(t *testing.T) {
+	assert.Equal(t, len(tagMutations("orders_v1", nil)), 0)
+	tags := []models.Tag{{Key: "team", Value: "payments"}, {Key: "env", Value: "prod"}}
+	assert.Equal(t, len(tagMutations("orders_v1", tags)), 2)
+}
@@ -0,0 +1,286 @@
+// Copyright 2020 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package services
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"slices"
+	"sync/atomic"
+	"time"
+
+	"cloud.google.com/go/spanner"
+	"github.com/cloudspannerecosystem/dynamodb-adapter/config"
+	"github.com/cloudspannerecosystem/dynamodb-adapter/models"
+	"github.com/cloudspannerecosystem/dynamodb-adapter/pkg/errors"
+	"github.com/cloudspannerecosystem/dynamodb-adapter/storage"
+	"google.golang.org/grpc/codes"
+)
+
+const (
+	maxTransactItems = 100
+	maxTransactBytes = 4 << 20
+
+	maxClientRequestTokenLength = 36
+	// clientRequestTokenTTL - a token is valid for 10 minutes, afterwards a request with the same token is a new request
+	clientRequestTokenTTL = 10 * time.Minute
+)
+
+// adapterClientTokensTableDDL creates the metadata table holding the ClientRequestToken of the committed
+// TransactWriteItems with the hash of the request and its responses. Spanner deletes the expired tokens.
+const adapterClientTokensTableDDL = `CREATE TABLE IF NOT EXISTS dynamodb_adapter_client_tokens (
+	token STRING(MAX) NOT NULL,
+	requestHash STRING(MAX),
+	responses STRING(MAX),
+	createdAt TIMESTAMP OPTIONS (allow_commit_timestamp=true),
+) PRIMARY KEY (token), ROW DELETION POLICY (OLDER_THAN(createdAt, INTERVAL 1 DAY))`
+
+// clientTokensTableReady is set once dynamodb_adapter_client_tokens is known to exist
+var clientTokensTableReady atomic.Bool
+
+// ValidateTransactWrite validates the actions of a TransactWriteItems in the same way DynamoDB does. Item is
+// set for a Put and Key for the other actions. The expression attribute values of the actions count toward
+// the size of the request.
+func ValidateTransactWrite(actions []models.BatchWriteMeta) error {
+	if len(actions) == 0 {
+		return errors.New("ValidationException", "1 validation error detected: Value null at 'transactItems' failed to satisfy constraint: Member must have length greater than or equal to 1")
+	}
+	if len(actions) > maxTransactItems {
+		return errors.New("ValidationException", "1 validation error detected: Value at 'transactItems' failed to satisfy constraint: Member must have length less than or equal to 100")
+	}
+	seen := make(map[string]struct{}, len(actions))
+	var size int64
+	for _, action := range actions {
+		tableConf, err := config.GetTableConf(action.TableName)
+		if err != nil {
+			return err
+		}
+		item := action.Item
+		if item == nil {
+			item = action.Key
+		}
+		if _, ok := item[tableConf.PartitionKey]; !ok {
+			return errors.New("ValidationException", "The provided key element does not match the schema")
+		}
+		if _, ok := item[tableConf.SortKey]; tableConf.SortKey != "" && !ok {
+			return errors.New("ValidationException", "The provided key element does not match the schema")
+		}
+		k := tableConf.ActualTable + "\x00" + batchGetKey(tableConf, item)
+		if _, ok := seen[k]; ok {
+			return errors.New("ValidationException", "Transaction request cannot include multiple operations on one item")
+		}
+		seen[k] = struct{}{}
+		size += storage.ItemSize(item) + storage.ItemSize(action.ExpressionAttributeMap)
+	}
+	if size > maxTransactBytes {
+		return errors.New("ValidationException", "Transaction request size exceeds the maximum allowed size of 4 MB")
+	}
+	return nil
+}
+
+// ReadWriteTransaction runs f in a read-write transaction, with the transaction in the context of f so that
+// the reads of the storage are made in it. f runs again when Spanner aborts the transaction, so every attempt
+// must start from scratch. The error of the request which rolled the transaction back is returned as it is,
+// as Spanner may wrap it, and an aborted transaction which could not be retried is a TransactionConflictException.
+func (s *spannerService) ReadWriteTransaction(ctx context.Context, f func(ctx context.Context, txn *spanner.ReadWriteTransaction) error) error {
+	var requestErr *errors.Error
+	_, err := s.spannerClient.ReadWriteTransaction(ctx, func(ctx context.Context, txn *spanner.ReadWriteTransaction) error {
+		requestErr = nil
+		err := f(storage.WithReadWriteTransaction(ctx, txn), txn)
+		if e, ok := err.(*errors.Error); ok && spanner.ErrCode(err) != codes.Aborted {
+			requestErr = e
+		}
+		return err
+	})
+	if err == nil {
+		return nil
+	}
+	if requestErr != nil {
+		return requestErr
+	}
+	if e := errors.AssignError(err); e != nil && e.ErrorCode == "TransactionConflictException" {
+		return e
+	}
+	return err
+}
+
+// ValidateTransactGet validates the gets of a TransactGetItems in the same way DynamoDB does
+func ValidateTransactGet(gets []models.GetItemMeta) error {
+	if len(gets) == 0 {
+		return errors.New("ValidationException", "1 validation error detected: Value null at 'transactItems' failed to satisfy constraint: Member must have length greater than or equal to 1")
+	}
+	if len(gets) > maxTransactItems {
+		return errors.New("ValidationException", "1 validation error detected: Value at 'transactItems' failed to satisfy constraint: Member must have length less than or equal to 100")
+	}
+	seen := make(map[string]struct{}, len(gets))
+	for _, get := range gets {
+		tableConf, err := config.GetTableConf(get.TableName)
+		if err != nil {
+			return err
+		}
+		if _, ok := get.PrimaryKeyMap[tableConf.PartitionKey]; !ok {
+			return errors.New("ValidationException", "The provided key element does not match the schema")
+		}
+		if _, ok := get.PrimaryKeyMap[tableConf.SortKey]; tableConf.SortKey != "" && !ok {
+			return errors.New("ValidationException", "The provided key element does not match the schema")
+		}
+		k := tableConf.ActualTable + "\x00" + batchGetKey(tableConf, get.PrimaryKeyMap)
+		if _, ok := seen[k]; ok {
+			return errors.New("ValidationException", "Transaction request cannot include multiple operations on one item")
+		}
+		seen[k] = struct{}{}
+	}
+	return nil
+}
+
+// TransactGetItems reads the items of a TransactGetItems at one timestamp, in the order of the gets, nil
+// for an item which does not exist. The keys are always read to tell the items which do not exist from
+// the items without the projected attributes, and removed again when they are not part of the projection.
+func (s *spannerService) TransactGetItems(ctx context.Context, gets []models.GetItemMeta) ([]map[string]interface{}, error) {
+	reads := make([]storage.TransactGet, len(gets))
+	addedCols := make([][]string, len(gets))
+	for i, get := range gets {
+		tableConf, err := config.GetTableConf(get.TableName)
+		if err != nil {
+			return nil, err
+		}
+		projectionCols := getSpannerProjections(get.ProjectionExpression, tableConf.ActualTable, get.ExpressionAttributeNames)
+		if len(projectionCols) > 0 {
+			keyCols := []string{tableConf.PartitionKey}
+			if tableConf.SortKey != "" {
+				keyCols = append(keyCols, tableConf.SortKey)
+			}
+			for _, col := range keyCols {
+				if !slices.Contains(projectionCols, col) {
+					projectionCols = append(projectionCols, col)
+					addedCols[i] = append(addedCols[i], col)
+				}
+			}
+		}
+		reads[i] = storage.TransactGet{
+			Table:          tableConf.ActualTable,
+			PartitionKey:   get.PrimaryKeyMap[tableConf.PartitionKey],
+			ProjectionCols: projectionCols,
+		}
+		if tableConf.SortKey != "" {
+			reads[i].SortKey = get.PrimaryKeyMap[tableConf.SortKey]
+		}
+	}
+	items, err := s.st.SpannerTransactGetItems(ctx, reads)
+	if err != nil {
+		return nil, err
+	}
+	var size int64
+	for i, item := range items {
+		for _, col := range addedCols[i] {
+			delete(item, col)
+		}
+		size += storage.ItemSize(item)
+	}
+	if size > maxTransactBytes {
+		return nil, errors.New("ValidationException", "Transaction request size exceeds the maximum allowed size of 4 MB")
+	}
+	return items, nil
+}
+
+// PrepareClientRequestToken validates the ClientRequestToken of a TransactWriteItems and creates
+// dynamodb_adapter_client_tokens on first use. It returns false when the request is not idempotent,
+// i.e. the token is not set or operation.replayProtection is disabled.
+func PrepareClientRequestToken(ctx context.Context, token string) (bool, error) {
+	if token == "" || models.GlobalConfig == nil || !models.GlobalConfig.Operation.ReplayProtection {
+		return false, nil
+	}
+	if len(token) > maxClientRequestTokenLength {
+		return false, errors.New("ValidationException", "1 validation error detected: Value at 'clientRequestToken' failed to satisfy constraint: Member must have length less than or equal to 36")
+	}
+	if clientTokensTableReady.Load() {
+		return true, nil
+	}
+	exists, err := storage.GetStorageInstance().SpannerTableExists(ctx, "dynamodb_adapter_client_tokens")
+	if err != nil {
+		return false, errors.New("InternalServerError", err)
+	}
+	if !exists {
+		if err := storage.GetStorageInstance().SpannerUpdateDDL(ctx, []string{adapterClientTokensTableDDL}); err != nil {
+			return false, errors.New("InternalServerError", err)
+		}
+	}
+	clientTokensTableReady.Store(true)
+	return true, nil
+}
+
+// TransactWriteRequestHash hashes the actions of a TransactWriteItems, so that a retry with the same
+// ClientRequestToken can be told apart from a different request. As in DynamoDB, only TransactItems is
+// compared: a retry may change ReturnConsumedCapacity or ReturnItemCollectionMetrics.
+func TransactWriteRequestHash(transactItems []models.TransactWriteItem) (string, error) {
+	b, err := json.Marshal(transactItems)
+	if err != nil {
+		return "", errors.New("ValidationException", err)
+	}
+	sum := sha256.Sum256(b)
+	return hex.EncodeToString(sum[:]), nil
+}
+
+// ReplayTransactWrite looks up the ClientRequestToken within the transaction. It returns the responses of
+// the original request when the token was used in the last 10 minutes with the same parameters, and an
+// IdempotentParameterMismatchException when they differ. The Spanner server time is used, so that the
+// clocks of the adapter instances do not need to be in sync.
+func ReplayTransactWrite(ctx context.Context, txn *spanner.ReadWriteTransaction, token, requestHash string) ([]map[string]interface{}, bool, error) {
+	stmt := spanner.Statement{
+		SQL: "SELECT CURRENT_TIMESTAMP() AS now, " +
+			"(SELECT requestHash FROM dynamodb_adapter_client_tokens WHERE token = @token) AS requestHash, " +
+			"(SELECT responses FROM dynamodb_adapter_client_tokens WHERE token = @token) AS responses, " +
+			"(SELECT createdAt FROM dynamodb_adapter_client_tokens WHERE token = @token) AS createdAt",
+		Params: map[string]interface{}{"token": token},
+	}
+	itr := txn.Query(ctx, stmt)
+	defer itr.Stop()
+	row, err := itr.Next()
+	if err != nil {
+		return nil, false, err
+	}
+	var now time.Time
+	var storedHash, responses spanner.NullString
+	var createdAt spanner.NullTime
+	if err := row.Columns(&now, &storedHash, &responses, &createdAt); err != nil {
+		return nil, false, err
+	}
+	if !createdAt.Valid || now.Sub(createdAt.Time) > clientRequestTokenTTL {
+		return nil, false, nil
+	}
+	if storedHash.StringVal != requestHash {
+		return nil, false, errors.New("IdempotentParameterMismatchException", "DynamoDB rejected the request because you retried a request with a different payload but with an idempotent token that was already used.")
+	}
+	var result []map[string]interface{}
+	if err := json.Unmarshal([]byte(responses.StringVal), &result); err != nil {
+		return nil, false, errors.New("InternalServerError", err)
+	}
+	return result, true, nil
+}
+
+// SaveTransactWrite stores the ClientRequestToken with the hash and the responses of the request within
+// the transaction, so that the token is only stored when the transaction commits
+func SaveTransactWrite(txn *spanner.ReadWriteTransaction, token, requestHash string, responses []map[string]interface{}) error {
+	b, err := json.Marshal(responses)
+	if err != nil {
+		return errors.New("InternalServerError", err)
+	}
+	return txn.BufferWrite([]*spanner.Mutation{
+		spanner.InsertOrUpdate("dynamodb_adapter_client_tokens", []string{"token", "requestHash", "responses", "createdAt"},
+			[]interface{}{token, requestHash, string(b), spanner.CommitTimestamp}),
+	})
+}
@@ -0,0 +1,170 @@
+// Copyright 2020 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package services
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/cloudspannerecosystem/dynamodb-adapter/models"
+	"github.com/cloudspannerecosystem/dynamodb-adapter/pkg/errors"
+	"gopkg.in/go-playground/assert.v1"
+)
+
+func TestValidateTransactWrite(t *testing.T) {
+	tableConf, columns := buildTableMetadata("orders_v1", ordersCreateTableMeta())
+	registerTable("orders_v1", tableConf, columns)
+	defer unregisterTable("orders_v1")
+
+	key := func(customer string, date float64) map[string]interface{} {
+		return map[string]interface{}{"customer_id": customer, "order_date": date}
+	}
+	put := func(customer string, date float64) models.BatchWriteMeta {
+		item := key(customer, date)
+		item["status"] = "NEW"
+		return models.BatchWriteMeta{TableName: "orders-v1", Item: item}
+	}
+	check := func(customer string, date float64) models.BatchWriteMeta {
+		return models.BatchWriteMeta{TableName: "orders-v1", Key: key(customer, date)}
+	}
+	tooManyActions := make([]models.BatchWriteMeta, 0, maxTransactItems+1)
+	for i := 0; i <= maxTransactItems; i++ {
+		tooManyActions = append(tooManyActions, put("c1", float64(i)))
+	}
+	// the values of an update count toward the size of the request, even when its key is small
+	largeUpdate := check("c1", 1)
+	largeUpdate.ExpressionAttributeMap = map[string]interface{}{":status": strings.Repeat("x", maxTransactBytes)}
+
+	tests := []struct {
+		testName string
+		actions  []models.BatchWriteMeta
+		wantCode string
+	}{
+		{
+			"puts and checks",
+			[]models.BatchWriteMeta{put("c1", 1), check("c1", 2), check("c2", 1)},
+			"",
+		},
+		{
+			"no action",
+			nil,
+			"ValidationException",
+		},
+		{
+			"too many actions",
+			tooManyActions,
+			"ValidationException",
+		},
+		{
+			"two actions on one item",
+			[]models.BatchWriteMeta{put("c1", 1), check("c1", 1)},
+			"ValidationException",
+		},
+		{
+			"missing sort key",
+			[]models.BatchWriteMeta{{TableName: "orders-v1", Key: map[string]interface{}{"customer_id": "c1"}}},
+			"ValidationException",
+		},
+		{
+			"too large update values",
+			[]models.BatchWriteMeta{largeUpdate},
+			"ValidationException",
+		},
+		{
+			"unknown table",
+			[]models.BatchWriteMeta{{TableName: "payments", Key: key("c1", 1)}},
+			"ResourceNotFoundException",
+		},
+	}
+
+	for _, tc := range tests {
+		err := ValidateTransactWrite(tc.actions)
+		if tc.wantCode == "" {
+			assert.Equal(t, err, nil)
+			continue
+		}
+		assert.NotEqual(t, err, nil)
+		assert.Equal(t, err.(*errors.Error).ErrorCode, tc.wantCode)
+	}
+}
+
+func TestValidateTransactGet(t *testing.T) {
+	tableConf, columns := buildTableMetadata("orders_v1", ordersCreateTableMeta())
+	registerTable("orders_v1", tableConf, columns)
+	defer unregisterTable("orders_v1")
+
+	get := func(customer string, date float64) models.GetItemMeta {
+		return models.GetItemMeta{TableName: "orders-v1", PrimaryKeyMap: map[string]interface{}{"customer_id": customer, "order_date": date}}
+	}
+	tooManyGets := make([]models.GetItemMeta, 0, maxTransactItems+1)
+	for i := 0; i <= maxTransactItems; i++ {
+		tooManyGets = append(tooManyGets, get("c1", float64(i)))
+	}
+
+	tests := []struct {
+		testName string
+		gets     []models.GetItemMeta
+		wantCode string
+	}{
+		{"gets", []models.GetItemMeta{get("c1", 1), get("c1", 2), get("c2", 1)}, ""},
+		{"no get", nil, "ValidationException"},
+		{"too many gets", tooManyGets, "ValidationException"},
+		{"two gets of one item", []models.GetItemMeta{get("c1", 1), get("c1", 1)}, "ValidationException"},
+		{"missing sort key", []models.GetItemMeta{{TableName: "orders-v1", PrimaryKeyMap: map[string]interface{}{"customer_id": "c1"}}}, "ValidationException"},
+		{"unknown table", []models.GetItemMeta{{TableName: "payments", PrimaryKeyMap: map[string]interface{}{"customer_id": "c1"}}}, "ResourceNotFoundException"},
+	}
+
+	for _, tc := range tests {
+		err := ValidateTransactGet(tc.gets)
+		if tc.wantCode == "" {
+			assert.Equal(t, err, nil)
+			continue
+		}
+		assert.NotEqual(t, err, nil)
+		assert.Equal(t, err.(*errors.Error).ErrorCode, tc.wantCode)
+	}
+}
+
+func TestPrepareClientRequestToken(t *testing.T) {
+	globalConfig := models.GlobalConfig
+	defer func() { models.GlobalConfig = globalConfig }()
+
+	models.GlobalConfig = &models.Config{}
+	idempotent, err := PrepareClientRequestToken(context.Background(), "token-1")
+	assert.Equal(t, err, nil)
+	assert.Equal(t, idempotent, false)
+
+	models.GlobalConfig = &models.Config{Operation: models.Operation{ReplayProtection: true}}
+	idempotent, err = PrepareClientRequestToken(context.Background(), "")
+	assert.Equal(t, err, nil)
+	assert.Equal(t, idempotent, false)
+
+	_, err = PrepareClientRequestToken(context.Background(), strings.Repeat("a", maxClientRequestTokenLength+1))
+	assert.NotEqual(t, err, nil)
+	assert.Equal(t, err.(*errors.Error).ErrorCode, "ValidationException")
+}
+
+func TestTransactWriteRequestHash(t *testing.T) {
+	transactItems := []models.TransactWriteItem{{Delete: models.DeleteItemRequest{TableName: "orders-v1"}}}
+	hash, err := TransactWriteRequestHash(transactItems)
+	assert.Equal(t, err, nil)
+
+	retryHash, _ := TransactWriteRequestHash([]models.TransactWriteItem{{Delete: models.DeleteItemRequest{TableName: "orders-v1"}}})
+	assert.Equal(t, retryHash, hash)
+
+	changedHash, _ := TransactWriteRequestHash([]models.TransactWriteItem{{Delete: models.DeleteItemRequest{TableName: "orders-v2"}}})
+	assert.NotEqual(t, changedHash, hash)
+}
@@ -0,0 +1,251 @@
+// Copyright 2020 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package services
+
+import (
+	"context"
+	"fmt"
+	"maps"
+	"os"
+	"slices"
+	"strconv"
+	"sync/atomic"
+	"time"
+
+	"cloud.google.com/go/spanner"
+	"github.com/cloudspannerecosystem/dynamodb-adapter/models"
+	"github.com/cloudspannerecosystem/dynamodb-adapter/pkg/errors"
+	"github.com/cloudspannerecosystem/dynamodb-adapter/pkg/logger"
+	"github.com/cloudspannerecosystem/dynamodb-adapter/storage"
+	"github.com/cloudspannerecosystem/dynamodb-adapter/utils"
+	"github.com/robfig/cron"
+)
+
+const (
+	ttlStatusEnabled  = "ENABLED"
+	ttlStatusDisabled = "DISABLED"
+
+	ttlLeaseName        = "ttl_sweeper"
+	defaultTTLBatchSize = 500
+)
+
+// adapterTTLTableDDL creates the metadata tables holding the TimeToLive attribute of the tables
+// and the lease which elects the adapter instance running the expiry sweeper
+var adapterTTLTableDDL = []string{
+	`CREATE TABLE IF NOT EXISTS dynamodb_adapter_ttl (
+	tableName STRING(MAX) NOT NULL,
+	attributeName STRING(MAX),
+) PRIMARY KEY (tableName)`,
+	`CREATE TABLE IF NOT EXISTS dynamodb_adapter_lease (
+	name STRING(MAX) NOT NULL,
+	owner STRING(MAX),
+	expiresAt TIMESTAMP,
+) PRIMARY KEY (name)`,
+}
+
+var (
+	ttlCron *cron.Cron
+	// ttlSweeping prevents overlapping sweeps when a sweep takes longer than the interval
+	ttlSweeping int32
+	// ttlOwner identifies this adapter instance in the sweeper lease
+	ttlOwner = ttlOwnerID()
+)
+
+// ttlOwnerID builds an identifier of the adapter instance from the host name and process id
+func ttlOwnerID() string {
+	host, _ := os.Hostname()
+	return host + "-" + strconv.Itoa(os.Getpid()) + "-" + strconv.FormatInt(time.Now().UnixNano(), 36)
+}
+
+// UpdateTimeToLive enables or disables the TimeToLive of a table. The attribute has to be a Number
+// holding the expiry time in epoch seconds, it is added as a column when the table does not have it.
+func UpdateTimeToLive(ctx context.Context, meta models.UpdateTimeToLiveMeta) (*models.TimeToLiveSpecification, error) {
+	spec := meta.TimeToLiveSpecification
+	if len(spec.AttributeName) < 1 || len(spec.AttributeName) > 255 {
+		return nil, errors.New("ValidationException", "TimeToLiveSpecification.AttributeName must be between 1 and 255 characters long")
+	}
+	spannerTable := utils.ChangeTableNameForSpanner(meta.TableName)
+
+	tableMetaMux.Lock()
+	defer tableMetaMux.Unlock()
+
+	tableConf, ok := models.DbConfigMap[spannerTable]
+	if !ok {
+		return nil, errors.New("ResourceNotFoundException", "Requested resource not found: Table: "+meta.TableName+" not found")
+	}
+	column := spannerColumnName(spec.AttributeName)
+	switch {
+	case spec.Enabled && tableConf.TTLAttribute != "":
+		return nil, errors.New("ValidationException", "TimeToLive is already enabled")
+	case !spec.Enabled && tableConf.TTLAttribute == "":
+		return nil, errors.New("ValidationException", "TimeToLive is already disabled")
+	case !spec.Enabled && tableConf.TTLAttribute != column:
+		return nil, errors.New("ValidationException", "TimeToLive is active on a different AttributeName: current AttributeName is "+originalColumnName(tableConf.TTLAttribute))
+	}
+	if !spec.Enabled {
+		err := storage.GetStorageInstance().SpannerApply(ctx, []*spanner.Mutation{
+			spanner.Delete("dynamodb_adapter_ttl", spanner.Key{spannerTable}),
+		})
+		if err != nil {
+			return nil, errors.New("InternalServerError", err)
+		}
+		setTableTTL(spannerTable, "")
+		return &spec, nil
+	}
+
+	dataType, exists := models.TableDDL[spannerTable][column]
+	if exists && dataType != "N" {
+		return nil, errors.New("ValidationException", "TimeToLive attribute "+spec.AttributeName+" must be a Number attribute")
+	}
+	statements := slices.Clone(adapterTTLTableDDL)
+	var newColumns []tableColumn
+	if !exists {
+		col := tableColumn{Column: column, OriginalColumn: spec.AttributeName, DynamoDataType: "N", SpannerType: utils.ConvertDynamoTypeToSpannerType("N")}
+		newColumns = append(newColumns, col)
+		statements = append(statements, fmt.Sprintf("ALTER TABLE `%s` ADD COLUMN `%s` %s", spannerTable, col.Column, col.SpannerType))
+	}
+	if err := storage.GetStorageInstance().SpannerUpdateDDL(ctx, statements); err != nil {
+		return nil, errors.New("InternalServerError", err)
+	}
+
+	mutations := buildTableMetadataMutations(meta.TableName, spannerTable, models.TableConfig{PartitionKey: tableConf.PartitionKey, SortKey: tableConf.SortKey}, newColumns)
+	mutations = append(mutations, spanner.InsertOrUpdate("dynamodb_adapter_ttl", []string{"tableName", "attributeName"}, []interface{}{spannerTable, column}))
+	if err := storage.GetStorageInstance().SpannerApply(ctx, mutations); err != nil {
+		return nil, errors.New("InternalServerError", err)
+	}
+	if len(newColumns) > 0 {
+		registerColumns(spannerTable, newColumns)
+	}
+	setTableTTL(spannerTable, column)
+	return &spec, nil
+}
+
+// DescribeTimeToLive returns the TimeToLive status of a table
+func DescribeTimeToLive(ctx context.Context, tableName string) (*models.TimeToLiveDescription, error) {
+	tableConf, ok := models.DbConfigMap[utils.ChangeTableNameForSpanner(tableName)]
+	if !ok {
+		return nil, errors.New("ResourceNotFoundException", "Requested resource not found: Table: "+tableName+" not found")
+	}
+	if tableConf.TTLAttribute == "" {
+		return &models.TimeToLiveDescription{TimeToLiveStatus: ttlStatusDisabled}, nil
+	}
+	return &models.TimeToLiveDescription{
+		TimeToLiveStatus: ttlStatusEnabled,
+		AttributeName:    originalColumnName(tableConf.TTLAttribute),
+	}, nil
+}
+
+// setTableTTL sets the TimeToLive column of the table in the in-memory metadata, see registerTable
+func setTableTTL(spannerTable, column string) {
+	dbConfigMap := maps.Clone(models.DbConfigMap)
+	tableConf, ok := dbConfigMap[spannerTable]
+	if !ok || tableConf.TTLAttribute == column {
+		return
+	}
+	tableConf.TTLAttribute = column
+	dbConfigMap[spannerTable] = tableConf
+	models.DbConfigMap = dbConfigMap
+}
+
+// StartTTLSweeper starts the background sweeper deleting the expired items every
+// ttl.sweep_interval_seconds. The sweeper is disabled when the interval is not set.
+func StartTTLSweeper() {
+	if models.GlobalConfig == nil || models.GlobalConfig.TTL.SweepIntervalSeconds <= 0 {
+		logger.Info("TimeToLive sweeper is disabled")
+		return
+	}
+	ttlCron = cron.New()
+	err := ttlCron.AddFunc("@every "+strconv.Itoa(models.GlobalConfig.TTL.SweepIntervalSeconds)+"s", sweepExpiredItems)
+	if err != nil {
+		logger.Error(err)
+		return
+	}
+	ttlCron.Start()
+}
+
+// sweepExpiredItems refreshes the TimeToLive settings written by the other adapter instances and,
+// when this instance holds the sweeper lease, deletes the expired items of all the tables in batches
+func sweepExpiredItems() {
+	if !atomic.CompareAndSwapInt32(&ttlSweeping, 0, 1) {
+		return
+	}
+	defer atomic.StoreInt32(&ttlSweeping, 0)
+
+	interval := time.Duration(models.GlobalConfig.TTL.SweepIntervalSeconds) * time.Second
+	// the lease outlives a missed sweep, so that a slow sweep does not hand over the work
+	leaseDuration := 3 * interval
+	ctx, cancel := context.WithTimeout(context.Background(), leaseDuration)
+	defer cancel()
+
+	if err := refreshTTLConfig(ctx); err != nil {
+		logger.Debug("skipping TimeToLive sweep: ", err)
+		return
+	}
+	acquired, err := storage.GetStorageInstance().SpannerAcquireLease(ctx, ttlLeaseName, ttlOwner, leaseDuration)
+	if err != nil {
+		logger.Error("failed to acquire the TimeToLive sweeper lease: ", err)
+		return
+	}
+	if !acquired {
+		return
+	}
+
+	batchSize := models.GlobalConfig.TTL.BatchSize
+	if batchSize <= 0 {
+		batchSize = defaultTTLBatchSize
+	}
+	deadline := time.Now().Add(interval)
+	for spannerTable, tableConf := range models.DbConfigMap {
+		if tableConf.TTLAttribute == "" {
+			continue
+		}
+		total := 0
+		for time.Now().Before(deadline) {
+			deleted, err := storage.GetStorageInstance().SpannerDeleteExpired(ctx, spannerTable, tableConf.TTLAttribute, time.Now(), batchSize)
+			if err != nil {
+				logger.Error("failed to delete the expired items: ", spannerTable, err)
+				break
+			}
+			total += deleted
+			if deleted < batchSize {
+				break
+			}
+		}
+		if total > 0 {
+			logger.Info("deleted expired items: ", spannerTable, total)
+		}
+	}
+}
+
+// refreshTTLConfig loads the TimeToLive settings of all the tables from dynamodb_adapter_ttl
+func refreshTTLConfig(ctx context.Context) error {
+	stmt := spanner.Statement{SQL: "SELECT * FROM dynamodb_adapter_ttl"}
+	rows, err := storage.GetStorageInstance().ExecuteSpannerQuery(ctx, "dynamodb_adapter_ttl", models.TableColumnMap["dynamodb_adapter_ttl"], false, stmt)
+	if err != nil {
+		return err
+	}
+	columns := make(map[string]string, len(rows))
+	for _, row := range rows {
+		tableName, _ := row["tableName"].(string)
+		columns[tableName], _ = row["attributeName"].(string)
+	}
+
+	tableMetaMux.Lock()
+	defer tableMetaMux.Unlock()
+	for spannerTable := range models.DbConfigMap {
+		setTableTTL(spannerTable, columns[spannerTable])
+	}
+	return nil
+}
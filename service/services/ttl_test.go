@@ -0,0 +1,63 @@
+// Copyright 2020 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package services
+
+import (
+	"context"
+	"testing"
+
+	"github.com/cloudspannerecosystem/dynamodb-adapter/models"
+	"github.com/cloudspannerecosystem/dynamodb-adapter/pkg/errors"
+	"gopkg.in/go-playground/assert.v1"
+)
+
+func TestDescribeTimeToLive(t *testing.T) {
+	tableConf, columns := buildTableMetadata("orders_v1", ordersCreateTableMeta())
+	registerTable("orders_v1", tableConf, columns)
+	defer unregisterTable("orders_v1")
+
+	desc, err := DescribeTimeToLive(context.Background(), "orders-v1")
+	assert.Equal(t, err, nil)
+	assert.Equal(t, *desc, models.TimeToLiveDescription{TimeToLiveStatus: ttlStatusDisabled})
+
+	setTableTTL("orders_v1", "order_date")
+	desc, err = DescribeTimeToLive(context.Background(), "orders-v1")
+	assert.Equal(t, err, nil)
+	assert.Equal(t, *desc, models.TimeToLiveDescription{TimeToLiveStatus: ttlStatusEnabled, AttributeName: "order-date"})
+
+	_, err = DescribeTimeToLive(context.Background(), "unknown")
+	assert.Equal(t, err.(*errors.Error).ErrorCode, "ResourceNotFoundException")
+}
+
+func TestUpdateTimeToLiveValidation(t *testing.T) {
+	tableConf, columns := buildTableMetadata("orders_v1", ordersCreateTableMeta())
+	registerTable("orders_v1", tableConf, columns)
+	defer unregisterTable("orders_v1")
+	setTableTTL("orders_v1", "order_date")
+
+	tests := []struct {
+		testName string
+		spec     models.TimeToLiveSpecification
+		wantCode string
+	}{
+		{"missing attribute name", models.TimeToLiveSpecification{Enabled: true}, "ValidationException"},
+		{"already enabled", models.TimeToLiveSpecification{Enabled: true, AttributeName: "status"}, "ValidationException"},
+		{"different attribute", models.TimeToLiveSpecification{Enabled: false, AttributeName: "status"}, "ValidationException"},
+	}
+	for _, tc := range tests {
+		_, err := UpdateTimeToLive(context.Background(), models.UpdateTimeToLiveMeta{TableName: "orders-v1", TimeToLiveSpecification: tc.spec})
+		assert.Equal(t, err.(*errors.Error).ErrorCode, tc.wantCode)
+	}
+}
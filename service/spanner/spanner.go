@@ -21,6 +21,7 @@ import (
 
 	"cloud.google.com/go/spanner"
 	"github.com/cloudspannerecosystem/dynamodb-adapter/models"
+	"github.com/cloudspannerecosystem/dynamodb-adapter/pkg/logger"
 	"github.com/cloudspannerecosystem/dynamodb-adapter/storage"
 )
 
@@ -77,5 +78,64 @@ func ParseDDL(updateDB bool) error {
 			models.TableSpannerDDL[tableName][column] = spannerDataType
 		}
 	}
+	parseIndexDDL()
+	parseTTLConfig()
 	return nil
 }
+
+// parseIndexDDL - this loads the secondary index definitions of the tables created through
+// the CreateTable API from dynamodb_adapter_index_ddl table. The table is created by the init code,
+// or on the first CreateTable or UpdateTable request with indexes, so a missing table is not an error.
+func parseIndexDDL() {
+	stmt := spanner.Statement{}
+	stmt.SQL = "SELECT * FROM dynamodb_adapter_index_ddl"
+	ms, err := storage.GetStorageInstance().ExecuteSpannerQuery(context.Background(), "dynamodb_adapter_index_ddl", models.TableColumnMap["dynamodb_adapter_index_ddl"], false, stmt)
+	if err != nil {
+		logger.Debug("skipping index metadata: ", err)
+		return
+	}
+	for _, m := range ms {
+		tableName, _ := m["tableName"].(string)
+		tableConf, ok := models.DbConfigMap[tableName]
+		if !ok {
+			continue
+		}
+		indexName, _ := m["indexName"].(string)
+		conf := models.TableConfig{
+			DDBIndexName: indexName,
+			ActualTable:  tableName,
+		}
+		conf.SpannerIndexName, _ = m["spannerIndexName"].(string)
+		conf.IndexType, _ = m["indexType"].(string)
+		conf.PartitionKey, _ = m["partitionKey"].(string)
+		conf.SortKey, _ = m["sortKey"].(string)
+		conf.ProjectionType, _ = m["projectionType"].(string)
+		conf.NonKeyAttributes, _ = m["nonKeyAttributes"].([]string)
+		if tableConf.Indices == nil {
+			tableConf.Indices = make(map[string]models.TableConfig)
+		}
+		tableConf.Indices[indexName] = conf
+		models.DbConfigMap[tableName] = tableConf
+	}
+}
+
+// parseTTLConfig - this loads the TimeToLive attribute of the tables from dynamodb_adapter_ttl table.
+// The table is created on the first UpdateTimeToLive request, so a missing table is not an error.
+func parseTTLConfig() {
+	stmt := spanner.Statement{}
+	stmt.SQL = "SELECT * FROM dynamodb_adapter_ttl"
+	ms, err := storage.GetStorageInstance().ExecuteSpannerQuery(context.Background(), "dynamodb_adapter_ttl", models.TableColumnMap["dynamodb_adapter_ttl"], false, stmt)
+	if err != nil {
+		logger.Debug("skipping TimeToLive metadata: ", err)
+		return
+	}
+	for _, m := range ms {
+		tableName, _ := m["tableName"].(string)
+		tableConf, ok := models.DbConfigMap[tableName]
+		if !ok {
+			continue
+		}
+		tableConf.TTLAttribute, _ = m["attributeName"].(string)
+		models.DbConfigMap[tableName] = tableConf
+	}
+}
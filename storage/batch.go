@@ -0,0 +1,94 @@
+// Copyright 2020 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package storage
+
+import (
+	"context"
+	"strings"
+
+	"cloud.google.com/go/spanner"
+	otelgo "github.com/cloudspannerecosystem/dynamodb-adapter/otel"
+	"github.com/cloudspannerecosystem/dynamodb-adapter/pkg/errors"
+	"google.golang.org/grpc/codes"
+)
+
+const SpannerBatchWriteAnnotation = "Calling SpannerBatchWrite Method"
+
+// BatchWrite - one put or delete of a BatchWriteItem, Item is set for a put and Key for a delete
+type BatchWrite struct {
+	Table string
+	Item  map[string]interface{}
+	Key   map[string]interface{}
+}
+
+// SpannerBatchWrite - applies the puts and deletes of a batch in one commit. When the commit fails with
+// a retryable error or exceeds the mutation limit of Spanner, every write is applied on its own, so that
+// only the writes which failed are reported. It returns the error of each write, nil when it was applied.
+func (s Storage) SpannerBatchWrite(ctx context.Context, writes []BatchWrite) ([]error, error) {
+	otelgo.AddAnnotation(ctx, SpannerBatchWriteAnnotation)
+	writeErrs := make([]error, len(writes))
+	if len(writes) == 0 {
+		return writeErrs, nil
+	}
+	mutations := make([]*spanner.Mutation, len(writes))
+	for i, w := range writes {
+		var err error
+		if w.Item != nil {
+			mutations[i], err = batchPutMutation(w.Table, w.Item, nil)
+		} else {
+			mutations[i], err = batchDeleteMutation(w.Table, w.Key)
+		}
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	client := s.getSpannerClient("")
+	_, err := client.Apply(ctx, mutations)
+	if err == nil {
+		return writeErrs, nil
+	}
+	if !isMutationLimitError(err) && !isRetryableSpannerError(err) {
+		return nil, batchWriteError(err)
+	}
+	for i, m := range mutations {
+		if _, err := client.Apply(ctx, []*spanner.Mutation{m}); err != nil {
+			writeErrs[i] = batchWriteError(err)
+		}
+	}
+	return writeErrs, nil
+}
+
+// isMutationLimitError - checks whether the commit failed because it holds more mutations than Spanner allows
+func isMutationLimitError(err error) bool {
+	return spanner.ErrCode(err) == codes.InvalidArgument && strings.Contains(err.Error(), "too many mutations")
+}
+
+// isRetryableSpannerError - checks whether the error is transient, the request can then be sent again
+func isRetryableSpannerError(err error) bool {
+	switch spanner.ErrCode(err) {
+	case codes.InvalidArgument, codes.NotFound, codes.AlreadyExists, codes.FailedPrecondition, codes.OutOfRange, codes.PermissionDenied:
+		return false
+	}
+	return true
+}
+
+// batchWriteError - the writes which do not match the schema are rejected, the other errors are retryable
+func batchWriteError(err error) error {
+	if isMutationLimitError(err) || isRetryableSpannerError(err) {
+		return errors.New("InternalServerError", err)
+	}
+	return errors.New("ValidationException", err)
+}
@@ -0,0 +1,44 @@
+// Copyright 2020
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package storage
+
+import (
+	"testing"
+
+	"github.com/cloudspannerecosystem/dynamodb-adapter/pkg/errors"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+func Test_batchWriteError(t *testing.T) {
+	tests := []struct {
+		name     string
+		err      error
+		wantCode string
+	}{
+		{"transient", status.Error(codes.Unavailable, "unavailable"), "InternalServerError"},
+		{"aborted", status.Error(codes.Aborted, "transaction aborted"), "InternalServerError"},
+		{"mutation limit", status.Error(codes.InvalidArgument, "The transaction contains too many mutations."), "InternalServerError"},
+		{"wrong key type", status.Error(codes.InvalidArgument, "Invalid value for column customer_id"), "ValidationException"},
+		{"unknown column", status.Error(codes.NotFound, "Column not found in table orders: total"), "ValidationException"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := batchWriteError(tt.err).(*errors.Error).ErrorCode
+			if got != tt.wantCode {
+				t.Errorf("batchWriteError() = %v, want %v", got, tt.wantCode)
+			}
+		})
+	}
+}
@@ -0,0 +1,253 @@
+// Copyright 2020 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package storage
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"cloud.google.com/go/spanner"
+	Admindatabase "cloud.google.com/go/spanner/admin/database/apiv1"
+	"cloud.google.com/go/spanner/admin/database/apiv1/databasepb"
+	"github.com/cloudspannerecosystem/dynamodb-adapter/models"
+	otelgo "github.com/cloudspannerecosystem/dynamodb-adapter/otel"
+	"github.com/cloudspannerecosystem/dynamodb-adapter/pkg/errors"
+	"github.com/cloudspannerecosystem/dynamodb-adapter/pkg/logger"
+	"google.golang.org/api/iterator"
+)
+
+const (
+	SpannerUpdateDDLAnnotation          = "Calling SpannerUpdateDDL Method"
+	SpannerStartUpdateDDLAnnotation     = "Calling SpannerStartUpdateDDL Method"
+	SpannerApplyAnnotation              = "Calling SpannerApply Method"
+	SpannerTableIndexesAnnotation       = "Calling SpannerTableIndexes Method"
+	SpannerIndexSchemasAnnotation       = "Calling SpannerIndexSchemas Method"
+	SpannerTableStatisticsAnnotation    = "Calling SpannerTableStatistics Method"
+	SpannerDatabaseCreateTimeAnnotation = "Calling SpannerDatabaseCreateTime Method"
+	SpannerListTablesAnnotation         = "Calling SpannerListTables Method"
+	SpannerTableExistsAnnotation        = "Calling SpannerTableExists Method"
+)
+
+// databasePath - fully qualified name of the adapter database
+func databasePath() string {
+	return fmt.Sprintf("projects/%s/instances/%s/databases/%s",
+		models.GlobalConfig.Spanner.ProjectID,
+		models.GlobalConfig.Spanner.InstanceID,
+		models.GlobalConfig.Spanner.DatabaseName,
+	)
+}
+
+// SpannerUpdateDDL - applies the DDL statements to the adapter database and waits
+// until the schema change has completed
+func (s Storage) SpannerUpdateDDL(ctx context.Context, statements []string) error {
+	otelgo.AddAnnotation(ctx, SpannerUpdateDDLAnnotation)
+	if len(statements) == 0 {
+		return nil
+	}
+	op, err := s.SpannerStartUpdateDDL(ctx, statements)
+	if err != nil {
+		return err
+	}
+	return op.Wait(ctx)
+}
+
+// SpannerStartUpdateDDL - starts applying the DDL statements to the adapter database and returns
+// the long-running operation without waiting for it, e.g. for index backfills
+func (s Storage) SpannerStartUpdateDDL(ctx context.Context, statements []string) (*Admindatabase.UpdateDatabaseDdlOperation, error) {
+	otelgo.AddAnnotation(ctx, SpannerStartUpdateDDLAnnotation)
+	if s.adminClient == nil {
+		return nil, errors.New("InternalServerError", "Spanner admin client is not initialized")
+	}
+	logger.Debug(statements)
+	return s.adminClient.UpdateDatabaseDdl(ctx, &databasepb.UpdateDatabaseDdlRequest{
+		Database:   databasePath(),
+		Statements: statements,
+	})
+}
+
+// SpannerApply - applies the mutations atomically, used for the adapter metadata tables
+func (s Storage) SpannerApply(ctx context.Context, mutations []*spanner.Mutation) error {
+	otelgo.AddAnnotation(ctx, SpannerApplyAnnotation)
+	if len(mutations) == 0 {
+		return nil
+	}
+	_, err := s.getSpannerClient("").Apply(ctx, mutations)
+	return err
+}
+
+// SpannerTableIndexes - returns the names of the secondary indexes of the table from INFORMATION_SCHEMA
+func (s Storage) SpannerTableIndexes(ctx context.Context, table string) ([]string, error) {
+	otelgo.AddAnnotation(ctx, SpannerTableIndexesAnnotation)
+	stmt := spanner.Statement{
+		SQL:    "SELECT INDEX_NAME FROM INFORMATION_SCHEMA.INDEXES WHERE TABLE_SCHEMA = '' AND TABLE_NAME = @tableName AND INDEX_TYPE = 'INDEX'",
+		Params: map[string]interface{}{"tableName": table},
+	}
+	itr := s.getSpannerClient(table).Single().Query(ctx, stmt)
+	defer itr.Stop()
+	var indexes []string
+	err := itr.Do(func(r *spanner.Row) error {
+		var name string
+		if err := r.Columns(&name); err != nil {
+			return err
+		}
+		indexes = append(indexes, name)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return indexes, nil
+}
+
+// IndexSchema - secondary index definition read from INFORMATION_SCHEMA. State is READ_WRITE once
+// the index is usable, WRITE_ONLY while it is being backfilled and PREPARE before that.
+type IndexSchema struct {
+	Name       string
+	State      string
+	KeyColumns []string
+}
+
+// SpannerIndexSchemas - returns the state and key columns of the secondary indexes of the table
+func (s Storage) SpannerIndexSchemas(ctx context.Context, table string) ([]IndexSchema, error) {
+	otelgo.AddAnnotation(ctx, SpannerIndexSchemasAnnotation)
+	stmt := spanner.Statement{
+		SQL: "SELECT c.INDEX_NAME, i.INDEX_STATE, c.COLUMN_NAME, c.ORDINAL_POSITION FROM INFORMATION_SCHEMA.INDEX_COLUMNS AS c " +
+			"JOIN INFORMATION_SCHEMA.INDEXES AS i ON i.TABLE_SCHEMA = c.TABLE_SCHEMA AND i.TABLE_NAME = c.TABLE_NAME AND i.INDEX_NAME = c.INDEX_NAME " +
+			"WHERE c.TABLE_SCHEMA = '' AND c.TABLE_NAME = @tableName AND c.INDEX_TYPE = 'INDEX' ORDER BY c.INDEX_NAME, c.ORDINAL_POSITION",
+		Params: map[string]interface{}{"tableName": table},
+	}
+	itr := s.getSpannerClient(table).Single().Query(ctx, stmt)
+	defer itr.Stop()
+	var indexes []IndexSchema
+	err := itr.Do(func(r *spanner.Row) error {
+		var indexName, columnName string
+		var state spanner.NullString
+		var position spanner.NullInt64
+		if err := r.Columns(&indexName, &state, &columnName, &position); err != nil {
+			return err
+		}
+		if len(indexes) == 0 || indexes[len(indexes)-1].Name != indexName {
+			indexes = append(indexes, IndexSchema{Name: indexName, State: state.StringVal})
+		}
+		// storing columns are not part of the index key and have no ordinal position
+		if position.Valid {
+			idx := &indexes[len(indexes)-1]
+			idx.KeyColumns = append(idx.KeyColumns, columnName)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return indexes, nil
+}
+
+// SpannerTableStatistics - returns the number of rows of the table and its size in bytes. The rows are
+// counted with a stale read, the size comes from the hourly SPANNER_SYS statistics and is 0 when
+// those are not available yet or not supported, as on the emulator.
+func (s Storage) SpannerTableStatistics(ctx context.Context, table string) (int64, int64, error) {
+	otelgo.AddAnnotation(ctx, SpannerTableStatisticsAnnotation)
+	client := s.getSpannerClient(table)
+
+	var itemCount int64
+	countItr := client.Single().WithTimestampBound(spanner.MaxStaleness(15*time.Second)).
+		Query(ctx, spanner.Statement{SQL: "SELECT COUNT(*) FROM `" + table + "`"})
+	defer countItr.Stop()
+	row, err := countItr.Next()
+	if err != nil {
+		return 0, 0, err
+	}
+	if err := row.Columns(&itemCount); err != nil {
+		return 0, 0, err
+	}
+
+	var sizeBytes int64
+	stmt := spanner.Statement{
+		SQL:    "SELECT USED_BYTES FROM SPANNER_SYS.TABLE_SIZES_STATS_1HOUR WHERE TABLE_NAME = @tableName ORDER BY INTERVAL_END DESC LIMIT 1",
+		Params: map[string]interface{}{"tableName": table},
+	}
+	itr := client.Single().Query(ctx, stmt)
+	defer itr.Stop()
+	row, err = itr.Next()
+	switch {
+	case err == iterator.Done:
+	case err != nil:
+		logger.Debug("table size statistics are not available: ", table, err)
+	default:
+		if err := row.Columns(&sizeBytes); err != nil {
+			return 0, 0, err
+		}
+	}
+	return itemCount, sizeBytes, nil
+}
+
+// SpannerDatabaseCreateTime - returns the time the adapter database was created
+func (s Storage) SpannerDatabaseCreateTime(ctx context.Context) (time.Time, error) {
+	otelgo.AddAnnotation(ctx, SpannerDatabaseCreateTimeAnnotation)
+	if s.adminClient == nil {
+		return time.Time{}, errors.New("InternalServerError", "Spanner admin client is not initialized")
+	}
+	db, err := s.adminClient.GetDatabase(ctx, &databasepb.GetDatabaseRequest{Name: databasePath()})
+	if err != nil {
+		return time.Time{}, err
+	}
+	return db.GetCreateTime().AsTime(), nil
+}
+
+// SpannerListTables - returns up to limit DynamoDB table names from dynamodb_adapter_table_ddl in
+// ascending order, starting after exclusiveStart when it is set
+func (s Storage) SpannerListTables(ctx context.Context, exclusiveStart string, limit int64) ([]string, error) {
+	otelgo.AddAnnotation(ctx, SpannerListTablesAnnotation)
+	stmt := spanner.Statement{
+		SQL: "SELECT name FROM (SELECT DISTINCT IFNULL(NULLIF(actualTable, ''), tableName) AS name FROM dynamodb_adapter_table_ddl) " +
+			"WHERE name > @exclusiveStart ORDER BY name LIMIT @limit",
+		Params: map[string]interface{}{"exclusiveStart": exclusiveStart, "limit": limit},
+	}
+	itr := s.getSpannerClient("dynamodb_adapter_table_ddl").Single().Query(ctx, stmt)
+	defer itr.Stop()
+	var names []string
+	err := itr.Do(func(r *spanner.Row) error {
+		var name string
+		if err := r.Columns(&name); err != nil {
+			return err
+		}
+		names = append(names, name)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return names, nil
+}
+
+// SpannerTableExists - checks in INFORMATION_SCHEMA whether the table exists
+func (s Storage) SpannerTableExists(ctx context.Context, table string) (bool, error) {
+	otelgo.AddAnnotation(ctx, SpannerTableExistsAnnotation)
+	stmt := spanner.Statement{
+		SQL:    "SELECT 1 FROM INFORMATION_SCHEMA.TABLES WHERE TABLE_SCHEMA = '' AND TABLE_NAME = @tableName",
+		Params: map[string]interface{}{"tableName": table},
+	}
+	itr := s.getSpannerClient(table).Single().Query(ctx, stmt)
+	defer itr.Stop()
+	_, err := itr.Next()
+	if err == iterator.Done {
+		return false, nil
+	}
+	if err != nil {
+		return false, err
+	}
+	return true, nil
+}
@@ -0,0 +1,178 @@
+// Copyright 2020 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package storage
+
+import (
+	"context"
+	"fmt"
+	"math/big"
+	"slices"
+
+	"cloud.google.com/go/spanner"
+	otelgo "github.com/cloudspannerecosystem/dynamodb-adapter/otel"
+	"github.com/cloudspannerecosystem/dynamodb-adapter/pkg/errors"
+	"github.com/cloudspannerecosystem/dynamodb-adapter/pkg/expression"
+	"github.com/cloudspannerecosystem/dynamodb-adapter/utils"
+	"google.golang.org/api/iterator"
+)
+
+const SpannerQueryPageAnnotation = "Calling SpannerQueryPage Method"
+
+// QueryFilterColumn - the column holding the result of the FilterExpression of a Query or a Scan. The filter
+// is selected instead of being part of the WHERE clause, as the filtered out items count as evaluated items.
+const QueryFilterColumn = "dynamodb_adapter_filter"
+
+// QueryPage - a page of the items of a Query or a Scan
+type QueryPage struct {
+	Items        []map[string]interface{}
+	ScannedCount int64
+	// LastEvaluated is the last item read, also when it does not match the filter. It is nil when
+	// all the items have been read.
+	LastEvaluated map[string]interface{}
+}
+
+// QueryFilter - the FilterExpression of a Query or a Scan evaluated on the items read
+type QueryFilter struct {
+	Expr *expression.Expression
+	// Projection lists the columns returned when the statement also selects the columns used by the
+	// filter only, it is nil when all the selected columns are returned
+	Projection []string
+}
+
+// SpannerQueryPage - reads a page of a Query or a Scan with the DynamoDB semantics: at most limit items are
+// evaluated, the page stops once maxBytes of items have been read, and only the items matching the
+// QueryFilterColumn, when selected, and the filter, when given, are returned. The statement has to select
+// one more item than the limit, so that the LastEvaluated item is only set when there are more items.
+func (s Storage) SpannerQueryPage(ctx context.Context, table string, stmt spanner.Statement, limit, maxBytes int64, filter *QueryFilter) (*QueryPage, error) {
+	otelgo.AddAnnotation(ctx, SpannerQueryPageAnnotation)
+	spannerTable := utils.ChangeTableNameForSpanner(table)
+	itr := s.readTransaction(ctx, spannerTable).Query(ctx, stmt)
+	defer itr.Stop()
+
+	page := &QueryPage{Items: []map[string]interface{}{}}
+	var size int64
+	var last map[string]interface{}
+	for {
+		r, err := itr.Next()
+		if err == iterator.Done {
+			return page, nil
+		}
+		if err != nil {
+			return nil, errors.New("ResourceNotFoundException", err)
+		}
+		if (limit > 0 && page.ScannedCount >= limit) || (maxBytes > 0 && size >= maxBytes) {
+			page.LastEvaluated = last
+			return page, nil
+		}
+		row, spannerRow, err := parseRow(r, spannerTable)
+		if err != nil {
+			return nil, err
+		}
+		matches, err := filterMatches(r)
+		if err != nil {
+			return nil, err
+		}
+		if filter != nil {
+			if matches && filter.Expr != nil {
+				if matches, err = filter.Expr.Evaluate(conditionItem(spannerTable, row, spannerRow)); err != nil {
+					return nil, errors.New("ValidationException", err)
+				}
+			}
+			if filter.Projection != nil {
+				for col := range row {
+					if !slices.Contains(filter.Projection, col) {
+						delete(row, col)
+					}
+				}
+			}
+		}
+		page.ScannedCount++
+		size += ItemSize(row)
+		last = row
+		if matches {
+			page.Items = append(page.Items, row)
+		}
+	}
+}
+
+// filterMatches - reads the QueryFilterColumn of the row, a row matches when the query has no filter
+func filterMatches(r *spanner.Row) (bool, error) {
+	for i, col := range r.ColumnNames() {
+		if col == QueryFilterColumn {
+			var matches spanner.NullBool
+			if err := r.Column(i, &matches); err != nil {
+				return false, errors.New("InternalServerError", err)
+			}
+			return matches.Valid && matches.Bool, nil
+		}
+	}
+	return true, nil
+}
+
+// ItemSize - approximates the size of an item as computed by DynamoDB for the 1 MB page limit: the
+// length of the attribute names plus the size of the values. Only the projected attributes are counted.
+func ItemSize(item map[string]interface{}) int64 {
+	var size int64
+	for name, value := range item {
+		size += int64(len(name)) + valueSize(value)
+	}
+	return size
+}
+
+func valueSize(value interface{}) int64 {
+	switch v := value.(type) {
+	case nil, bool:
+		return 1
+	case string:
+		return int64(len(v))
+	case []byte:
+		return int64(len(v))
+	case int64, float64, big.Rat, *big.Rat:
+		// numbers take one byte per two significant digits plus one byte
+		return int64(len(fmt.Sprint(v)))/2 + 1
+	case []string:
+		var size int64
+		for _, s := range v {
+			size += int64(len(s))
+		}
+		return size
+	case []float64:
+		var size int64
+		for _, f := range v {
+			size += valueSize(f)
+		}
+		return size
+	case [][]byte:
+		var size int64
+		for _, b := range v {
+			size += int64(len(b))
+		}
+		return size
+	case []interface{}:
+		// lists and maps take 3 bytes plus one byte per element
+		size := int64(3)
+		for _, e := range v {
+			size += 1 + valueSize(e)
+		}
+		return size
+	case map[string]interface{}:
+		size := int64(3)
+		for k, e := range v {
+			size += 1 + int64(len(k)) + valueSize(e)
+		}
+		return size
+	}
+	return int64(len(fmt.Sprint(value)))
+}
@@ -0,0 +1,40 @@
+// Copyright 2021
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package storage
+
+import "testing"
+
+func TestItemSize(t *testing.T) {
+	tests := []struct {
+		name string
+		item map[string]interface{}
+		want int64
+	}{
+		{"string", map[string]interface{}{"id": "abc"}, 5},
+		{"number", map[string]interface{}{"n": int64(12345)}, 4},
+		{"bool", map[string]interface{}{"ok": true}, 3},
+		{"binary", map[string]interface{}{"b": []byte{1, 2, 3}}, 4},
+		{"string set", map[string]interface{}{"tags": []string{"a", "bc"}}, 7},
+		{"map", map[string]interface{}{"m": map[string]interface{}{"k": "vv"}}, 8},
+		{"list", map[string]interface{}{"l": []interface{}{"x", nil}}, 8},
+		{"several attributes", map[string]interface{}{"id": "abc", "ok": true}, 8},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := ItemSize(tt.item); got != tt.want {
+				t.Errorf("ItemSize() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
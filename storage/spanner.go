@@ -20,6 +20,7 @@ import (
 	"encoding/json"
 	"fmt"
 	"log"
+	"maps"
 	"math"
 	"reflect"
 	"regexp"
@@ -49,11 +50,6 @@ const (
 	ExecuteSpannerQueryAnnotation = "Calling ExecuteSpannerQuery Method"
 	SpannerPutAnnotation          = "Calling SpannerPut Method"
 	SpannerDeleteAnnotation       = "Calling SpannerDelete Method"
-	SpannerBatchDeleteAnnotation  = "Calling SpannerBatchDelete Method"
-	SpannerAddAnnotation          = "Calling SpannerAdd Method"
-	SpannerDelAnnotation          = "Calling SpannerDel Method"
-	SpannerRemoveAnnotation       = "Calling SpannerRemove Method"
-	SpannerBatchPutAnnotation     = "Calling SpannerBatchPut Method"
 )
 
 // SpannerBatchGet - fetch all rows
@@ -76,9 +72,10 @@ func (s Storage) SpannerBatchGet(ctx context.Context, tableName string, pKeys, s
 		}
 	}
 	tableName = utils.ChangeTableNameForSpanner(tableName)
-	client := s.getSpannerClient(tableName)
-	itr := client.Single().Read(ctx, tableName, spanner.KeySets(keySet...), projectionCols)
+	projectionCols, ttlColumn := ttlProjection(tableName, projectionCols)
+	itr := s.readTransaction(ctx, tableName).Read(ctx, tableName, spanner.KeySets(keySet...), projectionCols)
 	defer itr.Stop()
+	now := time.Now()
 	allRows := []map[string]interface{}{}
 	for {
 		r, err := itr.Next()
@@ -86,12 +83,22 @@ func (s Storage) SpannerBatchGet(ctx context.Context, tableName string, pKeys, s
 			if err == iterator.Done {
 				break
 			}
-			return nil, errors.New("ValidationException", err)
+			// the keys which do not match the key types are rejected, the other errors are retryable
+			if code := spanner.ErrCode(err); code == codes.InvalidArgument || code == codes.NotFound {
+				return nil, errors.New("ValidationException", err)
+			}
+			return nil, errors.New("InternalServerError", err)
 		}
 		singleRow, _, err := parseRow(r, tableName)
 		if err != nil {
 			return nil, err
 		}
+		if isExpired(tableName, singleRow, now) {
+			continue
+		}
+		if ttlColumn != "" {
+			delete(singleRow, ttlColumn)
+		}
 		if len(singleRow) > 0 {
 			allRows = append(allRows, singleRow)
 		}
@@ -116,27 +123,37 @@ func (s Storage) SpannerGet(ctx context.Context, tableName string, pKeys, sKeys
 		}
 	}
 	tableName = utils.ChangeTableNameForSpanner(tableName)
-	client := s.getSpannerClient(tableName)
-	row, err := client.Single().ReadRow(ctx, tableName, key, projectionCols)
+	projectionCols, ttlColumn := ttlProjection(tableName, projectionCols)
+	row, err := s.readTransaction(ctx, tableName).ReadRow(ctx, tableName, key, projectionCols)
 	if err := errors.AssignError(err); err != nil {
 		logger.Error(err)
 		return nil, nil, errors.New("ResourceNotFoundException", tableName, key, err)
 	}
 	logger.Debug(err)
 
-	return parseRow(row, tableName)
+	singleRow, spannerRow, err := parseRow(row, tableName)
+	if err != nil {
+		return nil, nil, err
+	}
+	if isExpired(tableName, singleRow, time.Now()) {
+		return map[string]interface{}{}, nil, nil
+	}
+	if ttlColumn != "" {
+		delete(singleRow, ttlColumn)
+	}
+	return singleRow, spannerRow, nil
 }
 
-// ExecuteSpannerQuery - this will execute query on spanner database
+// ExecuteSpannerQuery - this will execute query on spanner database. cols are the columns returned, the
+// TimeToLive column is removed from the rows when the statement only selects it to hide the expired items,
+// see TTLColumn.
 func (s Storage) ExecuteSpannerQuery(ctx context.Context, table string, cols []string, isCountQuery bool, stmt spanner.Statement) ([]map[string]interface{}, error) {
 	otelgo.AddAnnotation(ctx, ExecuteSpannerQueryAnnotation)
-
-	// We should not default to 10s stale reads
-	//itr := s.getSpannerClient(table).Single().WithTimestampBound(spanner.ExactStaleness(time.Second*10)).Query(ctx, stmt)
-
-	itr := s.getSpannerClient(table).Single().Query(ctx, stmt)
+	itr := s.readTransaction(ctx, table).Query(ctx, stmt)
+	_, ttlColumn := ttlProjection(utils.ChangeTableNameForSpanner(table), cols)
 
 	defer itr.Stop()
+	now := time.Now()
 	allRows := []map[string]interface{}{}
 	for {
 		r, err := itr.Next()
@@ -160,6 +177,12 @@ func (s Storage) ExecuteSpannerQuery(ctx context.Context, table string, cols []s
 		if err != nil {
 			return nil, err
 		}
+		if isExpired(utils.ChangeTableNameForSpanner(table), singleRow, now) {
+			continue
+		}
+		if len(cols) > 0 && ttlColumn != "" {
+			delete(singleRow, ttlColumn)
+		}
 		allRows = append(allRows, singleRow)
 	}
 
@@ -246,444 +269,88 @@ func (s Storage) SpannerDelete(ctx context.Context, table string, m map[string]i
 	return err
 }
 
-// SpannerBatchDelete - this delete the data in batch
-func (s Storage) SpannerBatchDelete(ctx context.Context, table string, keys []map[string]interface{}) error {
-	otelgo.AddAnnotation(ctx, SpannerBatchDeleteAnnotation)
+// batchDeleteMutation - builds the delete mutation of one key of a batch
+func batchDeleteMutation(table string, m map[string]interface{}) (*spanner.Mutation, error) {
 	tableConf, err := config.GetTableConf(table)
 	if err != nil {
-		return err
+		return nil, err
 	}
 	table = utils.ChangeTableNameForSpanner(table)
 
 	pKey := tableConf.PartitionKey
-	ms := make([]*spanner.Mutation, len(keys))
 	sKey := tableConf.SortKey
-	for i := 0; i < len(keys); i++ {
-		m := keys[i]
-		pValue, ok := m[pKey]
-		if !ok {
-			return errors.New("ResourceNotFoundException", pKey)
-		}
-		var key spanner.Key
-		if sKey != "" {
-			sValue, ok := m[sKey]
-			if !ok {
-				return errors.New("ResourceNotFoundException", sKey)
-			}
-			key = spanner.Key{pValue, sValue}
-
-		} else {
-			key = spanner.Key{pValue}
-		}
-		ms[i] = spanner.Delete(table, key)
-	}
-	_, err = s.getSpannerClient(table).Apply(ctx, ms)
-	if err != nil {
-		return errors.New("ResourceNotFoundException", err)
-	}
-	return nil
-}
-
-// SpannerAdd - Spanner Add functionality like update attribute
-func (s Storage) SpannerAdd(ctx context.Context, table string, m map[string]interface{}, eval *models.Eval, expr *models.UpdateExpressionCondition) (map[string]interface{}, error) {
-	otelgo.AddAnnotation(ctx, SpannerAddAnnotation)
-	tableConf, err := config.GetTableConf(table)
-	if err != nil {
-		return nil, err
+	pValue, ok := m[pKey]
+	if !ok {
+		return nil, errors.New("ResourceNotFoundException", pKey)
 	}
-	pKey := tableConf.PartitionKey
-	var pValue interface{}
-	var sValue interface{}
-	sKey := tableConf.SortKey
-
-	cols := []string{}
 	var key spanner.Key
-	var m1 = make(map[string]interface{})
-
-	for k, v := range m {
-		m1[k] = v
-		if k == pKey {
-			pValue = v
-			delete(m, k)
-			continue
-		}
-		if k == sKey {
-			delete(m, k)
-			sValue = v
-			continue
+	if sKey != "" {
+		sValue, ok := m[sKey]
+		if !ok {
+			return nil, errors.New("ResourceNotFoundException", sKey)
 		}
-		cols = append(cols, k)
-	}
-	if sValue != nil {
 		key = spanner.Key{pValue, sValue}
+
 	} else {
 		key = spanner.Key{pValue}
 	}
-
-	updatedObj := map[string]interface{}{}
-	_, err = s.getSpannerClient(table).ReadWriteTransaction(ctx, func(ctx context.Context, t *spanner.ReadWriteTransaction) error {
-		tmpMap := map[string]interface{}{}
-		for k, v := range m1 {
-			tmpMap[k] = v
-		}
-
-		if len(eval.Attributes) > 0 || (expr != nil && len(expr.Field) > 0) {
-			status, _ := EvaluateConditionalExpression(ctx, t, table, tmpMap, eval, expr)
-			if !status {
-				return errors.New("ConditionalCheckFailedException")
-			}
-		}
-		table = utils.ChangeTableNameForSpanner(table)
-
-		r, err := t.ReadRow(ctx, table, key, cols)
-		if err != nil {
-			// If the row does not exist, treat as empty (DynamoDB upsert behavior)
-			if spanner.ErrCode(err) == codes.NotFound {
-				r = nil
-			} else {
-				return errors.New("ValidationException", err)
-			}
-		}
-		rs, _, err := parseRow(r, table)
-		if err != nil {
-			return err
-		}
-
-		for k, v := range tmpMap {
-			if existingVal, ok := rs[k]; ok {
-				switch existingVal := existingVal.(type) { // TODO: Support timestamp and big.rat
-				case int64:
-					// Handling int64
-					v2, ok := v.(float64)
-					if !ok {
-						strV, ok := v.(string)
-						if !ok {
-							return errors.New("ValidationException", reflect.TypeOf(v).String())
-						}
-						v2, err = strconv.ParseFloat(strV, 64)
-						if err != nil {
-							return errors.New("ValidationException", reflect.TypeOf(v).String())
-						}
-					}
-					tmpMap[k] = existingVal + int64(v2)
-
-				case float64:
-					// Handling float64
-					v2, ok := v.(float64)
-					if !ok {
-						strV, ok := v.(string)
-						if !ok {
-							return errors.New("ValidationException", reflect.TypeOf(v).String())
-						}
-						v2, err = strconv.ParseFloat(strV, 64)
-						if err != nil {
-							return errors.New("ValidationException", reflect.TypeOf(v).String())
-						}
-					}
-					tmpMap[k] = existingVal + v2
-
-				default:
-					logger.Debug(reflect.TypeOf(v).String())
-				}
-			}
-		}
-
-		// Add partition and sort keys to the updated object
-		tmpMap[pKey] = pValue
-		if sValue != nil {
-			tmpMap[sKey] = sValue
-		}
-
-		ddl := models.TableDDL[table]
-		for k, v := range tmpMap {
-			updatedObj[k] = v
-			t, ok := ddl[k]
-			if t == "BYTES(MAX)" && ok {
-				ba, err := json.Marshal(v)
-				if err != nil {
-					return errors.New("ValidationException", err)
-				}
-				tmpMap[k] = ba
-			}
-			switch v := v.(type) {
-			case []interface{}:
-				// Serialize lists to JSON
-				jsonValue, err := json.Marshal(v)
-				if err != nil {
-					return fmt.Errorf("failed to serialize column %s to JSON: %v", k, err)
-				}
-				tmpMap[k] = string(jsonValue)
-			default:
-				// Assign other types as-is
-				tmpMap[k] = v
-			}
-		}
-
-		mutation := spanner.InsertOrUpdateMap(table, tmpMap)
-		err = t.BufferWrite([]*spanner.Mutation{mutation})
-		if err != nil {
-			return errors.New("ResourceNotFoundException", err)
-		}
-
-		return nil
-	})
-
-	return updatedObj, err
+	return spanner.Delete(table, key), nil
 }
 
-func (s Storage) SpannerDel(ctx context.Context, table string, m map[string]interface{}, eval *models.Eval, expr *models.UpdateExpressionCondition) error {
-	otelgo.AddAnnotation(ctx, SpannerDelAnnotation)
-	tableConf, err := config.GetTableConf(table)
-	if err != nil {
-		return err
-	}
-	pKey := tableConf.PartitionKey
-	var pValue interface{}
-	var sValue interface{}
-	sKey := tableConf.SortKey
-
-	cols := []string{}
-	var key spanner.Key
-	var m1 = make(map[string]interface{})
-
-	// Process primary and secondary keys
+// batchPutMutation - builds the insert or update mutation of one item of a batch, converting the
+// binary, map and list attributes into their column values
+func batchPutMutation(table string, m map[string]interface{}, spannerRow map[string]interface{}) (*spanner.Mutation, error) {
+	table = utils.ChangeTableNameForSpanner(table)
+	ddl := models.TableDDL[table]
 	for k, v := range m {
-		m1[k] = v
-		if k == pKey {
-			pValue = v
-			delete(m, k)
-			continue
-		}
-		if k == sKey {
-			delete(m, k)
-			sValue = v
-			continue
-		}
-		cols = append(cols, k)
-	}
-	if sValue != nil {
-		key = spanner.Key{pValue, sValue}
-	} else {
-		key = spanner.Key{pValue}
-	}
-
-	_, err = s.getSpannerClient(table).ReadWriteTransaction(ctx, func(ctx context.Context, t *spanner.ReadWriteTransaction) error {
-		tmpMap := map[string]interface{}{}
-		for k, v := range m {
-			tmpMap[k] = v
-		}
-
-		// Evaluate conditional expressions
-		if len(eval.Attributes) > 0 || (expr != nil && len(expr.Field) > 0) {
-			status, _ := EvaluateConditionalExpression(ctx, t, table, m1, eval, expr)
-			if !status {
-				return errors.New("ConditionalCheckFailedException")
-			}
-		}
-
-		table = utils.ChangeTableNameForSpanner(table)
-
-		// Read the row
-		r, err := t.ReadRow(ctx, table, key, cols)
-		if err != nil {
-			// If the row does not exist, there's nothing to do
-			// DynamoDB API considers transaction OK
-			if spanner.ErrCode(err) == codes.NotFound {
-				return nil
-			} else {
-				return errors.New("ValidationException", err)
-			}
-		}
-		rs, _, err := parseRow(r, table)
-		if err != nil {
-			return err
-		}
-
-		// Process and merge data for deletion
-		for k, v := range tmpMap {
-			v1, ok := rs[k]
-			if ok {
-				switch v1.(type) {
-				case []interface{}:
-					var ifaces1 []interface{}
-					ba, ok := v.([]byte)
-					if ok {
-						err = json.Unmarshal(ba, &ifaces1)
-						if err != nil {
-							logger.Error(err, string(ba))
-						}
-					} else {
-						ifaces1 = v.([]interface{})
-					}
-					m1 := map[interface{}]struct{}{}
-					ifaces := v1.([]interface{})
-					for i := 0; i < len(ifaces); i++ {
-						m1[reflect.ValueOf(ifaces[i]).Interface()] = struct{}{}
-					}
-					for i := 0; i < len(ifaces1); i++ {
+		// t, ok := ddl[k]
+		if strings.Contains(k, ".") {
+			pathfeilds := strings.Split(k, ".")
+			colName := pathfeilds[0]
+			t, ok := ddl[colName]
+			if t == "JSON" || t == "M" && ok {
 
-						delete(m1, reflect.ValueOf(ifaces1[i]).Interface())
-					}
-					ifaces = []interface{}{}
-					for k := range m1 {
-						ifaces = append(ifaces, k)
-					}
-					tmpMap[k] = ifaces
-				default:
-					logger.Debug(reflect.TypeOf(v).String())
+				var err error
+				// Store the updated JSON in the map
+				m[colName], err = updateMapColumnObject(spannerRow, colName, k, v)
+				if err != nil {
+					return nil, errors.New("Error updating the Map object:", err)
 				}
+				delete(m, k)
 			}
-		}
-		tmpMap[pKey] = pValue
-		if sValue != nil {
-			tmpMap[sKey] = sValue
-		}
-
-		ddl := models.TableDDL[table]
-
-		// Handle special cases like BYTES(MAX) columns
-		for k, v := range tmpMap {
+		} else {
 			t, ok := ddl[k]
-			if t == "BYTES(MAX)" && ok {
+			if t == "BYTES(MAX)" || t == "B" && ok {
 				ba, err := json.Marshal(v)
 				if err != nil {
-					return errors.New("ValidationException", err)
-				}
-				tmpMap[k] = ba
-			}
-		}
-
-		// Perform the delete operation by updating the row
-		mutation := spanner.InsertOrUpdateMap(table, tmpMap)
-		err = t.BufferWrite([]*spanner.Mutation{mutation})
-		if err != nil {
-			return errors.New("ResourceNotFoundException", err)
-		}
-		return nil
-	})
-	return err
-}
-
-// SpannerRemove - Spanner Remove functionality like update attribute
-func (s Storage) SpannerRemove(ctx context.Context, table string, m map[string]interface{}, eval *models.Eval, expr *models.UpdateExpressionCondition, colsToRemove []string, oldRes map[string]interface{}) error {
-	otelgo.AddAnnotation(ctx, SpannerRemoveAnnotation)
-	_, err := s.getSpannerClient(table).ReadWriteTransaction(ctx, func(ctx context.Context, t *spanner.ReadWriteTransaction) error {
-		tmpMap := map[string]interface{}{}
-		for k, v := range m {
-			tmpMap[k] = v
-		}
-		if len(eval.Attributes) > 0 || (expr != nil && len(expr.Field) > 0) {
-			status, _ := EvaluateConditionalExpression(ctx, t, table, m, eval, expr)
-			if !status {
-				return errors.New("ConditionalCheckFailedException")
-			}
-		}
-
-		// Process each removal target
-		for _, target := range colsToRemove {
-			if strings.Contains(target, "[") && strings.Contains(target, "]") {
-				// Handle list element removal
-				listAttr, idx := utils.ParseListRemoveTarget(target)
-				if val, ok := oldRes[listAttr]; ok {
-					if list, ok := val.([]interface{}); ok {
-						oldRes[listAttr] = utils.RemoveListElement(list, idx)
-						tmpMap[listAttr] = oldRes[listAttr]
-					}
+					return nil, errors.New("ValidationException", err)
 				}
-			} else {
-				// Direct column removal from oldRes
-				delete(oldRes, target)
+				m[k] = ba
 			}
-		}
-		// Handle special cases like BYTES(MAX) columns
-		for k, v := range tmpMap {
-			switch v := v.(type) {
-			case []interface{}:
-				// Serialize lists to JSON
-				jsonValue, err := json.Marshal(v)
+			if t == "M" && ok {
+				ba, err := json.MarshalIndent(v, "", "  ")
 				if err != nil {
-					return fmt.Errorf("failed to serialize column %s to JSON: %v", k, err)
+					return nil, errors.New("ValidationException", err)
 				}
-				tmpMap[k] = string(jsonValue)
-			default:
-				// Assign other types as-is
-				tmpMap[k] = v
+				m[k] = string(ba)
 			}
-		}
-
-		table = utils.ChangeTableNameForSpanner(table)
-		mutation := spanner.InsertOrUpdateMap(table, tmpMap)
-		err := t.BufferWrite([]*spanner.Mutation{mutation})
-		if err != nil {
-			return errors.New("ResourceNotFoundException", err)
-		}
-		return nil
-	})
-	return err
-}
-
-// SpannerBatchPut - this insert or update data in batch
-func (s Storage) SpannerBatchPut(ctx context.Context, table string, m []map[string]interface{}, spannerRow []map[string]interface{}) error {
-	otelgo.AddAnnotation(ctx, SpannerBatchPutAnnotation)
-	mutations := make([]*spanner.Mutation, len(m))
-	ddl := models.TableDDL[utils.ChangeTableNameForSpanner(table)]
-	table = utils.ChangeTableNameForSpanner(table)
-	for i := 0; i < len(m); i++ {
-		for k, v := range m[i] {
-			// t, ok := ddl[k]
-			if strings.Contains(k, ".") {
-				pathfeilds := strings.Split(k, ".")
-				colName := pathfeilds[0]
-				t, ok := ddl[colName]
-				if t == "JSON" || t == "M" && ok {
-
-					var err error
-					// Store the updated JSON in the map
-					m[i][colName], err = updateMapColumnObject(spannerRow[i], colName, k, v)
-					if err != nil {
-						return errors.New("Error updating the Map object:", err)
-					}
-					delete(m[i], k)
-				}
-			} else {
-				t, ok := ddl[k]
-				if t == "BYTES(MAX)" || t == "B" && ok {
-					ba, err := json.Marshal(v)
-					if err != nil {
-						return errors.New("ValidationException", err)
-					}
-					m[i][k] = ba
-				}
-				if t == "M" && ok {
-					ba, err := json.MarshalIndent(v, "", "  ")
-					if err != nil {
-						return errors.New("ValidationException", err)
-					}
-					m[i][k] = string(ba)
+			if t == "L" && ok {
+				list, ok := v.([]interface{})
+				if !ok {
+					return nil, errors.New("invalid list format")
 				}
-				if t == "L" && ok {
-					list, ok := v.([]interface{})
-					if !ok {
-						return errors.New("invalid list format")
-					}
 
-					jsonData, err := json.Marshal(list)
-					if err != nil {
-						return fmt.Errorf("error marshaling list to JSON: %w", err)
-					}
-
-					m[i][k] = string(jsonData)
+				jsonData, err := json.Marshal(list)
+				if err != nil {
+					return nil, fmt.Errorf("error marshaling list to JSON: %w", err)
 				}
 
+				m[k] = string(jsonData)
 			}
+
 		}
-		mutations[i] = spanner.InsertOrUpdateMap(table, m[i])
 	}
-	_, err := s.getSpannerClient(table).Apply(ctx, mutations)
-	if err != nil {
-		return errors.New("ResourceNotFoundException", err.Error())
-	}
-	return nil
+	return spanner.InsertOrUpdateMap(table, m), nil
 }
 
 // performPutOperation handles the insertion or update of data in a specified Spanner table.
@@ -861,7 +528,7 @@ func EvaluateConditionalExpression(ctx context.Context, t *spanner.ReadWriteTran
 	logger.Debug(err)
 
 	// Parse row into a map
-	rowMap, _, err := parseRow(r, utils.ChangeTableNameForSpanner(table))
+	rowMap, spannerRow, err := parseRow(r, utils.ChangeTableNameForSpanner(table))
 	if err != nil {
 		return false, err
 	}
@@ -923,18 +590,21 @@ func EvaluateConditionalExpression(ctx context.Context, t *spanner.ReadWriteTran
 		}
 	}
 
-	// Evaluate main attributes
-	for i := 0; i < len(e.Attributes); i++ {
-		e.ValueMap[e.Tokens[i]] = evaluateStatementFromRowMap(e.Attributes[i], e.Cols[i], rowMap)
-	}
+	return utils.EvaluateExpression(e, conditionItem(utils.ChangeTableNameForSpanner(table), rowMap, spannerRow))
+}
 
-	// Execute the expression evaluation
-	status, err := utils.EvaluateExpression(e)
-	if err != nil {
-		return false, err
+// conditionItem - builds the item a condition or a filter is evaluated on from a parsed row: the map
+// attributes are taken from their JSON value, as parseRow wraps the nested maps, and the binary
+// attributes read as strings are turned back into bytes.
+func conditionItem(spannerTable string, row, spannerRow map[string]interface{}) map[string]interface{} {
+	item := maps.Clone(row)
+	maps.Copy(item, spannerRow)
+	for col, v := range item {
+		if s, ok := v.(string); ok && models.TableDDL[spannerTable][col] == "B" {
+			item[col] = []byte(s)
+		}
 	}
-
-	return status, nil
+	return item
 }
 
 func evaluateStatementFromRowMap(conditionalExpression, colName string, rowMap map[string]interface{}) interface{} {
@@ -1006,7 +676,7 @@ func parseRow(r *spanner.Row, spannerTableName string) (map[string]interface{},
 
 	cols := r.ColumnNames()
 	for i, k := range cols {
-		if k == "" || k == "commit_timestamp" {
+		if k == "" || k == "commit_timestamp" || k == QueryFilterColumn {
 			continue
 		}
 		v, ok := tableDDL[k]
@@ -1041,7 +711,7 @@ func parseRow(r *spanner.Row, spannerTableName string) (map[string]interface{},
 		case "M":
 			err = parseMapColumn(r, i, k, singleRow, spannerRow)
 		default:
-			return nil, nil, errors.New("TypeNotFound", err, k)
+			return nil, nil, errors.New("InternalServerError", "unsupported column type", k)
 		}
 		if err != nil {
 			return nil, nil, errors.New("ValidationException", err, k)
@@ -1307,7 +977,7 @@ func parseMapColumn(r *spanner.Row, idx int, col string, row map[string]interfac
 	if !s.IsNull() {
 		var decodedData interface{}
 		if err = json.Unmarshal([]byte(s.String()), &decodedData); err != nil {
-			return errors.New("JSONParseException", err)
+			return errors.New("InternalServerError", err)
 		}
 		row[col] = utils.ParseNestedJSON(decodedData)
 		spannerRow[col] = decodedData
@@ -1471,72 +1141,6 @@ func checkInifinty(value float64, logData interface{}) error { // TODO: Support
 	return nil
 }
 
-// SpannerTransactGetItems is a utility function to fetch data for a single TransactGetItems operation.
-// It takes a context, a table name, a map of projection columns, a map of primary keys, and a map of secondary keys.
-// It returns a slice of maps and an error.
-// The function first gets a Spanner client and then performs a transaction read operation.
-// It then iterates over the results and parses the Spanner rows into DynamoDB-style rows.
-// Finally, it returns the parsed rows.
-func (s Storage) SpannerTransactGetItems(ctx context.Context, tableProjectionCols map[string][]string, pValues map[string]interface{}, sValues map[string]interface{}) ([]map[string]interface{}, error) {
-	client := s.getSpannerClient("") // Get a generic client
-	txn := client.ReadOnlyTransaction()
-	defer txn.Close()
-
-	// Initialize the result slice
-	allRows := []map[string]interface{}{}
-	// Iterate over the tables
-	for tableName, projectionCols := range tableProjectionCols {
-		// Get the column definitions for the table
-		// Get the primary keys, secondary keys, and construct the key set
-		pKeys := pValues[tableName].([]interface{})
-		sKeys := sValues[tableName].([]interface{})
-		var keySet []spanner.KeySet
-
-		for i := range pKeys {
-			if len(sKeys) == 0 || sKeys[i] == nil {
-				keySet = append(keySet, spanner.Key{pKeys[i]})
-			} else {
-				keySet = append(keySet, spanner.Key{pKeys[i], sKeys[i]})
-			}
-		}
-		// If no projection columns are specified, then get all columns
-		if len(projectionCols) == 0 {
-			var ok bool
-			projectionCols, ok = models.TableColumnMap[utils.ChangeTableNameForSpanner(tableName)]
-			if !ok {
-				return nil, errors.New("ResourceNotFoundException", tableName)
-			}
-		}
-		// Perform the transaction read operation
-		itr := txn.Read(ctx, tableName, spanner.KeySets(keySet...), projectionCols)
-		defer itr.Stop()
-		// Iterate over the results
-		for {
-			r, err := itr.Next()
-			if err != nil {
-				if err == iterator.Done {
-					break
-				}
-				return nil, errors.New("ValidationException", err)
-			}
-			// Parse the Spanner row into a DynamoDB-style row
-			singleRow, _, err := parseRow(r, utils.ChangeTableNameForSpanner(tableName))
-			if err != nil {
-				return nil, err
-			}
-			// If the row is not empty, add it to the result slice
-			if len(singleRow) > 0 {
-				rowWithTable := map[string]interface{}{
-					"Item":      singleRow,
-					"TableName": tableName,
-				}
-				allRows = append(allRows, rowWithTable)
-			}
-		}
-	}
-	return allRows, nil
-}
-
 // SpannerTransactWritePut performs a transactional write operation in Spanner.
 // It checks for conditional expressions and updates the database accordingly.
 //
@@ -1618,6 +1222,9 @@ func (s Storage) performTransactPutOperation(table string, m map[string]interfac
 			}
 		} else {
 			t, ok := ddl[k]
+			if v == nil {
+				continue
+			}
 			if t == "B" && ok {
 				ba, err := json.Marshal(v)
 				if err != nil {
@@ -1626,9 +1233,6 @@ func (s Storage) performTransactPutOperation(table string, m map[string]interfac
 				newMap[k] = ba
 			}
 			if t == "M" && ok {
-				if v == nil {
-					continue
-				}
 				ba, err := json.MarshalIndent(v, "", "  ")
 				if err != nil {
 					return nil, errors.New("ValidationException", err)
@@ -1761,189 +1365,6 @@ func (s Storage) TransactWriteSpannerDel(ctx context.Context, table string, m ma
 	return mutation, err
 }
 
-func (s Storage) TransactWriteSpannerAdd(ctx context.Context, table string, m map[string]interface{}, eval *models.Eval, expr *models.UpdateExpressionCondition, txn *spanner.ReadWriteTransaction) (map[string]interface{}, *spanner.Mutation, error) {
-	tableConf, err := config.GetTableConf(table)
-	if err != nil {
-		return nil, nil, err
-	}
-	pKey := tableConf.PartitionKey
-	var pValue interface{}
-	var sValue interface{}
-	sKey := tableConf.SortKey
-
-	cols := []string{}
-	var key spanner.Key
-	var m1 = make(map[string]interface{})
-
-	for k, v := range m {
-		m1[k] = v
-		if k == pKey {
-			pValue = v
-			delete(m, k)
-			continue
-		}
-		if k == sKey {
-			delete(m, k)
-			sValue = v
-			continue
-		}
-		cols = append(cols, k)
-	}
-	if sValue != nil {
-		key = spanner.Key{pValue, sValue}
-	} else {
-		key = spanner.Key{pValue}
-	}
-	updatedObj := map[string]interface{}{}
-
-	tmpMap := map[string]interface{}{}
-	for k, v := range m1 {
-		tmpMap[k] = v
-	}
-	if len(eval.Attributes) > 0 || (expr != nil && len(expr.Field) > 0) {
-		status, _ := EvaluateConditionalExpression(ctx, txn, table, tmpMap, eval, expr)
-		if !status {
-			return nil, nil, errors.New("ConditionalCheckFailedException")
-		}
-	}
-	table = utils.ChangeTableNameForSpanner(table)
-
-	r, err := txn.ReadRow(ctx, table, key, cols)
-	if err != nil {
-		return nil, nil, errors.New("ResourceNotFoundException", err)
-	}
-	rs, _, err := parseRow(r, table)
-	if err != nil {
-		return nil, nil, err
-	}
-	for k, v := range tmpMap {
-		v1, ok := rs[k]
-		if ok {
-			switch v1.(type) { // TODO: Support timestamp and big.rat
-			case int64:
-				v2, ok := v.(float64)
-				if !ok {
-					strV, ok := v.(string)
-					if !ok {
-						return nil, nil, errors.New("ValidationException", reflect.TypeOf(v).String())
-					}
-					v2, err = strconv.ParseFloat(strV, 64)
-					if err != nil {
-						return nil, nil, errors.New("ValidationException", reflect.TypeOf(v).String())
-					}
-					err = checkInifinty(v2, strV)
-					if err != nil {
-						return nil, nil, err
-					}
-				}
-				tmpMap[k] = v1.(int64) + int64(v2)
-				err = checkInifinty(float64(m[k].(int64)), m)
-				if err != nil {
-					return nil, nil, err
-				}
-			case float64:
-				v2, ok := v.(float64)
-				if !ok {
-					strV, ok := v.(string)
-					if !ok {
-						return nil, nil, errors.New("ValidationException", reflect.TypeOf(v).String())
-					}
-					v2, err = strconv.ParseFloat(strV, 64)
-					if err != nil {
-						return nil, nil, errors.New("ValidationException", reflect.TypeOf(v).String())
-					}
-					err = checkInifinty(v2, strV)
-					if err != nil {
-						return nil, nil, err
-					}
-				}
-				tmpMap[k] = v1.(float64) + v2
-				err = checkInifinty(m[k].(float64), m)
-				if err != nil {
-					return nil, nil, err
-				}
-
-			case []interface{}:
-				var ifaces1 []interface{}
-				ba, ok := v.([]byte)
-				if ok {
-					err = json.Unmarshal(ba, &ifaces1)
-					if err != nil {
-						logger.Error(err, string(ba))
-					}
-				} else {
-					ifaces1 = v.([]interface{})
-				}
-				m1 := map[interface{}]struct{}{}
-				ifaces := v1.([]interface{})
-				for i := 0; i < len(ifaces); i++ {
-					m1[ifaces[i]] = struct{}{}
-				}
-				for i := 0; i < len(ifaces1); i++ {
-					m1[ifaces1[i]] = struct{}{}
-				}
-				ifaces = []interface{}{}
-				for k := range m1 {
-					ifaces = append(ifaces, k)
-				}
-				tmpMap[k] = ifaces
-			default:
-				logger.Debug(reflect.TypeOf(v).String())
-			}
-		}
-	}
-	tmpMap[pKey] = pValue
-	if sValue != nil {
-		tmpMap[sKey] = sValue
-	}
-	ddl := models.TableDDL[table]
-
-	for k, v := range tmpMap {
-		updatedObj[k] = v
-		t, ok := ddl[k]
-		if t == "BYTES(MAX)" && ok {
-			ba, err := json.Marshal(v)
-			if err != nil {
-				return nil, nil, errors.New("ValidationException", err)
-			}
-			tmpMap[k] = ba
-		}
-	}
-
-	mutation := spanner.InsertOrUpdateMap(table, tmpMap)
-
-	return updatedObj, mutation, err
-}
-
-// TransactWriteSpannerRemove - Spanner Remove functionality like update attribute inside a transaction
-//
-// This is used in the context of a transaction, and it will remove the given columns from the given
-// table. The condition expression in the eval and expr parameters will be evaluated and if it fails,
-// this will return an error.
-//
-// The colsToRemove parameter should contain the names of the columns to be removed.
-func (s Storage) TransactWriteSpannerRemove(ctx context.Context, table string, m map[string]interface{}, eval *models.Eval, expr *models.UpdateExpressionCondition, colsToRemove []string, txn *spanner.ReadWriteTransaction) (*spanner.Mutation, error) {
-
-	tmpMap := map[string]interface{}{}
-	for k, v := range m {
-		tmpMap[k] = v
-	}
-	if len(eval.Attributes) > 0 || (expr != nil && len(expr.Field) > 0) {
-		status, _ := EvaluateConditionalExpression(ctx, txn, table, m, eval, expr)
-		if !status {
-			return nil, errors.New("ConditionalCheckFailedException")
-		}
-	}
-	var null spanner.NullableValue
-	for _, col := range colsToRemove {
-		tmpMap[col] = null
-	}
-	table = utils.ChangeTableNameForSpanner(table)
-	mutation := spanner.InsertOrUpdateMap(table, tmpMap)
-
-	return mutation, nil
-}
-
 func (s Storage) TransactWriteSpannerDelete(ctx context.Context, table string, m map[string]interface{}, eval *models.Eval, expr *models.UpdateExpressionCondition, txn *spanner.ReadWriteTransaction) (*spanner.Mutation, error) {
 
 	tmpMap := map[string]interface{}{}
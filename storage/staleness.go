@@ -0,0 +1,104 @@
+// Copyright 2020 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package storage
+
+import (
+	"context"
+	"strings"
+	"time"
+
+	"cloud.google.com/go/spanner"
+	"github.com/cloudspannerecosystem/dynamodb-adapter/models"
+)
+
+type (
+	eventuallyConsistentKey struct{}
+	snapshotKey             struct{}
+	readWriteTxnKey         struct{}
+)
+
+// spannerReader - the reads shared by the read-only and the read-write transactions
+type spannerReader interface {
+	Read(ctx context.Context, table string, keys spanner.KeySet, columns []string) *spanner.RowIterator
+	ReadRow(ctx context.Context, table string, key spanner.Key, columns []string) (*spanner.Row, error)
+	Query(ctx context.Context, statement spanner.Statement) *spanner.RowIterator
+}
+
+// WithConsistentRead - sets the consistency of the item reads done with the context. The reads are strong
+// unless ConsistentRead is false, so that the reads of the write operations always see the latest items.
+func WithConsistentRead(ctx context.Context, consistentRead bool) context.Context {
+	if consistentRead {
+		return ctx
+	}
+	return context.WithValue(ctx, eventuallyConsistentKey{}, true)
+}
+
+// isEventuallyConsistent - checks whether the context was set up for eventually consistent reads
+func isEventuallyConsistent(ctx context.Context) bool {
+	eventual, _ := ctx.Value(eventuallyConsistentKey{}).(bool)
+	return eventual
+}
+
+// readTimestampBound - the timestamp bound of an eventually consistent read of the table: the staleness
+// configured for the table, or else for all the tables. The read is strong when no staleness is configured.
+func readTimestampBound(spannerTable string) spanner.TimestampBound {
+	if models.GlobalConfig == nil {
+		return spanner.StrongRead()
+	}
+	reads := models.GlobalConfig.Reads
+	staleness := reads.StalenessConfig
+	// the configuration keys are case insensitive
+	if conf, ok := reads.Tables[strings.ToLower(spannerTable)]; ok {
+		staleness = conf
+	}
+	switch {
+	case staleness.ExactStalenessMs > 0:
+		return spanner.ExactStaleness(time.Duration(staleness.ExactStalenessMs) * time.Millisecond)
+	case staleness.MaxStalenessMs > 0:
+		return spanner.MaxStaleness(time.Duration(staleness.MaxStalenessMs) * time.Millisecond)
+	}
+	return spanner.StrongRead()
+}
+
+// WithSnapshot - makes the reads done with the context share one strong read-only transaction, so that
+// they see the same snapshot of the tables, also when they run concurrently. The eventually consistent
+// reads are served from the snapshot too, instead of using their staleness. The returned function closes
+// the transaction.
+func (s Storage) WithSnapshot(ctx context.Context) (context.Context, func()) {
+	txn := s.getSpannerClient("").ReadOnlyTransaction()
+	return context.WithValue(ctx, snapshotKey{}, txn), txn.Close
+}
+
+// WithReadWriteTransaction - makes the reads done with the context part of the read-write transaction,
+// so that the items read by a write operation are locked until it commits
+func WithReadWriteTransaction(ctx context.Context, txn *spanner.ReadWriteTransaction) context.Context {
+	return context.WithValue(ctx, readWriteTxnKey{}, txn)
+}
+
+// readTransaction - the transaction of a read on the table: the read-write transaction or the snapshot of
+// the context, else a single use transaction with a strong read unless the context was set up for eventually
+// consistent reads
+func (s Storage) readTransaction(ctx context.Context, spannerTable string) spannerReader {
+	if txn, ok := ctx.Value(readWriteTxnKey{}).(*spanner.ReadWriteTransaction); ok {
+		return txn
+	}
+	if snapshot, ok := ctx.Value(snapshotKey{}).(*spanner.ReadOnlyTransaction); ok {
+		return snapshot
+	}
+	if isEventuallyConsistent(ctx) {
+		return s.getSpannerClient(spannerTable).Single().WithTimestampBound(readTimestampBound(spannerTable))
+	}
+	return s.getSpannerClient(spannerTable).Single()
+}
@@ -0,0 +1,67 @@
+// Copyright 2021
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package storage
+
+import (
+	"context"
+	"reflect"
+	"testing"
+	"time"
+
+	"cloud.google.com/go/spanner"
+	"github.com/cloudspannerecosystem/dynamodb-adapter/models"
+)
+
+func Test_readTimestampBound(t *testing.T) {
+	oldConfig := models.GlobalConfig
+	t.Cleanup(func() { models.GlobalConfig = oldConfig })
+	models.GlobalConfig = &models.Config{Reads: models.ReadsConfig{
+		StalenessConfig: models.StalenessConfig{MaxStalenessMs: 10000},
+		Tables: map[string]models.StalenessConfig{
+			"orders":   {ExactStalenessMs: 5000, MaxStalenessMs: 1000},
+			"payments": {},
+		},
+	}}
+
+	tests := []struct {
+		name  string
+		table string
+		want  spanner.TimestampBound
+	}{
+		{"default staleness", "employee", spanner.MaxStaleness(10 * time.Second)},
+		{"table staleness", "orders", spanner.ExactStaleness(5 * time.Second)},
+		{"table names are case insensitive", "Orders", spanner.ExactStaleness(5 * time.Second)},
+		{"strong table", "payments", spanner.StrongRead()},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := readTimestampBound(tt.table); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("readTimestampBound() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestWithConsistentRead(t *testing.T) {
+	ctx := context.Background()
+	if isEventuallyConsistent(WithConsistentRead(ctx, true)) {
+		t.Errorf("a consistent read is eventually consistent")
+	}
+	if !isEventuallyConsistent(WithConsistentRead(ctx, false)) {
+		t.Errorf("an eventually consistent read is strong")
+	}
+	if isEventuallyConsistent(ctx) {
+		t.Errorf("reads are not strong by default")
+	}
+}
@@ -25,6 +25,7 @@ import (
 	"time"
 
 	"cloud.google.com/go/spanner"
+	Admindatabase "cloud.google.com/go/spanner/admin/database/apiv1"
 	"github.com/cloudspannerecosystem/dynamodb-adapter/models"
 	otelgo "github.com/cloudspannerecosystem/dynamodb-adapter/otel"
 	"github.com/cloudspannerecosystem/dynamodb-adapter/pkg/logger"
@@ -36,6 +37,7 @@ import (
 // Storage object for intracting with storage package
 type Storage struct {
 	spannerClient map[string]*spanner.Client
+	adminClient   *Admindatabase.DatabaseAdminClient
 }
 
 func (s *Storage) GetSpannerClient() (*spanner.Client, error) {
@@ -137,6 +139,13 @@ func InitializeDriver(ctx context.Context) error {
 	storage.spannerClient[models.GlobalConfig.Spanner.InstanceID] = spannerClient
 	logger.Info("Spanner client initialized successfully")
 
+	// Admin client is used for table management APIs (CreateTable, DeleteTable, ...)
+	adminClient, err := Admindatabase.NewDatabaseAdminClient(ctx, option.WithUserAgent(models.GlobalConfig.UserAgent))
+	if err != nil {
+		return fmt.Errorf("failed to create Spanner admin client: %w", err)
+	}
+	storage.adminClient = adminClient
+
 	if models.GlobalConfig.Otel.Traces.Enabled {
 		if models.GlobalConfig.Otel.Traces.SamplingRatio < 0 || models.GlobalConfig.Otel.Traces.SamplingRatio > 1 {
 			return fmt.Errorf("sampling ratio for Otel Traces should be between 0 and 1]")
@@ -158,6 +167,9 @@ func (s Storage) Close() {
 	for _, v := range s.spannerClient {
 		v.Close()
 	}
+	if s.adminClient != nil {
+		s.adminClient.Close()
+	}
 	logger.Debug("Connection shutted down")
 }
 
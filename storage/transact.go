@@ -0,0 +1,97 @@
+// Copyright 2020 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package storage
+
+import (
+	"context"
+
+	"cloud.google.com/go/spanner"
+	"github.com/cloudspannerecosystem/dynamodb-adapter/models"
+	otelgo "github.com/cloudspannerecosystem/dynamodb-adapter/otel"
+	"github.com/cloudspannerecosystem/dynamodb-adapter/pkg/errors"
+	"github.com/cloudspannerecosystem/dynamodb-adapter/utils"
+	"google.golang.org/grpc/codes"
+)
+
+const SpannerTransactGetItemsAnnotation = "Calling SpannerTransactGetItems Method"
+
+// TransactGet - one Get of a TransactGetItems, SortKey is nil when the table has no sort key and all the
+// columns are read when ProjectionCols is empty
+type TransactGet struct {
+	Table          string
+	PartitionKey   interface{}
+	SortKey        interface{}
+	ProjectionCols []string
+}
+
+// SpannerTransactGetItems - reads the items of a TransactGetItems in one read-only transaction, so that
+// all of them are read at the same timestamp, also across tables. The items are returned in the order of
+// the gets, nil for an item which does not exist. The expiry of the items is checked at the read timestamp.
+func (s Storage) SpannerTransactGetItems(ctx context.Context, gets []TransactGet) ([]map[string]interface{}, error) {
+	otelgo.AddAnnotation(ctx, SpannerTransactGetItemsAnnotation)
+	txn := s.getSpannerClient("").ReadOnlyTransaction()
+	defer txn.Close()
+
+	items := make([]map[string]interface{}, len(gets))
+	for i, get := range gets {
+		spannerTable := utils.ChangeTableNameForSpanner(get.Table)
+		projectionCols := get.ProjectionCols
+		if len(projectionCols) == 0 {
+			var ok bool
+			projectionCols, ok = models.TableColumnMap[spannerTable]
+			if !ok {
+				return nil, errors.New("ResourceNotFoundException", get.Table)
+			}
+		}
+		projectionCols, ttlColumn := ttlProjection(spannerTable, projectionCols)
+		key := spanner.Key{get.PartitionKey}
+		if get.SortKey != nil {
+			key = append(key, get.SortKey)
+		}
+		row, err := txn.ReadRow(ctx, spannerTable, key, projectionCols)
+		if spanner.ErrCode(err) == codes.NotFound {
+			continue
+		}
+		if err != nil {
+			return nil, transactGetError(err)
+		}
+		item, _, err := parseRow(row, spannerTable)
+		if err != nil {
+			return nil, err
+		}
+		// the transaction has its timestamp once the first read is done
+		readTime, err := txn.Timestamp()
+		if err != nil {
+			return nil, errors.New("InternalServerError", err)
+		}
+		if isExpired(spannerTable, item, readTime) {
+			continue
+		}
+		if ttlColumn != "" {
+			delete(item, ttlColumn)
+		}
+		items[i] = item
+	}
+	return items, nil
+}
+
+// transactGetError - converts the error of a read of a TransactGetItems. A read-only transaction never
+// aborts, so a read does not conflict with the concurrent writes.
+func transactGetError(err error) error {
+	if isRetryableSpannerError(err) {
+		return errors.New("InternalServerError", err)
+	}
+	return errors.New("ValidationException", err)
+}
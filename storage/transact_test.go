@@ -0,0 +1,40 @@
+// Copyright 2021
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package storage
+
+import (
+	"testing"
+
+	"github.com/cloudspannerecosystem/dynamodb-adapter/pkg/errors"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+func Test_transactGetError(t *testing.T) {
+	tests := []struct {
+		name     string
+		err      error
+		wantCode string
+	}{
+		{"transient", status.Error(codes.Unavailable, "unavailable"), "InternalServerError"},
+		{"wrong key type", status.Error(codes.InvalidArgument, "Invalid value for column customer_id"), "ValidationException"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := transactGetError(tt.err).(*errors.Error); got.ErrorCode != tt.wantCode {
+				t.Errorf("transactGetError() = %v, want %v", got.ErrorCode, tt.wantCode)
+			}
+		})
+	}
+}
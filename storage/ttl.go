@@ -0,0 +1,200 @@
+// Copyright 2020 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package storage
+
+import (
+	"context"
+	"fmt"
+	"math/big"
+	"time"
+
+	"cloud.google.com/go/spanner"
+	"github.com/cloudspannerecosystem/dynamodb-adapter/models"
+	otelgo "github.com/cloudspannerecosystem/dynamodb-adapter/otel"
+	"github.com/cloudspannerecosystem/dynamodb-adapter/pkg/errors"
+	"github.com/cloudspannerecosystem/dynamodb-adapter/utils"
+)
+
+const (
+	SpannerDeleteExpiredAnnotation = "Calling SpannerDeleteExpired Method"
+	SpannerAcquireLeaseAnnotation  = "Calling SpannerAcquireLease Method"
+)
+
+// ttlMaxAge - items which expired longer ago are never deleted nor hidden, as in DynamoDB,
+// so that a wrongly interpreted attribute (e.g. milliseconds) does not remove the data
+const ttlMaxAge = 5 * 365 * 24 * time.Hour
+
+// ttlSeconds - converts a parsed TimeToLive attribute value into epoch seconds
+func ttlSeconds(v interface{}) (float64, bool) {
+	switch n := v.(type) {
+	case float64:
+		return n, true
+	case int64:
+		return float64(n), true
+	case big.Rat:
+		f, _ := n.Float64()
+		return f, true
+	case *big.Rat:
+		f, _ := n.Float64()
+		return f, true
+	}
+	return 0, false
+}
+
+// TTLColumn - returns the TimeToLive column of the table when its expired items are hidden, the column
+// has to be read with the items for isExpired. It is empty when no item is hidden.
+func TTLColumn(spannerTable string) string {
+	if models.GlobalConfig == nil || !models.GlobalConfig.TTL.HideExpiredItems {
+		return ""
+	}
+	return models.DbConfigMap[spannerTable].TTLAttribute
+}
+
+// NotExpiredCondition - returns the SQL condition matching the items of the table isExpired does not hide,
+// its parameters are added to params. It is empty when no item is hidden, or when the TimeToLive column is
+// not a number column. Queries filter the expired items in SQL, so that they are not read at all.
+func NotExpiredCondition(spannerTable string, now time.Time, params map[string]interface{}) string {
+	ttlColumn := TTLColumn(spannerTable)
+	if ttlColumn == "" || models.TableDDL[spannerTable][ttlColumn] != "N" {
+		return ""
+	}
+	params["ttlNow"] = float64(now.Unix())
+	params["ttlOldest"] = float64(now.Add(-ttlMaxAge).Unix())
+	return fmt.Sprintf("`%s` IS NULL OR `%s` >= @ttlNow OR `%s` < @ttlOldest", ttlColumn, ttlColumn, ttlColumn)
+}
+
+// isExpired - checks whether the item has expired according to the TimeToLive attribute of the table.
+// It is only used to hide the items which have not been deleted by the sweeper yet, when configured.
+func isExpired(spannerTable string, row map[string]interface{}, now time.Time) bool {
+	ttlColumn := TTLColumn(spannerTable)
+	if ttlColumn == "" {
+		return false
+	}
+	expiresAt, ok := ttlSeconds(row[ttlColumn])
+	if !ok {
+		return false
+	}
+	return expiresAt < float64(now.Unix()) && expiresAt >= float64(now.Add(-ttlMaxAge).Unix())
+}
+
+// ttlProjection - adds the TimeToLive column of the table to the projection when expired items are
+// hidden, and returns the column when it has to be removed from the result again
+func ttlProjection(spannerTable string, projectionCols []string) ([]string, string) {
+	ttlColumn := TTLColumn(spannerTable)
+	if ttlColumn == "" {
+		return projectionCols, ""
+	}
+	for _, col := range projectionCols {
+		if col == ttlColumn {
+			return projectionCols, ""
+		}
+	}
+	cols := make([]string, 0, len(projectionCols)+1)
+	cols = append(cols, projectionCols...)
+	return append(cols, ttlColumn), ttlColumn
+}
+
+// SpannerDeleteExpired - deletes up to batchSize expired items of the table in one transaction and
+// returns the number of deleted items. The keys are read in the same transaction, so that items
+// whose TimeToLive attribute has just been updated are not deleted.
+func (s Storage) SpannerDeleteExpired(ctx context.Context, tableName, ttlColumn string, now time.Time, batchSize int) (int, error) {
+	otelgo.AddAnnotation(ctx, SpannerDeleteExpiredAnnotation)
+	spannerTable := utils.ChangeTableNameForSpanner(tableName)
+	tableConf, ok := models.DbConfigMap[spannerTable]
+	if !ok {
+		return 0, errors.New("ResourceNotFoundException", tableName)
+	}
+	keyColumns := "`" + tableConf.PartitionKey + "`"
+	if tableConf.SortKey != "" {
+		keyColumns += ", `" + tableConf.SortKey + "`"
+	}
+	stmt := spanner.Statement{
+		SQL: fmt.Sprintf("SELECT %s FROM `%s` WHERE `%s` < @now AND `%s` >= @oldest LIMIT @batchSize",
+			keyColumns, spannerTable, ttlColumn, ttlColumn),
+		Params: map[string]interface{}{
+			"now":       float64(now.Unix()),
+			"oldest":    float64(now.Add(-ttlMaxAge).Unix()),
+			"batchSize": int64(batchSize),
+		},
+	}
+
+	deleted := 0
+	_, err := s.getSpannerClient(spannerTable).ReadWriteTransaction(ctx, func(ctx context.Context, txn *spanner.ReadWriteTransaction) error {
+		deleted = 0
+		var keys []spanner.Key
+		err := txn.Query(ctx, stmt).Do(func(r *spanner.Row) error {
+			row, _, err := parseRow(r, spannerTable)
+			if err != nil {
+				return err
+			}
+			key := spanner.Key{row[tableConf.PartitionKey]}
+			if tableConf.SortKey != "" {
+				key = append(key, row[tableConf.SortKey])
+			}
+			keys = append(keys, key)
+			return nil
+		})
+		if err != nil || len(keys) == 0 {
+			return err
+		}
+		deleted = len(keys)
+		return txn.BufferWrite([]*spanner.Mutation{spanner.Delete(spannerTable, spanner.KeySetFromKeys(keys...))})
+	})
+	if err != nil {
+		return 0, err
+	}
+	return deleted, nil
+}
+
+// SpannerAcquireLease - acquires or renews the named lease in dynamodb_adapter_lease for the owner.
+// The lease is granted when it is free, expired or already held by the owner. The Spanner server
+// time is used, so that the clocks of the adapter instances do not need to be in sync.
+func (s Storage) SpannerAcquireLease(ctx context.Context, name, owner string, duration time.Duration) (bool, error) {
+	otelgo.AddAnnotation(ctx, SpannerAcquireLeaseAnnotation)
+	stmt := spanner.Statement{
+		SQL: "SELECT CURRENT_TIMESTAMP() AS now, " +
+			"(SELECT owner FROM dynamodb_adapter_lease WHERE name = @name) AS owner, " +
+			"(SELECT expiresAt FROM dynamodb_adapter_lease WHERE name = @name) AS expiresAt",
+		Params: map[string]interface{}{"name": name},
+	}
+	acquired := false
+	_, err := s.getSpannerClient("dynamodb_adapter_lease").ReadWriteTransaction(ctx, func(ctx context.Context, txn *spanner.ReadWriteTransaction) error {
+		acquired = false
+		itr := txn.Query(ctx, stmt)
+		defer itr.Stop()
+		row, err := itr.Next()
+		if err != nil {
+			return err
+		}
+		var now time.Time
+		var holder spanner.NullString
+		var expiresAt spanner.NullTime
+		if err := row.Columns(&now, &holder, &expiresAt); err != nil {
+			return err
+		}
+		if holder.Valid && holder.StringVal != owner && expiresAt.Valid && expiresAt.Time.After(now) {
+			return nil
+		}
+		acquired = true
+		return txn.BufferWrite([]*spanner.Mutation{
+			spanner.InsertOrUpdate("dynamodb_adapter_lease", []string{"name", "owner", "expiresAt"},
+				[]interface{}{name, owner, now.Add(duration)}),
+		})
+	})
+	if err != nil {
+		return false, err
+	}
+	return acquired, nil
+}
@@ -0,0 +1,123 @@
+// Copyright 2021
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package storage
+
+import (
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/cloudspannerecosystem/dynamodb-adapter/models"
+)
+
+func setTTLConfig(t *testing.T, hideExpired bool) {
+	oldConfig, oldDbConfigMap := models.GlobalConfig, models.DbConfigMap
+	t.Cleanup(func() {
+		models.GlobalConfig, models.DbConfigMap = oldConfig, oldDbConfigMap
+	})
+	models.GlobalConfig = &models.Config{TTL: models.TTLConfig{HideExpiredItems: hideExpired}}
+	models.DbConfigMap = map[string]models.TableConfig{
+		"sessions": {PartitionKey: "id", TTLAttribute: "expires_at"},
+		"users":    {PartitionKey: "id"},
+	}
+}
+
+func Test_isExpired(t *testing.T) {
+	now := time.Unix(1700000000, 0)
+	tests := []struct {
+		name        string
+		table       string
+		hideExpired bool
+		row         map[string]interface{}
+		want        bool
+	}{
+		{"expired float", "sessions", true, map[string]interface{}{"expires_at": float64(1699999999)}, true},
+		{"expired int", "sessions", true, map[string]interface{}{"expires_at": int64(1699999999)}, true},
+		{"not expired", "sessions", true, map[string]interface{}{"expires_at": float64(1700000001)}, false},
+		{"expired more than five years ago", "sessions", true, map[string]interface{}{"expires_at": float64(1500000000)}, false},
+		{"not a number", "sessions", true, map[string]interface{}{"expires_at": "1699999999"}, false},
+		{"missing attribute", "sessions", true, map[string]interface{}{"id": "a"}, false},
+		{"hiding disabled", "sessions", false, map[string]interface{}{"expires_at": float64(1699999999)}, false},
+		{"ttl not enabled", "users", true, map[string]interface{}{"expires_at": float64(1699999999)}, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			setTTLConfig(t, tt.hideExpired)
+			if got := isExpired(tt.table, tt.row, now); got != tt.want {
+				t.Errorf("isExpired() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestTTLColumn(t *testing.T) {
+	setTTLConfig(t, true)
+	if got := TTLColumn("sessions"); got != "expires_at" {
+		t.Errorf("TTLColumn() = %v, want expires_at", got)
+	}
+	if got := TTLColumn("users"); got != "" {
+		t.Errorf("TTLColumn() = %v, want none", got)
+	}
+	setTTLConfig(t, false)
+	if got := TTLColumn("sessions"); got != "" {
+		t.Errorf("TTLColumn() = %v, want none", got)
+	}
+}
+
+func TestNotExpiredCondition(t *testing.T) {
+	setTTLConfig(t, true)
+	oldTableDDL := models.TableDDL
+	t.Cleanup(func() { models.TableDDL = oldTableDDL })
+	models.TableDDL = map[string]map[string]string{
+		"sessions": {"id": "S", "expires_at": "N"},
+		"users":    {"id": "S"},
+	}
+	now := time.Unix(1700000000, 0)
+
+	params := map[string]interface{}{}
+	got := NotExpiredCondition("sessions", now, params)
+	if want := "`expires_at` IS NULL OR `expires_at` >= @ttlNow OR `expires_at` < @ttlOldest"; got != want {
+		t.Errorf("NotExpiredCondition() = %v, want %v", got, want)
+	}
+	wantParams := map[string]interface{}{"ttlNow": float64(1700000000), "ttlOldest": float64(now.Add(-ttlMaxAge).Unix())}
+	if !reflect.DeepEqual(params, wantParams) {
+		t.Errorf("NotExpiredCondition() params = %v, want %v", params, wantParams)
+	}
+
+	params = map[string]interface{}{}
+	if got := NotExpiredCondition("users", now, params); got != "" || len(params) != 0 {
+		t.Errorf("NotExpiredCondition() = %v, %v, want none", got, params)
+	}
+	models.TableDDL["sessions"]["expires_at"] = "S"
+	if got := NotExpiredCondition("sessions", now, params); got != "" || len(params) != 0 {
+		t.Errorf("NotExpiredCondition() = %v, %v, want none", got, params)
+	}
+}
+
+func Test_ttlProjection(t *testing.T) {
+	setTTLConfig(t, true)
+
+	cols, added := ttlProjection("sessions", []string{"id"})
+	if !reflect.DeepEqual(cols, []string{"id", "expires_at"}) || added != "expires_at" {
+		t.Errorf("ttlProjection() = %v, %v", cols, added)
+	}
+	cols, added = ttlProjection("sessions", []string{"id", "expires_at"})
+	if !reflect.DeepEqual(cols, []string{"id", "expires_at"}) || added != "" {
+		t.Errorf("ttlProjection() = %v, %v", cols, added)
+	}
+	cols, added = ttlProjection("users", []string{"id"})
+	if !reflect.DeepEqual(cols, []string{"id"}) || added != "" {
+		t.Errorf("ttlProjection() = %v, %v", cols, added)
+	}
+}
@@ -1,6 +1,7 @@
 package translator
 
 import (
+	"slices"
 	"strings"
 
 	"github.com/antlr4-go/antlr/v4"
@@ -125,3 +126,15 @@ func (t *Translator) ToSpannerSelect(query string) (*SelectQueryMap, error) {
 	}
 	return selectQueryMap, nil
 }
+
+// AddProjectionColumn adds the column to the projection of the query, unless the query selects all the
+// columns or the column already, and forms the Spanner query again
+func (s *SelectQueryMap) AddProjectionColumn(column string) error {
+	if len(s.ProjectionColumns) == 0 || slices.Contains(s.ProjectionColumns, column) {
+		return nil
+	}
+	s.ProjectionColumns = append(s.ProjectionColumns, column)
+	var err error
+	s.SpannerQuery, err = formSpannerSelectQuery(s, s.Where)
+	return err
+}
@@ -47,3 +47,21 @@ func TestToSpannerSelect(t *testing.T) {
 	// Assertions for OFFSET clause
 	assert.Equal(t, expectedOffset, response.Offset)
 }
+
+func TestAddProjectionColumn(t *testing.T) {
+	translator := Translator{}
+	response, err := translator.ToSpannerSelect("SELECT age, address FROM employee WHERE age > 30;")
+	assert.NoError(t, err)
+
+	assert.NoError(t, response.AddProjectionColumn("expires_at"))
+	assert.Equal(t, []string{"age", "address", "expires_at"}, response.ProjectionColumns)
+	assert.Contains(t, response.SpannerQuery, "SELECT age, address, expires_at FROM employee WHERE")
+
+	assert.NoError(t, response.AddProjectionColumn("age"))
+	assert.Equal(t, []string{"age", "address", "expires_at"}, response.ProjectionColumns)
+
+	response, err = translator.ToSpannerSelect("SELECT * FROM employee;")
+	assert.NoError(t, err)
+	assert.NoError(t, response.AddProjectionColumn("expires_at"))
+	assert.Empty(t, response.ProjectionColumns)
+}
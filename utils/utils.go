@@ -17,15 +17,15 @@ package utils
 import (
 	"encoding/base64"
 	"encoding/json"
-	"fmt"
 	"log"
 	"regexp"
+	"slices"
 	"strconv"
 	"strings"
 
-	"github.com/antonmedv/expr"
 	"github.com/cloudspannerecosystem/dynamodb-adapter/models"
 	"github.com/cloudspannerecosystem/dynamodb-adapter/pkg/errors"
+	"github.com/cloudspannerecosystem/dynamodb-adapter/pkg/expression"
 	"github.com/cloudspannerecosystem/dynamodb-adapter/pkg/logger"
 )
 
@@ -34,17 +34,6 @@ var base64Regexp = regexp.MustCompile("^([A-Za-z0-9+/]{4})*([A-Za-z0-9+/]{3}=|[A
 
 var CreateConditionExpressionFunc = CreateConditionExpression
 
-// GetFieldNameFromConditionalExpression returns the field name from conditional expression
-func GetFieldNameFromConditionalExpression(conditionalExpression string) string {
-	if strings.Contains(conditionalExpression, "attribute_exists") {
-		return GetStringInBetween(conditionalExpression, "(", ")")
-	}
-	if strings.Contains(conditionalExpression, "attribute_not_exists") {
-		return GetStringInBetween(conditionalExpression, "(", ")")
-	}
-	return conditionalExpression
-}
-
 // GetStringInBetween Returns empty string if no start string found
 func GetStringInBetween(str string, start string, end string) (result string) {
 	s := strings.Index(str, start)
@@ -59,220 +48,37 @@ func GetStringInBetween(str string, start string, end string) (result string) {
 	return str[s:e]
 }
 
-// stripWrappingParens removes unnecessary wrapping or unmatched parentheses from a string.
-//
-// - If the string is wrapped in balanced parentheses, it removes the outermost pair(s).
-// - If there are unmatched leading or trailing parentheses, it removes them until balanced.
-// - It preserves valid, balanced parentheses inside the string.
-//
-// Examples:
-//
-//	stripWrappingParens("(foo)")            // "foo"
-//	stripWrappingParens("((foo))")          // "foo"
-//	stripWrappingParens("(foo")             // "foo"
-//	stripWrappingParens("foo)")             // "foo"
-//	stripWrappingParens("((foo)")           // "foo"
-//	stripWrappingParens("(size(bar))")      // "size(bar)"
-//	stripWrappingParens("(size(bar)")       // "size(bar)"
-//	stripWrappingParens("size(bar))")       // "size(bar)"
-//	stripWrappingParens("size(bar)")        // "size(bar)"
-//	stripWrappingParens("foo(bar(baz))")    // "foo(bar(baz))"
-//	stripWrappingParens("((foo(bar)))")     // "foo(bar)"
-func stripWrappingParens(s string) string {
-	s = strings.TrimSpace(s)
-	// Remove balanced wrapping parentheses
-	for {
-		changed := false
-		// Remove balanced outermost parens
-		for strings.HasPrefix(s, "(") && strings.HasSuffix(s, ")") && len(s) > 1 {
-			inner := s[1 : len(s)-1]
-			if countParens(inner) == 0 {
-				s = strings.TrimSpace(inner)
-				changed = true
-			} else {
-				break
-			}
-		}
-		// Remove unmatched leading paren
-		if strings.HasPrefix(s, "(") && countParens(s) > 0 {
-			s = strings.TrimSpace(s[1:])
-			changed = true
-		}
-		// Remove unmatched trailing paren
-		if strings.HasSuffix(s, ")") && countParens(s) < 0 {
-			s = strings.TrimSpace(s[:len(s)-1])
-			changed = true
-		}
-		if !changed {
-			break
-		}
-	}
-	return s
-}
-
-// Helper: returns open parens minus close parens
-func countParens(s string) int {
-	open := 0
-	close := 0
-	for _, c := range s {
-		if c == '(' {
-			open++
-		} else if c == ')' {
-			close++
-		}
-	}
-	return open - close
-}
-
-// cleanExpressionSpacing removes unnecessary spaces between function names and their opening parenthesis
-// in a DynamoDB expression, while preserving spaces for logical operators like AND, OR, etc. This is needed
-// since logic below splits tokens by spaces.
-//
-// For example, it converts:
-//
-//	"attribute_exists (foo) AND begins_with (bar, :val)"
-//
-// to:
-//
-//	"attribute_exists(foo) AND begins_with(bar, :val)"
-//
-// Logical operators (AND, OR, etc.) are not affected, so their spacing remains
-func cleanExpressionSpacing(expression string) string {
-	// Regex to find words followed by any whitespace (space, tab, etc.) and (
-	re := regexp.MustCompile(`\b(\w+)\s+\(`)
-
-	// List of logical operators to exclude
-	logicalOps := map[string]bool{
-		"AND": true,
-		"and": true,
-		"OR":  true,
-		"or":  true,
-	}
-
-	// Use ReplaceAllStringFunc to process each match
-	return re.ReplaceAllStringFunc(expression, func(m string) string {
-		// Extract the word before whitespace+(
-		// m is like "attribute_exists (" or "attribute_exists\t("
-		parts := regexp.MustCompile(`\s+`).Split(m, 2)
-		if len(parts) != 2 {
-			return m
-		}
-		word := parts[0]
-		if logicalOps[word] {
-			// If logical operator, don't change spacing
-			return m
-		}
-		// Otherwise remove all whitespace before '('
-		return word + "("
-	})
-}
-
-// CreateConditionExpression - create evelute condition from condition
-func CreateConditionExpression(condtionExpression string, expressionAttr map[string]interface{}) (*models.Eval, error) {
+// CreateConditionExpression - parses the condition expression, names are the ExpressionAttributeNames
+// of the request with the column names of Spanner
+func CreateConditionExpression(condtionExpression string, names map[string]string, expressionAttr map[string]interface{}) (*models.Eval, error) {
 	if condtionExpression == "" {
 		e := new(models.Eval)
 		return e, nil
 	}
 	logger.Debug("Original condition expression:", condtionExpression)
-	condtionExpression = strings.TrimSpace(condtionExpression)
-	condtionExpression = strings.ReplaceAll(condtionExpression, "( ", "(")
-	condtionExpression = strings.ReplaceAll(condtionExpression, " )", ")")
-	condtionExpression = strings.ReplaceAll(condtionExpression, "NOT ", "!")
-	condtionExpression = cleanExpressionSpacing(condtionExpression)
-	tokens := strings.Split(condtionExpression, " ")
-	sb := strings.Builder{}
-	evalTokens := []string{}
-	cols := []string{}
-	ts := []string{}
-	var err error
-	for i := 0; i < len(tokens); i++ {
-		token := tokens[i]
-		if i%2 == 0 {
-			isNegated := false
-			if strings.HasPrefix(token, "!") {
-				isNegated = true
-				token = strings.TrimPrefix(token, "!")
-			}
-			token = stripWrappingParens(token)
-			if strings.Contains(token, ":") {
-				v, ok := expressionAttr[token]
-				if !ok {
-					return nil, errors.New("ResourceNotFoundException", expressionAttr, token)
-				}
-				str := fmt.Sprint(v)
-				_, ok = v.(string)
-				if ok {
-					str = "\"" + str + "\""
-				}
-				switch v.(type) { // TODO: Support timestamp and big.rat
-				case float64:
-					str = fmt.Sprintf("%f", v)
-				case int64:
-					str = fmt.Sprintf("%d", v)
-				case []interface{}:
-					// Handle lists by converting them to JSON for easier evaluation
-					listBytes, err := json.Marshal(v)
-					if err != nil {
-						return nil, errors.New("InvalidListException", err.Error(), token)
-					}
-					str = string(listBytes)
-				}
-				sb.WriteString(str)
-				sb.WriteString(" ")
-				continue
-			}
-
-			t := "TOKEN" + strconv.Itoa(i)
-			col := GetFieldNameFromConditionalExpression(token)
-			if isNegated {
-				sb.WriteString("!(" + t + ") ")
-			} else {
-				sb.WriteString(t + " ")
-			}
-			evalTokens = append(evalTokens, token)
-			cols = append(cols, col)
-			ts = append(ts, t)
-		} else {
-			sb.WriteString(token)
-			sb.WriteString(" ")
-		}
-	}
-	e := new(models.Eval)
-	str := sb.String()
-	str = strings.ReplaceAll(str, " = ", " == ")
-	str = strings.ReplaceAll(str, " OR ", " || ")
-	str = strings.ReplaceAll(str, " or ", " || ")
-	str = strings.ReplaceAll(str, " and ", " && ")
-	str = strings.ReplaceAll(str, " AND ", " && ")
-	str = strings.ReplaceAll(str, " <> ", " != ")
-
-	e.Cond, err = expr.Compile(str)
+	cond, err := expression.ParseCondition("ConditionExpression", condtionExpression, names, expressionAttr)
 	if err != nil {
-		return nil, errors.New("ConditionalCheckFailedException", err.Error(), str)
+		return nil, errors.New("ValidationException", err)
+	}
+	e := &models.Eval{Cond: cond, ValueMap: expressionAttr}
+	for _, path := range expression.Paths(cond.Cond) {
+		e.Attributes = append(e.Attributes, path.String())
+		if !slices.Contains(e.Cols, path.Attribute()) {
+			e.Cols = append(e.Cols, path.Attribute())
+		}
 	}
-	e.Attributes = evalTokens
-	e.Cols = cols
-	e.Tokens = ts
-	e.ValueMap = make(map[string]interface{}, len(evalTokens))
 	return e, nil
 }
 
-// EvaluateExpression - evalute expression
-func EvaluateExpression(expression *models.Eval) (bool, error) {
-	if expression == nil || expression.Cond == nil {
+// EvaluateExpression - evaluates the condition against the item, maps and lists of the item are
+// plain Go maps and slices
+func EvaluateExpression(e *models.Eval, item map[string]interface{}) (bool, error) {
+	if e == nil || e.Cond == nil {
 		return true, nil
 	}
-	if expression.ValueMap == nil {
-		return false, nil
-	}
-
-	val, err := expr.Run(expression.Cond, expression.ValueMap)
+	status, err := e.Cond.Evaluate(item)
 	if err != nil {
-		return false, errors.New("ConditionalCheckFailedException", err.Error())
-	}
-	status, ok := val.(bool)
-	if !status || !ok {
-		return false, errors.New("ConditionalCheckFailedException")
+		return false, errors.New("ValidationException", err)
 	}
 	return status, nil
 }
@@ -21,8 +21,9 @@ import (
 
 	"github.com/tj/assert"
 
-	"github.com/antonmedv/expr"
 	"github.com/cloudspannerecosystem/dynamodb-adapter/models"
+	"github.com/cloudspannerecosystem/dynamodb-adapter/pkg/errors"
+	"github.com/cloudspannerecosystem/dynamodb-adapter/pkg/expression"
 )
 
 func TestGetStringInBetween(t *testing.T) {
@@ -42,116 +43,127 @@ func TestGetStringInBetween(t *testing.T) {
 	}
 }
 
-func TestGetFieldNameFromConditionalExpression(t *testing.T) {
-	tests := []struct {
-		testName, condExpr, want string
-	}{
-		{"Empty Value", "", ""},
-		{"Any String passed", "Any stirng", "Any stirng"},
-		{"String with attribute_exists ", "attribute_exists(name)", "name"},
-		{"String with attribute_not_exists", "attribute_not_exists(some_field)", "some_field"},
-	}
-
-	for _, tc := range tests {
-		got := GetFieldNameFromConditionalExpression(tc.condExpr)
-		assert.Equal(t, got, tc.want)
-	}
-}
-
 func TestCreateConditionExpression(t *testing.T) {
-	cond1, _ := expr.Compile(`TOKEN0 > "20" && TOKEN4 `)
+	values := map[string]interface{}{":val": "20"}
+	cond, _ := expression.ParseCondition("ConditionExpression", "age > :val AND (attribute_exists(c.d) OR age <> :val)", nil, values)
+	names := map[string]string{"#a": "age", "#d": "c-d"}
+	namedCond, _ := expression.ParseCondition("ConditionExpression", "#a > :val AND attribute_exists(#d)", names, values)
 
 	tests := []struct {
 		testName            string
 		conditionExpression string
+		names               map[string]string
 		attributeMap        map[string]interface{}
 		want                *models.Eval
+		wantErr             string
 	}{
 		{
 			"empty Conditonal Expression",
 			"",
 			nil,
+			nil,
 			new(models.Eval),
+			"",
 		},
 		{
 			"Attribute map not present",
 			"age > :val AND attribute_exists(c)",
 			nil,
 			nil,
+			nil,
+			"Invalid ConditionExpression: An expression attribute value used in expression is not defined; attribute value: :val\n",
 		},
 		{
 			"Conditonal Expression with attributeMap",
-			"age > :val AND attribute_exists(c)",
-			map[string]interface{}{":val": "20"},
+			"age > :val AND (attribute_exists(c.d) OR age <> :val)",
+			nil,
+			values,
 			&models.Eval{
-				Cond:       cond1,
-				Attributes: []string{"age", "attribute_exists(c)"},
+				Cond:       cond,
+				Attributes: []string{"age", "c.d", "age"},
 				Cols:       []string{"age", "c"},
-				Tokens:     []string{"TOKEN0", "TOKEN4"},
-				ValueMap:   make(map[string]interface{}),
+				ValueMap:   values,
 			},
+			"",
+		},
+		{
+			"Conditonal Expression with attribute names",
+			"#a > :val AND attribute_exists(#d)",
+			names,
+			values,
+			&models.Eval{
+				Cond:       namedCond,
+				Attributes: []string{"age", "c-d"},
+				Cols:       []string{"age", "c-d"},
+				ValueMap:   values,
+			},
+			"",
+		},
+		{
+			"Attribute name not present",
+			"#a > :val",
+			nil,
+			values,
+			nil,
+			"Invalid ConditionExpression: An expression attribute name used in the document path is not defined; attribute name: #a\n",
+		},
+		{
+			"Syntax error",
+			"age > :val AND",
+			nil,
+			values,
+			nil,
+			"Invalid ConditionExpression: Syntax error; token: \"<EOF>\", near: \"AND\"\n",
 		},
 	}
 
 	for _, tc := range tests {
-		got, _ := CreateConditionExpression(tc.conditionExpression, tc.attributeMap)
+		got, err := CreateConditionExpression(tc.conditionExpression, tc.names, tc.attributeMap)
 		assert.Equal(t, got, tc.want)
+		if tc.wantErr != "" {
+			assert.Equal(t, err.(*errors.Error).ErrorCode, "ValidationException")
+			assert.Equal(t, err.(*errors.Error).ErrorMessage, tc.wantErr)
+		}
 	}
 }
 
 func TestEvaluateExpression(t *testing.T) {
-	cond1, _ := expr.Compile(`TOKEN0 > "20" && TOKEN4 `)
+	cond, _ := CreateConditionExpression("age > :val AND attribute_exists(c)", nil, map[string]interface{}{":val": 20.0})
 	tests := []struct {
 		testName string
 		input    *models.Eval
+		item     map[string]interface{}
 		want     bool
 	}{
 		{
 			"No Input",
 			nil,
+			nil,
 			true,
 		},
 		{
 			"Cond is nil in input",
-			&models.Eval{
-				Cond:       nil,
-				Attributes: []string{"age", "attribute_exists(c)"},
-				Cols:       []string{"age", "c"},
-				Tokens:     []string{"TOKEN0", "TOKEN4"},
-				ValueMap:   make(map[string]interface{}),
-			},
+			new(models.Eval),
+			nil,
 			true,
 		},
 		{
-			"ValueMap is nil",
-			&models.Eval{
-				Cond:       cond1,
-				Attributes: []string{"age", "attribute_exists(c)"},
-				Cols:       []string{"age", "c"},
-				Tokens:     []string{"TOKEN0", "TOKEN4"},
-				ValueMap:   nil,
-			},
-			false,
+			"Condition met",
+			cond,
+			map[string]interface{}{"age": int64(30), "c": "x"},
+			true,
 		},
 		{
-			"Correct Params",
-			&models.Eval{
-				Cond:       cond1,
-				Attributes: []string{"age", "attribute_exists(c)"},
-				Cols:       []string{"age", "c"},
-				Tokens:     []string{"TOKEN0", "TOKEN4"},
-				ValueMap: map[string]interface{}{
-					"TOKEN0": "age",
-					"TOKEN4": true,
-				},
-			},
-			true,
+			"Condition not met",
+			cond,
+			map[string]interface{}{"age": int64(30)},
+			false,
 		},
 	}
 
-	// EvaluateExpression()
 	for _, tc := range tests {
-		got, _ := EvaluateExpression(tc.input)
+		got, err := EvaluateExpression(tc.input, tc.item)
+		assert.Equal(t, err, nil)
 		assert.Equal(t, got, tc.want)
 	}
 }
@@ -454,175 +466,3 @@ func TestTrimSingleQuotes(t *testing.T) {
 		})
 	}
 }
-func TestCleanExpressionSpacing(t *testing.T) {
-	tests := []struct {
-		name     string
-		input    string
-		expected string
-	}{
-		{
-			name:     "Removes space after function name",
-			input:    "attribute_exists (foo)",
-			expected: "attribute_exists(foo)",
-		},
-		{
-			name:     "Multiple functions and logical operators",
-			input:    "attribute_exists (foo) AND begins_with (bar, :val)",
-			expected: "attribute_exists(foo) AND begins_with(bar, :val)",
-		},
-		{
-			name:     "Logical operators not affected",
-			input:    "foo AND bar OR baz",
-			expected: "foo AND bar OR baz",
-		},
-		{
-			name:     "Lowercase logical operators not affected",
-			input:    "foo and bar or baz",
-			expected: "foo and bar or baz",
-		},
-		{
-			name:     "Mixed case logical operators not affected",
-			input:    "foo Or bar aNd baz",
-			expected: "foo Or bar aNd baz",
-		},
-		{
-			name:     "No space after function name",
-			input:    "attribute_exists(foo)",
-			expected: "attribute_exists(foo)",
-		},
-		{
-			name:     "Space after logical operator and function",
-			input:    "AND begins_with (bar, :val)",
-			expected: "AND begins_with(bar, :val)",
-		},
-		{
-			name:     "Function with multiple spaces before paren",
-			input:    "begins_with    (bar, :val)",
-			expected: "begins_with(bar, :val)",
-		},
-		{
-			name:     "Function with tab before paren",
-			input:    "begins_with\t(bar, :val)",
-			expected: "begins_with(bar, :val)",
-		},
-		{
-			name:     "Function with space and tab before paren",
-			input:    "begins_with \t(bar, :val)",
-			expected: "begins_with(bar, :val)",
-		},
-		{
-			name:     "No function, just text",
-			input:    "foo bar baz",
-			expected: "foo bar baz",
-		},
-		{
-			name:     "Function name is logical operator (should not remove space)",
-			input:    "AND (foo)",
-			expected: "AND (foo)",
-		},
-		{
-			name:     "Lowercase logical operator as function name (should not remove space)",
-			input:    "or (foo)",
-			expected: "or (foo)",
-		},
-		{
-			name:     "Function with underscore",
-			input:    "my_func (x)",
-			expected: "my_func(x)",
-		},
-		{
-			name:     "Function with digits",
-			input:    "func123 (x)",
-			expected: "func123(x)",
-		},
-	}
-
-	for _, tt := range tests {
-		t.Run(tt.name, func(t *testing.T) {
-			result := cleanExpressionSpacing(tt.input)
-			assert.Equal(t, tt.expected, result)
-		})
-	}
-}
-func TestStripWrappingParens(t *testing.T) {
-	tests := []struct {
-		input    string
-		expected string
-	}{
-		{"(foo)", "foo"},
-		{"((foo))", "foo"},
-		{"(foo", "foo"},
-		{"foo)", "foo"},
-		{"((foo)", "foo"},
-		{"(size(bar))", "size(bar)"},
-		{"(size(bar)", "size(bar)"},
-		{"size(bar))", "size(bar)"},
-		{"size(bar)", "size(bar)"},
-		{"foo(bar(baz))", "foo(bar(baz))"},
-		{"((foo(bar)))", "foo(bar)"},
-		{"(((foo)))", "foo"},
-		{"(foo(bar))", "foo(bar)"},
-		{"", ""},
-		{"()", ""},
-		{"((()))", ""},
-		{"(foo)   ", "foo"},
-		{"   (foo)", "foo"},
-		{"   (foo)   ", "foo"},
-		{"(((foo(bar(baz)))))", "foo(bar(baz))"},
-		{"(foo(bar(baz))", "foo(bar(baz))"},
-		{"foo(bar(baz)))", "foo(bar(baz))"},
-		{"((foo(bar(baz))))", "foo(bar(baz))"},
-		{"(foo(bar(baz)))))", "foo(bar(baz))"},
-		{"(((foo(bar(baz)))", "foo(bar(baz))"},
-		{"(foo(bar(baz)) extra)", "foo(bar(baz)) extra"},
-		{"(foo)bar", "(foo)bar"},
-		{"foo(bar)", "foo(bar)"},
-	}
-
-	for _, tt := range tests {
-		t.Run(tt.input, func(t *testing.T) {
-			result := stripWrappingParens(tt.input)
-			if result != tt.expected {
-				t.Errorf("stripWrappingParens(%q) = %q; want %q", tt.input, result, tt.expected)
-			}
-		})
-	}
-}
-func TestCountParens(t *testing.T) {
-	tests := []struct {
-		input    string
-		expected int
-	}{
-		{"", 0},
-		{"()", 0},
-		{"((", 2},
-		{"))", -2},
-		{"(()", 1},
-		{"())", -1},
-		{"(foo)", 0},
-		{"((foo)", 1},
-		{"(foo))", -1},
-		{"foo(bar(baz))", 0},
-		{"foo(bar(baz)", 1},
-		{"foo)bar(baz(", 1},
-		{"((foo(bar)))", 0},
-		{"(((foo)))", 0},
-		{"(foo(bar(baz)) extra)", 0},
-		{"(foo)bar", 0},
-		{"foo(bar)", 0},
-		{"(((((", 5},
-		{")))))", -5},
-		{"(()())", 0},
-		{"(()", 1},
-		{"())", -1},
-	}
-
-	for _, tt := range tests {
-		t.Run(tt.input, func(t *testing.T) {
-			result := countParens(tt.input)
-			if result != tt.expected {
-				t.Errorf("countParens(%q) = %d; want %d", tt.input, result, tt.expected)
-			}
-		})
-	}
-}